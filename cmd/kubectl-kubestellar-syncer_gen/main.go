@@ -38,6 +38,15 @@ var (
 
 	# Directly apply the manifest
 	%[1]s syncer-gen <name> --syncer-image <kubestellar-syncer-image> -o - | KUBECONFIG=<a-physical-cluster-kubeconfig> kubectl apply -f -
+
+	# Output a Kustomize base that pulls the image from a mirror and runs the syncer on tainted edge nodes
+	%[1]s syncer-gen <name> --syncer-image <kubestellar-syncer-image> --image-registry <mirror-registry> --image-pull-secret <pull-secret> \
+	  --requests cpu=100m,memory=128Mi --toleration node-role.kubernetes.io/edge:NoSchedule --output-format kustomize -o kubestellar-syncer/
+`
+
+	rotateExample = `
+	# Create a new token for the syncer of a SyncTarget and update the syncer's Secret on the physical cluster
+	%[1]s syncer-gen rotate <name> -o - | KUBECONFIG=<a-physical-cluster-kubeconfig> kubectl apply -f -
 `
 )
 
@@ -51,6 +60,7 @@ func syncerGenCommand() *cobra.Command {
 		Short:        "Create service account and RBAC permissions in the workspace in kcp for Edge MC. Output a manifest to deploy a syncer in a physical cluster.",
 		Example:      fmt.Sprintf(syncerGenExample, "kubectl kubestellar"),
 		SilenceUsage: true,
+		Args:         cobra.ArbitraryArgs,
 		RunE: func(c *cobra.Command, args []string) error {
 			if len(args) != 1 {
 				return c.Help()
@@ -69,6 +79,7 @@ func syncerGenCommand() *cobra.Command {
 	}
 
 	options.BindFlags(cmd)
+	cmd.AddCommand(rotateCommand())
 
 	// setup klog
 	fs := goflags.NewFlagSet("klog", goflags.PanicOnError)
@@ -84,6 +95,36 @@ func syncerGenCommand() *cobra.Command {
 	return cmd
 }
 
+func rotateCommand() *cobra.Command {
+	options := plugin.NewRotateOptions(genericclioptions.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr})
+
+	cmd := &cobra.Command{
+		Use:          "rotate <name> -o <output-file>",
+		Short:        "Create a new token for the syncer of a SyncTarget. Output a manifest of the syncer's Secret with it for the physical cluster.",
+		Example:      fmt.Sprintf(rotateExample, "kubectl kubestellar"),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return c.Help()
+			}
+
+			if err := options.Complete(args); err != nil {
+				return err
+			}
+
+			if err := options.Validate(); err != nil {
+				return err
+			}
+
+			return options.Run(c.Context())
+		},
+	}
+
+	options.BindFlags(cmd)
+
+	return cmd
+}
+
 func main() {
 	cmd := syncerGenCommand()
 	if err := cmd.Execute(); err != nil {
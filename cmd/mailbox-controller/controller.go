@@ -30,6 +30,8 @@ import (
 	"k8s.io/klog/v2"
 
 	kcpcache "github.com/kcp-dev/apimachinery/v2/pkg/cache"
+	clusterdiscovery "github.com/kcp-dev/client-go/discovery"
+	clusterdynamic "github.com/kcp-dev/client-go/dynamic"
 	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
 	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
 	apisclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/cluster/typed/apis/v1alpha1"
@@ -62,6 +64,8 @@ type mbCtl struct {
 	workspaceScopedLister      tenancylisters.WorkspaceLister
 	workspaceScopedClient      tenancyclient.WorkspaceInterface
 	apiBindingClusterInterface apisclient.APIBindingClusterInterface
+	discoveryClusterInterface  clusterdiscovery.DiscoveryClusterInterface
+	dynamicClusterInterface    clusterdynamic.ClusterInterface
 	queue                      workqueue.RateLimitingInterface // of mailbox workspace Name
 }
 
@@ -74,6 +78,8 @@ func newMailboxController(ctx context.Context,
 	workspaceScopedPreInformer kcptenancyinformers.WorkspaceInformer,
 	workspaceScopedClient tenancyclient.WorkspaceInterface,
 	apiBindingClusterInterface apisclient.APIBindingClusterInterface,
+	discoveryClusterInterface clusterdiscovery.DiscoveryClusterInterface,
+	dynamicClusterInterface clusterdynamic.ClusterInterface,
 ) *mbCtl {
 	syncTargetClusterInformer := syncTargetClusterPreInformer.Informer()
 	syncTargetClusterInformer.AddIndexers(cache.Indexers{mbwsNameIndexKey: mbwsNameOfObj})
@@ -89,6 +95,8 @@ func newMailboxController(ctx context.Context,
 		workspaceScopedLister:      workspaceScopedPreInformer.Lister(),
 		workspaceScopedClient:      workspaceScopedClient,
 		apiBindingClusterInterface: apiBindingClusterInterface,
+		discoveryClusterInterface:  discoveryClusterInterface,
+		dynamicClusterInterface:    dynamicClusterInterface,
 		queue:                      workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "mailbox-controller"),
 	}
 
@@ -216,10 +224,18 @@ func (ctl *mbCtl) sync(ctx context.Context, refany any) bool {
 		return true
 	}
 	if syncTarget == nil || syncTarget.DeletionTimestamp != nil {
-		if workspace == nil || workspace.DeletionTimestamp != nil {
+		if workspace == nil {
 			logger.V(3).Info("Both SyncTarget and Workspace are absent or deleting, nothing to do", "mbwsName", mbwsName)
 			return false
 		}
+		// With the SyncTarget gone there is no syncer to remove its finalizers
+		if ctl.releaseSyncerFinalizers(ctx, workspace) {
+			return true
+		}
+		if workspace.DeletionTimestamp != nil {
+			logger.V(3).Info("SyncTarget is absent or deleting and Workspace is deleting, nothing more to do", "mbwsName", mbwsName)
+			return false
+		}
 		err := ctl.workspaceScopedClient.Delete(ctx, mbwsName, metav1.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &workspace.UID}})
 		if err == nil || k8sapierrors.IsNotFound(err) {
 			logger.V(2).Info("Deleted unwanted workspace", "mbwsName", mbwsName)
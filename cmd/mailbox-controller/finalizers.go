@@ -0,0 +1,93 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/klog/v2"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kubestellar/kubestellar/pkg/syncer/shared"
+)
+
+// releaseSyncerFinalizers removes, from every object in the given mailbox workspace,
+// the finalizer that the syncer of the workspace's SyncTarget puts on the objects that it downsyncs.
+// Once the SyncTarget is gone there is no syncer to remove them, and they would hold up
+// the deletion of those objects, and of the workspace, forever.
+// Returns whether to retry.
+func (ctl *mbCtl) releaseSyncerFinalizers(ctx context.Context, workspace *tenancyv1alpha1.Workspace) bool {
+	logger := klog.FromContext(ctx).WithValues("mbwsName", workspace.Name)
+	mbwsCluster := logicalcluster.Name(workspace.Spec.Cluster)
+	if mbwsCluster == "" {
+		logger.V(3).Info("Mailbox workspace does not have a Spec.Cluster, so it has no objects to release")
+		return false
+	}
+	syncTargetName := workspace.Annotations[SyncTargetNameAnnotationKey]
+	if syncTargetName == "" {
+		logger.Error(nil, "Mailbox workspace does not identify its SyncTarget, not releasing syncer finalizers")
+		return false
+	}
+	finalizer := shared.FinalizerForSyncTarget(syncTargetName)
+	logger = logger.WithValues("mbwsCluster", mbwsCluster, "finalizer", finalizer)
+	resourceLists, err := ctl.discoveryClusterInterface.Cluster(mbwsCluster.Path()).ServerPreferredResources()
+	retry := false
+	if err != nil {
+		if len(resourceLists) == 0 {
+			logger.Error(err, "Failed to discover resources in mailbox workspace")
+			return true
+		}
+		// Release what can be found now, and try again for the rest
+		logger.Error(err, "Failed to discover some resources in mailbox workspace")
+		retry = true
+	}
+	resourceLists = discovery.FilteredBy(discovery.SupportsAllVerbs{Verbs: []string{"list", "patch"}}, resourceLists)
+	gvrs, err := discovery.GroupVersionResources(resourceLists)
+	if err != nil {
+		logger.Error(err, "Failed to parse discovered resources in mailbox workspace")
+		return true
+	}
+	for gvr := range gvrs {
+		resourceClient := ctl.dynamicClusterInterface.Cluster(mbwsCluster.Path()).Resource(gvr)
+		list, err := resourceClient.List(ctx, metav1.ListOptions{})
+		if err != nil {
+			logger.Error(err, "Failed to list objects in mailbox workspace", "resource", gvr.String())
+			retry = true
+			continue
+		}
+		for _, obj := range list.Items {
+			patch, found := shared.RemoveFinalizerPatch(obj.GetFinalizers(), finalizer)
+			if !found {
+				continue
+			}
+			_, err := resourceClient.Namespace(obj.GetNamespace()).Patch(ctx, obj.GetName(), types.JSONPatchType, patch, metav1.PatchOptions{FieldManager: "mailbox-controller"})
+			if err != nil && !k8sapierrors.IsNotFound(err) {
+				logger.Error(err, "Failed to release object in mailbox workspace", "resource", gvr.String(), "namespace", obj.GetNamespace(), "name", obj.GetName())
+				retry = true
+				continue
+			}
+			logger.V(2).Info("Released object in mailbox workspace", "resource", gvr.String(), "namespace", obj.GetNamespace(), "name", obj.GetName())
+		}
+	}
+	return retry
+}
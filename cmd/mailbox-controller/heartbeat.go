@@ -0,0 +1,276 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	kcpcache "github.com/kcp-dev/apimachinery/v2/pkg/cache"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/util/conditions"
+	kcptenancyinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	tenancylisters "github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+	edgeclient "github.com/kubestellar/kubestellar/pkg/client/clientset/versioned/cluster/typed/edge/v1alpha1"
+	edgev1alpha1informers "github.com/kubestellar/kubestellar/pkg/client/informers/externalversions/edge/v1alpha1"
+	edgev1alpha1listers "github.com/kubestellar/kubestellar/pkg/client/listers/edge/v1alpha1"
+)
+
+// This identifies an index in the Workspace informer
+const mbwsClusterIndexKey = "mbwsCluster"
+
+// heartbeatMonitor relays the heartbeats that syncers write into the SyncerConfig objects
+// in their mailbox workspaces to the corresponding SyncTarget objects, and maintains
+// the HeartbeatHealthy condition of each SyncTarget that has ever had a heartbeat.
+// The capacity and allocatable resources that come with the latest heartbeat
+// are relayed too.
+// That condition goes False, with reason ErrorHeartbeatMissedReason, when the latest
+// heartbeat is older than the threshold.
+type heartbeatMonitor struct {
+	context                     context.Context
+	threshold                   time.Duration
+	syncTargetClusterInformer   kcpcache.ScopeableSharedIndexInformer
+	syncTargetIndexer           cache.Indexer
+	syncTargetClusterClient     edgeclient.SyncTargetClusterInterface
+	syncerConfigClusterInformer kcpcache.ScopeableSharedIndexInformer
+	syncerConfigClusterLister   edgev1alpha1listers.SyncerConfigClusterLister
+	workspaceScopedInformer     cache.SharedIndexInformer
+	workspaceScopedLister       tenancylisters.WorkspaceLister
+	workspaceIndexer            cache.Indexer
+	queue                       workqueue.RateLimitingInterface // of mailbox workspace Name
+}
+
+// newHeartbeatMonitor constructs a new heartbeat monitor.
+// Call this after newMailboxController, which adds the needed index to the SyncTarget informer.
+func newHeartbeatMonitor(ctx context.Context,
+	threshold time.Duration,
+	syncTargetClusterPreInformer edgev1alpha1informers.SyncTargetClusterInformer,
+	syncTargetClusterClient edgeclient.SyncTargetClusterInterface,
+	syncerConfigClusterPreInformer edgev1alpha1informers.SyncerConfigClusterInformer,
+	workspaceScopedPreInformer kcptenancyinformers.WorkspaceInformer,
+) *heartbeatMonitor {
+	syncTargetClusterInformer := syncTargetClusterPreInformer.Informer()
+	syncerConfigClusterInformer := syncerConfigClusterPreInformer.Informer()
+	workspacesInformer := workspaceScopedPreInformer.Informer()
+	workspacesInformer.AddIndexers(cache.Indexers{mbwsClusterIndexKey: mbwsClusterOfObj})
+
+	hbm := &heartbeatMonitor{
+		context:                     ctx,
+		threshold:                   threshold,
+		syncTargetClusterInformer:   syncTargetClusterInformer,
+		syncTargetIndexer:           syncTargetClusterInformer.GetIndexer(),
+		syncTargetClusterClient:     syncTargetClusterClient,
+		syncerConfigClusterInformer: syncerConfigClusterInformer,
+		syncerConfigClusterLister:   syncerConfigClusterPreInformer.Lister(),
+		workspaceScopedInformer:     workspacesInformer,
+		workspaceScopedLister:       workspaceScopedPreInformer.Lister(),
+		workspaceIndexer:            workspacesInformer.GetIndexer(),
+		queue:                       workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "heartbeat-monitor"),
+	}
+
+	syncTargetClusterInformer.AddEventHandler(hbm)
+	syncerConfigClusterInformer.AddEventHandler(hbm)
+	workspacesInformer.AddEventHandler(hbm)
+	return hbm
+}
+
+// Run animates the monitor, finishing and returning when the context of
+// the monitor is done.
+// Call this after the informers have been started.
+func (hbm *heartbeatMonitor) Run(concurrency int) {
+	ctx := hbm.context
+	logger := klog.FromContext(ctx).WithName("heartbeat-monitor")
+	ctx = klog.NewContext(ctx, logger)
+	doneCh := ctx.Done()
+	defer hbm.queue.ShutDown()
+	if !cache.WaitForNamedCacheSync("heartbeat-monitor", doneCh, hbm.syncTargetClusterInformer.HasSynced, hbm.syncerConfigClusterInformer.HasSynced, hbm.workspaceScopedInformer.HasSynced) {
+		logger.Error(nil, "Informer syncs not achieved")
+		return
+	}
+	logger.V(1).Info("Informers synced")
+	for worker := 0; worker < concurrency; worker++ {
+		go hbm.syncLoop(ctx, worker)
+	}
+	<-doneCh
+}
+
+func (hbm *heartbeatMonitor) OnAdd(obj any) {
+	hbm.enqueue(obj)
+}
+
+func (hbm *heartbeatMonitor) OnUpdate(oldObj, newObj any) {
+	if newObj != nil {
+		hbm.enqueue(newObj)
+	} else if oldObj != nil {
+		hbm.enqueue(oldObj)
+	}
+}
+
+func (hbm *heartbeatMonitor) OnDelete(obj any) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	hbm.enqueue(obj)
+}
+
+func (hbm *heartbeatMonitor) enqueue(obj any) {
+	logger := klog.FromContext(hbm.context)
+	switch typed := obj.(type) {
+	case *tenancyv1alpha1.Workspace:
+		hbm.queue.Add(typed.Name)
+	case *edgev1alpha1.SyncTarget:
+		hbm.queue.Add(mbwsNameOfSynctarget(typed))
+	case *edgev1alpha1.SyncerConfig:
+		cluster := logicalcluster.From(typed)
+		byIndex, err := hbm.workspaceIndexer.ByIndex(mbwsClusterIndexKey, cluster.String())
+		if err != nil {
+			logger.Error(err, "Failed to lookup Workspace by cluster", "cluster", cluster)
+			return
+		}
+		for _, wsAny := range byIndex {
+			ws := wsAny.(*tenancyv1alpha1.Workspace)
+			logger.V(4).Info("Enqueuing reference due to SyncerConfig", "wsName", ws.Name, "syncerConfigName", typed.Name)
+			hbm.queue.Add(ws.Name)
+		}
+	default:
+		logger.Error(nil, "Notified of object of unexpected type", "object", obj, "type", fmt.Sprintf("%T", obj))
+	}
+}
+
+func (hbm *heartbeatMonitor) syncLoop(ctx context.Context, worker int) {
+	logger := klog.FromContext(ctx).WithValues("worker", worker)
+	ctx = klog.NewContext(ctx, logger)
+	for {
+		ref, shutdown := hbm.queue.Get()
+		if shutdown {
+			logger.V(2).Info("Queue shutdown")
+			return
+		}
+		hbm.sync1(ctx, ref)
+	}
+}
+
+func (hbm *heartbeatMonitor) sync1(ctx context.Context, ref any) {
+	defer hbm.queue.Done(ref)
+	logger := klog.FromContext(ctx)
+	logger.V(4).Info("Dequeued reference", "ref", ref)
+	retry := hbm.sync(ctx, ref.(string))
+	if retry {
+		hbm.queue.AddRateLimited(ref)
+	} else {
+		hbm.queue.Forget(ref)
+	}
+}
+
+// sync brings the heartbeat fields of the SyncTarget for the given mailbox workspace up to date.
+// It returns whether to retry.
+func (hbm *heartbeatMonitor) sync(ctx context.Context, mbwsName string) bool {
+	logger := klog.FromContext(ctx).WithValues("mbwsName", mbwsName)
+	if len(strings.Split(mbwsName, wsNameSep)) != 2 {
+		return false
+	}
+	byIndex, err := hbm.syncTargetIndexer.ByIndex(mbwsNameIndexKey, mbwsName)
+	if err != nil {
+		logger.Error(err, "Failed to lookup SyncTargets by mailbox workspace name")
+		return false
+	}
+	if len(byIndex) == 0 {
+		logger.V(4).Info("No SyncTarget for mailbox workspace")
+		return false
+	}
+	syncTarget := byIndex[0].(*edgev1alpha1.SyncTarget)
+	if syncTarget.DeletionTimestamp != nil {
+		return false
+	}
+	heartbeat := syncTarget.Status.LastSyncerHeartbeatTime
+	var latestSyncerConfig *edgev1alpha1.SyncerConfig
+	workspace, err := hbm.workspaceScopedLister.Get(mbwsName)
+	if err != nil && !k8sapierrors.IsNotFound(err) {
+		logger.Error(err, "Unable to Get referenced Workspace")
+		return true
+	}
+	if err == nil && workspace.Spec.Cluster != "" {
+		syncerConfigs, err := hbm.syncerConfigClusterLister.Cluster(logicalcluster.Name(workspace.Spec.Cluster)).List(labels.Everything())
+		if err != nil {
+			logger.Error(err, "Failed to list SyncerConfigs in mailbox workspace")
+			return false
+		}
+		for _, syncerConfig := range syncerConfigs {
+			if hb := syncerConfig.Status.LastSyncerHeartbeatTime; hb != nil && (heartbeat == nil || heartbeat.Before(hb)) {
+				heartbeat = hb
+				latestSyncerConfig = syncerConfig
+			}
+		}
+	}
+	if heartbeat == nil {
+		logger.V(4).Info("SyncTarget has never had a heartbeat")
+		return false
+	}
+	updated := syncTarget.DeepCopy()
+	updated.Status.LastSyncerHeartbeatTime = heartbeat
+	if latestSyncerConfig != nil && latestSyncerConfig.Status.Capacity != nil {
+		capacity, allocatable := latestSyncerConfig.Status.Capacity.DeepCopy(), latestSyncerConfig.Status.Allocatable.DeepCopy()
+		updated.Status.Capacity = &capacity
+		updated.Status.Allocatable = &allocatable
+	}
+	sinceHeartbeat := time.Since(heartbeat.Time)
+	if sinceHeartbeat <= hbm.threshold {
+		conditions.MarkTrue(updated, edgev1alpha1.HeartbeatHealthy)
+		// Check again when the heartbeat would become too old
+		hbm.queue.AddAfter(mbwsName, hbm.threshold-sinceHeartbeat+time.Second)
+	} else {
+		conditions.MarkFalse(updated, edgev1alpha1.HeartbeatHealthy, edgev1alpha1.ErrorHeartbeatMissedReason, conditionsv1alpha1.ConditionSeverityError,
+			"No heartbeat since %s", heartbeat.Format(time.RFC3339))
+	}
+	if apiequality.Semantic.DeepEqual(syncTarget.Status, updated.Status) {
+		return false
+	}
+	cluster := logicalcluster.From(syncTarget)
+	_, err = hbm.syncTargetClusterClient.Cluster(cluster.Path()).UpdateStatus(ctx, updated, metav1.UpdateOptions{FieldManager: "heartbeat-monitor"})
+	if err != nil {
+		logger.Error(err, "Failed to update SyncTarget status", "cluster", cluster, "syncTargetName", syncTarget.Name)
+		return true
+	}
+	logger.V(2).Info("Updated SyncTarget heartbeat", "cluster", cluster, "syncTargetName", syncTarget.Name,
+		"lastSyncerHeartbeatTime", heartbeat, "healthy", sinceHeartbeat <= hbm.threshold)
+	return false
+}
+
+func mbwsClusterOfObj(obj any) ([]string, error) {
+	ws, ok := obj.(*tenancyv1alpha1.Workspace)
+	if !ok {
+		return nil, fmt.Errorf("expected a Workspace but got %#+v, a %T", obj, obj)
+	}
+	if ws.Spec.Cluster == "" {
+		return []string{}, nil
+	}
+	return []string{ws.Spec.Cluster}, nil
+}
@@ -44,6 +44,8 @@ import (
 	"k8s.io/klog/v2"
 	utilflag "k8s.io/kubernetes/pkg/util/flag"
 
+	clusterdiscovery "github.com/kcp-dev/client-go/discovery"
+	clusterdynamic "github.com/kcp-dev/client-go/dynamic"
 	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
 	"github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/util/conditions"
 	kcpscopedclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
@@ -60,6 +62,7 @@ import (
 func main() {
 	resyncPeriod := time.Duration(0)
 	var concurrency int = 4
+	heartbeatThreshold := 2 * time.Minute
 	serverBindAddress := ":10203"
 	espwPath := logicalcluster.Name("root").Path().Join("espw").String()
 	fs := pflag.NewFlagSet("mailbox-controller", pflag.ExitOnError)
@@ -69,6 +72,7 @@ func main() {
 
 	fs.IntVar(&concurrency, "concurrency", concurrency, "number of syncs to run in parallel")
 	fs.StringVar(&espwPath, "espw-path", espwPath, "the pathname of the edge service provider workspace")
+	fs.DurationVar(&heartbeatThreshold, "heartbeat-threshold", heartbeatThreshold, "how old the latest syncer heartbeat may be before a SyncTarget is considered unhealthy")
 
 	inventoryClientOpts := clientopts.NewClientOpts("inventory", "access to APIExport view of SyncTarget objects")
 	inventoryClientOpts.SetDefaultCurrentContext("root")
@@ -130,6 +134,7 @@ func main() {
 	}
 	edgeSharedInformerFactory := edgeinformers.NewSharedInformerFactoryWithOptions(edgeViewClusterClientset, resyncPeriod)
 	syncTargetClusterPreInformer := edgeSharedInformerFactory.Edge().V1alpha1().SyncTargets()
+	syncerConfigClusterPreInformer := edgeSharedInformerFactory.Edge().V1alpha1().SyncerConfigs()
 
 	// create config for accessing edge service provider workspace
 
@@ -160,10 +165,25 @@ func main() {
 		logger.Error(err, "Failed to create all-cluster clientset")
 		os.Exit(24)
 	}
+	mbwsDiscoveryClient, err := clusterdiscovery.NewForConfig(mbwsClientConfig)
+	if err != nil {
+		logger.Error(err, "Failed to create all-cluster discovery client")
+		os.Exit(28)
+	}
+	mbwsDynamicClient, err := clusterdynamic.NewForConfig(mbwsClientConfig)
+	if err != nil {
+		logger.Error(err, "Failed to create all-cluster dynamic client")
+		os.Exit(32)
+	}
 
 	ctl := newMailboxController(ctx, espwPath, syncTargetClusterPreInformer, workspaceScopedPreInformer,
 		workspaceScopedClientset.TenancyV1alpha1().Workspaces(),
 		mbwsClientset.ApisV1alpha1().APIBindings(),
+		mbwsDiscoveryClient, mbwsDynamicClient,
+	)
+	hbm := newHeartbeatMonitor(ctx, heartbeatThreshold, syncTargetClusterPreInformer,
+		edgeViewClusterClientset.EdgeV1alpha1().SyncTargets(),
+		syncerConfigClusterPreInformer, workspaceScopedPreInformer,
 	)
 
 	doneCh := ctx.Done()
@@ -171,6 +191,7 @@ func main() {
 
 	workspaceScopedInformerFactory.Start(doneCh)
 
+	go hbm.Run(concurrency)
 	ctl.Run(concurrency)
 
 	logger.Info("Time to stop")
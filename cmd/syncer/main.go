@@ -16,22 +16,40 @@ limitations under the License.
 
 package main
 
+// Import of k8s.io/component-base/metrics/prometheus/clientgo
+// makes the k8s client library produce Prometheus metrics,
+// and import of k8s.io/component-base/metrics/prometheus/workqueue
+// does the same for the work queues.
+
 import (
 	"context"
 	"flag"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"github.com/spf13/pflag"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apiserver/pkg/server/mux"
+	"k8s.io/apiserver/pkg/server/routes"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/component-base/metrics/legacyregistry"
+	_ "k8s.io/component-base/metrics/prometheus/clientgo"
+	_ "k8s.io/component-base/metrics/prometheus/workqueue"
 	"k8s.io/klog/v2"
 
 	"github.com/kcp-dev/logicalcluster/v3"
 
 	synceroptions "github.com/kubestellar/kubestellar/cmd/syncer/options"
 	"github.com/kubestellar/kubestellar/pkg/syncer"
+	"github.com/kubestellar/kubestellar/pkg/syncer/credentials"
 )
 
 func main() {
@@ -51,12 +69,21 @@ func main() {
 	kcpConfigOverrides := &clientcmd.ConfigOverrides{
 		CurrentContext: options.FromContext,
 	}
-	upstreamConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+	upstreamClientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
 		&clientcmd.ClientConfigLoadingRules{ExplicitPath: options.FromKubeconfig},
-		kcpConfigOverrides).ClientConfig()
+		kcpConfigOverrides)
+	upstreamConfig, err := upstreamClientConfig.ClientConfig()
 	if err != nil {
 		panic(err)
 	}
+	if options.UpstreamTokenLifetime != 0 {
+		upstreamNamespace, _, err := upstreamClientConfig.Namespace()
+		if err != nil {
+			panic(err)
+		}
+		credentials.NewTokenRequestSource(upstreamConfig, logicalcluster.NewPath(options.FromClusterPath),
+			upstreamNamespace, options.UpstreamServiceAccount, options.UpstreamTokenLifetime).Use(upstreamConfig)
+	}
 
 	upstreamConfig.QPS = options.QPS
 	upstreamConfig.Burst = options.Burst
@@ -73,20 +100,99 @@ func main() {
 	downstreamConfig.QPS = options.QPS
 	downstreamConfig.Burst = options.Burst
 
+	health := syncer.NewHealth()
 	syncerConfig := &syncer.SyncerConfig{
-		UpstreamConfig:   upstreamConfig,
-		DownstreamConfig: downstreamConfig,
-		SyncTargetPath:   logicalcluster.NewPath(options.FromClusterPath),
-		SyncTargetName:   options.SyncTargetName,
-		SyncTargetUID:    options.SyncTargetUID,
+		UpstreamConfig:    upstreamConfig,
+		DownstreamConfig:  downstreamConfig,
+		SyncTargetPath:    logicalcluster.NewPath(options.FromClusterPath),
+		SyncTargetName:    options.SyncTargetName,
+		SyncTargetUID:     options.SyncTargetUID,
+		Interval:          options.ResyncInterval,
+		HeartbeatInterval: options.HeartbeatInterval,
+		LocalStateDir:     options.StateDir,
+		Health:            health,
 	}
 
+	mymux := mux.NewPathRecorderMux("kubestellar-syncer")
+	mymux.Handle("/metrics", legacyregistry.Handler())
+	health.InstallHandlers(mymux)
+	routes.Profiling{}.Install(mymux)
+	go func() {
+		err := http.ListenAndServe(options.ServerBindAddress, mymux)
+		if err != nil {
+			klog.Background().Error(err, "Failure in web serving")
+			panic(err)
+		}
+	}()
+
+	// The client for the Lease is made before RunSyncer instruments downstreamConfig
+	leaseConfig := rest.CopyConfig(downstreamConfig)
+
 	ctx := setupSignalContext()
-	if err := syncer.RunSyncer(ctx, syncerConfig, 1); err != nil {
-		panic(err)
+	run := func(ctx context.Context) {
+		if err := syncer.RunSyncer(ctx, syncerConfig, 1); err != nil {
+			panic(err)
+		}
+		<-ctx.Done()
+	}
+	if !options.LeaderElect {
+		run(ctx)
+		return
 	}
+	health.SetStandby(true)
+	runWithLeaderElection(ctx, options, leaseConfig, health, run)
+}
 
-	<-ctx.Done()
+// runWithLeaderElection calls run once this replica holds the Lease, and returns when ctx is done.
+// The leader releases the Lease when ctx is done, so that another replica takes over right away.
+// The syncer can not be restarted within a process, so a leader that loses the Lease exits.
+func runWithLeaderElection(ctx context.Context, options *synceroptions.Options, config *rest.Config, health *syncer.Health, run func(context.Context)) {
+	logger := klog.FromContext(ctx)
+	client, err := kubernetes.NewForConfig(rest.AddUserAgent(config, "kubestellar-syncer-leader-election"))
+	if err != nil {
+		panic(err)
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		panic(err)
+	}
+	identity := hostname + "_" + string(uuid.NewUUID())
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Namespace: options.LeaderElectNamespace,
+			Name:      options.LeaderElectName,
+		},
+		Client:     client.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{Identity: identity},
+	}
+	leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		Name:            options.LeaderElectName,
+		LeaseDuration:   options.LeaderElectLeaseDuration,
+		RenewDeadline:   options.LeaderElectRenewDeadline,
+		RetryPeriod:     options.LeaderElectRetryPeriod,
+		ReleaseOnCancel: true,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				logger.Info("Became the leader", "identity", identity)
+				health.SetStandby(false)
+				run(ctx)
+			},
+			OnStoppedLeading: func() {
+				if ctx.Err() != nil {
+					logger.Info("Stopped leading on shutdown", "identity", identity)
+					return
+				}
+				logger.Error(nil, "Lost the Lease, exiting", "identity", identity)
+				os.Exit(1)
+			},
+			OnNewLeader: func(leader string) {
+				if leader != identity {
+					logger.Info("Another replica is the leader", "leader", leader)
+				}
+			},
+		},
+	})
 }
 
 var onlyOneSignalHandler = make(chan struct{})
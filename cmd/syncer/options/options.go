@@ -19,26 +19,51 @@ package options
 import (
 	"errors"
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/spf13/pflag"
+
+	utilflag "k8s.io/kubernetes/pkg/util/flag"
 )
 
 type Options struct {
-	QPS             float32
-	Burst           int
-	FromKubeconfig  string
-	FromContext     string
-	FromClusterPath string
-	ToKubeconfig    string
-	ToContext       string
-	SyncTargetName  string
-	SyncTargetUID   string
+	QPS               float32
+	Burst             int
+	FromKubeconfig    string
+	FromContext       string
+	FromClusterPath   string
+	ToKubeconfig      string
+	ToContext         string
+	SyncTargetName    string
+	SyncTargetUID     string
+	ResyncInterval    time.Duration
+	HeartbeatInterval time.Duration
+	StateDir          string
+	ServerBindAddress string
+
+	UpstreamServiceAccount string
+	UpstreamTokenLifetime  time.Duration
+
+	LeaderElect              bool
+	LeaderElectNamespace     string
+	LeaderElectName          string
+	LeaderElectLeaseDuration time.Duration
+	LeaderElectRenewDeadline time.Duration
+	LeaderElectRetryPeriod   time.Duration
 }
 
 func NewOptions() *Options {
 	return &Options{
-		QPS:   30,
-		Burst: 20,
+		QPS:               30,
+		Burst:             20,
+		ResyncInterval:    5 * time.Minute,
+		HeartbeatInterval: 30 * time.Second,
+		ServerBindAddress: ":10205",
+
+		LeaderElectLeaseDuration: 15 * time.Second,
+		LeaderElectRenewDeadline: 10 * time.Second,
+		LeaderElectRetryPeriod:   2 * time.Second,
 	}
 }
 
@@ -53,9 +78,35 @@ func (options *Options) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&options.SyncTargetName, "sync-target-name", options.SyncTargetName,
 		fmt.Sprintf("ID of the -to cluster. Resources with this ID set in the %q label will be synced.", "<ClusterID>"))
 	fs.StringVar(&options.SyncTargetUID, "sync-target-uid", options.SyncTargetUID, "The UID from the SyncTarget resource in KCP.")
+	fs.DurationVar(&options.ResyncInterval, "resync-interval", options.ResyncInterval, "Period of the full resync that backs up the watch-driven syncing.")
+	fs.DurationVar(&options.HeartbeatInterval, "heartbeat-interval", options.HeartbeatInterval, "Period of the heartbeat written to the SyncerConfig.")
+	fs.StringVar(&options.StateDir, "state-dir", options.StateDir,
+		"Directory where the syncer keeps a local copy of its state from the -from cluster, so that it keeps syncing while that cluster is unreachable. If not set, no local copy is kept.")
+	fs.Var(&utilflag.IPPortVar{Val: &options.ServerBindAddress}, "server-bind-address", "The IP address with port at which to serve /metrics, /healthz, /readyz, /debug/syncer/state and /debug/pprof/")
+	fs.StringVar(&options.UpstreamServiceAccount, "upstream-service-account", options.UpstreamServiceAccount,
+		"Name of the syncer's ServiceAccount in the -from cluster, in the namespace of the -from context. Required with --upstream-token-lifetime.")
+	fs.DurationVar(&options.UpstreamTokenLifetime, "upstream-token-lifetime", options.UpstreamTokenLifetime,
+		"If set, the syncer uses the credential in --from-kubeconfig only to request short-lived tokens of this lifetime for --upstream-service-account, and authenticates with those; every token is requested with that credential, before the previous one expires.")
+	fs.BoolVar(&options.LeaderElect, "leader-elect", options.LeaderElect,
+		"Use a Lease in the -to cluster so that only one of several replicas syncs at a time. The leader releases the Lease when it shuts down, so that another replica takes over right away.")
+	fs.StringVar(&options.LeaderElectNamespace, "leader-elect-namespace", options.LeaderElectNamespace, "Namespace of the Lease. If not set, the NAMESPACE environment variable is used.")
+	fs.StringVar(&options.LeaderElectName, "leader-elect-name", options.LeaderElectName, "Name of the Lease. If not set, it is \"kubestellar-syncer-\" followed by the SyncTarget name (or UID).")
+	fs.DurationVar(&options.LeaderElectLeaseDuration, "leader-elect-lease-duration", options.LeaderElectLeaseDuration, "How long the other replicas wait, after the leader stops renewing the Lease, before one of them takes over.")
+	fs.DurationVar(&options.LeaderElectRenewDeadline, "leader-elect-renew-deadline", options.LeaderElectRenewDeadline, "How long the leader keeps trying to renew the Lease before it gives up leadership.")
+	fs.DurationVar(&options.LeaderElectRetryPeriod, "leader-elect-retry-period", options.LeaderElectRetryPeriod, "How long the replicas wait between attempts to acquire or renew the Lease.")
 }
 
 func (options *Options) Complete() error {
+	if options.LeaderElectNamespace == "" {
+		options.LeaderElectNamespace = os.Getenv("NAMESPACE")
+	}
+	if options.LeaderElectName == "" {
+		if options.SyncTargetName != "" {
+			options.LeaderElectName = "kubestellar-syncer-" + options.SyncTargetName
+		} else {
+			options.LeaderElectName = "kubestellar-syncer-" + options.SyncTargetUID
+		}
+	}
 	return nil
 }
 
@@ -69,5 +120,21 @@ func (options *Options) Validate() error {
 	if options.SyncTargetUID == "" {
 		return errors.New("--sync-target-uid is required")
 	}
+	if options.UpstreamTokenLifetime != 0 {
+		if options.UpstreamServiceAccount == "" {
+			return errors.New("--upstream-service-account is required with --upstream-token-lifetime")
+		}
+		if options.UpstreamTokenLifetime < 10*time.Minute {
+			return errors.New("--upstream-token-lifetime must be at least 10m")
+		}
+	}
+	if options.LeaderElect {
+		if options.LeaderElectNamespace == "" {
+			return errors.New("--leader-elect-namespace (or the NAMESPACE environment variable) is required with --leader-elect")
+		}
+		if options.LeaderElectLeaseDuration <= options.LeaderElectRenewDeadline {
+			return errors.New("--leader-elect-lease-duration must be greater than --leader-elect-renew-deadline")
+		}
+	}
 	return nil
 }
@@ -17,7 +17,9 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // EdgePlacement exists in the center and binds (a) a collection of
@@ -50,6 +52,7 @@ import (
 // +crd
 // +genclient
 // +genclient:nonNamespaced
+// +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster,shortName=epl
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 type EdgePlacement struct {
@@ -90,6 +93,143 @@ type EdgePlacementSpec struct {
 	// An object matches `upsync` if and only if it matches at least one member of `upsync`.
 	// +optional
 	Upsync []UpsyncSet `json:"upsync,omitempty"`
+
+	// `ignoreDifferences` identifies fields of the downsynced objects
+	// that are maintained in the edge clusters and so are not to be
+	// overwritten by the syncer.
+	// +optional
+	IgnoreDifferences []IgnoreDifferences `json:"ignoreDifferences,omitempty"`
+
+	// `driftPolicies` says, per resource, what the syncers do when a
+	// downsynced object is changed in an edge cluster.
+	// +optional
+	DriftPolicies []ResourceDriftPolicy `json:"driftPolicies,omitempty"`
+
+	// `deletionPolicies` says, per resource, how the syncers delete
+	// downsynced objects from the edge clusters.
+	// +optional
+	DeletionPolicies []ResourceDeletionPolicy `json:"deletionPolicies,omitempty"`
+
+	// `subresources` says, per resource, what the syncers do with
+	// subresources of downsynced objects other than `status`.
+	// +optional
+	Subresources []ResourceSubresources `json:"subresources,omitempty"`
+
+	// `numberOfClusters`, when set, limits the destinations to at most this many
+	// of the SyncTargets selected through `locationSelectors`.
+	// When omitted, every selected SyncTarget is a destination.
+	// A SyncTarget that is already a destination is preferred over the others,
+	// so that unrelated SyncTargets coming and going do not cause churn.
+	// +optional
+	NumberOfClusters *int32 `json:"numberOfClusters,omitempty"`
+
+	// `spreadConstraints` direct how the choice among SyncTargets,
+	// when limited by `numberOfClusters`, is spread across topology domains.
+	// +optional
+	SpreadConstraints []SpreadConstraint `json:"spreadConstraints,omitempty"`
+
+	// `tieBreaker` orders the SyncTargets that are otherwise equally preferable.
+	// Defaults to `Name`.
+	// +optional
+	TieBreaker TieBreaker `json:"tieBreaker,omitempty"`
+
+	// `resourceRequests` states the amount of each resource that the workload
+	// needs from each destination.
+	// A SyncTarget fits when, for every resource named here, its reported
+	// allocatable amount is at least the requested amount.
+	// +optional
+	ResourceRequests corev1.ResourceList `json:"resourceRequests,omitempty"`
+
+	// `capacityPolicy` says how `resourceRequests` affects the choice of destinations.
+	// Defaults to `Filter`.
+	// +optional
+	CapacityPolicy CapacityPolicy `json:"capacityPolicy,omitempty"`
+
+	// `rollout` controls how changes to the downsynced objects are delivered
+	// to the destinations.
+	// When omitted, a change is delivered to all of the destinations at once.
+	// +optional
+	Rollout *RolloutStrategy `json:"rollout,omitempty"`
+}
+
+// SpreadConstraint directs that the chosen SyncTargets be spread evenly
+// across the values of a label.
+type SpreadConstraint struct {
+	// `topologyKey` is the key of a SyncTarget label.
+	// SyncTargets with the same value for this label are in the same domain;
+	// SyncTargets without this label are together in one domain.
+	// Each choice prefers a SyncTarget from a domain with the fewest chosen so far.
+	// When there are several constraints, the earlier ones take precedence.
+	TopologyKey string `json:"topologyKey"`
+}
+
+// TieBreaker identifies a way of ordering SyncTargets that are otherwise equally preferable.
+// +kubebuilder:validation:Enum=Name;Hash
+type TieBreaker string
+
+const (
+	// TieBreakerName orders by logical cluster and then SyncTarget name.
+	TieBreakerName TieBreaker = "Name"
+
+	// TieBreakerHash orders by a hash of the EdgePlacement and SyncTarget identities,
+	// so that different EdgePlacements tend to choose different SyncTargets.
+	TieBreakerHash TieBreaker = "Hash"
+)
+
+// CapacityPolicy identifies how the fit of a SyncTarget to the resource requests
+// affects its being chosen.
+// +kubebuilder:validation:Enum=Filter;Rank
+type CapacityPolicy string
+
+const (
+	// CapacityPolicyFilter rejects every SyncTarget that does not fit,
+	// including those that do not report allocatable resources.
+	CapacityPolicyFilter CapacityPolicy = "Filter"
+
+	// CapacityPolicyRank rejects no SyncTarget for capacity reasons but,
+	// when `numberOfClusters` limits the choice, prefers SyncTargets that fit
+	// and, among those, the ones with the most room to spare.
+	CapacityPolicyRank CapacityPolicy = "Rank"
+)
+
+// RolloutStrategy directs that a change to the downsynced objects be delivered
+// to the destinations in waves.
+// A rollout starts when a creation, update, or deletion of a downsynced object
+// is noticed.  The first wave of destinations gets the change immediately;
+// the other destinations keep the previous state until their wave is admitted.
+// A wave is admitted once the `pause` has passed since the previous wave
+// was admitted and, if `requireHealthy`, the destinations of the previous
+// waves are healthy enough.
+// The destinations are ordered by logical cluster and SyncTarget name.
+type RolloutStrategy struct {
+	// `batchSize` is the number of destinations in each wave,
+	// either an absolute number or a percentage of the destinations
+	// (rounded up).
+	// Defaults to 1.
+	// +optional
+	BatchSize *intstr.IntOrString `json:"batchSize,omitempty"`
+
+	// `maxUnavailable` is the number of admitted destinations that may be
+	// unhealthy while the rollout proceeds,
+	// either an absolute number or a percentage of the destinations
+	// (rounded down).
+	// The next wave is delayed while more admitted destinations than this
+	// are unhealthy.
+	// Only used when `requireHealthy`.
+	// Defaults to 0.
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// `pause` is the minimum time between the admission of consecutive waves.
+	// +optional
+	Pause *metav1.Duration `json:"pause,omitempty"`
+
+	// `requireHealthy` means that a destination only counts as healthy
+	// once its syncer reports no failures for the EdgePlacement's objects
+	// and the readiness conditions (`Ready` and `Available`) in the reported
+	// state of the projected objects are all True.
+	// +optional
+	RequireHealthy bool `json:"requireHealthy,omitempty"`
 }
 
 // NonNamespacedObjectReferenceSet specifies a set of non-namespaced objects
@@ -122,8 +262,11 @@ type NonNamespacedObjectReferenceSet struct {
 // An object is in this set if:
 // - its API group is the one listed;
 // - its resource (lowercase plural form of object type) is one of those listed;
-// - EITHER the resource is cluster-scoped OR the object's namespace matches `namespaces`; and
-// - the object's name matches `names`.
+// - EITHER the resource is cluster-scoped OR the object's namespace matches `namespaces`
+// or `namespaceSelector`;
+// - the object's name matches `names`;
+// - the object's labels match `labelSelector`, if given; and
+// - the object's fields match `fieldSelector`, if given.
 type UpsyncSet struct {
 	// `apiGroup` is the API group of the referenced object, empty string for the core API group.
 	APIGroup string `json:"apiGroup,omitempty"`
@@ -141,10 +284,77 @@ type UpsyncSet struct {
 
 	// `Names` is a list of objects that match by name.
 	// An entry of `"*"` means that all match.
-	// Empty list means nothing matches (you probably never want an empty list).
+	// If a `labelSelector`, `fieldSelector` or `namespaceSelector` is given then an
+	// empty list means that all match (like `["*"]`), otherwise it means nothing matches
+	// (you probably never want that).
 	Names []string `json:"names,omitempty"`
+
+	// `labelSelector`, if given, restricts the set to the objects whose labels match it.
+	// +optional
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+
+	// `fieldSelector`, if given, restricts the set to the objects whose fields match it.
+	// It has the syntax of a Kubernetes field selector (e.g., `status.phase=Running`)
+	// but is evaluated by the syncer, so it may refer to any field of the object
+	// whose value is a string, number or boolean.
+	// +optional
+	FieldSelector string `json:"fieldSelector,omitempty"`
+
+	// `namespaceSelector`, if given, adds the namespaces whose labels match it
+	// to those listed in `namespaces`.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// `collisionPolicy` says how the upsynced copies of these objects are named,
+	// so that the copies from different edge clusters can be told apart
+	// once they are gathered together.
+	// The default is `Merge`.
+	// +optional
+	CollisionPolicy UpsyncCollisionPolicy `json:"collisionPolicy,omitempty"`
 }
 
+// UpsyncCollisionPolicy says how the upsynced copy of an object from an edge cluster
+// is named, to deal with same-named objects from many edge clusters.
+// Every upsynced copy is labeled with its source SyncTarget and Location
+// (see SourceSyncTargetLabelKey and SourceLocationLabelKey).
+// +kubebuilder:validation:Enum=Merge;Prefix;Suffix;PerClusterNamespace
+type UpsyncCollisionPolicy string
+
+const (
+	// UpsyncCollisionPolicyMerge keeps the name and namespace, so that same-named
+	// objects from different edge clusters are one object when gathered together;
+	// the source labels tell which edge cluster wrote it.
+	UpsyncCollisionPolicyMerge UpsyncCollisionPolicy = "Merge"
+
+	// UpsyncCollisionPolicyPrefix prepends the SyncTarget name and a dash to the object name.
+	// A name that gets too long for its kind is cut short and ended with a hash of the whole name;
+	// the same goes for the other policies that change names.
+	UpsyncCollisionPolicyPrefix UpsyncCollisionPolicy = "Prefix"
+
+	// UpsyncCollisionPolicySuffix appends a dash and the SyncTarget name to the object name.
+	UpsyncCollisionPolicySuffix UpsyncCollisionPolicy = "Suffix"
+
+	// UpsyncCollisionPolicyPerClusterNamespace puts a namespaced object in a namespace
+	// whose name is the SyncTarget name, a dash, and the object's namespace.
+	// That is also the name of the upsynced copy of the Namespace object itself.
+	// The syncer creates that namespace if it does not exist yet.
+	// Other cluster-scoped objects are treated as for UpsyncCollisionPolicyPrefix.
+	UpsyncCollisionPolicyPerClusterNamespace UpsyncCollisionPolicy = "PerClusterNamespace"
+)
+
+// SourceSyncTargetLabelKey is the key of the label, on an upsynced copy,
+// whose value is the name of the SyncTarget of the edge cluster that the object came from.
+const SourceSyncTargetLabelKey = "edge.kubestellar.io/source-sync-target"
+
+// SourceLocationLabelKey is the key of the label, on an upsynced copy,
+// whose value is the name of the Location of the edge cluster that the object came from.
+const SourceLocationLabelKey = "edge.kubestellar.io/source-location"
+
+// SourceObjectAnnotationKey is the key of the annotation, on an upsynced copy,
+// whose value is the namespace and name (as `namespace/name`, or just the name
+// of a cluster-scoped object) of the edge cluster object that it is a copy of.
+const SourceObjectAnnotationKey = "edge.kubestellar.io/source-object"
+
 type EdgePlacementStatus struct {
 	// `specGeneration` identifies the generation of the spec that this
 	// is the status for.
@@ -155,6 +365,114 @@ type EdgePlacementStatus struct {
 	// `matchingLocationCount` is the number of Locations that satisfy the spec's
 	// `locationSelectors`.
 	MatchingLocationCount int32 `json:"matchingLocationCount"`
+
+	// `syncedObjectCount` is the number of (object, SyncTarget) pairs
+	// for which the syncer reports that its latest sync succeeded.
+	// +optional
+	SyncedObjectCount int32 `json:"syncedObjectCount,omitempty"`
+
+	// `failedObjectCount` is the number of (object, SyncTarget) pairs
+	// for which the syncer reports that its latest sync failed.
+	// +optional
+	FailedObjectCount int32 `json:"failedObjectCount,omitempty"`
+
+	// `syncFailures` details some of the failures counted in `failedObjectCount`.
+	// +optional
+	SyncFailures []EdgePlacementSyncFailure `json:"syncFailures,omitempty"`
+
+	// `destinations` summarizes the delivery and health of the workload
+	// at each of the destinations listed in the SinglePlacementSlice.
+	// +optional
+	Destinations []EdgePlacementDestinationStatus `json:"destinations,omitempty"`
+
+	// `rollout` reports the progress of the latest rollout,
+	// when the spec has a `rollout` strategy.
+	// +optional
+	Rollout *EdgePlacementRolloutStatus `json:"rollout,omitempty"`
+}
+
+type RolloutPhase string
+
+const (
+	RolloutPhaseProgressing RolloutPhase = "Progressing"
+	RolloutPhaseComplete    RolloutPhase = "Complete"
+)
+
+// EdgePlacementRolloutStatus reports the progress of a rollout.
+type EdgePlacementRolloutStatus struct {
+	Phase RolloutPhase `json:"phase"`
+
+	// `wave` is the number of waves admitted so far in the latest rollout.
+	// +optional
+	Wave int32 `json:"wave,omitempty"`
+
+	// `updatedDestinationCount` is the number of destinations that have been
+	// admitted to receive the change.
+	UpdatedDestinationCount int32 `json:"updatedDestinationCount"`
+
+	// `unhealthyDestinationCount` is the number of admitted destinations that
+	// are not healthy.  Only computed when `requireHealthy`.
+	// +optional
+	UnhealthyDestinationCount int32 `json:"unhealthyDestinationCount,omitempty"`
+
+	DestinationCount int32 `json:"destinationCount"`
+
+	// `waveStartTime` is when the latest wave was admitted.
+	// +optional
+	WaveStartTime *metav1.Time `json:"waveStartTime,omitempty"`
+
+	// `message` explains what the rollout is waiting for, if anything.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// EdgePlacementDestinationStatus summarizes the delivery and health of
+// an EdgePlacement's downsynced objects at one destination.
+type EdgePlacementDestinationStatus struct {
+	// `cluster` is the logicalcluster.Name of the logical cluster that contains
+	// both the Location and the SyncTarget.
+	Cluster string `json:"cluster"`
+
+	LocationName string `json:"locationName"`
+
+	SyncTargetName string `json:"syncTargetName"`
+
+	// `projectedObjectCount` is the number of the EdgePlacement's downsynced objects
+	// that are in the destination's mailbox workspace.
+	ProjectedObjectCount int32 `json:"projectedObjectCount"`
+
+	// `confirmedObjectCount` is the number of those projected objects that the syncer
+	// has delivered to the edge cluster (it has put its finalizer on them) and for which
+	// it does not report that its latest sync failed or found drift.
+	ConfirmedObjectCount int32 `json:"confirmedObjectCount"`
+
+	// `conditions` aggregates the readiness conditions (`Ready` and `Available`)
+	// in the reported state of the projected objects.
+	// An aggregated condition is True if it is True for every object that has it,
+	// False if it is False for some object, and Unknown otherwise.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// `lastError` is the message of the most recent failure
+	// that the syncer reports for one of the EdgePlacement's objects.
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+
+	// `lastErrorTime` is when that failure happened.
+	// +optional
+	LastErrorTime *metav1.Time `json:"lastErrorTime,omitempty"`
+}
+
+// EdgePlacementSyncFailure is a failure, reported by a syncer, to sync an object
+// that is involved with an EdgePlacement.
+type EdgePlacementSyncFailure struct {
+	// `cluster` is the logicalcluster.Name of the logical cluster that contains
+	// the SyncTarget.
+	Cluster string `json:"cluster"`
+
+	SyncTargetName string `json:"syncTargetName"`
+
+	SyncedObjectStatus `json:",inline"`
 }
 
 // EdgePlacementList is the API type for a list of EdgePlacement
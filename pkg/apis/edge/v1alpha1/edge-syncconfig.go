@@ -64,9 +64,27 @@ type EdgeSyncConfigResource struct {
 	// Namespace of down/up synced resource if it's not cluster scoped resource
 	// +optional
 	Namespace string `json:"namespace,omitempty"`
+
+	// LabelSelector, in the string form of a label selector, restricts the down/up synced objects
+	// to those whose labels match it.
+	// +optional
+	LabelSelector string `json:"labelSelector,omitempty"`
+
+	// FieldSelector, in the string form of a field selector, restricts the down/up synced objects
+	// to those whose fields match it.
+	// +optional
+	FieldSelector string `json:"fieldSelector,omitempty"`
+
+	// NamespaceSelector, in the string form of a label selector, restricts the namespaces
+	// matched by the Namespace "*" to those whose labels match it.
+	// +optional
+	NamespaceSelector string `json:"namespaceSelector,omitempty"`
 }
 
-// Resource to be renatured
+// Resource to be renatured.
+// An empty Name in both Upstream and Downstream means that the conversion
+// applies to every object of the resource, keeping the object's name.
+// An empty Version means that the version is not changed.
 type EdgeSynConversion struct {
 	// Resource representation in upstream
 	Upstream EdgeSyncConfigResource `json:"upstream,omitempty"`
@@ -48,8 +48,38 @@ type SinglePlacementSlice struct {
 
 	// `destinations` holds some of the matching locations
 	Destinations []SinglePlacement `json:"destinations"`
+
+	// `rejectedCandidates` lists the SyncTargets that were selected through the
+	// EdgePlacement's Locations but are not destinations, with the reasons.
+	// +optional
+	RejectedCandidates []RejectedCandidate `json:"rejectedCandidates,omitempty"`
 }
 
+// RejectedCandidate identifies a SyncTarget that was considered but not chosen.
+type RejectedCandidate struct {
+	// Cluster is the logicacluster.Name of the logical cluster that contains the SyncTarget.
+	Cluster string `json:"cluster"`
+
+	SyncTargetName string `json:"syncTargetName"`
+
+	// `reason` is a CamelCase word saying why the SyncTarget was rejected.
+	Reason string `json:"reason"`
+
+	// `message` gives details.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+const (
+	// RejectedInsufficientCapacity means that the SyncTarget's allocatable
+	// resources do not cover the EdgePlacement's resource requests.
+	RejectedInsufficientCapacity = "InsufficientCapacity"
+
+	// RejectedCapacityUnknown means that the SyncTarget does not report
+	// allocatable resources.
+	RejectedCapacityUnknown = "CapacityUnknown"
+)
+
 // SinglePlacement describes one Location that matches the relevant EdgePlacement.
 type SinglePlacement struct {
 	// Cluster is the logicacluster.Name of the logical cluster that contains
@@ -0,0 +1,210 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// StatusCollector exists in a workload management workspace and directs the
+// placement translator to summarize the reported state of some of the
+// downsynced objects, across all of their destinations.
+// Each downsynced object has a copy in the mailbox workspace of each of its
+// destinations, and the syncer returns the reported state of the copy at the
+// edge to the copy in the mailbox workspace.  A StatusCollector selects
+// some of those copies --- by API group, resource, namespace, and name --- and
+// evaluates some JSONPath expressions on each of them.  The results are
+// written into the StatusCollector's status, both per copy and reduced
+// across all the copies.
+//
+// The copies considered are those of objects that are downsynced by
+// an EdgePlacement in the same workspace as the StatusCollector.
+//
+// +crd
+// +genclient
+// +genclient:nonNamespaced
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,shortName=stc
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type StatusCollector struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object metadata.
+	// More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// `spec` identifies the objects and the values to collect from them.
+	Spec StatusCollectorSpec `json:"spec,omitempty"`
+
+	// `status` holds the collected values.
+	// +optional
+	Status StatusCollectorStatus `json:"status,omitempty"`
+}
+
+// StatusCollectorSpec identifies a set of downsynced objects, all of
+// the same kind, and the values to collect from their copies.
+// As elsewhere in this API, the kind of object is identified by
+// API group and resource.
+// An object is in this set if:
+// - its API group is the one listed;
+// - its resource (lowercase plural form of object type) is the one listed;
+// - EITHER the resource is cluster-scoped OR the object's namespace matches `namespaces`; and
+// - the object's name matches `names`.
+type StatusCollectorSpec struct {
+	// `apiGroup` is the API group of the objects, empty string for the core API group.
+	APIGroup string `json:"apiGroup,omitempty"`
+
+	// `resource` is the lowercase plural name for the sort of objects to match.
+	Resource string `json:"resource"`
+
+	// `namespaces` is a list of acceptable namespaces.
+	// An entry of `"*"` means that all match.
+	// Empty list means that all match.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// `names` is a list of acceptable object names.
+	// An entry of `"*"` means that all match.
+	// Empty list means that all match.
+	// +optional
+	Names []string `json:"names,omitempty"`
+
+	// `fields` identifies the values to collect from each copy.
+	// +optional
+	Fields []StatusCollectorField `json:"fields,omitempty"`
+}
+
+// StatusCollectorField identifies a value to collect from each copy,
+// and how to reduce the collected values.
+type StatusCollectorField struct {
+	// `name` identifies this field in the status.
+	Name string `json:"name"`
+
+	// `jsonPath` is evaluated on each copy, as with `pkg/jsonpath`.
+	// For example: `$.status.readyReplicas`.
+	// When the expression selects more than one place, all of the
+	// selected values are collected.
+	JSONPath string `json:"jsonPath"`
+
+	// `reductions` lists the reductions to apply to the numeric values collected.
+	// +optional
+	Reductions []StatusReduction `json:"reductions,omitempty"`
+}
+
+// StatusReduction is a way of reducing a collection of numbers to one number.
+// +kubebuilder:validation:Enum=Min;Max;Sum
+type StatusReduction string
+
+const (
+	StatusReductionMin StatusReduction = "Min"
+	StatusReductionMax StatusReduction = "Max"
+	StatusReductionSum StatusReduction = "Sum"
+)
+
+// MaxCollectedObjects bounds the length of StatusCollectorStatus.Objects
+const MaxCollectedObjects = 100
+
+type StatusCollectorStatus struct {
+	// `observedGeneration` identifies the generation of the spec that this
+	// is the status for.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// `errors` reports problems with the spec, such as a JSONPath expression
+	// that does not parse.
+	// +optional
+	Errors []string `json:"errors,omitempty"`
+
+	// `destinationCount` is the number of distinct destinations that
+	// have at least one of the selected copies.
+	DestinationCount int32 `json:"destinationCount"`
+
+	// `objectCount` is the number of selected copies.
+	ObjectCount int32 `json:"objectCount"`
+
+	// `objects` holds the values collected from each copy, for up to
+	// `MaxCollectedObjects` of them.
+	// +optional
+	Objects []CollectedObjectStatus `json:"objects,omitempty"`
+
+	// `fields` holds the reductions of the collected values, over all the copies.
+	// +optional
+	Fields []CollectedFieldSummary `json:"fields,omitempty"`
+}
+
+// CollectedObjectStatus holds the values collected from one copy.
+type CollectedObjectStatus struct {
+	// `cluster` is the logicalcluster.Name of the logical cluster that contains
+	// the SyncTarget.
+	Cluster string `json:"cluster"`
+
+	SyncTargetName string `json:"syncTargetName"`
+
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	Name string `json:"name"`
+
+	// +optional
+	Values []CollectedValue `json:"values,omitempty"`
+}
+
+// CollectedValue is a value collected for one of the `fields` of a StatusCollector.
+type CollectedValue struct {
+	// `name` is the name of the field in the StatusCollector's spec.
+	Name string `json:"name"`
+
+	// `value` is the JSON encoding of the collected value.
+	// When the JSONPath expression selected more than one place,
+	// this is the JSON encoding of the array of the selected values.
+	Value string `json:"value"`
+}
+
+// CollectedFieldSummary holds the reductions of the values collected
+// for one of the `fields` of a StatusCollector.
+type CollectedFieldSummary struct {
+	// `name` is the name of the field in the StatusCollector's spec.
+	Name string `json:"name"`
+
+	// `count` is the number of values collected.
+	Count int32 `json:"count"`
+
+	// `numericCount` is the number of those values that are numbers.
+	// Only these are reduced.
+	NumericCount int32 `json:"numericCount"`
+
+	// `min`, `max`, and `sum` are the requested reductions of the numeric values,
+	// in decimal notation.
+	// +optional
+	Min string `json:"min,omitempty"`
+	// +optional
+	Max string `json:"max,omitempty"`
+	// +optional
+	Sum string `json:"sum,omitempty"`
+}
+
+// StatusCollectorList is the API type for a list of StatusCollector
+//
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type StatusCollectorList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard list metadata.
+	// More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []StatusCollector `json:"items"`
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -30,6 +31,7 @@ import (
 // +crd
 // +genclient
 // +genclient:nonNamespaced
+// +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster,shortName=escfg
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 type SyncerConfig struct {
@@ -69,6 +71,190 @@ type SyncerConfigSpec struct {
 	// API version preferred in the edge cluster.
 	// +optional
 	Upsync []UpsyncSet `json:"upsync,omitempty"`
+
+	// `locationName` is the name of the Location through which the edge cluster
+	// was chosen. The syncer labels the objects that it upsyncs with it.
+	// +optional
+	LocationName string `json:"locationName,omitempty"`
+
+	// `conversions` identifies the resources whose objects are stored
+	// denatured in the mailbox workspace.
+	// The syncer renatures such an object on its way to the edge cluster,
+	// and denatures it again (status included) on its way back.
+	// +optional
+	Conversions []ResourceConversion `json:"conversions,omitempty"`
+
+	// `driftPolicies` says, per resource, what the syncer does when a
+	// downsynced object is changed in the edge cluster.
+	// The policy for a resource not listed here is `Enforce`.
+	// The `edge.kubestellar.io/drift-policy` annotation on an object in
+	// the mailbox workspace overrides this for that object.
+	// The placement translator sets this to the union of the
+	// `driftPolicies` of the EdgePlacements that place objects here.
+	// +optional
+	DriftPolicies []ResourceDriftPolicy `json:"driftPolicies,omitempty"`
+
+	// `deletionPolicies` says, per resource, how the syncer deletes
+	// downsynced objects from the edge cluster.
+	// A resource not listed here gets the zero value of DeletionPolicy.
+	// The placement translator sets this to the union of the
+	// `deletionPolicies` of the EdgePlacements that place objects here.
+	// +optional
+	DeletionPolicies []ResourceDeletionPolicy `json:"deletionPolicies,omitempty"`
+
+	// `ignoreDifferences` identifies fields of downsynced objects whose
+	// values in the edge cluster the syncer keeps when it updates the objects.
+	// The placement translator sets this to the union of the
+	// `ignoreDifferences` of the EdgePlacements that place objects here.
+	// +optional
+	IgnoreDifferences []IgnoreDifferences `json:"ignoreDifferences,omitempty"`
+
+	// `subresources` says, per resource, what the syncer does with
+	// subresources of downsynced objects other than `status`.
+	// The placement translator sets this to the union of the
+	// `subresources` of the EdgePlacements that place objects here.
+	// +optional
+	Subresources []ResourceSubresources `json:"subresources,omitempty"`
+}
+
+// ResourceSubresources says what the syncer does with the subresources
+// of the downsynced objects of one resource.
+type ResourceSubresources struct {
+	// GroupResource holds the API group and resource name,
+	// as they appear in the mailbox workspace.
+	metav1.GroupResource `json:",inline"`
+
+	// `scale`, if set, makes the edge cluster authoritative for the scale of the objects.
+	// When updating an object, the syncer keeps the edge cluster's value of the replicas,
+	// so that, for example, a HorizontalPodAutoscaler in the edge cluster can own it.
+	// The syncer reports the scale that it reads through the object's `scale`
+	// subresource in the edge cluster in the object's status here.
+	// +optional
+	Scale *ScaleSubresource `json:"scale,omitempty"`
+
+	// `names` lists other subresources, for example `ephemeralcontainers` of pods,
+	// that the syncer writes each downsynced object through, after writing the object itself.
+	// This is for fields that the edge cluster only accepts changes to through a subresource.
+	// `status` and `scale` are ignored here.
+	// +optional
+	Names []string `json:"names,omitempty"`
+}
+
+// ScaleSubresource locates the replicas in the objects of a resource that has the `scale` subresource.
+type ScaleSubresource struct {
+	// `specReplicasPath` selects the desired number of replicas in an object,
+	// in the JSONPath syntax of pkg/jsonpath.
+	// The default is `$.spec.replicas`.
+	// +optional
+	SpecReplicasPath string `json:"specReplicasPath,omitempty"`
+}
+
+// DefaultSpecReplicasPath is where the replicas are when a ScaleSubresource does not say.
+const DefaultSpecReplicasPath = "$.spec.replicas"
+
+// IgnoreDifferences identifies some fields of the downsynced objects of one resource
+// whose values are maintained in the edge cluster, for example by an autoscaler,
+// an admission webhook, or an operator.
+// When updating such an object, the syncer keeps the edge cluster's values of these fields
+// rather than overwriting them with the values from the mailbox workspace.
+// Differences in these fields are not drift.
+// Exactly one of `jsonPath` and `managedFieldsManager` is set.
+type IgnoreDifferences struct {
+	// GroupResource holds the API group and resource name,
+	// as they appear in the mailbox workspace.
+	metav1.GroupResource `json:",inline"`
+
+	// `jsonPath` selects the fields, in the JSONPath syntax of pkg/jsonpath.
+	// For example: `$.spec.replicas`, `$.spec.template.spec.containers[*].image`.
+	// +optional
+	JSONPath string `json:"jsonPath,omitempty"`
+
+	// `managedFieldsManager` selects the fields that the named field manager
+	// owns in the edge cluster, according to the object's `managedFields`.
+	// +optional
+	ManagedFieldsManager string `json:"managedFieldsManager,omitempty"`
+}
+
+// ResourceDriftPolicy gives the drift policy for the objects of one resource.
+type ResourceDriftPolicy struct {
+	// GroupResource holds the API group and resource name.
+	metav1.GroupResource `json:",inline"`
+
+	Policy DriftPolicy `json:"policy"`
+}
+
+// DriftPolicy says what the syncer does about a downsynced object
+// whose desired fields were changed in the edge cluster.
+// In every case the syncer reports the drift, as an Event on the object
+// in the edge cluster and in the object's status in the SyncerConfig.
+// +kubebuilder:validation:Enum=Enforce;Report;Adopt
+type DriftPolicy string
+
+const (
+	// DriftPolicyEnforce overwrites the change with the desired state.
+	DriftPolicyEnforce DriftPolicy = "Enforce"
+
+	// DriftPolicyReport leaves the change in place.
+	DriftPolicyReport DriftPolicy = "Report"
+
+	// DriftPolicyAdopt leaves the change in place and proposes it upstream,
+	// in the DriftProposalAnnotationKey annotation of the object in the mailbox workspace.
+	DriftPolicyAdopt DriftPolicy = "Adopt"
+)
+
+// DriftPolicyAnnotationKey is the key of the annotation that overrides,
+// for the object that has it, the drift policy of the object's resource.
+// The value is the name of a DriftPolicy.
+const DriftPolicyAnnotationKey = "edge.kubestellar.io/drift-policy"
+
+// DriftProposalAnnotationKey is the key of the annotation in which the syncer
+// proposes, under the Adopt policy, the edge cluster's values of the drifted fields.
+// The value is a JSON object mapping field path to value.
+const DriftProposalAnnotationKey = "edge.kubestellar.io/drift-proposal"
+
+// ResourceDeletionPolicy gives the deletion policy for the objects of one resource.
+type ResourceDeletionPolicy struct {
+	// GroupResource holds the API group and resource name,
+	// as they appear in the mailbox workspace.
+	metav1.GroupResource `json:",inline"`
+
+	DeletionPolicy `json:",inline"`
+}
+
+// DeletionPolicy says how the syncer deletes a downsynced object from the edge cluster
+// when the object is deleted from the mailbox workspace.
+// The syncer puts a finalizer on every object of the mailbox workspace that it downsyncs,
+// and removes it only once the object is gone from the edge cluster.
+type DeletionPolicy struct {
+	// `propagationPolicy` is used when deleting the object from the edge cluster.
+	// The default is the edge cluster's default for the resource.
+	// +kubebuilder:validation:Enum=Foreground;Background;Orphan
+	// +optional
+	PropagationPolicy metav1.DeletionPropagation `json:"propagationPolicy,omitempty"`
+
+	// `timeout` bounds how long the syncer waits for the deletion to finish
+	// in the edge cluster, counting from the deletion in the mailbox workspace.
+	// Once it has passed, the object is reported as failing to sync.
+	// The default is to wait indefinitely.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// `force` says that, once `timeout` has passed, the syncer removes its finalizer
+	// from the object in the mailbox workspace even though the object is not
+	// gone from the edge cluster yet.
+	// +optional
+	Force bool `json:"force,omitempty"`
+}
+
+// ResourceConversion says that the objects of one resource in the mailbox workspace
+// are the objects of another resource in the edge cluster.
+// Object names and namespaces are not changed.
+type ResourceConversion struct {
+	// `upstream` is the resource as it appears in the mailbox workspace.
+	Upstream metav1.GroupResource `json:"upstream"`
+
+	// `downstream` is the resource as it appears in the edge cluster.
+	Downstream metav1.GroupResource `json:"downstream"`
 }
 
 // NamespaceScopeDownsyncs describes what namespace-scoped objects
@@ -147,6 +333,153 @@ type SyncerConfigStatus struct {
 	// A timestamp indicating when the syncer last reported status.
 	// +optional
 	LastSyncerHeartbeatTime *metav1.Time `json:"lastSyncerHeartbeatTime,omitempty"`
+
+	// `objectCounts` counts the objects that the syncer is syncing, by direction,
+	// resource and namespace, according to the outcome of its latest attempt to sync each.
+	// +optional
+	ObjectCounts []SyncedObjectCount `json:"objectCounts,omitempty"`
+
+	// `objectStatuses` reports the outcome of the syncer's latest attempt to sync
+	// the objects for which that failed or found drift that was left in place.
+	// Only the most recent of them are reported, at most 20; `objectCounts` counts them all.
+	// Objects are identified as they appear in the mailbox workspace.
+	// +optional
+	ObjectStatuses []SyncedObjectStatus `json:"objectStatuses,omitempty"`
+
+	// `capacity` is the sum of the capacities of the nodes of the edge cluster.
+	// +optional
+	Capacity corev1.ResourceList `json:"capacity,omitempty"`
+
+	// `allocatable` is the sum of the allocatable resources of the
+	// schedulable nodes of the edge cluster.
+	// +optional
+	Allocatable corev1.ResourceList `json:"allocatable,omitempty"`
+}
+
+// SyncDirection identifies which way an object is being synced.
+type SyncDirection string
+
+const (
+	// SyncDirectionDown is from the mailbox workspace to the edge cluster
+	SyncDirectionDown SyncDirection = "Down"
+
+	// SyncDirectionUp is from the edge cluster to the mailbox workspace
+	SyncDirectionUp SyncDirection = "Up"
+)
+
+// SyncOutcome is the result of an attempt to sync an object.
+type SyncOutcome string
+
+const (
+	SyncOutcomeSucceeded SyncOutcome = "Succeeded"
+	SyncOutcomeFailed    SyncOutcome = "Failed"
+
+	// SyncOutcomeDrifted means that the object was changed in the edge cluster
+	// and, following its drift policy, the syncer left the change in place.
+	SyncOutcomeDrifted SyncOutcome = "Drifted"
+)
+
+// SyncedObjectCount counts the objects of one resource in one namespace that the syncer
+// is syncing in one direction, by the outcome of its latest attempt to sync each.
+type SyncedObjectCount struct {
+	Direction SyncDirection `json:"direction"`
+
+	// `apiGroup` is the API group of the objects, empty string for the core API group.
+	// +optional
+	APIGroup string `json:"apiGroup,omitempty"`
+
+	// `resource` is the lowercase plural name for the sort of objects.
+	Resource string `json:"resource"`
+
+	// `namespace` is empty for cluster-scoped objects.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// +optional
+	Succeeded int32 `json:"succeeded,omitempty"`
+
+	// +optional
+	Failed int32 `json:"failed,omitempty"`
+
+	// +optional
+	Drifted int32 `json:"drifted,omitempty"`
+}
+
+// SyncedObjectStatus is the outcome of the syncer's latest attempt to sync one object.
+type SyncedObjectStatus struct {
+	Direction SyncDirection `json:"direction"`
+
+	// `apiGroup` is the API group of the object, empty string for the core API group.
+	// +optional
+	APIGroup string `json:"apiGroup,omitempty"`
+
+	// `resource` is the lowercase plural name for the sort of object.
+	Resource string `json:"resource"`
+
+	// `namespace` is empty for a cluster-scoped object.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	Name string `json:"name"`
+
+	Outcome SyncOutcome `json:"outcome"`
+
+	// `message` explains a failure.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// `driftedFields` lists the paths of the fields of a downsynced object
+	// that were found changed in the edge cluster in the latest attempt.
+	// +optional
+	DriftedFields []string `json:"driftedFields,omitempty"`
+
+	// `observedGeneration` is the generation of the source object that was synced.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// `scale` is the scale of a downsynced object in the edge cluster,
+	// for a resource whose scale the edge cluster is authoritative for.
+	// +optional
+	Scale *ObservedScale `json:"scale,omitempty"`
+
+	// `upsyncSelections` lists, for an upsynced object, the selectors of the
+	// UpsyncSets under which the syncer found the object selected.
+	// An entry whose selectors are all empty stands for an UpsyncSet without selectors.
+	// +optional
+	UpsyncSelections []UpsyncSelection `json:"upsyncSelections,omitempty"`
+
+	// `lastSyncTime` is when the syncer last attempted to sync this object.
+	// The syncer does not write the status only to update this, so it is
+	// as of the latest change to the outcomes.
+	LastSyncTime metav1.Time `json:"lastSyncTime"`
+}
+
+// ObservedScale is the scale of an object in the edge cluster,
+// as read through the object's `scale` subresource.
+type ObservedScale struct {
+	// `replicas` is the desired number of replicas in the edge cluster.
+	Replicas int32 `json:"replicas"`
+
+	// `statusReplicas` is the actual number of replicas in the edge cluster.
+	// +optional
+	StatusReplicas int32 `json:"statusReplicas,omitempty"`
+
+	// `selector` is the label selector, in string form, of the replicas.
+	// +optional
+	Selector string `json:"selector,omitempty"`
+}
+
+// UpsyncSelection is the selectors of an UpsyncSet, in string form.
+// The label selectors are in the form of `labels.Selector.String()`.
+type UpsyncSelection struct {
+	// +optional
+	LabelSelector string `json:"labelSelector,omitempty"`
+
+	// +optional
+	FieldSelector string `json:"fieldSelector,omitempty"`
+
+	// +optional
+	NamespaceSelector string `json:"namespaceSelector,omitempty"`
 }
 
 // SyncerConfigList is the API type for a list of SyncerConfig
@@ -26,6 +26,7 @@ import (
 	resource "k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	intstr "k8s.io/apimachinery/pkg/util/intstr"
 
 	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
 	conditionsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
@@ -74,6 +75,59 @@ func (in *ClusterScopeDownsyncResource) DeepCopy() *ClusterScopeDownsyncResource
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CollectedFieldSummary) DeepCopyInto(out *CollectedFieldSummary) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CollectedFieldSummary.
+func (in *CollectedFieldSummary) DeepCopy() *CollectedFieldSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(CollectedFieldSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CollectedObjectStatus) DeepCopyInto(out *CollectedObjectStatus) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]CollectedValue, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CollectedObjectStatus.
+func (in *CollectedObjectStatus) DeepCopy() *CollectedObjectStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CollectedObjectStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CollectedValue) DeepCopyInto(out *CollectedValue) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CollectedValue.
+func (in *CollectedValue) DeepCopy() *CollectedValue {
+	if in == nil {
+		return nil
+	}
+	out := new(CollectedValue)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Customizer) DeepCopyInto(out *Customizer) {
 	*out = *in
@@ -138,13 +192,34 @@ func (in *CustomizerList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeletionPolicy) DeepCopyInto(out *DeletionPolicy) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeletionPolicy.
+func (in *DeletionPolicy) DeepCopy() *DeletionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(DeletionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EdgePlacement) DeepCopyInto(out *EdgePlacement) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -166,6 +241,33 @@ func (in *EdgePlacement) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EdgePlacementDestinationStatus) DeepCopyInto(out *EdgePlacementDestinationStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastErrorTime != nil {
+		in, out := &in.LastErrorTime, &out.LastErrorTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EdgePlacementDestinationStatus.
+func (in *EdgePlacementDestinationStatus) DeepCopy() *EdgePlacementDestinationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EdgePlacementDestinationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EdgePlacementList) DeepCopyInto(out *EdgePlacementList) {
 	*out = *in
@@ -199,6 +301,26 @@ func (in *EdgePlacementList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EdgePlacementRolloutStatus) DeepCopyInto(out *EdgePlacementRolloutStatus) {
+	*out = *in
+	if in.WaveStartTime != nil {
+		in, out := &in.WaveStartTime, &out.WaveStartTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EdgePlacementRolloutStatus.
+func (in *EdgePlacementRolloutStatus) DeepCopy() *EdgePlacementRolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EdgePlacementRolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EdgePlacementSpec) DeepCopyInto(out *EdgePlacementSpec) {
 	*out = *in
@@ -224,6 +346,52 @@ func (in *EdgePlacementSpec) DeepCopyInto(out *EdgePlacementSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.IgnoreDifferences != nil {
+		in, out := &in.IgnoreDifferences, &out.IgnoreDifferences
+		*out = make([]IgnoreDifferences, len(*in))
+		copy(*out, *in)
+	}
+	if in.DriftPolicies != nil {
+		in, out := &in.DriftPolicies, &out.DriftPolicies
+		*out = make([]ResourceDriftPolicy, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeletionPolicies != nil {
+		in, out := &in.DeletionPolicies, &out.DeletionPolicies
+		*out = make([]ResourceDeletionPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Subresources != nil {
+		in, out := &in.Subresources, &out.Subresources
+		*out = make([]ResourceSubresources, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NumberOfClusters != nil {
+		in, out := &in.NumberOfClusters, &out.NumberOfClusters
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SpreadConstraints != nil {
+		in, out := &in.SpreadConstraints, &out.SpreadConstraints
+		*out = make([]SpreadConstraint, len(*in))
+		copy(*out, *in)
+	}
+	if in.ResourceRequests != nil {
+		in, out := &in.ResourceRequests, &out.ResourceRequests
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutStrategy)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -240,6 +408,25 @@ func (in *EdgePlacementSpec) DeepCopy() *EdgePlacementSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EdgePlacementStatus) DeepCopyInto(out *EdgePlacementStatus) {
 	*out = *in
+	if in.SyncFailures != nil {
+		in, out := &in.SyncFailures, &out.SyncFailures
+		*out = make([]EdgePlacementSyncFailure, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Destinations != nil {
+		in, out := &in.Destinations, &out.Destinations
+		*out = make([]EdgePlacementDestinationStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(EdgePlacementRolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -253,6 +440,23 @@ func (in *EdgePlacementStatus) DeepCopy() *EdgePlacementStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EdgePlacementSyncFailure) DeepCopyInto(out *EdgePlacementSyncFailure) {
+	*out = *in
+	in.SyncedObjectStatus.DeepCopyInto(&out.SyncedObjectStatus)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EdgePlacementSyncFailure.
+func (in *EdgePlacementSyncFailure) DeepCopy() *EdgePlacementSyncFailure {
+	if in == nil {
+		return nil
+	}
+	out := new(EdgePlacementSyncFailure)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EdgeSynConversion) DeepCopyInto(out *EdgeSynConversion) {
 	*out = *in
@@ -415,6 +619,23 @@ func (in *GroupVersionResource) DeepCopy() *GroupVersionResource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IgnoreDifferences) DeepCopyInto(out *IgnoreDifferences) {
+	*out = *in
+	out.GroupResource = in.GroupResource
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IgnoreDifferences.
+func (in *IgnoreDifferences) DeepCopy() *IgnoreDifferences {
+	if in == nil {
+		return nil
+	}
+	out := new(IgnoreDifferences)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Location) DeepCopyInto(out *Location) {
 	*out = *in
@@ -652,6 +873,38 @@ func (in *NonNamespacedObjectReferenceSet) DeepCopy() *NonNamespacedObjectRefere
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObservedScale) DeepCopyInto(out *ObservedScale) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObservedScale.
+func (in *ObservedScale) DeepCopy() *ObservedScale {
+	if in == nil {
+		return nil
+	}
+	out := new(ObservedScale)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RejectedCandidate) DeepCopyInto(out *RejectedCandidate) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RejectedCandidate.
+func (in *RejectedCandidate) DeepCopy() *RejectedCandidate {
+	if in == nil {
+		return nil
+	}
+	out := new(RejectedCandidate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Replacement) DeepCopyInto(out *Replacement) {
 	*out = *in
@@ -668,6 +921,86 @@ func (in *Replacement) DeepCopy() *Replacement {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceConversion) DeepCopyInto(out *ResourceConversion) {
+	*out = *in
+	out.Upstream = in.Upstream
+	out.Downstream = in.Downstream
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceConversion.
+func (in *ResourceConversion) DeepCopy() *ResourceConversion {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceConversion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceDeletionPolicy) DeepCopyInto(out *ResourceDeletionPolicy) {
+	*out = *in
+	out.GroupResource = in.GroupResource
+	in.DeletionPolicy.DeepCopyInto(&out.DeletionPolicy)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceDeletionPolicy.
+func (in *ResourceDeletionPolicy) DeepCopy() *ResourceDeletionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceDeletionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceDriftPolicy) DeepCopyInto(out *ResourceDriftPolicy) {
+	*out = *in
+	out.GroupResource = in.GroupResource
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceDriftPolicy.
+func (in *ResourceDriftPolicy) DeepCopy() *ResourceDriftPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceDriftPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceSubresources) DeepCopyInto(out *ResourceSubresources) {
+	*out = *in
+	out.GroupResource = in.GroupResource
+	if in.Scale != nil {
+		in, out := &in.Scale, &out.Scale
+		*out = new(ScaleSubresource)
+		**out = **in
+	}
+	if in.Names != nil {
+		in, out := &in.Names, &out.Names
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSubresources.
+func (in *ResourceSubresources) DeepCopy() *ResourceSubresources {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceSubresources)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceToSync) DeepCopyInto(out *ResourceToSync) {
 	*out = *in
@@ -690,6 +1023,53 @@ func (in *ResourceToSync) DeepCopy() *ResourceToSync {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutStrategy) DeepCopyInto(out *RolloutStrategy) {
+	*out = *in
+	if in.BatchSize != nil {
+		in, out := &in.BatchSize, &out.BatchSize
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.Pause != nil {
+		in, out := &in.Pause, &out.Pause
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStrategy.
+func (in *RolloutStrategy) DeepCopy() *RolloutStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScaleSubresource) DeepCopyInto(out *ScaleSubresource) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaleSubresource.
+func (in *ScaleSubresource) DeepCopy() *ScaleSubresource {
+	if in == nil {
+		return nil
+	}
+	out := new(ScaleSubresource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SinglePlacement) DeepCopyInto(out *SinglePlacement) {
 	*out = *in
@@ -716,6 +1096,11 @@ func (in *SinglePlacementSlice) DeepCopyInto(out *SinglePlacementSlice) {
 		*out = make([]SinglePlacement, len(*in))
 		copy(*out, *in)
 	}
+	if in.RejectedCandidates != nil {
+		in, out := &in.RejectedCandidates, &out.RejectedCandidates
+		*out = make([]RejectedCandidate, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -770,6 +1155,170 @@ func (in *SinglePlacementSliceList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpreadConstraint) DeepCopyInto(out *SpreadConstraint) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpreadConstraint.
+func (in *SpreadConstraint) DeepCopy() *SpreadConstraint {
+	if in == nil {
+		return nil
+	}
+	out := new(SpreadConstraint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatusCollector) DeepCopyInto(out *StatusCollector) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatusCollector.
+func (in *StatusCollector) DeepCopy() *StatusCollector {
+	if in == nil {
+		return nil
+	}
+	out := new(StatusCollector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StatusCollector) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatusCollectorField) DeepCopyInto(out *StatusCollectorField) {
+	*out = *in
+	if in.Reductions != nil {
+		in, out := &in.Reductions, &out.Reductions
+		*out = make([]StatusReduction, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatusCollectorField.
+func (in *StatusCollectorField) DeepCopy() *StatusCollectorField {
+	if in == nil {
+		return nil
+	}
+	out := new(StatusCollectorField)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatusCollectorList) DeepCopyInto(out *StatusCollectorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]StatusCollector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatusCollectorList.
+func (in *StatusCollectorList) DeepCopy() *StatusCollectorList {
+	if in == nil {
+		return nil
+	}
+	out := new(StatusCollectorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StatusCollectorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatusCollectorSpec) DeepCopyInto(out *StatusCollectorSpec) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Names != nil {
+		in, out := &in.Names, &out.Names
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]StatusCollectorField, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatusCollectorSpec.
+func (in *StatusCollectorSpec) DeepCopy() *StatusCollectorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StatusCollectorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatusCollectorStatus) DeepCopyInto(out *StatusCollectorStatus) {
+	*out = *in
+	if in.Errors != nil {
+		in, out := &in.Errors, &out.Errors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Objects != nil {
+		in, out := &in.Objects, &out.Objects
+		*out = make([]CollectedObjectStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]CollectedFieldSummary, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatusCollectorStatus.
+func (in *StatusCollectorStatus) DeepCopy() *StatusCollectorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StatusCollectorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SyncTarget) DeepCopyInto(out *SyncTarget) {
 	*out = *in
@@ -924,6 +1473,54 @@ func (in *SyncTargetStatus) DeepCopy() *SyncTargetStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncedObjectCount) DeepCopyInto(out *SyncedObjectCount) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncedObjectCount.
+func (in *SyncedObjectCount) DeepCopy() *SyncedObjectCount {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncedObjectCount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncedObjectStatus) DeepCopyInto(out *SyncedObjectStatus) {
+	*out = *in
+	if in.DriftedFields != nil {
+		in, out := &in.DriftedFields, &out.DriftedFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Scale != nil {
+		in, out := &in.Scale, &out.Scale
+		*out = new(ObservedScale)
+		**out = **in
+	}
+	if in.UpsyncSelections != nil {
+		in, out := &in.UpsyncSelections, &out.UpsyncSelections
+		*out = make([]UpsyncSelection, len(*in))
+		copy(*out, *in)
+	}
+	in.LastSyncTime.DeepCopyInto(&out.LastSyncTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncedObjectStatus.
+func (in *SyncedObjectStatus) DeepCopy() *SyncedObjectStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncedObjectStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SyncerConfig) DeepCopyInto(out *SyncerConfig) {
 	*out = *in
@@ -1010,6 +1607,35 @@ func (in *SyncerConfigSpec) DeepCopyInto(out *SyncerConfigSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Conversions != nil {
+		in, out := &in.Conversions, &out.Conversions
+		*out = make([]ResourceConversion, len(*in))
+		copy(*out, *in)
+	}
+	if in.DriftPolicies != nil {
+		in, out := &in.DriftPolicies, &out.DriftPolicies
+		*out = make([]ResourceDriftPolicy, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeletionPolicies != nil {
+		in, out := &in.DeletionPolicies, &out.DeletionPolicies
+		*out = make([]ResourceDeletionPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.IgnoreDifferences != nil {
+		in, out := &in.IgnoreDifferences, &out.IgnoreDifferences
+		*out = make([]IgnoreDifferences, len(*in))
+		copy(*out, *in)
+	}
+	if in.Subresources != nil {
+		in, out := &in.Subresources, &out.Subresources
+		*out = make([]ResourceSubresources, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -1030,6 +1656,32 @@ func (in *SyncerConfigStatus) DeepCopyInto(out *SyncerConfigStatus) {
 		in, out := &in.LastSyncerHeartbeatTime, &out.LastSyncerHeartbeatTime
 		*out = (*in).DeepCopy()
 	}
+	if in.ObjectCounts != nil {
+		in, out := &in.ObjectCounts, &out.ObjectCounts
+		*out = make([]SyncedObjectCount, len(*in))
+		copy(*out, *in)
+	}
+	if in.ObjectStatuses != nil {
+		in, out := &in.ObjectStatuses, &out.ObjectStatuses
+		*out = make([]SyncedObjectStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Allocatable != nil {
+		in, out := &in.Allocatable, &out.Allocatable
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
 	return
 }
 
@@ -1043,6 +1695,22 @@ func (in *SyncerConfigStatus) DeepCopy() *SyncerConfigStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpsyncSelection) DeepCopyInto(out *UpsyncSelection) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpsyncSelection.
+func (in *UpsyncSelection) DeepCopy() *UpsyncSelection {
+	if in == nil {
+		return nil
+	}
+	out := new(UpsyncSelection)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UpsyncSet) DeepCopyInto(out *UpsyncSet) {
 	*out = *in
@@ -1061,6 +1729,16 @@ func (in *UpsyncSet) DeepCopyInto(out *UpsyncSet) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -38,6 +38,7 @@ type EdgeV1alpha1ClusterInterface interface {
 	EdgePlacementsClusterGetter
 	EdgeSyncConfigsClusterGetter
 	SinglePlacementSlicesClusterGetter
+	StatusCollectorsClusterGetter
 	SyncerConfigsClusterGetter
 	SyncTargetsClusterGetter
 	LocationsClusterGetter
@@ -74,6 +75,10 @@ func (c *EdgeV1alpha1ClusterClient) SinglePlacementSlices() SinglePlacementSlice
 	return &singlePlacementSlicesClusterInterface{clientCache: c.clientCache}
 }
 
+func (c *EdgeV1alpha1ClusterClient) StatusCollectors() StatusCollectorClusterInterface {
+	return &statusCollectorsClusterInterface{clientCache: c.clientCache}
+}
+
 func (c *EdgeV1alpha1ClusterClient) SyncerConfigs() SyncerConfigClusterInterface {
 	return &syncerConfigsClusterInterface{clientCache: c.clientCache}
 }
@@ -60,6 +60,10 @@ func (c *EdgeV1alpha1ClusterClient) SinglePlacementSlices() kcpedgev1alpha1.Sing
 	return &singlePlacementSlicesClusterClient{Fake: c.Fake}
 }
 
+func (c *EdgeV1alpha1ClusterClient) StatusCollectors() kcpedgev1alpha1.StatusCollectorClusterInterface {
+	return &statusCollectorsClusterClient{Fake: c.Fake}
+}
+
 func (c *EdgeV1alpha1ClusterClient) SyncerConfigs() kcpedgev1alpha1.SyncerConfigClusterInterface {
 	return &syncerConfigsClusterClient{Fake: c.Fake}
 }
@@ -100,6 +104,10 @@ func (c *EdgeV1alpha1Client) SinglePlacementSlices() edgev1alpha1.SinglePlacemen
 	return &singlePlacementSlicesClient{Fake: c.Fake, ClusterPath: c.ClusterPath}
 }
 
+func (c *EdgeV1alpha1Client) StatusCollectors() edgev1alpha1.StatusCollectorInterface {
+	return &statusCollectorsClient{Fake: c.Fake, ClusterPath: c.ClusterPath}
+}
+
 func (c *EdgeV1alpha1Client) SyncerConfigs() edgev1alpha1.SyncerConfigInterface {
 	return &syncerConfigsClient{Fake: c.Fake, ClusterPath: c.ClusterPath}
 }
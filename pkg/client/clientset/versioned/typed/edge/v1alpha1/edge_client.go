@@ -34,6 +34,7 @@ type EdgeV1alpha1Interface interface {
 	EdgeSyncConfigsGetter
 	LocationsGetter
 	SinglePlacementSlicesGetter
+	StatusCollectorsGetter
 	SyncTargetsGetter
 	SyncerConfigsGetter
 }
@@ -63,6 +64,10 @@ func (c *EdgeV1alpha1Client) SinglePlacementSlices() SinglePlacementSliceInterfa
 	return newSinglePlacementSlices(c)
 }
 
+func (c *EdgeV1alpha1Client) StatusCollectors() StatusCollectorInterface {
+	return newStatusCollectors(c)
+}
+
 func (c *EdgeV1alpha1Client) SyncTargets() SyncTargetInterface {
 	return newSyncTargets(c)
 }
@@ -49,6 +49,10 @@ func (c *FakeEdgeV1alpha1) SinglePlacementSlices() v1alpha1.SinglePlacementSlice
 	return &FakeSinglePlacementSlices{c}
 }
 
+func (c *FakeEdgeV1alpha1) StatusCollectors() v1alpha1.StatusCollectorInterface {
+	return &FakeStatusCollectors{c}
+}
+
 func (c *FakeEdgeV1alpha1) SyncTargets() v1alpha1.SyncTargetInterface {
 	return &FakeSyncTargets{c}
 }
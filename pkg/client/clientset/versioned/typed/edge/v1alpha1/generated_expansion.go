@@ -28,6 +28,8 @@ type LocationExpansion interface{}
 
 type SinglePlacementSliceExpansion interface{}
 
+type StatusCollectorExpansion interface{}
+
 type SyncTargetExpansion interface{}
 
 type SyncerConfigExpansion interface{}
@@ -34,6 +34,8 @@ type ClusterInterface interface {
 	EdgeSyncConfigs() EdgeSyncConfigClusterInformer
 	// SinglePlacementSlices returns a SinglePlacementSliceClusterInformer
 	SinglePlacementSlices() SinglePlacementSliceClusterInformer
+	// StatusCollectors returns a StatusCollectorClusterInformer
+	StatusCollectors() StatusCollectorClusterInformer
 	// SyncerConfigs returns a SyncerConfigClusterInformer
 	SyncerConfigs() SyncerConfigClusterInformer
 	// SyncTargets returns a SyncTargetClusterInformer
@@ -72,6 +74,11 @@ func (v *version) SinglePlacementSlices() SinglePlacementSliceClusterInformer {
 	return &singlePlacementSliceClusterInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
 }
 
+// StatusCollectors returns a StatusCollectorClusterInformer
+func (v *version) StatusCollectors() StatusCollectorClusterInformer {
+	return &statusCollectorClusterInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // SyncerConfigs returns a SyncerConfigClusterInformer
 func (v *version) SyncerConfigs() SyncerConfigClusterInformer {
 	return &syncerConfigClusterInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
@@ -96,6 +103,8 @@ type Interface interface {
 	EdgeSyncConfigs() EdgeSyncConfigInformer
 	// SinglePlacementSlices returns a SinglePlacementSliceInformer
 	SinglePlacementSlices() SinglePlacementSliceInformer
+	// StatusCollectors returns a StatusCollectorInformer
+	StatusCollectors() StatusCollectorInformer
 	// SyncerConfigs returns a SyncerConfigInformer
 	SyncerConfigs() SyncerConfigInformer
 	// SyncTargets returns a SyncTargetInformer
@@ -135,6 +144,11 @@ func (v *scopedVersion) SinglePlacementSlices() SinglePlacementSliceInformer {
 	return &singlePlacementSliceScopedInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
 }
 
+// StatusCollectors returns a StatusCollectorInformer
+func (v *scopedVersion) StatusCollectors() StatusCollectorInformer {
+	return &statusCollectorScopedInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // SyncerConfigs returns a SyncerConfigInformer
 func (v *scopedVersion) SyncerConfigs() SyncerConfigInformer {
 	return &syncerConfigScopedInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
@@ -97,6 +97,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericClusterInformer{resource: resource.GroupResource(), informer: f.Edge().V1alpha1().EdgeSyncConfigs().Informer()}, nil
 	case edgev1alpha1.SchemeGroupVersion.WithResource("singleplacementslices"):
 		return &genericClusterInformer{resource: resource.GroupResource(), informer: f.Edge().V1alpha1().SinglePlacementSlices().Informer()}, nil
+	case edgev1alpha1.SchemeGroupVersion.WithResource("statuscollectors"):
+		return &genericClusterInformer{resource: resource.GroupResource(), informer: f.Edge().V1alpha1().StatusCollectors().Informer()}, nil
 	case edgev1alpha1.SchemeGroupVersion.WithResource("syncerconfigs"):
 		return &genericClusterInformer{resource: resource.GroupResource(), informer: f.Edge().V1alpha1().SyncerConfigs().Informer()}, nil
 	case edgev1alpha1.SchemeGroupVersion.WithResource("synctargets"):
@@ -133,6 +135,9 @@ func (f *sharedScopedInformerFactory) ForResource(resource schema.GroupVersionRe
 	case edgev1alpha1.SchemeGroupVersion.WithResource("singleplacementslices"):
 		informer := f.Edge().V1alpha1().SinglePlacementSlices().Informer()
 		return &genericInformer{lister: cache.NewGenericLister(informer.GetIndexer(), resource.GroupResource()), informer: informer}, nil
+	case edgev1alpha1.SchemeGroupVersion.WithResource("statuscollectors"):
+		informer := f.Edge().V1alpha1().StatusCollectors().Informer()
+		return &genericInformer{lister: cache.NewGenericLister(informer.GetIndexer(), resource.GroupResource()), informer: informer}, nil
 	case edgev1alpha1.SchemeGroupVersion.WithResource("syncerconfigs"):
 		informer := f.Edge().V1alpha1().SyncerConfigs().Informer()
 		return &genericInformer{lister: cache.NewGenericLister(informer.GetIndexer(), resource.GroupResource()), informer: informer}, nil
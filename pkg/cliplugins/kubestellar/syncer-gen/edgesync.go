@@ -26,7 +26,6 @@ import (
 	"errors"
 	"fmt"
 	"net"
-	"os"
 	"strings"
 	"text/template"
 	"time"
@@ -39,6 +38,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -52,6 +52,7 @@ import (
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/restmapper"
 	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
 
 	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
 	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
@@ -64,6 +65,12 @@ var embeddedResources embed.FS
 
 const (
 	SyncerSecretConfigKey = "kubeconfig"
+	// SyncerSecretTokenKey is the key, in the syncer's Secret, of the token that the kubeconfig refers to.
+	// The token is kept apart from the kubeconfig so that the syncer picks up a new one without restarting.
+	SyncerSecretTokenKey = "token"
+	// DefaultBootstrapTokenLifetime is the default lifetime of the bound token that a syncer
+	// exchanging its token for short-lived ones is given.
+	DefaultBootstrapTokenLifetime = 30 * 24 * time.Hour
 )
 
 // EdgeSyncOptions contains options for configuring a SyncTarget and its corresponding syncer.
@@ -89,6 +96,31 @@ type EdgeSyncOptions struct {
 	SyncTargetName string
 	// SyncTargetLabels are the labels to be applied to the SyncTarget in the kcp workspace.
 	SyncTargetLabels []string
+	// TokenLifetime, if not zero, makes the syncer exchange its token for short-lived ones of this lifetime.
+	TokenLifetime time.Duration
+	// BootstrapTokenLifetime is the lifetime of the bound token that the syncer is given, in place of
+	// the long-lived ServiceAccount token, when TokenLifetime is set.
+	BootstrapTokenLifetime time.Duration
+	// OutputFormat is the format of the output: yaml, kustomize or helm.
+	OutputFormat string
+	// ImageRegistry, if not empty, replaces the registry of SyncerImage.
+	ImageRegistry string
+	// ImagePullSecrets are the names of the Secrets, in the syncer's namespace in the physical cluster,
+	// to pull the syncer image with.
+	ImagePullSecrets []string
+	// ResourceRequests are the resource requests of the syncer container, as quantities by resource name.
+	ResourceRequests map[string]string
+	// ResourceLimits are the resource limits of the syncer container, as quantities by resource name.
+	ResourceLimits map[string]string
+	// NodeSelector is the node selector of the syncer pods.
+	NodeSelector map[string]string
+	// Tolerations are the tolerations of the syncer pods, each in the format key[=value][:effect].
+	Tolerations []string
+	// StateVolumeSize, if not empty, is the size of a PersistentVolumeClaim that is mounted
+	// in the syncer pods as the syncer's --state-dir.
+	StateVolumeSize string
+	// StateStorageClass is the storage class of the state volume; the cluster's default when empty.
+	StateStorageClass string
 }
 
 // NewSyncOptions returns a new EdgeSyncOptions.
@@ -96,10 +128,12 @@ func NewEdgeSyncOptions(streams genericclioptions.IOStreams) *EdgeSyncOptions {
 	return &EdgeSyncOptions{
 		Options: base.NewOptions(streams),
 
-		Replicas:     1,
-		KCPNamespace: "default",
-		QPS:          20,
-		Burst:        30,
+		Replicas:               1,
+		KCPNamespace:           "default",
+		QPS:                    20,
+		Burst:                  30,
+		OutputFormat:           OutputFormatYAML,
+		BootstrapTokenLifetime: DefaultBootstrapTokenLifetime,
 	}
 }
 
@@ -108,13 +142,24 @@ func (o *EdgeSyncOptions) BindFlags(cmd *cobra.Command) {
 	o.Options.BindFlags(cmd)
 
 	cmd.Flags().StringVar(&o.SyncerImage, "syncer-image", o.SyncerImage, "The kubestellar-syncer image to use in the syncer's deployment YAML. Images are published at https://quay.io/repository/kcpedge/syncer")
-	cmd.Flags().IntVar(&o.Replicas, "replicas", o.Replicas, "Number of replicas of the syncer deployment.")
+	cmd.Flags().IntVar(&o.Replicas, "replicas", o.Replicas, "Number of replicas of the syncer deployment. With more than one, the replicas elect a leader and are spread across nodes.")
 	cmd.Flags().StringVar(&o.KCPNamespace, "kcp-namespace", o.KCPNamespace, "The name of the kcp namespace to create a service account in.")
-	cmd.Flags().StringVarP(&o.OutputFile, "output-file", "o", o.OutputFile, "The manifest file to be created and applied to the physical cluster. Use - for stdout.")
+	cmd.Flags().StringVarP(&o.OutputFile, "output-file", "o", o.OutputFile, "The manifest file to be created and applied to the physical cluster. Use - for stdout. With --output-format kustomize or helm, the directory to write into.")
+	cmd.Flags().StringVar(&o.OutputFormat, "output-format", o.OutputFormat, "The format of the output: yaml for a single manifest, kustomize for a Kustomize base, helm for a Helm chart whose values are the image, pull secrets, resources, node selector, tolerations and token of the syncer.")
 	cmd.Flags().StringVarP(&o.DownstreamNamespace, "namespace", "n", o.DownstreamNamespace, "The namespace to create the syncer in the physical cluster. By default this is \"kubestellar-syncer-<synctarget-name>-<uid>\".")
 	cmd.Flags().Float32Var(&o.QPS, "qps", o.QPS, "QPS to use when talking to API servers.")
 	cmd.Flags().IntVar(&o.Burst, "burst", o.Burst, "Burst to use when talking to API servers.")
 	cmd.Flags().StringSliceVar(&o.SyncTargetLabels, "labels", o.SyncTargetLabels, "Labels to apply on the SyncTarget created in kcp, each label should be in the format of key=value.")
+	cmd.Flags().StringVar(&o.ImageRegistry, "image-registry", o.ImageRegistry, "If set, replaces the registry of --syncer-image, for example with a mirror in an air-gapped environment.")
+	cmd.Flags().StringSliceVar(&o.ImagePullSecrets, "image-pull-secret", o.ImagePullSecrets, "Name of a Secret in the syncer's namespace of the physical cluster to pull the syncer image with. May be repeated.")
+	cmd.Flags().StringToStringVar(&o.ResourceRequests, "requests", o.ResourceRequests, "Resource requests of the syncer container, for example cpu=100m,memory=128Mi.")
+	cmd.Flags().StringToStringVar(&o.ResourceLimits, "limits", o.ResourceLimits, "Resource limits of the syncer container, for example cpu=500m,memory=256Mi.")
+	cmd.Flags().StringToStringVar(&o.NodeSelector, "node-selector", o.NodeSelector, "Node selector of the syncer pods, for example kubernetes.io/arch=arm64.")
+	cmd.Flags().StringArrayVar(&o.Tolerations, "toleration", o.Tolerations, "Toleration of the syncer pods in the format key[=value][:effect], for example node-role.kubernetes.io/edge:NoSchedule. May be repeated.")
+	cmd.Flags().StringVar(&o.StateVolumeSize, "state-volume-size", o.StateVolumeSize, "If set, the size of a PersistentVolumeClaim, for example 1Gi, that keeps the syncer's local state so that it keeps working while kcp is unreachable. With more than one replica the claim is ReadWriteMany, since the replicas share the state.")
+	cmd.Flags().StringVar(&o.StateStorageClass, "state-storage-class", o.StateStorageClass, "The storage class of the --state-volume-size claim. The cluster's default when not set.")
+	cmd.Flags().DurationVar(&o.TokenLifetime, "token-lifetime", o.TokenLifetime, "If set, the syncer is given a bound token of --bootstrap-token-lifetime instead of the long-lived ServiceAccount token, uses it only to request short-lived tokens of this lifetime, and authenticates to kcp with those.")
+	cmd.Flags().DurationVar(&o.BootstrapTokenLifetime, "bootstrap-token-lifetime", o.BootstrapTokenLifetime, "The lifetime of the bound token that the syncer is given with --token-lifetime. Renew it with syncer-gen rotate --bootstrap-token-lifetime before it expires.")
 }
 
 // Complete ensures all dynamically populated fields are initialized.
@@ -147,15 +192,32 @@ func (o *EdgeSyncOptions) Validate() error {
 	if o.Replicas < 0 {
 		errs = append(errs, errors.New("--replicas cannot be negative"))
 	}
-	if o.Replicas > 1 {
-		// TODO: relax when we have leader-election in the syncer
-		errs = append(errs, errors.New("only 0 and 1 are valid values for --replicas"))
-	}
 
 	if o.OutputFile == "" {
 		errs = append(errs, errors.New("--output-file is required"))
 	}
 
+	switch o.OutputFormat {
+	case OutputFormatYAML:
+	case OutputFormatKustomize, OutputFormatHelm:
+		if o.OutputFile == "-" {
+			errs = append(errs, fmt.Errorf("--output-file must be a directory with --output-format %s", o.OutputFormat))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("--output-format must be one of %v", outputFormats))
+	}
+
+	if err := o.customizePods(&templateInputForEdge{}); err != nil {
+		errs = append(errs, err)
+	}
+
+	if o.TokenLifetime != 0 && o.TokenLifetime < 10*time.Minute {
+		errs = append(errs, errors.New("--token-lifetime must be at least 10m"))
+	}
+	if o.TokenLifetime != 0 && o.BootstrapTokenLifetime < o.TokenLifetime {
+		errs = append(errs, errors.New("--bootstrap-token-lifetime must be at least --token-lifetime"))
+	}
+
 	for _, l := range o.SyncTargetLabels {
 		if len(strings.Split(l, "=")) != 2 {
 			errs = append(errs, fmt.Errorf("label '%s' is not in the format of key=value", l))
@@ -173,17 +235,6 @@ func (o *EdgeSyncOptions) Run(ctx context.Context) error {
 		return err
 	}
 
-	var outputFile *os.File
-	if o.OutputFile == "-" {
-		outputFile = os.Stdout
-	} else {
-		outputFile, err = os.Create(o.OutputFile)
-		if err != nil {
-			return err
-		}
-		defer outputFile.Close()
-	}
-
 	labels := map[string]string{}
 	for _, l := range o.SyncTargetLabels {
 		parts := strings.Split(l, "=")
@@ -198,36 +249,14 @@ func (o *EdgeSyncOptions) Run(ctx context.Context) error {
 		return err
 	}
 
-	configURL, _, err := helpers.ParseClusterURL(config.Host)
-	if err != nil {
-		return fmt.Errorf("current URL %q does not point to workspace", config.Host)
-	}
-
-	// Make sure the generated URL has the port specified correctly.
-	if _, _, err = net.SplitHostPort(configURL.Host); err != nil {
-		var addrErr *net.AddrError
-		const missingPort = "missing port in address"
-		if errors.As(err, &addrErr) && addrErr.Err == missingPort {
-			if configURL.Scheme == "https" {
-				configURL.Host = net.JoinHostPort(configURL.Host, "443")
-			} else {
-				configURL.Host = net.JoinHostPort(configURL.Host, "80")
-			}
-		} else {
-			return fmt.Errorf("failed to parse host %q: %w", configURL.Host, err)
-		}
-	}
-
 	if o.DownstreamNamespace == "" {
 		o.DownstreamNamespace = syncerID
 	}
 
-	// Compose the syncer's upstream configuration server URL without any path. This is
-	// required so long as the API importer and syncer expect to require cluster clients.
-	//
-	// TODO(marun) It's probably preferable that the syncer and importer are provided a
-	// cluster configuration since they only operate against a single workspace.
-	serverURL := configURL.Scheme + "://" + configURL.Host
+	serverURL, err := syncerServerURL(config)
+	if err != nil {
+		return err
+	}
 	input := templateInputForEdge{
 		ServerURL:    serverURL,
 		CAData:       base64.StdEncoding.EncodeToString(config.CAData),
@@ -239,23 +268,105 @@ func (o *EdgeSyncOptions) Run(ctx context.Context) error {
 		SyncTarget:     o.SyncTargetName,
 		SyncTargetUID:  string(edgeSyncTarget.UID),
 
-		Image:    o.SyncerImage,
-		Replicas: o.Replicas,
-		QPS:      o.QPS,
-		Burst:    o.Burst,
+		Image:         overrideImageRegistry(o.SyncerImage, o.ImageRegistry),
+		Replicas:      o.Replicas,
+		QPS:           o.QPS,
+		Burst:         o.Burst,
+		TokenLifetime: o.TokenLifetime,
+	}
+	if err := o.customizePods(&input); err != nil {
+		return err
 	}
 
-	resources, err := renderKubeStellarSyncerResources(input, syncerID)
+	files, err := renderOutput(o.OutputFormat, input, syncerID)
 	if err != nil {
 		return err
 	}
+	if err := writeOutput(o.OutputFormat, o.OutputFile, o.Out, files); err != nil {
+		return err
+	}
 
-	_, err = outputFile.Write(resources)
+	applyCommand := fmt.Sprintf("kubectl apply -f %q", o.OutputFile)
+	switch o.OutputFormat {
+	case OutputFormatKustomize:
+		applyCommand = fmt.Sprintf("kubectl apply -k %q", o.OutputFile)
+	case OutputFormatHelm:
+		applyCommand = fmt.Sprintf("helm install %s %q", syncerID, o.OutputFile)
+	}
 	if o.OutputFile != "-" {
-		fmt.Fprintf(o.ErrOut, "\nWrote physical cluster manifest to %s for namespace %q. Use\n\n  KUBECONFIG=<pcluster-config> kubectl apply -f %q\n\nto apply it. "+
-			"Use\n\n  KUBECONFIG=<pcluster-config> kubectl get deployment -n %q %s\n\nto verify the syncer pod is running.\n", o.OutputFile, o.DownstreamNamespace, o.OutputFile, o.DownstreamNamespace, syncerID)
+		fmt.Fprintf(o.ErrOut, "\nWrote physical cluster manifest to %s for namespace %q. Use\n\n  KUBECONFIG=<pcluster-config> %s\n\nto apply it. "+
+			"Use\n\n  KUBECONFIG=<pcluster-config> kubectl get deployment -n %q %s\n\nto verify the syncer pod is running.\n", o.OutputFile, o.DownstreamNamespace, applyCommand, o.DownstreamNamespace, syncerID)
+	}
+	return nil
+}
+
+// customizePods sets the pull secrets, resources, node selector and tolerations of the syncer pods in input.
+func (o *EdgeSyncOptions) customizePods(input *templateInputForEdge) error {
+	for _, name := range o.ImagePullSecrets {
+		input.ImagePullSecrets = append(input.ImagePullSecrets, corev1.LocalObjectReference{Name: name})
 	}
-	return err
+	requests, err := parseResourceList(o.ResourceRequests)
+	if err != nil {
+		return fmt.Errorf("invalid --requests: %w", err)
+	}
+	limits, err := parseResourceList(o.ResourceLimits)
+	if err != nil {
+		return fmt.Errorf("invalid --limits: %w", err)
+	}
+	if requests != nil || limits != nil {
+		input.Resources = &corev1.ResourceRequirements{Requests: requests, Limits: limits}
+	}
+	if len(o.NodeSelector) > 0 {
+		input.NodeSelector = o.NodeSelector
+	}
+	for _, spec := range o.Tolerations {
+		toleration, err := parseToleration(spec)
+		if err != nil {
+			return err
+		}
+		input.Tolerations = append(input.Tolerations, toleration)
+	}
+	if o.StateVolumeSize != "" {
+		if _, err := resource.ParseQuantity(o.StateVolumeSize); err != nil {
+			return fmt.Errorf("invalid --state-volume-size: %w", err)
+		}
+		input.StateVolumeSize = o.StateVolumeSize
+		input.StateStorageClass = o.StateStorageClass
+	} else if o.StateStorageClass != "" {
+		return errors.New("--state-storage-class requires --state-volume-size")
+	}
+	return nil
+}
+
+// syncerServerURL returns the URL of the kcp server, without any path, for the syncer's kubeconfig.
+// The given config is for a logical cluster (workspace).
+func syncerServerURL(config *rest.Config) (string, error) {
+	configURL, _, err := helpers.ParseClusterURL(config.Host)
+	if err != nil {
+		return "", fmt.Errorf("current URL %q does not point to workspace", config.Host)
+	}
+
+	// Make sure the generated URL has the port specified correctly.
+	if _, _, err = net.SplitHostPort(configURL.Host); err != nil {
+		var addrErr *net.AddrError
+		const missingPort = "missing port in address"
+		if errors.As(err, &addrErr) && addrErr.Err == missingPort {
+			if configURL.Scheme == "https" {
+				configURL.Host = net.JoinHostPort(configURL.Host, "443")
+			} else {
+				configURL.Host = net.JoinHostPort(configURL.Host, "80")
+			}
+		} else {
+			return "", fmt.Errorf("failed to parse host %q: %w", configURL.Host, err)
+		}
+	}
+
+	// Compose the syncer's upstream configuration server URL without any path. This is
+	// required so long as the API importer and syncer expect to require cluster clients.
+	//
+	// TODO(marun) It's probably preferable that the syncer and importer are provided a
+	// cluster configuration since they only operate against a single workspace.
+	return configURL.Scheme + "://" + configURL.Host, nil
 }
 
 // getKubeStellarSyncerID returns a unique ID for a syncer derived from the name and its UID. It's
@@ -475,6 +586,17 @@ func (o *EdgeSyncOptions) enableSyncerForWorkspace(ctx context.Context, config *
 		return "", "", nil, err
 	}
 
+	// A syncer that exchanges its token for short-lived ones is given a bound token,
+	// so that no long-lived credential leaves kcp
+	if o.TokenLifetime != 0 {
+		fmt.Fprintf(o.ErrOut, "Requesting a bootstrap token for service account %q, valid for %s\n", syncerID, o.BootstrapTokenLifetime)
+		token, err := requestBootstrapToken(ctx, kubeClient, namespace, sa.Name, o.BootstrapTokenLifetime)
+		if err != nil {
+			return "", "", nil, err
+		}
+		return token, syncerID, edgeSyncTarget, nil
+	}
+
 	// Wait for the service account to be updated with the name of the token secret
 	tokenSecretName := ""
 	err = wait.PollImmediateWithContext(ctx, 100*time.Millisecond, 20*time.Second, func(ctx context.Context) (bool, error) {
@@ -554,12 +676,28 @@ type templateInputForEdge struct {
 	SyncTargetUID string
 	// Image is the name of the container image that the syncer deployment will use
 	Image string
-	// Replicas is the number of syncer pods to run (should be 0 or 1).
+	// Replicas is the number of syncer pods to run. With more than one, the syncers
+	// use leader election and the pods get anti-affinity and a PodDisruptionBudget.
 	Replicas int
 	// QPS is the qps the syncer uses when talking to an apiserver.
 	QPS float32
 	// Burst is the burst the syncer uses when talking to an apiserver.
 	Burst int
+	// TokenLifetime, if not zero, is the lifetime of the short-lived tokens that the syncer
+	// exchanges its token for.
+	TokenLifetime time.Duration
+	// ImagePullSecrets are the Secrets to pull the syncer image with
+	ImagePullSecrets []corev1.LocalObjectReference
+	// Resources, if not nil, are the resource requirements of the syncer container
+	Resources *corev1.ResourceRequirements
+	// NodeSelector is the node selector of the syncer pods
+	NodeSelector map[string]string
+	// Tolerations are the tolerations of the syncer pods
+	Tolerations []corev1.Toleration
+	// StateVolumeSize, if not empty, is the size of the PersistentVolumeClaim for the syncer's local state
+	StateVolumeSize string
+	// StateStorageClass is the storage class of that claim, if not the default
+	StateStorageClass string
 }
 
 // templateArgsForEdge represents the full set of arguments required to render the resources
@@ -585,18 +723,34 @@ type templateArgsForEdge struct {
 	Secret string
 	// Key in the syncer secret for the kcp logical cluster kubconfig.
 	SecretConfigKey string
+	// Key in the syncer secret for the token that the kubeconfig refers to.
+	SecretTokenKey string
 	// Deployment is the name of the deployment that will run the syncer in the
 	// pcluster.
 	Deployment string
 	// DeploymentApp is the label value that the syncer's deployment will select its
 	// pods with.
 	DeploymentApp string
+	// SeparateSecret leaves the syncer's Secret out of the rendered resources, to be
+	// rendered on its own.
+	SeparateSecret bool
+	// Helm makes the rendered resources a Helm chart template, taking the pull secrets,
+	// resources, node selector and tolerations of the syncer pods from the chart values.
+	Helm bool
 }
 
 // renderKubeStellarSyncerResources renders the resources required to deploy a syncer to a pcluster.
 func renderKubeStellarSyncerResources(input templateInputForEdge, syncerID string) ([]byte, error) {
+	return renderTemplate("kubestellar-syncer.yaml", syncerTemplateArgs(input, syncerID))
+}
+
+// renderKubeStellarSyncerSecret renders only the syncer's Secret, which holds its credential for kcp.
+func renderKubeStellarSyncerSecret(input templateInputForEdge, syncerID string) ([]byte, error) {
+	return renderTemplate("secret", syncerTemplateArgs(input, syncerID))
+}
 
-	tmplArgs := templateArgsForEdge{
+func syncerTemplateArgs(input templateInputForEdge, syncerID string) templateArgsForEdge {
+	return templateArgsForEdge{
 		templateInputForEdge: input,
 		ServiceAccount:       syncerID,
 		ClusterRole:          syncerID,
@@ -604,26 +758,42 @@ func renderKubeStellarSyncerResources(input templateInputForEdge, syncerID strin
 		GroupMappings:        []groupMappingForEdge{},
 		Secret:               syncerID,
 		SecretConfigKey:      SyncerSecretConfigKey,
+		SecretTokenKey:       SyncerSecretTokenKey,
 		Deployment:           syncerID,
 		DeploymentApp:        syncerID,
 	}
+}
 
-	syncerTemplate, err := embeddedResources.ReadFile("kubestellar-syncer.yaml")
-	if err != nil {
-		return nil, err
-	}
-	tmpl, err := template.New("syncerTemplate").Parse(string(syncerTemplate))
+// renderTemplate renders the named template of the embedded files.
+func renderTemplate(name string, tmplArgs templateArgsForEdge) ([]byte, error) {
+	tmpl, err := template.New("").Funcs(templateFuncs).ParseFS(embeddedResources, "*.yaml")
 	if err != nil {
 		return nil, err
 	}
 	buffer := bytes.NewBuffer([]byte{})
-	err = tmpl.Execute(buffer, tmplArgs)
+	err = tmpl.ExecuteTemplate(buffer, name, tmplArgs)
 	if err != nil {
 		return nil, err
 	}
 	return buffer.Bytes(), nil
 }
 
+var templateFuncs = template.FuncMap{
+	"toYaml": func(value interface{}) (string, error) {
+		valueYAML, err := yaml.Marshal(value)
+		return strings.TrimSuffix(string(valueYAML), "\n"), err
+	},
+	"indent": func(spaces int, text string) string {
+		prefix := strings.Repeat(" ", spaces)
+		return prefix + strings.ReplaceAll(text, "\n", "\n"+prefix)
+	},
+	// helmValue renders a field, at the given indentation, that is set from the chart value of the same name when that is not empty
+	"helmValue": func(name string, spaces int) string {
+		prefix := strings.Repeat(" ", spaces)
+		return fmt.Sprintf("%[2]s{{- with .Values.%[1]s }}\n%[2]s%[1]s:\n%[2]s  {{- toYaml . | nindent %[3]d }}\n%[2]s{{- end }}", name, prefix, spaces+2)
+	},
+}
+
 // groupMappingForEdge associates an api group to the resources in that group.
 type groupMappingForEdge struct {
 	APIGroup  string
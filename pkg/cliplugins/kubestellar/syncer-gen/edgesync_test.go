@@ -17,7 +17,9 @@ limitations under the License.
 package plugin
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/require"
@@ -83,6 +85,7 @@ metadata:
   name: kcp-syncer-sync-target-name-34b23c4k
   namespace: kubestellar-syncer-sync-target-name-34b23c4k
 stringData:
+  token: token
   kubeconfig: |
     apiVersion: v1
     kind: Config
@@ -101,7 +104,7 @@ stringData:
     users:
     - name: default-user
       user:
-        token: token
+        tokenFile: /kcp/token
 ---
 apiVersion: apps/v1
 kind: Deployment
@@ -139,6 +142,20 @@ spec:
               fieldPath: metadata.namespace
         image: image
         imagePullPolicy: IfNotPresent
+        ports:
+        - name: metrics
+          containerPort: 10205
+        livenessProbe:
+          httpGet:
+            path: /healthz
+            port: metrics
+          initialDelaySeconds: 10
+          periodSeconds: 10
+        readinessProbe:
+          httpGet:
+            path: /readyz
+            port: metrics
+          periodSeconds: 10
         terminationMessagePolicy: FallbackToLogsOnError
         volumeMounts:
         - name: kcp-config
@@ -169,3 +186,132 @@ spec:
 	require.NoError(t, err)
 	require.Empty(t, cmp.Diff(expectedYAML, string(actualYAML)))
 }
+
+func TestNewKubeStellarSyncerYAMLWithReplicas(t *testing.T) {
+	actualYAML, err := renderKubeStellarSyncerResources(templateInputForEdge{
+		ServerURL:      "server-url",
+		Token:          "token",
+		CAData:         "ca-data",
+		KCPNamespace:   "kcp-namespace",
+		Namespace:      "kubestellar-syncer-sync-target-name-34b23c4k",
+		SyncTargetPath: "root:default:foo",
+		SyncTarget:     "sync-target-name",
+		SyncTargetUID:  "sync-target-uid",
+		Image:          "image",
+		Replicas:       3,
+		QPS:            123.4,
+		Burst:          456,
+	}, "kcp-syncer-sync-target-name-34b23c4k")
+	require.NoError(t, err)
+	actual := string(actualYAML)
+	for _, expected := range []string{`
+  replicas: 3
+  strategy:
+    type: RollingUpdate
+`, `
+        - --burst=456
+        - --leader-elect
+        - --v=3
+`, `
+      serviceAccountName: kcp-syncer-sync-target-name-34b23c4k
+      affinity:
+        podAntiAffinity:
+          preferredDuringSchedulingIgnoredDuringExecution:
+          - weight: 100
+            podAffinityTerm:
+              topologyKey: kubernetes.io/hostname
+              labelSelector:
+                matchLabels:
+                  app: kcp-syncer-sync-target-name-34b23c4k
+      volumes:
+`, `
+            optional: false
+---
+apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  name: kcp-syncer-sync-target-name-34b23c4k
+  namespace: kubestellar-syncer-sync-target-name-34b23c4k
+spec:
+  maxUnavailable: 1
+  selector:
+    matchLabels:
+      app: kcp-syncer-sync-target-name-34b23c4k
+`} {
+		require.Contains(t, actual, expected)
+	}
+	require.True(t, strings.HasSuffix(actual, "      app: kcp-syncer-sync-target-name-34b23c4k\n"))
+}
+
+func TestNewKubeStellarSyncerYAMLWithTokenLifetime(t *testing.T) {
+	actualYAML, err := renderKubeStellarSyncerResources(templateInputForEdge{
+		ServerURL:      "server-url",
+		Token:          "token",
+		CAData:         "ca-data",
+		KCPNamespace:   "kcp-namespace",
+		Namespace:      "kubestellar-syncer-sync-target-name-34b23c4k",
+		SyncTargetPath: "root:default:foo",
+		SyncTarget:     "sync-target-name",
+		SyncTargetUID:  "sync-target-uid",
+		Image:          "image",
+		Replicas:       1,
+		QPS:            123.4,
+		Burst:          456,
+		TokenLifetime:  time.Hour,
+	}, "kcp-syncer-sync-target-name-34b23c4k")
+	require.NoError(t, err)
+	require.Contains(t, string(actualYAML), `
+        - --burst=456
+        - --upstream-service-account=kcp-syncer-sync-target-name-34b23c4k
+        - --upstream-token-lifetime=1h0m0s
+`)
+}
+
+func TestNewKubeStellarSyncerYAMLWithStateVolume(t *testing.T) {
+	actualYAML, err := renderKubeStellarSyncerResources(templateInputForEdge{
+		ServerURL:         "server-url",
+		Token:             "token",
+		CAData:            "ca-data",
+		KCPNamespace:      "kcp-namespace",
+		Namespace:         "kubestellar-syncer-sync-target-name-34b23c4k",
+		SyncTargetPath:    "root:default:foo",
+		SyncTarget:        "sync-target-name",
+		SyncTargetUID:     "sync-target-uid",
+		Image:             "image",
+		Replicas:          2,
+		QPS:               123.4,
+		Burst:             456,
+		StateVolumeSize:   "1Gi",
+		StateStorageClass: "nfs",
+	}, "kcp-syncer-sync-target-name-34b23c4k")
+	require.NoError(t, err)
+	actual := string(actualYAML)
+	for _, expected := range []string{`
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: kcp-syncer-sync-target-name-34b23c4k-state
+  namespace: kubestellar-syncer-sync-target-name-34b23c4k
+spec:
+  accessModes:
+  - ReadWriteMany
+  storageClassName: nfs
+  resources:
+    requests:
+      storage: 1Gi
+---
+`, `
+        - --leader-elect
+        - --state-dir=/var/lib/kubestellar-syncer
+        - --v=3
+`, `
+        - name: state
+          mountPath: /var/lib/kubestellar-syncer
+`, `
+        - name: state
+          persistentVolumeClaim:
+            claimName: kcp-syncer-sync-target-name-34b23c4k-state
+`} {
+		require.Contains(t, actual, expected)
+	}
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// OutputFormatYAML is a single manifest file with all the syncer's resources.
+	OutputFormatYAML = "yaml"
+	// OutputFormatKustomize is a directory holding a Kustomize base, with the syncer's Secret in its own file.
+	OutputFormatKustomize = "kustomize"
+	// OutputFormatHelm is a directory holding a Helm chart. The image, pull secrets, resources,
+	// node selector, tolerations and token of the syncer are values of the chart.
+	OutputFormatHelm = "helm"
+)
+
+var outputFormats = []string{OutputFormatYAML, OutputFormatKustomize, OutputFormatHelm}
+
+const kustomization = `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- kubestellar-syncer.yaml
+- kubestellar-syncer-secret.yaml
+`
+
+const chart = `apiVersion: v2
+name: kubestellar-syncer
+description: KubeStellar-Syncer for SyncTarget %s
+type: application
+version: 0.1.0
+`
+
+// renderOutput renders the files of the given output format, keyed by their path relative to the
+// output directory. The yaml format has a single file.
+func renderOutput(format string, input templateInputForEdge, syncerID string) (map[string][]byte, error) {
+	tmplArgs := syncerTemplateArgs(input, syncerID)
+	switch format {
+	case OutputFormatYAML:
+		resources, err := renderTemplate("kubestellar-syncer.yaml", tmplArgs)
+		if err != nil {
+			return nil, err
+		}
+		return map[string][]byte{"kubestellar-syncer.yaml": resources}, nil
+	case OutputFormatKustomize:
+		tmplArgs.SeparateSecret = true
+		resources, err := renderTemplate("kubestellar-syncer.yaml", tmplArgs)
+		if err != nil {
+			return nil, err
+		}
+		secret, err := renderTemplate("secret", tmplArgs)
+		if err != nil {
+			return nil, err
+		}
+		return map[string][]byte{
+			"kustomization.yaml":             []byte(kustomization),
+			"kubestellar-syncer.yaml":        resources,
+			"kubestellar-syncer-secret.yaml": append(secret, '\n'),
+		}, nil
+	case OutputFormatHelm:
+		values := map[string]interface{}{
+			"image":            input.Image,
+			"imagePullSecrets": input.ImagePullSecrets,
+			"resources":        input.Resources,
+			"nodeSelector":     input.NodeSelector,
+			"tolerations":      input.Tolerations,
+			"token":            input.Token,
+		}
+		// Render empty values as such rather than null, so that they are easy to fill in
+		if input.ImagePullSecrets == nil {
+			values["imagePullSecrets"] = []corev1.LocalObjectReference{}
+		}
+		if input.Resources == nil {
+			values["resources"] = corev1.ResourceRequirements{}
+		}
+		if input.NodeSelector == nil {
+			values["nodeSelector"] = map[string]string{}
+		}
+		if input.Tolerations == nil {
+			values["tolerations"] = []corev1.Toleration{}
+		}
+		valuesYAML, err := yaml.Marshal(values)
+		if err != nil {
+			return nil, err
+		}
+		tmplArgs.Helm = true
+		tmplArgs.Image = `{{ .Values.image | quote }}`
+		tmplArgs.Token = `{{ .Values.token | quote }}`
+		resources, err := renderTemplate("kubestellar-syncer.yaml", tmplArgs)
+		if err != nil {
+			return nil, err
+		}
+		return map[string][]byte{
+			"Chart.yaml":                        []byte(fmt.Sprintf(chart, input.SyncTarget)),
+			"values.yaml":                       valuesYAML,
+			"templates/kubestellar-syncer.yaml": resources,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q, expected one of %v", format, outputFormats)
+	}
+}
+
+// writeOutput writes the files rendered by renderOutput.
+// For the yaml format, outputFile is the file to write, or - for out;
+// for the other formats, it is the directory to write into.
+func writeOutput(format, outputFile string, out io.Writer, files map[string][]byte) error {
+	if format == OutputFormatYAML {
+		for _, content := range files {
+			if outputFile == "-" {
+				_, err := out.Write(content)
+				return err
+			}
+			return os.WriteFile(outputFile, content, 0o600)
+		}
+	}
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fullPath := filepath.Join(outputFile, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(fullPath, files[path], 0o600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// overrideImageRegistry replaces the registry of the given image reference with the given one.
+// An image without a registry, which means Docker Hub, gets the given one prepended.
+func overrideImageRegistry(image, registry string) string {
+	registry = strings.TrimSuffix(registry, "/")
+	if registry == "" {
+		return image
+	}
+	if first, rest, found := strings.Cut(image, "/"); found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		image = rest
+	}
+	return registry + "/" + image
+}
+
+// parseResourceList parses a map of resource names to quantities, as given with --requests and --limits.
+func parseResourceList(quantities map[string]string) (corev1.ResourceList, error) {
+	if len(quantities) == 0 {
+		return nil, nil
+	}
+	resources := corev1.ResourceList{}
+	for name, quantity := range quantities {
+		parsed, err := resource.ParseQuantity(quantity)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantity %q for %s: %w", quantity, name, err)
+		}
+		resources[corev1.ResourceName(name)] = parsed
+	}
+	return resources, nil
+}
+
+// parseToleration parses a toleration in the format key[=value][:effect].
+// Without a value, the toleration matches any value of the key;
+// without an effect, it matches all effects.
+func parseToleration(spec string) (corev1.Toleration, error) {
+	toleration := corev1.Toleration{Operator: corev1.TolerationOpExists}
+	keyValue := spec
+	if idx := strings.LastIndex(spec, ":"); idx >= 0 {
+		keyValue = spec[:idx]
+		toleration.Effect = corev1.TaintEffect(spec[idx+1:])
+		switch toleration.Effect {
+		case corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+		default:
+			return toleration, fmt.Errorf("toleration %q has invalid effect %q, expected one of %s, %s or %s", spec, toleration.Effect,
+				corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute)
+		}
+	}
+	if key, value, found := strings.Cut(keyValue, "="); found {
+		toleration.Key, toleration.Value, toleration.Operator = key, value, corev1.TolerationOpEqual
+	} else {
+		toleration.Key = keyValue
+	}
+	if toleration.Key == "" && toleration.Operator == corev1.TolerationOpEqual {
+		return toleration, fmt.Errorf("toleration %q has a value but no key", spec)
+	}
+	return toleration, nil
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata")
+
+// TestRenderOutput compares the output of each format with the files in testdata/<format>.
+// Run with -update to regenerate them.
+func TestRenderOutput(t *testing.T) {
+	options := NewEdgeSyncOptions(genericclioptions.NewTestIOStreamsDiscard())
+	options.ImagePullSecrets = []string{"mirror-credentials"}
+	options.ResourceRequests = map[string]string{"cpu": "100m", "memory": "128Mi"}
+	options.ResourceLimits = map[string]string{"memory": "256Mi"}
+	options.NodeSelector = map[string]string{"kubernetes.io/arch": "arm64"}
+	options.Tolerations = []string{"node-role.kubernetes.io/edge:NoSchedule", "dedicated=kubestellar"}
+
+	input := templateInputForEdge{
+		ServerURL:      "server-url",
+		Token:          "token",
+		CAData:         "ca-data",
+		KCPNamespace:   "kcp-namespace",
+		Namespace:      "kubestellar-syncer-sync-target-name-34b23c4k",
+		SyncTargetPath: "root:default:foo",
+		SyncTarget:     "sync-target-name",
+		SyncTargetUID:  "sync-target-uid",
+		Image:          overrideImageRegistry("quay.io/kubestellar/syncer:v0.2.0", "mirror.example.com:5000"),
+		Replicas:       1,
+		QPS:            123.4,
+		Burst:          456,
+	}
+	require.NoError(t, options.customizePods(&input))
+
+	for _, format := range outputFormats {
+		t.Run(format, func(t *testing.T) {
+			files, err := renderOutput(format, input, "kcp-syncer-sync-target-name-34b23c4k")
+			require.NoError(t, err)
+			goldenDir := filepath.Join("testdata", format)
+
+			if *updateGolden {
+				require.NoError(t, os.RemoveAll(goldenDir))
+				require.NoError(t, writeOutput(OutputFormatKustomize, goldenDir, nil, files))
+			}
+
+			goldenFiles := map[string]bool{}
+			err = filepath.WalkDir(goldenDir, func(path string, d fs.DirEntry, err error) error {
+				if err != nil || d.IsDir() {
+					return err
+				}
+				relPath, err := filepath.Rel(goldenDir, path)
+				if err != nil {
+					return err
+				}
+				goldenFiles[filepath.ToSlash(relPath)] = true
+				return nil
+			})
+			require.NoError(t, err)
+			for path, content := range files {
+				assert.True(t, goldenFiles[path], "%s is not in %s", path, goldenDir)
+				expected, err := os.ReadFile(filepath.Join(goldenDir, path))
+				if err == nil {
+					assert.Empty(t, cmp.Diff(string(expected), string(content)), "%s differs from %s", path, goldenDir)
+				}
+				delete(goldenFiles, path)
+			}
+			assert.Empty(t, goldenFiles, "files in %s that are not rendered", goldenDir)
+		})
+	}
+}
+
+func TestOverrideImageRegistry(t *testing.T) {
+	for _, tt := range []struct{ image, registry, expected string }{
+		{"quay.io/kubestellar/syncer:v0.2.0", "mirror.example.com", "mirror.example.com/kubestellar/syncer:v0.2.0"},
+		{"localhost:5000/syncer@sha256:abc", "mirror.example.com/edge/", "mirror.example.com/edge/syncer@sha256:abc"},
+		{"kubestellar/syncer", "mirror.example.com", "mirror.example.com/kubestellar/syncer"},
+		{"syncer", "mirror.example.com", "mirror.example.com/syncer"},
+		{"quay.io/kubestellar/syncer", "", "quay.io/kubestellar/syncer"},
+	} {
+		assert.Equal(t, tt.expected, overrideImageRegistry(tt.image, tt.registry), "%s with %s", tt.image, tt.registry)
+	}
+}
+
+func TestParseToleration(t *testing.T) {
+	for _, tt := range []struct {
+		spec     string
+		expected corev1.Toleration
+		invalid  bool
+	}{
+		{spec: "key=value:NoExecute", expected: corev1.Toleration{Key: "key", Value: "value", Operator: corev1.TolerationOpEqual, Effect: corev1.TaintEffectNoExecute}},
+		{spec: "example.com/key:NoSchedule", expected: corev1.Toleration{Key: "example.com/key", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}},
+		{spec: "key=value", expected: corev1.Toleration{Key: "key", Value: "value", Operator: corev1.TolerationOpEqual}},
+		{spec: "", expected: corev1.Toleration{Operator: corev1.TolerationOpExists}},
+		{spec: "key:Sometimes", invalid: true},
+		{spec: "=value", invalid: true},
+	} {
+		toleration, err := parseToleration(tt.spec)
+		if tt.invalid {
+			assert.Error(t, err, tt.spec)
+			continue
+		}
+		require.NoError(t, err, tt.spec)
+		assert.Equal(t, tt.expected, toleration, tt.spec)
+	}
+}
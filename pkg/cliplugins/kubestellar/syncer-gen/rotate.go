@@ -0,0 +1,259 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+)
+
+// RotateOptions contains options for rotating the credential of an existing syncer.
+type RotateOptions struct {
+	*base.Options
+
+	// SyncTargetName is the name of the SyncTarget that the syncer was generated for.
+	SyncTargetName string
+	// KCPNamespace is the name of the namespace in the kcp workspace where the syncer's service account is.
+	KCPNamespace string
+	// ServiceAccount is the name of the syncer's service account. It is looked up from the SyncTarget name when empty.
+	ServiceAccount string
+	// DownstreamNamespace is the name of the namespace in the physical cluster where the syncer deployment is.
+	// It defaults to the name of the service account, as for syncer-gen.
+	DownstreamNamespace string
+	// OutputFile is the path to a file where the YAML for the syncer's Secret should be written.
+	OutputFile string
+	// BootstrapTokenLifetime, if not zero, makes the new token a bound token of this lifetime
+	// instead of a long-lived ServiceAccount token, for a syncer generated with a token lifetime.
+	BootstrapTokenLifetime time.Duration
+}
+
+// NewRotateOptions returns a new RotateOptions.
+func NewRotateOptions(streams genericclioptions.IOStreams) *RotateOptions {
+	return &RotateOptions{
+		Options:      base.NewOptions(streams),
+		KCPNamespace: "default",
+	}
+}
+
+// BindFlags binds fields RotateOptions as command line flags to cmd's flagset.
+func (o *RotateOptions) BindFlags(cmd *cobra.Command) {
+	o.Options.BindFlags(cmd)
+
+	cmd.Flags().StringVar(&o.KCPNamespace, "kcp-namespace", o.KCPNamespace, "The name of the kcp namespace of the syncer's service account.")
+	cmd.Flags().StringVar(&o.ServiceAccount, "service-account", o.ServiceAccount, "The name of the syncer's service account in kcp. Only needed when more than one syncer was generated for the SyncTarget.")
+	cmd.Flags().StringVarP(&o.DownstreamNamespace, "namespace", "n", o.DownstreamNamespace, "The namespace of the syncer in the physical cluster, if it was set for syncer-gen.")
+	cmd.Flags().StringVarP(&o.OutputFile, "output-file", "o", o.OutputFile, "The manifest file to be created and applied to the physical cluster. Use - for stdout.")
+	cmd.Flags().DurationVar(&o.BootstrapTokenLifetime, "bootstrap-token-lifetime", o.BootstrapTokenLifetime, "If set, the new token is a bound token of this lifetime instead of a long-lived ServiceAccount token. Use this for a syncer generated with --token-lifetime.")
+}
+
+// Complete ensures all dynamically populated fields are initialized.
+func (o *RotateOptions) Complete(args []string) error {
+	if err := o.Options.Complete(); err != nil {
+		return err
+	}
+
+	o.SyncTargetName = args[0]
+
+	return nil
+}
+
+// Validate validates the RotateOptions are complete and usable.
+func (o *RotateOptions) Validate() error {
+	var errs []error
+
+	if err := o.Options.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if o.KCPNamespace == "" {
+		errs = append(errs, errors.New("--kcp-namespace is required"))
+	}
+	if o.OutputFile == "" {
+		errs = append(errs, errors.New("--output-file is required"))
+	}
+	if o.BootstrapTokenLifetime < 0 {
+		errs = append(errs, errors.New("--bootstrap-token-lifetime cannot be negative"))
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// Run creates a new token for the syncer's service account and outputs the syncer's Secret with it.
+// The old tokens are left in place, so that the syncer keeps working until it picks up the new one.
+func (o *RotateOptions) Run(ctx context.Context) error {
+	config, err := o.ClientConfig.ClientConfig()
+	if err != nil {
+		return err
+	}
+	serverURL, err := syncerServerURL(config)
+	if err != nil {
+		return err
+	}
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	syncerID, token, oldTokenSecrets, err := o.rotateToken(ctx, kubeClient)
+	if err != nil {
+		return err
+	}
+
+	if o.DownstreamNamespace == "" {
+		o.DownstreamNamespace = syncerID
+	}
+	resources, err := renderKubeStellarSyncerSecret(templateInputForEdge{
+		ServerURL:    serverURL,
+		CAData:       base64.StdEncoding.EncodeToString(config.CAData),
+		Token:        token,
+		KCPNamespace: o.KCPNamespace,
+		Namespace:    o.DownstreamNamespace,
+	}, syncerID)
+	if err != nil {
+		return err
+	}
+
+	if o.OutputFile == "-" {
+		_, err = o.Out.Write(resources)
+	} else {
+		err = os.WriteFile(o.OutputFile, resources, 0o600)
+	}
+	if err != nil {
+		return err
+	}
+
+	if o.OutputFile != "-" {
+		fmt.Fprintf(o.ErrOut, "\nWrote the syncer's Secret to %s. Use\n\n  KUBECONFIG=<pcluster-config> kubectl apply -f %q\n\nto apply it. "+
+			"The syncer picks up the new token without restarting.\n", o.OutputFile, o.OutputFile)
+	}
+	if len(oldTokenSecrets) > 0 {
+		fmt.Fprintf(o.ErrOut, "\nOnce the syncer uses the new token (this takes a couple of minutes after the Secret is applied), use\n\n  kubectl delete secret -n %q %s\n\nto revoke the old ones.\n",
+			o.KCPNamespace, strings.Join(oldTokenSecrets, " "))
+	}
+	return nil
+}
+
+// rotateToken creates a new token Secret for the syncer's service account and waits for its token.
+// It returns the name of the service account, which is the syncer's ID, the new token and the names
+// of the other token Secrets of the service account.
+func (o *RotateOptions) rotateToken(ctx context.Context, kubeClient kubernetes.Interface) (syncerID, token string, oldTokenSecrets []string, err error) {
+	syncerID = o.ServiceAccount
+	if syncerID == "" {
+		saList, err := kubeClient.CoreV1().ServiceAccounts(o.KCPNamespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return "", "", nil, fmt.Errorf("failed to list ServiceAccounts in %q: %w", o.KCPNamespace, err)
+		}
+		prefix := fmt.Sprintf("kubestellar-syncer-%s-", o.SyncTargetName)
+		candidates := []string{}
+		for _, sa := range saList.Items {
+			if strings.HasPrefix(sa.Name, prefix) {
+				candidates = append(candidates, sa.Name)
+			}
+		}
+		switch len(candidates) {
+		case 0:
+			return "", "", nil, fmt.Errorf("no syncer ServiceAccount for SyncTarget %q in %q", o.SyncTargetName, o.KCPNamespace)
+		case 1:
+			syncerID = candidates[0]
+		default:
+			return "", "", nil, fmt.Errorf("more than one syncer ServiceAccount for SyncTarget %q in %q, use --service-account to pick one of %v", o.SyncTargetName, o.KCPNamespace, candidates)
+		}
+	}
+	if _, err := kubeClient.CoreV1().ServiceAccounts(o.KCPNamespace).Get(ctx, syncerID, metav1.GetOptions{}); err != nil {
+		return "", "", nil, fmt.Errorf("failed to get ServiceAccount %s/%s: %w", o.KCPNamespace, syncerID, err)
+	}
+
+	// A bound token expires by itself, so there is nothing to revoke afterwards
+	if o.BootstrapTokenLifetime != 0 {
+		fmt.Fprintf(o.ErrOut, "Requesting a bootstrap token for service account %q, valid for %s\n", syncerID, o.BootstrapTokenLifetime)
+		token, err := requestBootstrapToken(ctx, kubeClient, o.KCPNamespace, syncerID, o.BootstrapTokenLifetime)
+		if err != nil {
+			return "", "", nil, err
+		}
+		return syncerID, token, nil, nil
+	}
+
+	secretList, err := kubeClient.CoreV1().Secrets(o.KCPNamespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", "", nil, fmt.Errorf("failed to list Secrets in %q: %w", o.KCPNamespace, err)
+	}
+	for _, secret := range secretList.Items {
+		if secret.Type == corev1.SecretTypeServiceAccountToken && secret.Annotations[corev1.ServiceAccountNameKey] == syncerID {
+			oldTokenSecrets = append(oldTokenSecrets, secret.Name)
+		}
+	}
+
+	fmt.Fprintf(o.ErrOut, "Creating a token for service account %q\n", syncerID)
+	secret, err := kubeClient.CoreV1().Secrets(o.KCPNamespace).Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: syncerID + "-token-",
+			Annotations:  map[string]string{corev1.ServiceAccountNameKey: syncerID},
+		},
+		Type: corev1.SecretTypeServiceAccountToken,
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return "", "", nil, fmt.Errorf("failed to create token Secret for ServiceAccount %s/%s: %w", o.KCPNamespace, syncerID, err)
+	}
+
+	// Wait for the token controller to populate the token
+	err = wait.PollImmediateWithContext(ctx, 100*time.Millisecond, 20*time.Second, func(ctx context.Context) (bool, error) {
+		tokenSecret, err := kubeClient.CoreV1().Secrets(o.KCPNamespace).Get(ctx, secret.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, nil
+		}
+		token = string(tokenSecret.Data[corev1.ServiceAccountTokenKey])
+		return token != "", nil
+	})
+	if err != nil {
+		return "", "", nil, fmt.Errorf("timed out waiting for the token in Secret %s/%s", o.KCPNamespace, secret.Name)
+	}
+
+	return syncerID, token, oldTokenSecrets, nil
+}
+
+// requestBootstrapToken requests, through the TokenRequest API, a bound token of the given lifetime
+// for the named ServiceAccount. The syncer uses it only to request its short-lived tokens.
+func requestBootstrapToken(ctx context.Context, kubeClient kubernetes.Interface, namespace, name string, lifetime time.Duration) (string, error) {
+	expirationSeconds := int64(lifetime / time.Second)
+	tokenRequest, err := kubeClient.CoreV1().ServiceAccounts(namespace).CreateToken(ctx, name, &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: &expirationSeconds},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to request a token for ServiceAccount %s/%s: %w", namespace, name, err)
+	}
+	if tokenRequest.Status.Token == "" {
+		return "", fmt.Errorf("the token request for ServiceAccount %s/%s returned no token", namespace, name)
+	}
+	return tokenRequest.Status.Token, nil
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestRotateToken(t *testing.T) {
+	syncerID := "kubestellar-syncer-sync-target-name-34b23c4k"
+	client := fake.NewSimpleClientset(
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: syncerID}},
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "kubestellar-syncer-other-name-12345678"}},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: syncerID + "-token-old",
+				Annotations: map[string]string{corev1.ServiceAccountNameKey: syncerID}},
+			Type: corev1.SecretTypeServiceAccountToken,
+			Data: map[string][]byte{corev1.ServiceAccountTokenKey: []byte("old-token")},
+		},
+	)
+	// Play the token controller
+	client.PrependReactor("create", "secrets", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		secret := action.(clienttesting.CreateAction).GetObject().(*corev1.Secret)
+		secret.Name = secret.GenerateName + "new"
+		secret.Data = map[string][]byte{corev1.ServiceAccountTokenKey: []byte("new-token")}
+		return false, nil, nil
+	})
+
+	options := NewRotateOptions(genericclioptions.NewTestIOStreamsDiscard())
+	options.SyncTargetName = "sync-target-name"
+	actualID, token, oldTokenSecrets, err := options.rotateToken(context.Background(), client)
+	require.NoError(t, err)
+	assert.Equal(t, syncerID, actualID)
+	assert.Equal(t, "new-token", token)
+	assert.Equal(t, []string{syncerID + "-token-old"}, oldTokenSecrets)
+
+	secret, err := client.CoreV1().Secrets("default").Get(context.Background(), syncerID+"-token-new", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, syncerID, secret.Annotations[corev1.ServiceAccountNameKey])
+
+	options.SyncTargetName = "missing"
+	_, _, _, err = options.rotateToken(context.Background(), client)
+	assert.Error(t, err)
+}
+
+func TestRotateBootstrapToken(t *testing.T) {
+	syncerID := "kubestellar-syncer-sync-target-name-34b23c4k"
+	client := fake.NewSimpleClientset(
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: syncerID}},
+	)
+	var expirationSeconds int64
+	client.PrependReactor("create", "serviceaccounts", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "token" {
+			return false, nil, nil
+		}
+		request := action.(clienttesting.CreateAction).GetObject().(*authenticationv1.TokenRequest)
+		expirationSeconds = *request.Spec.ExpirationSeconds
+		request.Status.Token = "bound-token"
+		return true, request, nil
+	})
+
+	options := NewRotateOptions(genericclioptions.NewTestIOStreamsDiscard())
+	options.SyncTargetName = "sync-target-name"
+	options.BootstrapTokenLifetime = 24 * time.Hour
+	actualID, token, oldTokenSecrets, err := options.rotateToken(context.Background(), client)
+	require.NoError(t, err)
+	assert.Equal(t, syncerID, actualID)
+	assert.Equal(t, "bound-token", token)
+	assert.Empty(t, oldTokenSecrets, "a bound token expires by itself")
+	assert.Equal(t, int64(24*60*60), expirationSeconds)
+
+	secrets, err := client.CoreV1().Secrets("default").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, secrets.Items, "no long-lived token is created")
+}
+
+func TestRenderKubeStellarSyncerSecret(t *testing.T) {
+	input := templateInputForEdge{
+		ServerURL:    "server-url",
+		Token:        "token",
+		CAData:       "ca-data",
+		KCPNamespace: "kcp-namespace",
+		Namespace:    "kubestellar-syncer-sync-target-name-34b23c4k",
+	}
+	secretYAML, err := renderKubeStellarSyncerSecret(input, "kcp-syncer-sync-target-name-34b23c4k")
+	require.NoError(t, err)
+
+	input.Replicas = 1
+	allYAML, err := renderKubeStellarSyncerResources(input, "kcp-syncer-sync-target-name-34b23c4k")
+	require.NoError(t, err)
+	for _, doc := range strings.Split(string(allYAML), "---\n") {
+		if strings.HasPrefix(doc, "apiVersion: v1\nkind: Secret\nmetadata:\n  name: kcp-syncer-sync-target-name-34b23c4k\n") {
+			require.Empty(t, cmp.Diff(doc, string(secretYAML)+"\n"), "the rotated Secret replaces the generated one")
+			return
+		}
+	}
+	t.Fatal("no syncer Secret in the generated resources")
+}
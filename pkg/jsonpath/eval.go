@@ -83,3 +83,120 @@ func Apply(data JSONValue, path []Selector, definite bool, fn func(JSONValue) JS
 	}
 	return data
 }
+
+// Query returns the values at the places in the given data selected by
+// the given path, in the order that Apply visits them.
+// The given data is not modified.
+func Query(data JSONValue, path []Selector) []JSONValue {
+	var ans []JSONValue
+	Apply(data, path, false, func(val JSONValue) JSONValue {
+		ans = append(ans, val)
+		return val
+	})
+	return ans
+}
+
+// CopySelected returns the result of setting the places in dest selected
+// by the given path to the values at the same places in src.
+// Places correspond by map member name and array index.
+// A selected map member that src lacks is removed from dest;
+// a selected array element that either lacks is skipped.
+// The values copied from src are deep copies.
+func CopySelected(dest, src JSONValue, path []Selector) JSONValue {
+	if len(path) == 0 {
+		return deepCopy(src)
+	}
+	sel := path[0]
+	switch sel.Type {
+	case SelectorName:
+		srcMap, _ := src.(map[string]any)
+		srcElt, found := srcMap[sel.Name]
+		destMap, ok := dest.(map[string]any)
+		if !ok {
+			if dest != nil || !found {
+				return dest
+			}
+			destMap = map[string]any{}
+		}
+		if found {
+			destMap[sel.Name] = CopySelected(destMap[sel.Name], srcElt, path[1:])
+		} else if len(path) == 1 {
+			delete(destMap, sel.Name)
+		}
+		return destMap
+	case SelectorRange:
+		srcArr, srcOK := src.([]any)
+		destArr, destOK := dest.([]any)
+		if !(srcOK && destOK) {
+			return dest
+		}
+		limit := len(destArr)
+		if len(srcArr) < limit {
+			limit = len(srcArr)
+		}
+		if sel.Range.afterEnd != nil && *sel.Range.afterEnd < limit {
+			limit = *sel.Range.afterEnd
+		}
+		for index := sel.Range.start; index < limit; index += sel.Range.stride {
+			destArr[index] = CopySelected(destArr[index], srcArr[index], path[1:])
+		}
+	case SelectorList:
+		for _, sub := range sel.List {
+			dest = CopySelected(dest, src, append([]Selector{sub}, path[1:]...))
+		}
+	case SelectorEveryChild:
+		dest = copyChildren(dest, src, path[1:], true)
+	case SelectorRecurse:
+		dest = CopySelected(dest, src, path[1:])
+		dest = copyChildren(dest, src, path, false)
+	}
+	return dest
+}
+
+// copyChildren applies CopySelected to the corresponding children of dest and src.
+// If `every` then map members that only src has are included,
+// and if furthermore the path is empty then map members that only dest has are removed.
+func copyChildren(dest, src JSONValue, path []Selector, every bool) JSONValue {
+	switch srcTyped := src.(type) {
+	case []any:
+		if destTyped, ok := dest.([]any); ok {
+			for index := 0; index < len(destTyped) && index < len(srcTyped); index++ {
+				destTyped[index] = CopySelected(destTyped[index], srcTyped[index], path)
+			}
+		}
+	case map[string]any:
+		if destTyped, ok := dest.(map[string]any); ok {
+			for key, srcElt := range srcTyped {
+				if _, found := destTyped[key]; found || every {
+					destTyped[key] = CopySelected(destTyped[key], srcElt, path)
+				}
+			}
+			if every && len(path) == 0 {
+				for key := range destTyped {
+					if _, found := srcTyped[key]; !found {
+						delete(destTyped, key)
+					}
+				}
+			}
+		}
+	}
+	return dest
+}
+
+func deepCopy(data JSONValue) JSONValue {
+	switch typed := data.(type) {
+	case []any:
+		ans := make([]any, len(typed))
+		for index, elt := range typed {
+			ans[index] = deepCopy(elt)
+		}
+		return ans
+	case map[string]any:
+		ans := make(map[string]any, len(typed))
+		for key, elt := range typed {
+			ans[key] = deepCopy(elt)
+		}
+		return ans
+	}
+	return data
+}
@@ -87,3 +87,70 @@ func TestEval(t *testing.T) {
 		}
 	}
 }
+
+func TestQuery(t *testing.T) {
+	for _, testCase := range []struct {
+		inputStr  string
+		pathStr   string
+		expectStr string
+	}{
+		{`{"status": {"readyReplicas": 3}}`, `$.status.readyReplicas`, `[3]`},
+		{`{"status": {}}`, `$.status.readyReplicas`, `null`},
+		{`{"status": {"conditions": [{"type": "A", "status": "True"}, {"type": "B", "status": "False"}]}}`,
+			`$.status.conditions[*].status`, `["True", "False"]`},
+		{`{"items": [{"ready": 1}, {"other": 2}, {"ready": 3}]}`, `$.items[*].ready`, `[1, 3]`},
+	} {
+		var inputVal JSONValue
+		if err := json.Unmarshal([]byte(testCase.inputStr), &inputVal); err != nil {
+			panic(err)
+		}
+		var expectVal []JSONValue
+		if err := json.Unmarshal([]byte(testCase.expectStr), &expectVal); err != nil {
+			panic(err)
+		}
+		path, err := ParseString(testCase.pathStr)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %v", testCase.pathStr, err)
+		}
+		outputVal := Query(inputVal, path)
+		if !apiequality.Semantic.DeepEqual(outputVal, expectVal) {
+			t.Errorf("Failed case input=%s path=%s: expected %+v, got %+v", testCase.inputStr, testCase.pathStr, expectVal, outputVal)
+		}
+	}
+}
+
+func TestCopySelected(t *testing.T) {
+	for _, testCase := range []struct {
+		destStr   string
+		srcStr    string
+		pathStr   string
+		expectStr string
+	}{
+		{`{"spec": {"replicas": 1, "x": "a"}}`, `{"spec": {"replicas": 5, "x": "b"}}`, `$.spec.replicas`,
+			`{"spec": {"replicas": 5, "x": "a"}}`},
+		{`{"spec": {"replicas": 1}}`, `{"spec": {}}`, `$.spec.replicas`, `{"spec": {}}`},
+		{`{"spec": {}}`, `{"spec": {"replicas": 5}}`, `$.spec.replicas`, `{"spec": {"replicas": 5}}`},
+		{`{"cs": [{"image": "a", "n": 1}, {"image": "b", "n": 2}]}`, `{"cs": [{"image": "c", "n": 3}, {"image": "d"}, {"image": "e"}]}`, `$.cs[*].image`,
+			`{"cs": [{"image": "c", "n": 1}, {"image": "d", "n": 2}]}`},
+		{`{"cs": [{"image": "a"}]}`, `{"cs": [{"image": "a"}, {"image": "sidecar"}]}`, `$.cs`,
+			`{"cs": [{"image": "a"}, {"image": "sidecar"}]}`},
+	} {
+		var destVal, srcVal, expectVal JSONValue
+		for _, pair := range []struct {
+			str string
+			val *JSONValue
+		}{{testCase.destStr, &destVal}, {testCase.srcStr, &srcVal}, {testCase.expectStr, &expectVal}} {
+			if err := json.Unmarshal([]byte(pair.str), pair.val); err != nil {
+				panic(err)
+			}
+		}
+		path, err := ParseString(testCase.pathStr)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %v", testCase.pathStr, err)
+		}
+		outputVal := CopySelected(destVal, srcVal, path)
+		if !apiequality.Semantic.DeepEqual(outputVal, expectVal) {
+			t.Errorf("Failed case dest=%s src=%s path=%s: expected %+v, got %+v", testCase.destStr, testCase.srcStr, testCase.pathStr, expectVal, outputVal)
+		}
+	}
+}
@@ -128,6 +128,7 @@ type myList struct {
 var _ upstreamcache.ListerWatcher = &crossClusterListerWatcher[tenancyv1a1client.WorkspaceInterface, *tenancyv1a1.WorkspaceList]{}
 
 // List queries each mailbox workspace, one at a time, and combines the replies.
+// Each mailbox workspace is listed in pages, following continue tokens (see listCluster).
 // The ResourceVersion is a pain point here.
 // Background: all the underlying clusters share in the same progression of ResourceVersion.
 // Problem: what ResourceVersiom to put on the whole result?
@@ -143,7 +144,7 @@ func (clw *crossClusterListerWatcher[Scoped, ListType]) List(options metav1.List
 	var clusterOfMaxRV logicalcluster.Name
 	listCluster := func(clusterName logicalcluster.Name, lwForCluster *lwPerCluster[Scoped, ListType]) {
 		logger := logger.WithValues("cluster", clusterName)
-		sublist, err := lwForCluster.scopedLW.List(clw.ctx, options)
+		subItems, rv, err := clw.listCluster(lwForCluster.scopedLW, options)
 		if err != nil {
 			if k8sapierrors.IsNotFound(err) {
 				logger.V(4).Info("Resourece not (yet) known")
@@ -152,26 +153,6 @@ func (clw *crossClusterListerWatcher[Scoped, ListType]) List(options metav1.List
 			}
 			return
 		}
-		sublistGVK := sublist.GetObjectKind().GroupVersionKind()
-		if sublistGVK != clw.listGVK && sublistGVK != (machschema.GroupVersionKind{}) {
-			logger.Error(nil, "List returned unexpected GroupVersionKind", "expected", clw.listGVK, "got", sublistGVK)
-			return
-		}
-		subItems, err := machmeta.ExtractList(sublist)
-		if err != nil {
-			logger.Error(err, "Failed to machmeta.ExtractList", "sublist", sublist)
-			return
-		}
-		listMeta, err := machmeta.ListAccessor(sublist)
-		if err != nil {
-			logger.Error(err, "Failed to machmeta.ListAccessor", "sublist", sublist)
-			return
-		}
-		rv, err := strconv.ParseInt(listMeta.GetResourceVersion(), 10, 64)
-		if err != nil {
-			logger.Error(err, "Failed to parse ResourceVersion of a List result")
-			return
-		}
 		if rv > maxRV {
 			maxRV = rv
 			clusterOfMaxRV = clusterName
@@ -192,9 +173,6 @@ func (clw *crossClusterListerWatcher[Scoped, ListType]) List(options metav1.List
 				allItems = append(allItems, item)
 			}
 		}
-		if listMeta.GetContinue() != "" {
-			logger.Info("Warning: skipping list CONTINUE due to incomplete implementation", "gvk", clw.listGVK) // TODO: better
-		}
 	}
 	clw.Lock()
 	defer clw.Unlock()
@@ -226,6 +204,62 @@ func (clw *crossClusterListerWatcher[Scoped, ListType]) List(options metav1.List
 	}, nil
 }
 
+// listPageSize is the number of objects asked for in each request to a mailbox workspace,
+// when the caller of List does not ask for a smaller number.
+var listPageSize int64 = 500
+
+// listCluster lists everything in one mailbox workspace, following continue tokens so that
+// no request returns more than a page of objects.
+// It returns the items and the ResourceVersion of the whole list.
+// If the snapshot being paged through expires, the mailbox workspace is listed again in one request.
+func (clw *crossClusterListerWatcher[Scoped, ListType]) listCluster(scopedLW Scoped, options metav1.ListOptions) ([]runtime.Object, int64, error) {
+	pageOptions := options
+	if pageOptions.Limit == 0 {
+		pageOptions.Limit = listPageSize
+	}
+	items := []runtime.Object{}
+	var listRV int64
+	for {
+		sublist, err := scopedLW.List(clw.ctx, pageOptions)
+		if err != nil {
+			if k8sapierrors.IsResourceExpired(err) && pageOptions.Continue != "" {
+				pageOptions = options
+				pageOptions.Limit = 0
+				items = []runtime.Object{}
+				continue
+			}
+			return nil, 0, err
+		}
+		sublistGVK := sublist.GetObjectKind().GroupVersionKind()
+		if sublistGVK != clw.listGVK && sublistGVK != (machschema.GroupVersionKind{}) {
+			return nil, 0, fmt.Errorf("List returned unexpected GroupVersionKind %v, expected %v", sublistGVK, clw.listGVK)
+		}
+		subItems, err := machmeta.ExtractList(sublist)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to extract items of list: %w", err)
+		}
+		listMeta, err := machmeta.ListAccessor(sublist)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to access metadata of list: %w", err)
+		}
+		if pageOptions.Continue == "" {
+			// All the pages share the ResourceVersion of the first one
+			listRV, err = strconv.ParseInt(listMeta.GetResourceVersion(), 10, 64)
+			if err != nil {
+				return nil, 0, fmt.Errorf("failed to parse ResourceVersion of a List result: %w", err)
+			}
+		}
+		items = append(items, subItems...)
+		if listMeta.GetContinue() == "" {
+			return items, listRV, nil
+		}
+		pageOptions.Continue = listMeta.GetContinue()
+		// A continued list is served from the snapshot of the first page
+		pageOptions.ResourceVersion = ""
+		pageOptions.ResourceVersionMatch = ""
+	}
+}
+
 func (ml *myList) DeepCopyObject() runtime.Object {
 	ans := myList{
 		TypeMeta: ml.TypeMeta,
@@ -238,58 +272,166 @@ func (ml *myList) DeepCopyObject() runtime.Object {
 	return &ans
 }
 
+// Watch watches each mailbox workspace and merges the events.
+// The watch follows changes in the set of mailbox workspaces (see followReconfigs).
 func (clw *crossClusterListerWatcher[Scoped, ListType]) Watch(options metav1.ListOptions) (watch.Interface, error) {
-	ctx := clw.ctx
-	ctx, close := context.WithCancel(ctx)
+	ctx, cancel := context.WithCancel(clw.ctx)
 	clw.Lock()
 	defer clw.Unlock()
 	ans := &myWatch[Scoped, ListType]{
 		clw:          clw,
-		close:        close,
-		doneChan:     ctx.Done(),
+		ctx:          ctx,
+		cancel:       cancel,
+		options:      options,
+		stopped:      make(chan struct{}),
 		reconfigChan: clw.reconfigChan,
+		clusters:     map[logicalcluster.Name]struct{}{},
 		filtered:     make(chan watch.Event),
 	}
 	for clusterName, lwForCluster := range clw.perCluster {
-		clusterWatch, err := lwForCluster.scopedLW.Watch(clw.ctx, options)
+		clusterWatch, err := lwForCluster.scopedLW.Watch(ctx, options)
 		if err != nil {
+			cancel()
 			return nil, fmt.Errorf("Watch for cluster %s failed: %w", clusterName, err)
 		}
-		wpc := &watchPerCluster[Scoped, ListType]{
-			myWatch:     ans,
-			scopedWatch: clusterWatch,
-			scopedChan:  clusterWatch.ResultChan(),
-		}
-		go wpc.Run()
+		ans.clusters[clusterName] = struct{}{}
+		ans.startCluster(clusterWatch)
 	}
+	go ans.followReconfigs()
 	return ans, nil
 }
 
+// followReconfigs keeps the watch in step with the set of mailbox workspaces, until the watch ends.
+// A mailbox workspace that appears is listed, its objects are reported as added, and then it is watched.
+// When a mailbox workspace goes away, the watch reports that its ResourceVersion expired and ends,
+// so that the reflector lists again and drops the objects that were there.
+func (mw *myWatch[Scoped, ListType]) followReconfigs() {
+	reconfigChan := mw.reconfigChan
+	for !mw.isExpired() {
+		select {
+		case <-mw.ctx.Done():
+		case <-reconfigChan:
+		}
+		if mw.ctx.Err() != nil {
+			break
+		}
+		mw.clw.Lock()
+		reconfigChan = mw.clw.reconfigChan
+		added := map[logicalcluster.Name]*lwPerCluster[Scoped, ListType]{}
+		for clusterName, lwForCluster := range mw.clw.perCluster {
+			if _, have := mw.clusters[clusterName]; !have {
+				added[clusterName] = lwForCluster
+			}
+		}
+		removed := len(mw.clusters)+len(added) != len(mw.clw.perCluster)
+		mw.clw.Unlock()
+		if removed {
+			mw.end(true)
+			break
+		}
+		for clusterName, lwForCluster := range added {
+			mw.clusters[clusterName] = struct{}{}
+			mw.running.Add(1)
+			go mw.listAndWatchCluster(clusterName, lwForCluster)
+		}
+	}
+	mw.cancel()
+	mw.running.Wait()
+	if mw.isExpired() {
+		expired := k8sapierrors.NewResourceExpired("the set of mailbox workspaces changed")
+		select {
+		case mw.filtered <- watch.Event{Type: watch.Error, Object: &expired.ErrStatus}:
+		case <-mw.stopped:
+		}
+	}
+	close(mw.filtered)
+}
+
+// listAndWatchCluster brings a mailbox workspace that appeared into the watch.
+func (mw *myWatch[Scoped, ListType]) listAndWatchCluster(clusterName logicalcluster.Name, lwForCluster *lwPerCluster[Scoped, ListType]) {
+	defer mw.running.Done()
+	logger := klog.FromContext(mw.ctx).WithValues("cluster", clusterName)
+	listOptions := mw.options
+	listOptions.ResourceVersion = ""
+	listOptions.ResourceVersionMatch = ""
+	listOptions.Limit = 0
+	listOptions.Continue = ""
+	items, rv, err := mw.clw.listCluster(lwForCluster.scopedLW, listOptions)
+	if err != nil {
+		logger.V(4).Info("Failed to list newly appeared mailbox workspace, watch ends", "err", err)
+		mw.end(true)
+		return
+	}
+	for _, item := range items {
+		select {
+		case mw.filtered <- watch.Event{Type: watch.Added, Object: item}:
+		case <-mw.ctx.Done():
+			return
+		}
+	}
+	watchOptions := mw.options
+	watchOptions.ResourceVersion = strconv.FormatInt(rv, 10)
+	clusterWatch, err := lwForCluster.scopedLW.Watch(mw.ctx, watchOptions)
+	if err != nil {
+		logger.V(4).Info("Failed to watch newly appeared mailbox workspace, watch ends", "err", err)
+		mw.end(true)
+		return
+	}
+	mw.startCluster(clusterWatch)
+}
+
+func (mw *myWatch[Scoped, ListType]) startCluster(clusterWatch watch.Interface) {
+	wpc := &watchPerCluster[Scoped, ListType]{
+		myWatch:     mw,
+		scopedWatch: clusterWatch,
+		scopedChan:  clusterWatch.ResultChan(),
+	}
+	mw.running.Add(1)
+	go wpc.Run()
+}
+
 func (wpc *watchPerCluster[Scoped, ListType]) Run() {
+	defer wpc.running.Done()
+	defer wpc.scopedWatch.Stop()
 	for {
 		select {
-		case <-wpc.doneChan:
-			wpc.scopedWatch.Stop()
-			return
-		case <-wpc.reconfigChan:
-			wpc.scopedWatch.Stop()
+		case <-wpc.ctx.Done():
 			return
 		case event, ok := <-wpc.scopedChan:
 			if !ok {
-				wpc.close()
+				wpc.end(false)
+				return
+			}
+			select {
+			case wpc.filtered <- event:
+			case <-wpc.ctx.Done():
 				return
 			}
-			wpc.filtered <- event
 		}
 	}
 }
 
 type myWatch[Scoped ScopedListerWatcher[ListType], ListType runtime.Object] struct {
-	clw          *crossClusterListerWatcher[Scoped, ListType]
-	close        func()
-	doneChan     <-chan struct{}
+	clw     *crossClusterListerWatcher[Scoped, ListType]
+	ctx     context.Context // done when the watch is ending
+	cancel  func()
+	options metav1.ListOptions
+
+	stopOnce sync.Once
+	stopped  chan struct{} // closed when the consumer calls Stop
+
 	reconfigChan <-chan struct{} // the chan that was current when this watch started
-	filtered     chan watch.Event
+
+	// clusters is the set of mailbox workspaces in this watch; accessed only by followReconfigs after construction
+	clusters map[logicalcluster.Name]struct{}
+
+	// running counts the goroutines that may send to filtered
+	running sync.WaitGroup
+
+	expiredLock sync.Mutex
+	expired     bool
+
+	filtered chan watch.Event
 }
 
 type watchPerCluster[Scoped ScopedListerWatcher[ListType], ListType runtime.Object] struct {
@@ -298,8 +440,25 @@ type watchPerCluster[Scoped ScopedListerWatcher[ListType], ListType runtime.Obje
 	scopedChan  <-chan watch.Event
 }
 
+// end makes the watch end, after reporting that its ResourceVersion expired if `expired`.
+func (mw *myWatch[Scoped, ListType]) end(expired bool) {
+	if expired {
+		mw.expiredLock.Lock()
+		mw.expired = true
+		mw.expiredLock.Unlock()
+	}
+	mw.cancel()
+}
+
+func (mw *myWatch[Scoped, ListType]) isExpired() bool {
+	mw.expiredLock.Lock()
+	defer mw.expiredLock.Unlock()
+	return mw.expired
+}
+
 func (mw *myWatch[Scoped, ListType]) Stop() {
-	mw.close()
+	mw.stopOnce.Do(func() { close(mw.stopped) })
+	mw.cancel()
 }
 
 func (mw *myWatch[Scoped, ListType]) ResultChan() <-chan watch.Event {
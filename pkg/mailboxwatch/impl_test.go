@@ -22,15 +22,17 @@ import (
 	"math"
 	"math/rand"
 	"os"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
 
-	k8sapps "k8s.io/api/apps/v1"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	machruntime "k8s.io/apimachinery/pkg/runtime"
 	machschema "k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
 	upstreamcache "k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
@@ -128,10 +130,40 @@ func (tot *testObjectTracker) objectsEqualCond(gvk machschema.GroupVersionKind,
 	return func() (bool, error) {
 		tot.Lock()
 		defer tot.Unlock()
-		return MapEqual(tot.objectsByGVK[gvk], byName), nil
+		return KeysEqual(tot.objectsByGVK[gvk], byName), nil
 	}
 }
 
+// withResourceVersions wraps the fake client, which does not set ResourceVersions,
+// to give every list and object the ResourceVersion "1", as an API server would give some.
+type withResourceVersions struct {
+	edgeclusterclient.SyncerConfigClusterInterface
+}
+
+type scopedWithResourceVersions struct {
+	edgescopedclient.SyncerConfigInterface
+}
+
+func (wrv withResourceVersions) Cluster(cluster logicalcluster.Path) edgescopedclient.SyncerConfigInterface {
+	return scopedWithResourceVersions{wrv.SyncerConfigClusterInterface.Cluster(cluster)}
+}
+
+func (swrv scopedWithResourceVersions) List(ctx context.Context, opts metav1.ListOptions) (*edgeapi.SyncerConfigList, error) {
+	list, err := swrv.SyncerConfigInterface.List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	if list.ResourceVersion == "" {
+		list.ResourceVersion = "1"
+	}
+	for idx := range list.Items {
+		if list.Items[idx].ResourceVersion == "" {
+			list.Items[idx].ResourceVersion = "1"
+		}
+	}
+	return list, nil
+}
+
 func TestMailboxInformer(t *testing.T) {
 	resource := "syncerconfigs"
 	kind := "SyncerConfig"
@@ -159,15 +191,16 @@ func TestMailboxInformer(t *testing.T) {
 			}()
 		}
 		scInformer := NewSharedInformer[edgescopedclient.SyncerConfigInterface, *edgeapi.SyncerConfigList](ctx, sclGVK, wsPreInformer.Cluster(espwCluster),
-			edgeClientset.EdgeV1alpha1().SyncerConfigs(), &edgeapi.SyncerConfig{}, 0, upstreamcache.Indexers{})
+			withResourceVersions{edgeClientset.EdgeV1alpha1().SyncerConfigs()}, &edgeapi.SyncerConfig{}, 0, upstreamcache.Indexers{})
 		scInformer.AddEventHandler(actual)
+		wsPreInformer.Informer().AddEventHandler(actual)
 		kcpClusterInformerFactory.Start(ctx.Done())
 		go scInformer.Run(ctx.Done())
 		for iteration := 1; iteration <= 64; iteration++ {
 			if len(syncerConfigs) > 0 && rand.Intn(2) == 0 {
 				gonerIndex := rand.Intn(len(syncerConfigs))
 				_, gonerObj := MapRemove(syncerConfigs, gonerIndex)
-				gonerSC := gonerObj.(*k8sapps.ReplicaSet)
+				gonerSC := gonerObj.(*edgeapi.SyncerConfig)
 				cluster := logicalcluster.From(gonerSC)
 				err := edgeTracker.Cluster(cluster.Path()).Delete(scGVR, gonerSC.Namespace, gonerSC.Name)
 				if err != nil {
@@ -240,11 +273,12 @@ func TestMailboxInformer(t *testing.T) {
 				} else {
 					t.Logf("Added to tracker: SyncerConfig named %#v", objName)
 				}
+				syncerConfigs[objName] = obj
 			}
-			if wait.PollImmediate(time.Second, 5*time.Second, actual.objectsEqualCond(wsGVK, workspaces)) != nil {
+			if wait.PollImmediate(10*time.Millisecond, 5*time.Second, actual.objectsEqualCond(wsGVK, workspaces)) != nil {
 				t.Fatalf("Workspaces did not settle in time: %+v != %+v", actual.getObjects(wsGVK), workspaces)
 			}
-			if wait.PollImmediate(time.Second, 5*time.Second, actual.objectsEqualCond(scGVK, syncerConfigs)) != nil {
+			if wait.PollImmediate(10*time.Millisecond, 5*time.Second, actual.objectsEqualCond(scGVK, syncerConfigs)) != nil {
 				t.Fatalf("Workspaces did not settle in time: %+v != %+v", actual.getObjects(scGVK), syncerConfigs)
 			}
 		}
@@ -256,6 +290,7 @@ func MapRemove[Key comparable, Val any](from map[Key]Val, gonerIndex int) (Key,
 	index := 0
 	for key, val := range from {
 		if index == gonerIndex {
+			delete(from, key)
 			return key, val
 		}
 		index++
@@ -271,6 +306,20 @@ func MapCopy[Key comparable, Val any](old map[Key]Val) map[Key]Val {
 	return ans
 }
 
+// KeysEqual tells whether two maps have the same set of keys.
+// The informers hold their own copies of the objects, so the values can not be compared by identity.
+func KeysEqual[Key comparable, Val1, Val2 any](map1 map[Key]Val1, map2 map[Key]Val2) bool {
+	if len(map1) != len(map2) {
+		return false
+	}
+	for key := range map1 {
+		if _, has := map2[key]; !has {
+			return false
+		}
+	}
+	return true
+}
+
 // MapEqual compares two maps for equality.
 // `Val` has no type bound because bounding it by `comparable` does
 // not work in go 1.19 (and that is the current version for this module).
@@ -292,3 +341,109 @@ func MapEqual[Key comparable, Val any](map1, map2 map[Key]Val) bool {
 	}
 	return true
 }
+
+// pagedLister is a ScopedListerWatcher for SyncerConfigs that serves its items in pages,
+// like an API server does.
+type pagedLister struct {
+	cluster logicalcluster.Name
+	items   []edgeapi.SyncerConfig
+	// expireContinue, if not empty, is a continue token that is answered once with an Expired error
+	expireContinue string
+	requests       []metav1.ListOptions
+}
+
+func (pl *pagedLister) List(ctx context.Context, opts metav1.ListOptions) (*edgeapi.SyncerConfigList, error) {
+	pl.requests = append(pl.requests, opts)
+	if opts.Continue != "" && opts.Continue == pl.expireContinue {
+		pl.expireContinue = ""
+		return nil, k8sapierrors.NewResourceExpired("continue token expired")
+	}
+	start := 0
+	if opts.Continue != "" {
+		var err error
+		if start, err = strconv.Atoi(opts.Continue); err != nil {
+			return nil, k8sapierrors.NewBadRequest("malformed continue token")
+		}
+	}
+	end := len(pl.items)
+	if opts.Limit > 0 && start+int(opts.Limit) < end {
+		end = start + int(opts.Limit)
+	}
+	ans := &edgeapi.SyncerConfigList{
+		ListMeta: metav1.ListMeta{ResourceVersion: "100"},
+		Items:    append([]edgeapi.SyncerConfig{}, pl.items[start:end]...),
+	}
+	if end < len(pl.items) {
+		ans.Continue = strconv.Itoa(end)
+	}
+	return ans, nil
+}
+
+func (pl *pagedLister) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func TestListPages(t *testing.T) {
+	defer func(old int64) { listPageSize = old }(listPageSize)
+	listPageSize = 7
+	sclGVK := edgeapi.SchemeGroupVersion.WithKind("SyncerConfigList")
+	clw := &crossClusterListerWatcher[*pagedLister, *edgeapi.SyncerConfigList]{
+		ctx:        context.Background(),
+		listGVK:    sclGVK,
+		perCluster: map[logicalcluster.Name]*lwPerCluster[*pagedLister, *edgeapi.SyncerConfigList]{},
+	}
+	expected := map[objectName]bool{}
+	for clusterNum, numItems := range []int{100, 23, 7, 0} {
+		cluster := logicalcluster.Name(fmt.Sprintf("mc%d", clusterNum))
+		lister := &pagedLister{cluster: cluster}
+		for itemNum := 0; itemNum < numItems; itemNum++ {
+			name := fmt.Sprintf("sc%d", itemNum)
+			lister.items = append(lister.items, edgeapi.SyncerConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            name,
+					ResourceVersion: "50",
+					Annotations:     map[string]string{logicalcluster.AnnotationKey: cluster.String()},
+				},
+			})
+			expected[objectName{cluster: cluster, name: name}] = true
+		}
+		if clusterNum == 1 {
+			lister.expireContinue = "14"
+		}
+		clw.perCluster[cluster] = &lwPerCluster[*pagedLister, *edgeapi.SyncerConfigList]{clw, cluster, lister}
+	}
+
+	listObj, err := clw.List(metav1.ListOptions{ResourceVersion: "0"})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	actual := map[objectName]bool{}
+	for _, item := range listObj.(*myList).Items {
+		itemM := item.(metav1.Object)
+		name := objectName{cluster: logicalcluster.From(itemM), name: itemM.GetName()}
+		if actual[name] {
+			t.Errorf("Item %+v listed more than once", name)
+		}
+		actual[name] = true
+	}
+	if !MapEqual(actual, expected) {
+		t.Errorf("Listed %d items, expected %d: %+v", len(actual), len(expected), actual)
+	}
+	for cluster, lwForCluster := range clw.perCluster {
+		lister := lwForCluster.scopedLW
+		for idx, request := range lister.requests {
+			if request.Continue == "" && idx > 0 {
+				if request.Limit != 0 {
+					t.Errorf("Cluster %s: full list after expiry asked for a limit: %+v", cluster, request)
+				}
+				continue
+			}
+			if request.Limit != listPageSize {
+				t.Errorf("Cluster %s: request %d had Limit %d, expected %d", cluster, idx, request.Limit, listPageSize)
+			}
+			if request.Continue != "" && request.ResourceVersion != "" {
+				t.Errorf("Cluster %s: continued request %d had ResourceVersion %q", cluster, idx, request.ResourceVersion)
+			}
+		}
+	}
+}
@@ -208,6 +208,32 @@ func SimpleBindingOrganizer(logger klog.Logger) BindingOrganizer {
 			upsyncsRelay,
 			PairHashDomain[SinglePlacement, edgeapi.UpsyncSet](HashSinglePlacement{}, HashUpsyncSet{}),
 			HashExternalName)
+		ignoreDifferencesRelay := NewSetWriterFuncs(
+			func(tup Pair[SinglePlacement, edgeapi.IgnoreDifferences]) bool {
+				logger.V(4).Info("IgnoreDifferences added", "tuple", tup)
+				return sbo.workloadProjectionSections.IgnoreDifferences.Add(tup)
+			},
+			func(tup Pair[SinglePlacement, edgeapi.IgnoreDifferences]) bool {
+				logger.V(4).Info("IgnoreDifferences removed", "tuple", tup)
+				return sbo.workloadProjectionSections.IgnoreDifferences.Remove(tup)
+			})
+		sbo.ignoreDifferencesFull = NewSetChangeProjectorByMapMap[Triple[ExternalName, edgeapi.IgnoreDifferences, SinglePlacement], Pair[SinglePlacement, edgeapi.IgnoreDifferences], ExternalName](
+			factorIgnoreDifferencesTuple,
+			ignoreDifferencesRelay)
+		resourcePoliciesRelay := NewSetWriterFuncs(
+			func(tup Pair[SinglePlacement, ResourcePolicy]) bool {
+				logger.V(4).Info("ResourcePolicy added", "tuple", tup)
+				return sbo.workloadProjectionSections.ResourcePolicies.Add(tup)
+			},
+			func(tup Pair[SinglePlacement, ResourcePolicy]) bool {
+				logger.V(4).Info("ResourcePolicy removed", "tuple", tup)
+				return sbo.workloadProjectionSections.ResourcePolicies.Remove(tup)
+			})
+		sbo.resourcePoliciesFull = NewSetChangeProjectorByHashMap(
+			factorResourcePolicyTuple,
+			resourcePoliciesRelay,
+			PairHashDomain[SinglePlacement, ResourcePolicy](HashSinglePlacement{}, HashResourcePolicy{}),
+			HashExternalName)
 		return sbo
 	}
 }
@@ -220,6 +246,22 @@ var factorUpsyncTuple = NewFactorer(
 		return NewTriple(parts.Second, parts.First.Second, parts.First.First) // cdr, cdar, caar
 	})
 
+var factorIgnoreDifferencesTuple = NewFactorer(
+	func(whole Triple[ExternalName /* of EdgePlacement object */, edgeapi.IgnoreDifferences, SinglePlacement]) Pair[Pair[SinglePlacement, edgeapi.IgnoreDifferences], ExternalName /* of EdgePlacement object */] {
+		return NewPair(NewPair(whole.Third, whole.Second), whole.First)
+	},
+	func(parts Pair[Pair[SinglePlacement, edgeapi.IgnoreDifferences], ExternalName /* of EdgePlacement object */]) Triple[ExternalName /* of EdgePlacement object */, edgeapi.IgnoreDifferences, SinglePlacement] {
+		return NewTriple(parts.Second, parts.First.Second, parts.First.First)
+	})
+
+var factorResourcePolicyTuple = NewFactorer(
+	func(whole Triple[ExternalName /* of EdgePlacement object */, ResourcePolicy, SinglePlacement]) Pair[Pair[SinglePlacement, ResourcePolicy], ExternalName /* of EdgePlacement object */] {
+		return NewPair(NewPair(whole.Third, whole.Second), whole.First)
+	},
+	func(parts Pair[Pair[SinglePlacement, ResourcePolicy], ExternalName /* of EdgePlacement object */]) Triple[ExternalName /* of EdgePlacement object */, ResourcePolicy, SinglePlacement] {
+		return NewTriple(parts.Second, parts.First.Second, parts.First.First)
+	})
+
 // simpleBindingOrganizer is the top-level data structure of the organizer.
 // In the locking order it precedes its discovery and its projectionMapProvider,
 // which in turn precedes each projectionPerClusterImpl.
@@ -284,6 +326,8 @@ var factorUpsyncTuple = NewFactorer(
 //
 // The query plan is as follows.
 // upsyncsRelay <- WhatWheres.ProjectOut((epCluster,epName))
+//
+// The ignoreDifferences and the per-resource policies are treated the same way as the upsyncs.
 type simpleBindingOrganizer struct {
 	logger        klog.Logger
 	discovery     APIMapProvider
@@ -305,6 +349,8 @@ type simpleBindingOrganizer struct {
 	clusterWhatWhereFull      MappingReceiver[ClusterWhatWhereFullKey, ProjectionModeVal]
 	namespacedWhatWhereFull   SetWriter[NamespacedWhatWhereFullKey]
 	upsyncsFull               SetWriter[Triple[ExternalName /* of EdgePlacement object */, edgeapi.UpsyncSet, SinglePlacement]]
+	ignoreDifferencesFull     SetWriter[Triple[ExternalName /* of EdgePlacement object */, edgeapi.IgnoreDifferences, SinglePlacement]]
+	resourcePoliciesFull      SetWriter[Triple[ExternalName /* of EdgePlacement object */, ResourcePolicy, SinglePlacement]]
 	resourceDiscoveryReceiver MappingReceiver[ResourceDiscoveryKey, ProjectionModeVal]
 }
 
@@ -396,13 +442,13 @@ type NamespacedWhatWhereFullKey = Triple[ExternalName, NamespaceName, SinglePlac
 // ClusterWhatWhereFullKey is (EdgePlacement id, (resource, object name), destination)
 type ClusterWhatWhereFullKey = Triple[ExternalName, Pair[metav1.GroupResource, string], SinglePlacement]
 
-func (sbo *simpleBindingOrganizer) Transact(xn func(SingleBindingOps, UpsyncOps)) {
+func (sbo *simpleBindingOrganizer) Transact(xn func(SingleBindingOps, UpsyncOps, IgnoreDifferencesOps, ResourcePoliciesOps)) {
 	sbo.Lock()
 	defer sbo.Unlock()
 	sbo.logger.V(3).Info("Begin transaction")
 	sbo.workloadProjector.Transact(func(wps WorkloadProjectionSections) {
 		sbo.workloadProjectionSections = wps
-		xn(sboXnOps{sbo}, sbo.receiveUpsyncChange)
+		xn(sboXnOps{sbo}, sbo.receiveUpsyncChange, sbo.receiveIgnoreDifferencesChange, sbo.receiveResourcePolicyChange)
 		sbo.workloadProjectionSections = WorkloadProjectionSections{}
 	})
 	sbo.logger.V(3).Info("End transaction")
@@ -416,6 +462,22 @@ func (sbo *simpleBindingOrganizer) receiveUpsyncChange(add bool, tup Triple[Exte
 	}
 }
 
+func (sbo *simpleBindingOrganizer) receiveResourcePolicyChange(add bool, tup Triple[ExternalName /* of EdgePlacement object */, ResourcePolicy, SinglePlacement]) {
+	if add {
+		sbo.resourcePoliciesFull.Add(tup)
+	} else {
+		sbo.resourcePoliciesFull.Remove(tup)
+	}
+}
+
+func (sbo *simpleBindingOrganizer) receiveIgnoreDifferencesChange(add bool, tup Triple[ExternalName /* of EdgePlacement object */, edgeapi.IgnoreDifferences, SinglePlacement]) {
+	if add {
+		sbo.ignoreDifferencesFull.Add(tup)
+	} else {
+		sbo.ignoreDifferencesFull.Remove(tup)
+	}
+}
+
 // sboXnOps exposes the SingleBindingOps behavior, in the locked context of a transaction
 type sboXnOps struct {
 	sbo *simpleBindingOrganizer
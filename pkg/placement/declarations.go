@@ -96,6 +96,8 @@ type WorkloadProjectionSections struct {
 	NonNamespacedDistributions      SetWriter[NonNamespacedDistributionTuple]
 	NonNamespacedModes              MappingReceiver[ProjectionModeKey, ProjectionModeVal]
 	Upsyncs                         SetWriter[Pair[SinglePlacement, edgeapi.UpsyncSet]]
+	IgnoreDifferences               SetWriter[Pair[SinglePlacement, edgeapi.IgnoreDifferences]]
+	ResourcePolicies                SetWriter[Pair[SinglePlacement, ResourcePolicy]]
 }
 
 type SinglePlacement = edgeapi.SinglePlacement
@@ -149,8 +151,19 @@ type ResolvedWhere []*edgeapi.SinglePlacementSlice
 // ResolvedWhat describes what to downsync and what to upsync for a given
 // (workload management workspace, edge cluster) pair.
 type ResolvedWhat struct {
-	Downsync WorkloadParts
-	Upsync   []edgeapi.UpsyncSet
+	Downsync          WorkloadParts
+	Upsync            []edgeapi.UpsyncSet
+	IgnoreDifferences []edgeapi.IgnoreDifferences
+	ResourcePolicies  []ResourcePolicy
+}
+
+// ResourcePolicy is one of the per-resource entries of the `driftPolicies`,
+// `deletionPolicies` and `subresources` of an EdgePlacement, which are passed on
+// to the SyncerConfigs of its destinations. Exactly one field is set.
+type ResourcePolicy struct {
+	Drift        *edgeapi.ResourceDriftPolicy
+	Deletion     *edgeapi.ResourceDeletionPolicy
+	Subresources *edgeapi.ResourceSubresources
 }
 
 // WorkloadParts identifies what to downsync and provides
@@ -269,7 +282,7 @@ type BindingOrganizer func(discovery APIMapProvider, resourceModes ResourceModes
 // Remove calls are ordered by the reverse of the API machinery dependencies.
 type SingleBinder interface {
 	// Transact does a collection of adds and removes.
-	Transact(func(SingleBindingOps, UpsyncOps))
+	Transact(func(SingleBindingOps, UpsyncOps, IgnoreDifferencesOps, ResourcePoliciesOps))
 }
 
 // SingleBindingOps is a receiver of downsync tuples
@@ -281,6 +294,14 @@ type SingleBindingOps MappingReceiver[Triple[ExternalName /* of EdgePlacement ob
 // but today is not that day.  Today we simply treat each as a syntactic expression and look at syntactic equality.
 type UpsyncOps SetChangeReceiver[Triple[ExternalName /* of EdgePlacement object */, edgeapi.UpsyncSet, SinglePlacement]]
 
+// IgnoreDifferencesOps is a receiver of tuples saying that a particular EdgePlacement object
+// calls for the syncer at the given destination to keep the edge values of some fields.
+type IgnoreDifferencesOps SetChangeReceiver[Triple[ExternalName /* of EdgePlacement object */, edgeapi.IgnoreDifferences, SinglePlacement]]
+
+// ResourcePoliciesOps is a receiver of tuples saying that a particular EdgePlacement object
+// calls for the syncer at the given destination to follow a given per-resource policy.
+type ResourcePoliciesOps SetChangeReceiver[Triple[ExternalName /* of EdgePlacement object */, ResourcePolicy, SinglePlacement]]
+
 // APIMapProvider provides API information on a cluster-by-cluster basis,
 // as needed by clients.
 // This information comes from runtime monitoring of the API resources
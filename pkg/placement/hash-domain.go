@@ -17,8 +17,12 @@ limitations under the License.
 package placement
 
 import (
+	"encoding/json"
 	"hash/crc64"
 
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	"github.com/kcp-dev/logicalcluster/v3"
 
 	edgeapi "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
@@ -144,16 +148,40 @@ func (HashUpsyncSet) Equal(left, right edgeapi.UpsyncSet) bool {
 }
 
 func (HashUpsyncSet) Hash(arg edgeapi.UpsyncSet) HashValue {
-	return StringHash(arg.APIGroup) + 5*SliceOfStringDomain.Hash(arg.Resources) + 37*SliceOfStringDomain.Hash(arg.Namespaces) + 257*SliceOfStringDomain.Hash(arg.Names)
+	return StringHash(arg.APIGroup) + 5*SliceOfStringDomain.Hash(arg.Resources) + 37*SliceOfStringDomain.Hash(arg.Namespaces) + 257*SliceOfStringDomain.Hash(arg.Names) + 1031*StringHash(string(arg.CollisionPolicy)) +
+		4099*StringHash(metav1.FormatLabelSelector(arg.LabelSelector)) + 16411*StringHash(arg.FieldSelector) + 65537*StringHash(metav1.FormatLabelSelector(arg.NamespaceSelector))
 }
 
 func UpsyncSetEqual(left, right edgeapi.UpsyncSet) bool {
-	if left.APIGroup != right.APIGroup {
+	if left.APIGroup != right.APIGroup || left.CollisionPolicy != right.CollisionPolicy || left.FieldSelector != right.FieldSelector {
+		return false
+	}
+	if metav1.FormatLabelSelector(left.LabelSelector) != metav1.FormatLabelSelector(right.LabelSelector) ||
+		metav1.FormatLabelSelector(left.NamespaceSelector) != metav1.FormatLabelSelector(right.NamespaceSelector) {
 		return false
 	}
 	return SliceEqual(left.Resources, right.Resources) && SliceEqual(left.Namespaces, right.Namespaces) && SliceEqual(left.Names, right.Names)
 }
 
+type HashResourcePolicy struct{}
+
+var _ HashDomain[ResourcePolicy] = HashResourcePolicy{}
+
+func (HashResourcePolicy) Equal(left, right ResourcePolicy) bool {
+	return apiequality.Semantic.DeepEqual(left, right)
+}
+
+func (HashResourcePolicy) Hash(arg ResourcePolicy) HashValue {
+	return StringHash(resourcePolicyKey(arg))
+}
+
+// resourcePolicyKey returns the JSON form of the given policy,
+// which is the same for policies that are equal.
+func resourcePolicyKey(policy ResourcePolicy) string {
+	asJSON, _ := json.Marshal(policy)
+	return string(asJSON)
+}
+
 type HashSinglePlacement struct{}
 
 var _ HashDomain[SinglePlacement] = HashSinglePlacement{}
@@ -61,8 +61,11 @@ type placementTranslator struct {
 		Runnable
 	}
 
-	whatResolver  WhatResolver
-	whereResolver WhereResolver
+	whatResolver      WhatResolver
+	whereResolver     WhereResolver
+	statusAggregator  *statusAggregator
+	statusCollector   *statusCollectorController
+	rolloutController *rolloutController
 }
 
 func NewPlacementTranslator(
@@ -78,6 +81,8 @@ func NewPlacementTranslator(
 	syncfgClusterPreInformer edgev1a1informers.SyncerConfigClusterInformer,
 
 	customizerClusterPreInformer edgev1a1informers.CustomizerClusterInformer,
+	// pre-informer on StatusCollector objects, should be in workload management workspaces
+	stcClusterPreInformer edgev1a1informers.StatusCollectorClusterInformer,
 	// pre-informer on Workspaces objects in the ESPW
 	mbwsPreInformer tenancyv1a1informers.WorkspaceInformer,
 	// all-cluster clientset for kcp APIs,
@@ -120,12 +125,22 @@ func NewPlacementTranslator(
 			crdClusterPreInformer, bindingClusterPreInformer, dynamicClusterClient, numThreads),
 		whereResolver: NewWhereResolver(ctx, spsClusterPreInformer, numThreads),
 	}
-	pt.workloadProjector = NewWorkloadProjector(ctx, numThreads, DefaultResourceModes, pt.mbwsInformer, pt.mbwsLister,
+	wp := NewWorkloadProjector(ctx, numThreads, DefaultResourceModes, pt.mbwsInformer, pt.mbwsLister,
 		locationClusterPreInformer.Informer(), locationClusterPreInformer.Lister(),
 		pt.syncfgClusterInformer, pt.syncfgClusterLister,
 		customizerClusterPreInformer.Informer(), customizerClusterPreInformer.Lister(),
 		edgeClusterClientset, dynamicClusterClient,
 		nsClusterPreInformer, nsClusterClient)
+	pt.rolloutController = newRolloutController(ctx, numThreads, epClusterPreInformer.Lister(),
+		pt.syncfgClusterLister, pt.mbwsLister, edgeClusterClientset, wp)
+	// The rollout controller holds back changes and later has the projector reconsider them
+	wp.rolloutGate = pt.rolloutController
+	pt.rolloutController.requeue = func(soRef sourceObjectRef) { wp.queue.Add(soRef) }
+	pt.workloadProjector = wp
+	pt.statusAggregator = newStatusAggregator(ctx, numThreads, epClusterPreInformer.Lister(),
+		pt.syncfgClusterInformer, pt.syncfgClusterLister, pt.mbwsLister, edgeClusterClientset, wp)
+	pt.statusCollector = newStatusCollectorController(ctx, numThreads, stcClusterPreInformer.Informer(), stcClusterPreInformer.Lister(),
+		pt.syncfgClusterInformer, pt.syncfgClusterLister, pt.mbwsLister, edgeClusterClientset, wp)
 
 	return pt
 }
@@ -145,11 +160,11 @@ func (pt *placementTranslator) Run() {
 	}
 
 	whatResolver := func(mr MappingReceiver[ExternalName, ResolvedWhat]) Runnable {
-		fork := MappingReceiverFork[ExternalName, ResolvedWhat]{NewLoggingMappingReceiver[ExternalName, ResolvedWhat]("what", logger), mr}
+		fork := MappingReceiverFork[ExternalName, ResolvedWhat]{NewLoggingMappingReceiver[ExternalName, ResolvedWhat]("what", logger), pt.statusAggregator.WhatReceiver(), pt.statusCollector.WhatReceiver(), pt.rolloutController.WhatReceiver(), mr}
 		return pt.whatResolver(fork)
 	}
 	whereResolver := func(mr MappingReceiver[ExternalName, ResolvedWhere]) Runnable {
-		fork := MappingReceiverFork[ExternalName, ResolvedWhere]{NewLoggingMappingReceiver[ExternalName, ResolvedWhere]("where", logger), mr}
+		fork := MappingReceiverFork[ExternalName, ResolvedWhere]{NewLoggingMappingReceiver[ExternalName, ResolvedWhere]("where", logger), pt.statusAggregator.WhereReceiver(), pt.statusCollector.WhereReceiver(), pt.rolloutController.WhereReceiver(), mr}
 		return pt.whereResolver(fork)
 	}
 	setBinder := NewSetBinder(logger, NewWorkloadPartsDifferencer, NewUpsyncDifferencer, NewResolvedWhereDifferencer,
@@ -163,6 +178,9 @@ func (pt *placementTranslator) Run() {
 	// TODO: move all that stuff up before Run
 	go pt.apiProvider.Run(ctx)       // TODO: also wait for this to finish
 	go pt.workloadProjector.Run(ctx) // TODO: also wait for this to finish
+	go pt.statusAggregator.Run(ctx)  // TODO: also wait for this to finish
+	go pt.statusCollector.Run(ctx)   // TODO: also wait for this to finish
+	go pt.rolloutController.Run(ctx) // TODO: also wait for this to finish
 	runner.Run(ctx)
 }
 
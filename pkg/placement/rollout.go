@@ -0,0 +1,568 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placement
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	tenancyv1a1listers "github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	edgeapi "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+	edgeclusterclientset "github.com/kubestellar/kubestellar/pkg/client/clientset/versioned/cluster"
+	edgev1a1listers "github.com/kubestellar/kubestellar/pkg/client/listers/edge/v1alpha1"
+)
+
+// RolloutRecheckPeriod is how often a progressing rollout that is waiting
+// for destinations to become healthy is re-examined.
+const RolloutRecheckPeriod = 10 * time.Second
+
+// rolloutGate decides whether the workload projector may deliver a change
+// to an object in a mailbox workspace: its creation, update, or deletion.
+// Creations are gated too, so that adding an object to the "what" (or creating
+// an EdgePlacement with a RolloutStrategy) also goes out in waves.
+type rolloutGate interface {
+	// AdmitChange returns true if the change to the referenced source object
+	// may be delivered to the given destination now.
+	// When it returns false, the gate will later enqueue the reference again.
+	AdmitChange(soRef sourceObjectRef, destination SinglePlacement) bool
+}
+
+// rolloutController implements the RolloutStrategy of EdgePlacements.
+// It is kept appraised of the "what" and "where" resolutions, gates the
+// workload projector's changes to objects in the mailbox workspaces,
+// admits waves of destinations, and reports progress in the
+// EdgePlacement status.
+// The health of a destination is judged from the SyncerConfig in its mailbox
+// workspace and from the workload projector's informers on the projected objects there;
+// a rollout in progress is reconsidered when those objects change.
+// The rollout state is held in memory and reported in the EdgePlacement
+// status, from which a rollout in progress is resumed after a restart
+// of the placement translator.
+type rolloutController struct {
+	ctx        context.Context
+	logger     klog.Logger
+	numThreads int
+	queue      workqueue.RateLimitingInterface
+
+	epLister             edgev1a1listers.EdgePlacementClusterLister
+	syncfgClusterLister  edgev1a1listers.SyncerConfigClusterLister
+	mbwsLister           tenancyv1a1listers.WorkspaceLister
+	edgeClusterClientset edgeclusterclientset.ClusterInterface
+	projected            projectedObjectSource
+
+	// requeue is called to reconsider a source object that was held back
+	requeue func(sourceObjectRef)
+
+	sync.Mutex
+	whats    map[ExternalName]ResolvedWhat
+	wheres   map[ExternalName]ResolvedWhere
+	rollouts map[ExternalName]*rolloutState
+}
+
+// rolloutState is the state of a rollout in progress.
+type rolloutState struct {
+	admitted  MapSet[SinglePlacement]
+	wave      int32
+	waveStart time.Time
+	heldBack  MapSet[sourceObjectRef]
+}
+
+var _ Runnable = &rolloutController{}
+var _ rolloutGate = &rolloutController{}
+
+func newRolloutController(
+	ctx context.Context,
+	numThreads int,
+	epLister edgev1a1listers.EdgePlacementClusterLister,
+	syncfgClusterLister edgev1a1listers.SyncerConfigClusterLister,
+	mbwsLister tenancyv1a1listers.WorkspaceLister,
+	edgeClusterClientset edgeclusterclientset.ClusterInterface,
+	projected projectedObjectSource,
+) *rolloutController {
+	controllerName := "rollout-controller"
+	logger := klog.FromContext(ctx).WithValues("part", controllerName)
+	rc := &rolloutController{
+		ctx:                  klog.NewContext(ctx, logger),
+		logger:               logger,
+		numThreads:           numThreads,
+		queue:                workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName),
+		epLister:             epLister,
+		syncfgClusterLister:  syncfgClusterLister,
+		mbwsLister:           mbwsLister,
+		edgeClusterClientset: edgeClusterClientset,
+		projected:            projected,
+		requeue:              func(sourceObjectRef) {},
+		whats:                map[ExternalName]ResolvedWhat{},
+		wheres:               map[ExternalName]ResolvedWhere{},
+		rollouts:             map[ExternalName]*rolloutState{},
+	}
+	projected.addProjectedObjectHandler(rc.enqueueForDestination)
+	return rc
+}
+
+// enqueueForDestination enqueues the EdgePlacements that have a rollout in progress
+// to the given destination.
+func (rc *rolloutController) enqueueForDestination(destination SinglePlacement) {
+	rc.Lock()
+	defer rc.Unlock()
+	for epName := range rc.rollouts {
+		if whereIncludesDestination(rc.wheres[epName], destination) {
+			rc.queue.Add(epName)
+		}
+	}
+}
+
+// WhatReceiver returns the receiver of the "what" resolutions.
+func (rc *rolloutController) WhatReceiver() MappingReceiver[ExternalName, ResolvedWhat] {
+	return NewMappingReceiverFuncs(
+		func(epName ExternalName, what ResolvedWhat) {
+			rc.Lock()
+			defer rc.Unlock()
+			rc.whats[epName] = what
+			rc.queue.Add(epName)
+		},
+		func(epName ExternalName) {
+			rc.Lock()
+			defer rc.Unlock()
+			delete(rc.whats, epName)
+			rc.queue.Add(epName)
+		})
+}
+
+// WhereReceiver returns the receiver of the "where" resolutions.
+func (rc *rolloutController) WhereReceiver() MappingReceiver[ExternalName, ResolvedWhere] {
+	return NewMappingReceiverFuncs(
+		func(epName ExternalName, where ResolvedWhere) {
+			rc.Lock()
+			defer rc.Unlock()
+			rc.wheres[epName] = where
+			rc.queue.Add(epName)
+		},
+		func(epName ExternalName) {
+			rc.Lock()
+			defer rc.Unlock()
+			delete(rc.wheres, epName)
+			rc.queue.Add(epName)
+		})
+}
+
+func (rc *rolloutController) AdmitChange(soRef sourceObjectRef, destination SinglePlacement) bool {
+	rc.Lock()
+	defer rc.Unlock()
+	admit := true
+	for epName, where := range rc.wheres {
+		if epName.Cluster != soRef.cluster || !whereIncludesDestination(where, destination) ||
+			!whatIncludesSourceObject(rc.whats[epName], soRef) {
+			continue
+		}
+		ep, err := rc.epLister.Cluster(epName.Cluster).Get(epName.Name)
+		if err != nil || ep.Spec.Rollout == nil {
+			continue
+		}
+		state := rc.resumeRolloutLocked(epName, ep)
+		if state == nil {
+			destinations := sortedDestinations(where)
+			batchSize := rolloutBatchSize(ep.Spec.Rollout, len(destinations))
+			state = &rolloutState{
+				admitted:  NewMapSet(destinations[:batchSize]...),
+				wave:      1,
+				waveStart: time.Now(),
+				heldBack:  NewEmptyMapSet[sourceObjectRef](),
+			}
+			rc.rollouts[epName] = state
+			rc.logger.V(2).Info("Started rollout", "epName", epName, "firstWave", destinations[:batchSize])
+			rc.queue.Add(epName)
+		}
+		if !state.admitted.Has(destination) {
+			state.heldBack.Add(soRef)
+			admit = false
+		}
+	}
+	return admit
+}
+
+// resumeRolloutLocked returns the state of the rollout in progress for the given EdgePlacement, if any.
+// When there is none in memory but the status says that one is progressing (as after a restart),
+// the state is rebuilt from the status: the destinations admitted so far are the first ones
+// in rollout order, as many as the status says were updated.
+// That needs the "where"; until it is known, nil is returned.
+// The caller holds the mutex.
+func (rc *rolloutController) resumeRolloutLocked(epName ExternalName, ep *edgeapi.EdgePlacement) *rolloutState {
+	if state := rc.rollouts[epName]; state != nil {
+		return state
+	}
+	where, whereKnown := rc.wheres[epName]
+	if !rolloutProgressing(ep) || !whereKnown {
+		return nil
+	}
+	status := ep.Status.Rollout
+	destinations := sortedDestinations(where)
+	numAdmitted := int(status.UpdatedDestinationCount)
+	if numAdmitted > len(destinations) {
+		numAdmitted = len(destinations)
+	}
+	state := &rolloutState{
+		admitted:  NewMapSet(destinations[:numAdmitted]...),
+		wave:      status.Wave,
+		waveStart: time.Now(),
+		heldBack:  NewEmptyMapSet[sourceObjectRef](),
+	}
+	if status.WaveStartTime != nil {
+		state.waveStart = status.WaveStartTime.Time
+	}
+	rc.rollouts[epName] = state
+	rc.logger.V(2).Info("Resumed rollout", "epName", epName, "wave", state.wave, "admitted", destinations[:numAdmitted])
+	return state
+}
+
+// rolloutProgressing tells whether the status of the given EdgePlacement says that a rollout is in progress.
+func rolloutProgressing(ep *edgeapi.EdgePlacement) bool {
+	return ep.Status.Rollout != nil && ep.Status.Rollout.Phase == edgeapi.RolloutPhaseProgressing
+}
+
+func whereIncludesDestination(where ResolvedWhere, destination SinglePlacement) bool {
+	for _, sps := range where {
+		for _, dest := range sps.Destinations {
+			if dest == destination {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func whatIncludesSourceObject(what ResolvedWhat, soRef sourceObjectRef) bool {
+	if soRef.namespace != noNamespace {
+		_, found := what.Downsync[WorkloadPartID{APIGroup: "", Resource: "namespaces", Name: soRef.namespace}]
+		return found
+	}
+	_, found := what.Downsync[WorkloadPartID{APIGroup: soRef.groupResource.Group, Resource: soRef.groupResource.Resource, Name: soRef.name}]
+	return found
+}
+
+// sortedDestinations returns the destinations in the given where, in rollout order.
+func sortedDestinations(where ResolvedWhere) []SinglePlacement {
+	ans := []SinglePlacement{}
+	for _, sps := range where {
+		for _, dest := range sps.Destinations {
+			if !SliceContains(ans, dest) {
+				ans = append(ans, dest)
+			}
+		}
+	}
+	sort.Slice(ans, func(i, j int) bool {
+		if ans[i].Cluster != ans[j].Cluster {
+			return ans[i].Cluster < ans[j].Cluster
+		}
+		return ans[i].SyncTargetName < ans[j].SyncTargetName
+	})
+	return ans
+}
+
+// rolloutBatchSize returns the size of a wave, at least 1 and at most numDestinations.
+func rolloutBatchSize(strategy *edgeapi.RolloutStrategy, numDestinations int) int {
+	batchSize := 1
+	if strategy.BatchSize != nil {
+		scaled, err := intstr.GetScaledValueFromIntOrPercent(strategy.BatchSize, numDestinations, true)
+		if err == nil && scaled > 0 {
+			batchSize = scaled
+		}
+	}
+	if batchSize > numDestinations {
+		batchSize = numDestinations
+	}
+	return batchSize
+}
+
+// rolloutMaxUnavailable returns the number of destinations that may be unhealthy.
+func rolloutMaxUnavailable(strategy *edgeapi.RolloutStrategy, numDestinations int) int {
+	if strategy.MaxUnavailable == nil {
+		return 0
+	}
+	scaled, err := intstr.GetScaledValueFromIntOrPercent(strategy.MaxUnavailable, numDestinations, false)
+	if err != nil || scaled < 0 {
+		return 0
+	}
+	return scaled
+}
+
+// nextWave returns the destinations to admit next, given the destinations in order,
+// the ones already admitted, and the number of admitted ones that are unhealthy.
+// Returns an empty slice if too many are unhealthy.
+func nextWave(strategy *edgeapi.RolloutStrategy, destinations []SinglePlacement, admitted MapSet[SinglePlacement], numUnhealthy int) []SinglePlacement {
+	ans := []SinglePlacement{}
+	if strategy.RequireHealthy && numUnhealthy > rolloutMaxUnavailable(strategy, len(destinations)) {
+		return ans
+	}
+	size := rolloutBatchSize(strategy, len(destinations))
+	for _, dest := range destinations {
+		if len(ans) >= size {
+			break
+		}
+		if !admitted.Has(dest) {
+			ans = append(ans, dest)
+		}
+	}
+	return ans
+}
+
+func (rc *rolloutController) Run(ctx context.Context) {
+	defer rc.queue.ShutDown()
+	var wg sync.WaitGroup
+	wg.Add(rc.numThreads)
+	for i := 0; i < rc.numThreads; i++ {
+		go func() {
+			wait.Until(rc.runWorker, time.Second, ctx.Done())
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+}
+
+func (rc *rolloutController) runWorker() {
+	for rc.processNextWorkItem() {
+	}
+}
+
+func (rc *rolloutController) processNextWorkItem() bool {
+	itemAny, quit := rc.queue.Get()
+	if quit {
+		return false
+	}
+	defer rc.queue.Done(itemAny)
+	epName := itemAny.(ExternalName)
+
+	logger := rc.logger.WithValues("epName", epName)
+	ctx := klog.NewContext(rc.ctx, logger)
+	logger.V(4).Info("processing EdgePlacement")
+
+	if rc.process(ctx, epName) {
+		rc.queue.Forget(itemAny)
+	} else {
+		rc.queue.AddRateLimited(itemAny)
+	}
+	return true
+}
+
+// process returns true on success or unrecoverable error, false to retry
+func (rc *rolloutController) process(ctx context.Context, epName ExternalName) bool {
+	logger := klog.FromContext(ctx)
+	ep, err := rc.epLister.Cluster(epName.Cluster).Get(epName.Name)
+	if err != nil {
+		if !k8sapierrors.IsNotFound(err) {
+			logger.Error(err, "Failed to fetch EdgePlacement from local cache")
+		}
+		rc.finishRollout(epName)
+		return true
+	}
+	strategy := ep.Spec.Rollout
+	if strategy == nil {
+		rc.finishRollout(epName)
+		if ep.Status.Rollout == nil {
+			return true
+		}
+		return rc.updateStatus(ctx, ep, nil)
+	}
+	rc.Lock()
+	what := rc.whats[epName]
+	where, whereKnown := rc.wheres[epName]
+	destinations := sortedDestinations(where)
+	state := rc.resumeRolloutLocked(epName, ep)
+	admitted := NewEmptyMapSet[SinglePlacement]()
+	var wave int32
+	var waveStart time.Time
+	if state != nil {
+		admitted, wave, waveStart = MapSetCopy[SinglePlacement](state.admitted), state.wave, state.waveStart
+	}
+	rc.Unlock()
+	if state == nil && !whereKnown && rolloutProgressing(ep) {
+		// The rollout is resumed once the "where" is known, which enqueues this EdgePlacement again
+		return true
+	}
+
+	newStatus := &edgeapi.EdgePlacementRolloutStatus{
+		Phase:            edgeapi.RolloutPhaseComplete,
+		Wave:             wave,
+		DestinationCount: int32(len(destinations)),
+	}
+	if ep.Status.Rollout != nil && state == nil {
+		newStatus.Wave = ep.Status.Rollout.Wave
+	}
+	if state == nil {
+		newStatus.UpdatedDestinationCount = newStatus.DestinationCount
+		return rc.updateStatus(ctx, ep, newStatus)
+	}
+	waveStartTime := metav1.NewTime(waveStart)
+	newStatus.WaveStartTime = &waveStartTime
+	numUnhealthy := 0
+	if strategy.RequireHealthy {
+		for _, dest := range destinations {
+			if !admitted.Has(dest) {
+				continue
+			}
+			healthy, ok := rc.destinationIsHealthy(what, dest)
+			if !ok {
+				return false
+			}
+			if !healthy {
+				numUnhealthy++
+			}
+		}
+		newStatus.UnhealthyDestinationCount = int32(numUnhealthy)
+	}
+	var pause time.Duration
+	if strategy.Pause != nil {
+		pause = strategy.Pause.Duration
+	}
+	toAdmit := nextWave(strategy, destinations, admitted, numUnhealthy)
+	remainingPause := time.Until(waveStart.Add(pause))
+	numAdmitted := 0
+	for _, dest := range destinations {
+		if admitted.Has(dest) {
+			numAdmitted++
+		}
+	}
+	switch {
+	case numAdmitted == len(destinations) && numUnhealthy <= rolloutMaxUnavailable(strategy, len(destinations)):
+		rc.finishRollout(epName)
+		newStatus.UpdatedDestinationCount = int32(numAdmitted)
+		logger.V(2).Info("Completed rollout", "waves", wave)
+		return rc.updateStatus(ctx, ep, newStatus)
+	case numAdmitted == len(destinations):
+		newStatus.Message = fmt.Sprintf("waiting for %d destinations to become healthy", numUnhealthy)
+		rc.queue.AddAfter(epName, RolloutRecheckPeriod)
+	case remainingPause > 0:
+		newStatus.Message = fmt.Sprintf("pausing until %s", waveStart.Add(pause).Format(time.RFC3339))
+		rc.queue.AddAfter(epName, remainingPause)
+	case len(toAdmit) == 0:
+		newStatus.Message = fmt.Sprintf("waiting because %d destinations are unhealthy", numUnhealthy)
+		rc.queue.AddAfter(epName, RolloutRecheckPeriod)
+	default:
+		heldBack := rc.admitWave(epName, toAdmit)
+		numAdmitted += len(toAdmit)
+		newStatus.Wave++
+		now := metav1.Now()
+		newStatus.WaveStartTime = &now
+		logger.V(2).Info("Admitted rollout wave", "wave", newStatus.Wave, "destinations", toAdmit)
+		for _, soRef := range heldBack {
+			rc.requeue(soRef)
+		}
+		rc.queue.AddAfter(epName, pause)
+	}
+	newStatus.Phase = edgeapi.RolloutPhaseProgressing
+	newStatus.UpdatedDestinationCount = int32(numAdmitted)
+	return rc.updateStatus(ctx, ep, newStatus)
+}
+
+// admitWave adds the given destinations to the rollout in progress
+// and returns the source objects that were held back.
+func (rc *rolloutController) admitWave(epName ExternalName, toAdmit []SinglePlacement) []sourceObjectRef {
+	rc.Lock()
+	defer rc.Unlock()
+	state := rc.rollouts[epName]
+	if state == nil {
+		return nil
+	}
+	for _, dest := range toAdmit {
+		state.admitted.Add(dest)
+	}
+	state.wave++
+	state.waveStart = time.Now()
+	heldBack := []sourceObjectRef{}
+	state.heldBack.Visit(func(soRef sourceObjectRef) error {
+		heldBack = append(heldBack, soRef)
+		return nil
+	})
+	state.heldBack = NewEmptyMapSet[sourceObjectRef]()
+	return heldBack
+}
+
+// finishRollout forgets any rollout in progress, releasing the objects held back.
+func (rc *rolloutController) finishRollout(epName ExternalName) {
+	rc.Lock()
+	state := rc.rollouts[epName]
+	delete(rc.rollouts, epName)
+	rc.Unlock()
+	if state == nil {
+		return
+	}
+	state.heldBack.Visit(func(soRef sourceObjectRef) error {
+		rc.requeue(soRef)
+		return nil
+	})
+}
+
+// destinationIsHealthy returns (healthy, ok), where ok is false if a read failed
+// in a way that is worth retrying.
+func (rc *rolloutController) destinationIsHealthy(what ResolvedWhat, destination SinglePlacement) (bool, bool) {
+	_, syncfg := getMailbox(rc.mbwsLister, rc.syncfgClusterLister, destination)
+	if syncfg == nil {
+		return false, true
+	}
+	for _, count := range syncfg.Status.ObjectCounts {
+		if count.Failed > 0 && whatIncludesCount(what, count) {
+			return false, true
+		}
+	}
+	for _, objStatus := range syncfg.Status.ObjectStatuses {
+		if objStatus.Outcome == edgeapi.SyncOutcomeFailed && whatIncludesObject(what, objStatus) {
+			return false, true
+		}
+	}
+	projected, ok := readProjectedObjects(rc.projected, destination, syncfg, what, nil)
+	if !ok {
+		return false, false
+	}
+	for _, condition := range aggregateReadiness(nil, projected) {
+		if condition.Status != metav1.ConditionTrue {
+			return false, true
+		}
+	}
+	return true, true
+}
+
+func (rc *rolloutController) updateStatus(ctx context.Context, ep *edgeapi.EdgePlacement, rolloutStatus *edgeapi.EdgePlacementRolloutStatus) bool {
+	logger := klog.FromContext(ctx)
+	if rolloutStatus != nil && ep.Status.Rollout != nil && rolloutStatus.Phase == ep.Status.Rollout.Phase &&
+		rolloutStatus.Phase == edgeapi.RolloutPhaseComplete {
+		// Keep the time of the last wave of the completed rollout
+		rolloutStatus.WaveStartTime = ep.Status.Rollout.WaveStartTime
+	}
+	if apiequality.Semantic.DeepEqual(ep.Status.Rollout, rolloutStatus) {
+		return true
+	}
+	epName := ExternalName{Cluster: logicalcluster.From(ep), Name: ep.Name}
+	err := patchEdgePlacementStatus(ctx, rc.edgeClusterClientset, epName, map[string]interface{}{"rollout": rolloutStatus})
+	if err != nil {
+		logger.Error(err, "Failed to update EdgePlacement rollout status")
+		return k8sapierrors.IsNotFound(err)
+	}
+	return true
+}
@@ -0,0 +1,405 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placement
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	tenancyv1a1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	tenancyv1a1listers "github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8scache "k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	edgeapi "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+	edgefakeclient "github.com/kubestellar/kubestellar/pkg/client/clientset/versioned/cluster/fake"
+	edgeinformers "github.com/kubestellar/kubestellar/pkg/client/informers/externalversions"
+)
+
+func TestNextWave(t *testing.T) {
+	where := ResolvedWhere{
+		{Destinations: []edgeapi.SinglePlacement{{Cluster: "c2", SyncTargetName: "a"}, {Cluster: "c1", SyncTargetName: "b"}}},
+		{Destinations: []edgeapi.SinglePlacement{{Cluster: "c1", SyncTargetName: "a"}, {Cluster: "c2", SyncTargetName: "b"}, {Cluster: "c1", SyncTargetName: "b"}}},
+	}
+	destinations := sortedDestinations(where)
+	expectedOrder := []SinglePlacement{
+		{Cluster: "c1", SyncTargetName: "a"}, {Cluster: "c1", SyncTargetName: "b"},
+		{Cluster: "c2", SyncTargetName: "a"}, {Cluster: "c2", SyncTargetName: "b"},
+	}
+	if !reflect.DeepEqual(destinations, expectedOrder) {
+		t.Fatalf("Expected destinations %v, got %v", expectedOrder, destinations)
+	}
+	intPtr := func(val int) *intstr.IntOrString { ans := intstr.FromInt(val); return &ans }
+	strPtr := func(val string) *intstr.IntOrString { ans := intstr.FromString(val); return &ans }
+	for idx, testCase := range []struct {
+		strategy     edgeapi.RolloutStrategy
+		numAdmitted  int
+		numUnhealthy int
+		expected     []SinglePlacement
+	}{
+		{edgeapi.RolloutStrategy{}, 1, 0, expectedOrder[1:2]},
+		{edgeapi.RolloutStrategy{BatchSize: intPtr(2)}, 1, 0, expectedOrder[1:3]},
+		{edgeapi.RolloutStrategy{BatchSize: strPtr("50%")}, 0, 0, expectedOrder[0:2]},
+		{edgeapi.RolloutStrategy{BatchSize: strPtr("30%")}, 2, 0, expectedOrder[2:4]},
+		{edgeapi.RolloutStrategy{BatchSize: intPtr(9)}, 3, 0, expectedOrder[3:4]},
+		{edgeapi.RolloutStrategy{BatchSize: intPtr(2), RequireHealthy: true}, 1, 0, expectedOrder[1:3]},
+		{edgeapi.RolloutStrategy{BatchSize: intPtr(2), RequireHealthy: true}, 1, 1, []SinglePlacement{}},
+		{edgeapi.RolloutStrategy{BatchSize: intPtr(2)}, 1, 1, expectedOrder[1:3]},
+		{edgeapi.RolloutStrategy{BatchSize: intPtr(2), RequireHealthy: true, MaxUnavailable: intPtr(1)}, 2, 1, expectedOrder[2:4]},
+		{edgeapi.RolloutStrategy{BatchSize: intPtr(2), RequireHealthy: true, MaxUnavailable: strPtr("25%")}, 2, 2, []SinglePlacement{}},
+	} {
+		admitted := NewMapSet(destinations[:testCase.numAdmitted]...)
+		actual := nextWave(&testCase.strategy, destinations, admitted, testCase.numUnhealthy)
+		if !reflect.DeepEqual(actual, testCase.expected) {
+			t.Errorf("Case %d: expected %v, got %v", idx, testCase.expected, actual)
+		}
+	}
+}
+
+// newTestRolloutController makes a rolloutController for one EdgePlacement,
+// in cluster "wmw1", that has the given strategy.
+func newTestRolloutController(t *testing.T, ctx context.Context, strategy *edgeapi.RolloutStrategy, mbwsLister tenancyv1a1listers.WorkspaceLister, syncfgs []*edgeapi.SyncerConfig, source projectedObjectSource) (*rolloutController, ExternalName) {
+	return newTestRolloutControllerWithStatus(t, ctx, strategy, nil, mbwsLister, syncfgs, source)
+}
+
+// newTestRolloutControllerWithStatus is like newTestRolloutController,
+// with the given rollout status in the EdgePlacement.
+func newTestRolloutControllerWithStatus(t *testing.T, ctx context.Context, strategy *edgeapi.RolloutStrategy, status *edgeapi.EdgePlacementRolloutStatus, mbwsLister tenancyv1a1listers.WorkspaceLister, syncfgs []*edgeapi.SyncerConfig, source projectedObjectSource) (*rolloutController, ExternalName) {
+	epName := ExternalName{Cluster: "wmw1", Name: "ep1"}
+	ep := &edgeapi.EdgePlacement{
+		ObjectMeta: metav1.ObjectMeta{Name: epName.Name,
+			Annotations: map[string]string{logicalcluster.AnnotationKey: epName.Cluster.String()}},
+		Spec:   edgeapi.EdgePlacementSpec{Rollout: strategy},
+		Status: edgeapi.EdgePlacementStatus{Rollout: status, SyncedObjectCount: 7},
+	}
+	edgeClient := edgefakeclient.NewSimpleClientset(ep)
+	informerFactory := edgeinformers.NewSharedInformerFactory(edgeClient, 0)
+	epPreInformer := informerFactory.Edge().V1alpha1().EdgePlacements()
+	if err := epPreInformer.Informer().GetIndexer().Add(ep); err != nil {
+		t.Fatal(err)
+	}
+	syncfgPreInformer := informerFactory.Edge().V1alpha1().SyncerConfigs()
+	for _, syncfg := range syncfgs {
+		if err := syncfgPreInformer.Informer().GetIndexer().Add(syncfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rc := newRolloutController(ctx, 1, epPreInformer.Lister(), syncfgPreInformer.Lister(), mbwsLister, edgeClient, source)
+	return rc, epName
+}
+
+// drainQueue removes everything from the given controller's queue
+func (rc *rolloutController) drainQueue() {
+	for rc.queue.Len() > 0 {
+		item, _ := rc.queue.Get()
+		rc.queue.Done(item)
+	}
+}
+
+func TestRolloutGatesProjection(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := klog.FromContext(ctx)
+	dest1 := SinglePlacement{Cluster: "inv", LocationName: "loc", SyncTargetName: "st1"}
+	dest2 := SinglePlacement{Cluster: "inv", LocationName: "loc", SyncTargetName: "st2"}
+	clusterRoles := metav1.GroupResource{Group: "rbac.authorization.k8s.io", Resource: "clusterroles"}
+	gvr := schema.GroupVersionResource{Group: clusterRoles.Group, Version: "v1", Resource: clusterRoles.Resource}
+	source := &fakeProjectedObjects{}
+	rc, epName := newTestRolloutController(t, ctx, &edgeapi.RolloutStrategy{}, nil, nil, source)
+	defer rc.queue.ShutDown()
+	requeued := []sourceObjectRef{}
+	rc.requeue = func(soRef sourceObjectRef) { requeued = append(requeued, soRef) }
+	rc.WhatReceiver().Put(epName, ResolvedWhat{Downsync: WorkloadParts{
+		{APIGroup: clusterRoles.Group, Resource: clusterRoles.Resource, Name: "reader"}: {APIVersion: "v1"},
+	}})
+	rc.WhereReceiver().Put(epName, ResolvedWhere{{Destinations: []SinglePlacement{dest2, dest1}}})
+	rc.drainQueue()
+
+	wp := &workloadProjector{ctx: ctx, rolloutGate: rc,
+		perDestination: NewMapMap[SinglePlacement, *wpPerDestination](nil)}
+	modes := NewFactoredMapMap[ProjectionModeKey, SinglePlacement, metav1.GroupResource, ProjectionModeVal](factorProjectionModeKeyForSyncer, nil, nil, nil)
+	clients := map[SinglePlacement]*dynamicfake.FakeDynamicClient{}
+	for _, dest := range []SinglePlacement{dest1, dest2} {
+		client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{gvr: "ClusterRoleList"})
+		clients[dest] = client
+		wpd := wp.newPerDestinationLocked(dest)
+		wpd.dynamicClient = client
+		wpd.preInformers.Put(clusterRoles, dynamicDuo{apiVersion: "v1", client: client.Resource(gvr)})
+		wp.perDestination.Put(dest, wpd)
+		modes.Put(ProjectionModeKey{clusterRoles, dest}, ProjectionModeVal{APIVersion: "v1"})
+	}
+	soRef := sourceObjectRef{cluster: "wmw1", groupResource: clusterRoles, namespace: noNamespace, name: "reader"}
+	srcObj := &unstructured.Unstructured{}
+	srcObj.SetAPIVersion("rbac.authorization.k8s.io/v1")
+	srcObj.SetKind("ClusterRole")
+	srcObj.SetName("reader")
+	project := func(dest SinglePlacement, deleted bool) {
+		retry, rem := wp.syncSourceToDestLocked(ctx, logger, soRef, srcObj, false, deleted, modes, dest)
+		if retry || rem == nil || rem() {
+			t.Fatalf("Failed to project to %v", dest)
+		}
+	}
+	getProjected := func(dest SinglePlacement) *unstructured.Unstructured {
+		obj, err := clients[dest].Resource(gvr).Get(ctx, "reader", metav1.GetOptions{})
+		if k8sapierrors.IsNotFound(err) {
+			return nil
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		return obj
+	}
+	// admitNextWave runs the controller, which should admit dest2 and release the held back change
+	admitNextWave := func(change string) {
+		requeued = requeued[:0]
+		if !rc.process(ctx, epName) {
+			t.Fatalf("Failed to process rollout of %s", change)
+		}
+		if !reflect.DeepEqual(requeued, []sourceObjectRef{soRef}) {
+			t.Errorf("Expected held back %s of %v to be requeued, got %v", change, soRef, requeued)
+		}
+		source.handlers[0](dest2)
+		if rc.queue.Len() == 0 {
+			t.Errorf("Expected a change at %v to be noticed during the rollout of %s", dest2, change)
+		}
+		rc.drainQueue()
+	}
+	// finish runs the controller, which should complete the rollout
+	finish := func(change string) {
+		if !rc.process(ctx, epName) {
+			t.Fatalf("Failed to finish rollout of %s", change)
+		}
+		if len(rc.rollouts) != 0 {
+			t.Errorf("Expected rollout of %s to be complete, got %v", change, rc.rollouts)
+		}
+		ep, err := rc.edgeClusterClientset.EdgeV1alpha1().EdgePlacements().Cluster(epName.Cluster.Path()).Get(ctx, epName.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ep.Status.Rollout == nil || ep.Status.Rollout.Phase != edgeapi.RolloutPhaseComplete || ep.Status.Rollout.UpdatedDestinationCount != 2 {
+			t.Errorf("Expected complete rollout of %s in status, got %+v", change, ep.Status.Rollout)
+		}
+		rc.drainQueue()
+	}
+
+	// Creation
+	project(dest1, false)
+	project(dest2, false)
+	if getProjected(dest1) == nil {
+		t.Errorf("Expected creation at %v in first wave", dest1)
+	}
+	if getProjected(dest2) != nil {
+		t.Errorf("Expected creation at %v to be held back", dest2)
+	}
+	admitNextWave("creation")
+	project(dest2, false)
+	if getProjected(dest2) == nil {
+		t.Errorf("Expected creation at %v in second wave", dest2)
+	}
+	finish("creation")
+
+	// Update
+	srcObj.SetLabels(map[string]string{"version": "2"})
+	project(dest1, false)
+	project(dest2, false)
+	if getProjected(dest1).GetLabels()["version"] != "2" {
+		t.Errorf("Expected update at %v in first wave", dest1)
+	}
+	if getProjected(dest2).GetLabels()["version"] == "2" {
+		t.Errorf("Expected update at %v to be held back", dest2)
+	}
+	admitNextWave("update")
+	project(dest2, false)
+	if getProjected(dest2).GetLabels()["version"] != "2" {
+		t.Errorf("Expected update at %v in second wave", dest2)
+	}
+	finish("update")
+
+	// Deletion
+	project(dest1, true)
+	project(dest2, true)
+	if getProjected(dest1) != nil {
+		t.Errorf("Expected deletion at %v in first wave", dest1)
+	}
+	if getProjected(dest2) == nil {
+		t.Errorf("Expected deletion at %v to be held back", dest2)
+	}
+	admitNextWave("deletion")
+	project(dest2, true)
+	if getProjected(dest2) != nil {
+		t.Errorf("Expected deletion at %v in second wave", dest2)
+	}
+	finish("deletion")
+}
+
+func TestAdmitChangeIgnoresOtherPlacements(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dest1 := SinglePlacement{Cluster: "inv", SyncTargetName: "st1"}
+	dest2 := SinglePlacement{Cluster: "inv", SyncTargetName: "st2"}
+	rc, epName := newTestRolloutController(t, ctx, &edgeapi.RolloutStrategy{}, nil, nil, &fakeProjectedObjects{})
+	defer rc.queue.ShutDown()
+	rc.WhatReceiver().Put(epName, ResolvedWhat{Downsync: WorkloadParts{
+		{APIGroup: "", Resource: "namespaces", Name: "ns1"}: {APIVersion: "v1"},
+	}})
+	rc.WhereReceiver().Put(epName, ResolvedWhere{{Destinations: []SinglePlacement{dest1, dest2}}})
+	deployments := metav1.GroupResource{Group: "apps", Resource: "deployments"}
+	for _, soRef := range []sourceObjectRef{
+		{cluster: "wmw1", groupResource: deployments, namespace: "ns2", name: "d1"},
+		{cluster: "wmw2", groupResource: deployments, namespace: "ns1", name: "d1"},
+	} {
+		if !rc.AdmitChange(soRef, dest2) {
+			t.Errorf("Expected change of %v, which is not in the EdgePlacement's what, to be admitted", soRef)
+		}
+	}
+	if len(rc.rollouts) != 0 {
+		t.Errorf("Expected no rollout to start, got %v", rc.rollouts)
+	}
+	soRef := sourceObjectRef{cluster: "wmw1", groupResource: deployments, namespace: "ns1", name: "d1"}
+	if !rc.AdmitChange(soRef, dest1) {
+		t.Errorf("Expected change at %v to be admitted in the first wave", dest1)
+	}
+	if rc.AdmitChange(soRef, dest2) {
+		t.Errorf("Expected change at %v to be held back", dest2)
+	}
+}
+
+func TestRolloutResumesFromStatus(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dest1 := SinglePlacement{Cluster: "inv", SyncTargetName: "st1"}
+	dest2 := SinglePlacement{Cluster: "inv", SyncTargetName: "st2"}
+	dest3 := SinglePlacement{Cluster: "inv", SyncTargetName: "st3"}
+	waveStart := metav1.NewTime(time.Now().Add(-time.Minute).Truncate(time.Second))
+	status := &edgeapi.EdgePlacementRolloutStatus{Phase: edgeapi.RolloutPhaseProgressing, Wave: 2,
+		UpdatedDestinationCount: 2, DestinationCount: 3, WaveStartTime: &waveStart}
+	rc, epName := newTestRolloutControllerWithStatus(t, ctx, &edgeapi.RolloutStrategy{Pause: &metav1.Duration{Duration: time.Hour}},
+		status, nil, nil, &fakeProjectedObjects{})
+	defer rc.queue.ShutDown()
+	rc.WhatReceiver().Put(epName, ResolvedWhat{Downsync: WorkloadParts{
+		{APIGroup: "", Resource: "namespaces", Name: "ns1"}: {APIVersion: "v1"},
+	}})
+
+	// Until the "where" is known, the rollout in the status is left alone
+	if !rc.process(ctx, epName) {
+		t.Fatal("Failed to process rollout")
+	}
+	if len(rc.rollouts) != 0 {
+		t.Errorf("Expected no rollout before the where is known, got %v", rc.rollouts)
+	}
+	rc.WhereReceiver().Put(epName, ResolvedWhere{{Destinations: []SinglePlacement{dest3, dest2, dest1}}})
+	rc.drainQueue()
+
+	// The first two destinations in rollout order were admitted before the restart
+	soRef := sourceObjectRef{cluster: "wmw1", groupResource: metav1.GroupResource{Group: "apps", Resource: "deployments"}, namespace: "ns1", name: "d1"}
+	for _, dest := range []SinglePlacement{dest1, dest2} {
+		if !rc.AdmitChange(soRef, dest) {
+			t.Errorf("Expected change at %v to be admitted by the resumed rollout", dest)
+		}
+	}
+	if rc.AdmitChange(soRef, dest3) {
+		t.Errorf("Expected change at %v to be held back by the resumed rollout", dest3)
+	}
+	if !rc.process(ctx, epName) {
+		t.Fatal("Failed to process rollout")
+	}
+	ep, err := rc.edgeClusterClientset.EdgeV1alpha1().EdgePlacements().Cluster(epName.Cluster.Path()).Get(ctx, epName.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rollout := ep.Status.Rollout
+	if rollout == nil || rollout.Phase != edgeapi.RolloutPhaseProgressing || rollout.Wave != 2 || rollout.UpdatedDestinationCount != 2 ||
+		rollout.WaveStartTime == nil || !rollout.WaveStartTime.Equal(&waveStart) {
+		t.Errorf("Expected the rollout to still be pausing in its second wave, got %+v", rollout)
+	}
+	// The status fields written by others are kept
+	if ep.Status.SyncedObjectCount != 7 {
+		t.Errorf("Expected syncedObjectCount to be kept, got %d", ep.Status.SyncedObjectCount)
+	}
+}
+
+func TestRolloutDestinationIsHealthy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	destination := SinglePlacement{Cluster: "inv", SyncTargetName: "st1"}
+	deployments := metav1.GroupResource{Group: "apps", Resource: "deployments"}
+	mbwsIndexer := k8scache.NewIndexer(k8scache.MetaNamespaceKeyFunc, k8scache.Indexers{})
+	mbws := &tenancyv1a1.Workspace{ObjectMeta: metav1.ObjectMeta{Name: SPMailboxWorkspaceName(destination)},
+		Spec: tenancyv1a1.WorkspaceSpec{Cluster: "mb1"}}
+	if err := mbwsIndexer.Add(mbws); err != nil {
+		t.Fatal(err)
+	}
+	syncfg := &edgeapi.SyncerConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: SyncerConfigName,
+			Annotations: map[string]string{logicalcluster.AnnotationKey: "mb1"}},
+		Spec: edgeapi.SyncerConfigSpec{NamespaceScope: edgeapi.NamespaceScopeDownsyncs{
+			Namespaces: []string{"ns1"},
+			Resources:  []edgeapi.NamespaceScopeDownsyncResource{{GroupResource: deployments, APIVersion: "v1"}},
+		}},
+	}
+	deployment := projectedObject("ns1", "d1")
+	source := &fakeProjectedObjects{destination: destination, objects: map[metav1.GroupResource][]*unstructured.Unstructured{
+		deployments: {deployment},
+	}}
+	rc, _ := newTestRolloutController(t, ctx, &edgeapi.RolloutStrategy{RequireHealthy: true},
+		tenancyv1a1listers.NewWorkspaceLister(mbwsIndexer), []*edgeapi.SyncerConfig{syncfg}, source)
+	defer rc.queue.ShutDown()
+	what := ResolvedWhat{Downsync: WorkloadParts{{APIGroup: "", Resource: "namespaces", Name: "ns1"}: {APIVersion: "v1"}}}
+	setAvailable := func(status string) {
+		conditions := []any{map[string]any{"type": "Available", "status": status}}
+		if err := unstructured.SetNestedSlice(deployment.Object, conditions, "status", "conditions"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for idx, testCase := range []struct {
+		available string
+		unsynced  bool
+		healthy   bool
+		ok        bool
+	}{
+		{"True", false, true, true},
+		{"False", false, false, true},
+		{"True", true, false, false},
+	} {
+		setAvailable(testCase.available)
+		source.unsynced = testCase.unsynced
+		healthy, ok := rc.destinationIsHealthy(what, destination)
+		if healthy != testCase.healthy || ok != testCase.ok {
+			t.Errorf("Case %d: expected (%v, %v), got (%v, %v)", idx, testCase.healthy, testCase.ok, healthy, ok)
+		}
+	}
+	source.unsynced = false
+	setAvailable("True")
+	syncfg.Status.ObjectStatuses = []edgeapi.SyncedObjectStatus{{Direction: edgeapi.SyncDirectionDown,
+		APIGroup: "apps", Resource: "deployments", Namespace: "ns1", Name: "d1", Outcome: edgeapi.SyncOutcomeFailed}}
+	if healthy, ok := rc.destinationIsHealthy(what, destination); healthy || !ok {
+		t.Errorf("Expected a destination that failed to sync to be unhealthy, got (%v, %v)", healthy, ok)
+	}
+	if healthy, _ := rc.destinationIsHealthy(what, SinglePlacement{Cluster: "inv", SyncTargetName: "st9"}); healthy {
+		t.Error("Expected a destination without a mailbox workspace to be unhealthy")
+	}
+}
@@ -30,6 +30,8 @@ import (
 // For each EdgePlacement, it maintains:
 // - a map differencer for the downsync part of the resolved "what",
 // - a slice differencer for the upsync part of the resolved "what",
+// - a slice differencer for the ignoreDifferences part of the resolved "what",
+// - a slice differencer for the per-resource policies part of the resolved "what",
 // - a set difference for the resolved "where".
 // Those differencers feed into the downsyncJoinLeftInput and downsyncJoinRightInput, respectively.
 // These drive an equijoin on the ExternalName of the EdgePlacement.
@@ -45,8 +47,12 @@ type setBinder struct {
 	downsyncJoinLeftInput               MappingReceiver[Pair[ExternalName, WorkloadPartID], WorkloadPartDetails]
 	bothJoinRightInput                  SetWriter[Pair[ExternalName, SinglePlacement]]
 	upsyncJoinLeftInput                 SetWriter[Pair[ExternalName, edgeapi.UpsyncSet]]
+	ignoreJoinLeftInput                 SetWriter[Pair[ExternalName, edgeapi.IgnoreDifferences]]
+	policyJoinLeftInput                 SetWriter[Pair[ExternalName, ResourcePolicy]]
 	singleBindingOps                    SingleBindingOps
 	upsyncOps                           UpsyncOps
+	ignoreDifferencesOps                IgnoreDifferencesOps
+	resourcePoliciesOps                 ResourcePoliciesOps
 }
 
 type setBindingForCluster struct {
@@ -59,6 +65,8 @@ type setBindingForPlacement struct {
 	*setBindingForCluster
 	downsyncPartsReceiver Receiver[WorkloadParts]
 	upsyncReceiver        Receiver[[]edgeapi.UpsyncSet]
+	ignoreReceiver        Receiver[[]edgeapi.IgnoreDifferences]
+	policyReceiver        Receiver[[]ResourcePolicy]
 	resolvedWhereReceiver Receiver[ResolvedWhere]
 }
 
@@ -88,7 +96,7 @@ func NewSetBinder(
 			singleBinder:                        singleBinder,
 		}
 
-		var downsyncJoinRightInput, upsyncJoinRightInput SetWriter[Pair[ExternalName, SinglePlacement]]
+		var downsyncJoinRightInput, upsyncJoinRightInput, ignoreJoinRightInput, policyJoinRightInput SetWriter[Pair[ExternalName, SinglePlacement]]
 		sb.downsyncJoinLeftInput, downsyncJoinRightInput = NewDynamicFullJoin12VWith13(sb.logger,
 			NewMappingReceiverFuncs(
 				func(tup Triple[ExternalName, WorkloadPartID, SinglePlacement], workloadPartDetails WorkloadPartDetails) {
@@ -117,7 +125,38 @@ func NewSetBinder(
 				},
 			))
 
-		sb.bothJoinRightInput = SetWriterFork(true, downsyncJoinRightInput, upsyncJoinRightInput)
+		sb.ignoreJoinLeftInput, ignoreJoinRightInput = NewDynamicFullJoin12with13[ExternalName, edgeapi.IgnoreDifferences, SinglePlacement](sb.logger,
+			NewSetWriterFuncs(
+				func(tup Triple[ExternalName, edgeapi.IgnoreDifferences, SinglePlacement]) bool {
+					sb.logger.V(4).Info("Adding ignoreDifferences tuple", "epRef", tup.First, "ignoreDifferences", tup.Second, "where", tup.Third)
+					sb.ignoreDifferencesOps(true, tup)
+					return true
+				},
+				func(tup Triple[ExternalName, edgeapi.IgnoreDifferences, SinglePlacement]) bool {
+					sb.logger.V(4).Info("Removing ignoreDifferences tuple", "epRef", tup.First, "ignoreDifferences", tup.Second, "where", tup.Third)
+					sb.ignoreDifferencesOps(false, tup)
+					return true
+				},
+			))
+
+		sb.policyJoinLeftInput, policyJoinRightInput = NewDynamicFullJoin12with13Parametric[ExternalName, ResourcePolicy, SinglePlacement](sb.logger,
+			HashExternalName,
+			HashResourcePolicy{},
+			HashSinglePlacement{},
+			NewSetWriterFuncs(
+				func(tup Triple[ExternalName, ResourcePolicy, SinglePlacement]) bool {
+					sb.logger.V(4).Info("Adding resource policy tuple", "epRef", tup.First, "policy", tup.Second, "where", tup.Third)
+					sb.resourcePoliciesOps(true, tup)
+					return true
+				},
+				func(tup Triple[ExternalName, ResourcePolicy, SinglePlacement]) bool {
+					sb.logger.V(4).Info("Removing resource policy tuple", "epRef", tup.First, "policy", tup.Second, "where", tup.Third)
+					sb.resourcePoliciesOps(false, tup)
+					return true
+				},
+			))
+
+		sb.bothJoinRightInput = SetWriterFork(true, downsyncJoinRightInput, upsyncJoinRightInput, ignoreJoinRightInput, policyJoinRightInput)
 
 		return sbAsResolvedWhatReceiver{sb}, sbAsResolvedWhereReceiver{sb}
 	}
@@ -129,14 +168,20 @@ func (sb sbAsResolvedWhatReceiver) Put(epName ExternalName, resolvedWhat Resolve
 	sb.Lock()
 	defer sb.Unlock()
 	sbc := sb.getCluster(epName.Cluster, true)
-	sbc.singleBinder.Transact(func(downsyncOps SingleBindingOps, upsyncOps UpsyncOps) {
+	sbc.singleBinder.Transact(func(downsyncOps SingleBindingOps, upsyncOps UpsyncOps, ignoreOps IgnoreDifferencesOps, policyOps ResourcePoliciesOps) {
 		sbp := sbc.ensurePlacement(epName.Name)
 		sbc.singleBindingOps = downsyncOps
 		sbc.upsyncOps = upsyncOps
+		sbc.ignoreDifferencesOps = ignoreOps
+		sbc.resourcePoliciesOps = policyOps
 		sbp.downsyncPartsReceiver.Receive(resolvedWhat.Downsync)
 		sbp.upsyncReceiver.Receive(resolvedWhat.Upsync)
+		sbp.ignoreReceiver.Receive(resolvedWhat.IgnoreDifferences)
+		sbp.policyReceiver.Receive(resolvedWhat.ResourcePolicies)
 		sbc.singleBindingOps = nil
 		sbc.upsyncOps = nil
+		sbc.ignoreDifferencesOps = nil
+		sbc.resourcePoliciesOps = nil
 	})
 }
 
@@ -148,14 +193,20 @@ func (sb sbAsResolvedWhatReceiver) Delete(epName ExternalName) {
 		return
 	}
 	var resolvedWhat WorkloadParts
-	sbc.singleBinder.Transact(func(sbo SingleBindingOps, upsyncOps UpsyncOps) {
+	sbc.singleBinder.Transact(func(sbo SingleBindingOps, upsyncOps UpsyncOps, ignoreOps IgnoreDifferencesOps, policyOps ResourcePoliciesOps) {
 		sbp := sbc.ensurePlacement(epName.Name)
 		sbc.singleBindingOps = sbo
 		sbc.upsyncOps = upsyncOps
+		sbc.ignoreDifferencesOps = ignoreOps
+		sbc.resourcePoliciesOps = policyOps
 		sbp.downsyncPartsReceiver.Receive(resolvedWhat)
 		sbp.upsyncReceiver.Receive([]edgeapi.UpsyncSet{})
+		sbp.ignoreReceiver.Receive([]edgeapi.IgnoreDifferences{})
+		sbp.policyReceiver.Receive([]ResourcePolicy{})
 		sbc.singleBindingOps = nil
 		sbc.upsyncOps = nil
+		sbc.ignoreDifferencesOps = nil
+		sbc.resourcePoliciesOps = nil
 	})
 }
 
@@ -165,13 +216,17 @@ func (sb sbAsResolvedWhereReceiver) Put(epName ExternalName, resolvedWhere Resol
 	sb.Lock()
 	defer sb.Unlock()
 	sbc := sb.getCluster(epName.Cluster, true)
-	sbc.singleBinder.Transact(func(sbo SingleBindingOps, uso UpsyncOps) {
+	sbc.singleBinder.Transact(func(sbo SingleBindingOps, uso UpsyncOps, ido IgnoreDifferencesOps, rpo ResourcePoliciesOps) {
 		sbp := sbc.ensurePlacement(epName.Name)
 		sb.singleBindingOps = sbo
 		sb.upsyncOps = uso
+		sb.ignoreDifferencesOps = ido
+		sb.resourcePoliciesOps = rpo
 		sbp.resolvedWhereReceiver.Receive(resolvedWhere)
 		sb.singleBindingOps = nil
 		sb.upsyncOps = nil
+		sb.ignoreDifferencesOps = nil
+		sb.resourcePoliciesOps = nil
 	})
 }
 
@@ -183,13 +238,17 @@ func (sb sbAsResolvedWhereReceiver) Delete(epName ExternalName) {
 		return
 	}
 	var resolvedWhere ResolvedWhere
-	sbc.singleBinder.Transact(func(sbo SingleBindingOps, uso UpsyncOps) {
+	sbc.singleBinder.Transact(func(sbo SingleBindingOps, uso UpsyncOps, ido IgnoreDifferencesOps, rpo ResourcePoliciesOps) {
 		sbp := sbc.ensurePlacement(epName.Name)
 		sb.singleBindingOps = sbo
 		sb.upsyncOps = uso
+		sb.ignoreDifferencesOps = ido
+		sb.resourcePoliciesOps = rpo
 		sbp.resolvedWhereReceiver.Receive(resolvedWhere)
 		sb.singleBindingOps = nil
 		sb.upsyncOps = nil
+		sb.ignoreDifferencesOps = nil
+		sb.resourcePoliciesOps = nil
 	})
 }
 
@@ -227,6 +286,22 @@ func (sbc *setBindingForCluster) ensurePlacement(epName string) *setBindingForPl
 				sbc.upsyncJoinLeftInput.Remove(NewPair(epID, upTerm))
 			}
 		})
+		sbp.ignoreReceiver = NewSliceDifferencerParametric(func(a, b edgeapi.IgnoreDifferences) bool { return a == b },
+			func(add bool, ignore edgeapi.IgnoreDifferences) {
+				if add {
+					sbc.ignoreJoinLeftInput.Add(NewPair(epID, ignore))
+				} else {
+					sbc.ignoreJoinLeftInput.Remove(NewPair(epID, ignore))
+				}
+			}, nil)
+		sbp.policyReceiver = NewSliceDifferencerParametric(HashResourcePolicy{}.Equal,
+			func(add bool, policy ResourcePolicy) {
+				if add {
+					sbc.policyJoinLeftInput.Add(NewPair(epID, policy))
+				} else {
+					sbc.policyJoinLeftInput.Remove(NewPair(epID, policy))
+				}
+			}, nil)
 		sbp.resolvedWhereReceiver = sbc.resolvedWhereDifferencerConstructor(TransformSetWriter(
 			NewPair1Then2[ExternalName, SinglePlacement](epID),
 			sbc.bothJoinRightInput,
@@ -51,6 +51,20 @@ func exerciseSetBinder(t *testing.T, logger klog.Logger, resourceDiscoveryReceiv
 	ups1 := []edgeapi.UpsyncSet{
 		{APIGroup: "group1.test", Resources: []string{"sprockets", "flanges"}, Names: []string{"George", "Cosmo"}},
 		{APIGroup: "group2.test", Resources: []string{"cogs"}, Names: []string{"William"}}}
+	ignores1 := []edgeapi.IgnoreDifferences{
+		{GroupResource: metav1.GroupResource{Group: "apps", Resource: "deployments"}, JSONPath: "$.spec.replicas"},
+		{GroupResource: metav1.GroupResource{Group: "apps", Resource: "deployments"}, ManagedFieldsManager: "injector"}}
+	ignores2 := ignores1[1:]
+	policies1 := resourcePoliciesOf(edgeapi.EdgePlacementSpec{
+		DriftPolicies: []edgeapi.ResourceDriftPolicy{
+			{GroupResource: metav1.GroupResource{Group: "apps", Resource: "deployments"}, Policy: edgeapi.DriftPolicyReport}},
+		Subresources: []edgeapi.ResourceSubresources{
+			{GroupResource: metav1.GroupResource{Group: "apps", Resource: "deployments"}, Scale: &edgeapi.ScaleSubresource{}}},
+	})
+	policies2 := resourcePoliciesOf(edgeapi.EdgePlacementSpec{
+		Subresources: []edgeapi.ResourceSubresources{
+			{GroupResource: metav1.GroupResource{Group: "apps", Resource: "deployments"}, Scale: &edgeapi.ScaleSubresource{}}},
+	})
 	gr2 := metav1.GroupResource{
 		Group:    "",
 		Resource: "namespaces"}
@@ -86,6 +100,8 @@ func exerciseSetBinder(t *testing.T, logger klog.Logger, resourceDiscoveryReceiv
 	NonNamespacedDistributions := NewMapSet[NonNamespacedDistributionTuple]()
 	NonNamespacedModes := NewMapMap[ProjectionModeKey, ProjectionModeVal](nil)
 	Upsyncs := NewHashSet(PairHashDomain[SinglePlacement, edgeapi.UpsyncSet](HashSinglePlacement{}, HashUpsyncSet{}))
+	IgnoreDifferences := NewMapSet[Pair[SinglePlacement, edgeapi.IgnoreDifferences]]()
+	ResourcePolicies := NewHashSet(PairHashDomain[SinglePlacement, ResourcePolicy](HashSinglePlacement{}, HashResourcePolicy{}))
 	projectionTracker := WorkloadProjectionSections{
 		NamespaceDistributions:          NamespaceDistributions,
 		NamespacedResourceDistributions: NamespacedResourceDistributions,
@@ -93,9 +109,11 @@ func exerciseSetBinder(t *testing.T, logger klog.Logger, resourceDiscoveryReceiv
 		NonNamespacedDistributions:      NonNamespacedDistributions,
 		NonNamespacedModes:              NonNamespacedModes,
 		Upsyncs:                         Upsyncs,
+		IgnoreDifferences:               IgnoreDifferences,
+		ResourcePolicies:                ResourcePolicies,
 	}
 	whatReceiver, whereReceiver := binder(TrivialTransactor[WorkloadProjectionSections]{projectionTracker})
-	rw1 := ResolvedWhat{parts1, ups1}
+	rw1 := ResolvedWhat{parts1, ups1, ignores1, policies1}
 	t.Logf("Setting epRef=%v, ResolvedWhat=%v", ep1Ref, rw1)
 	logger.Info("Setting ResolvedWhat", "epRef", ep1Ref, "resolvedWhat", rw1)
 	whatReceiver.Put(ep1Ref, rw1)
@@ -134,12 +152,23 @@ func exerciseSetBinder(t *testing.T, logger klog.Logger, resourceDiscoveryReceiv
 			VisitableToSlice[Pair[SinglePlacement, edgeapi.UpsyncSet]](expectedUpsyncs),
 			VisitableToSlice[Pair[SinglePlacement, edgeapi.UpsyncSet]](Upsyncs))
 	}
+	expectedIgnoreDifferences := NewMapSet(NewPair(sp1, ignores1[0]), NewPair(sp1, ignores1[1]))
+	if !SetEqual[Pair[SinglePlacement, edgeapi.IgnoreDifferences]](expectedIgnoreDifferences, IgnoreDifferences) {
+		t.Fatalf("Wrong IgnoreDifferences: expected %v, got %v", expectedIgnoreDifferences, IgnoreDifferences)
+	}
+	expectedResourcePolicies := NewHashSet(PairHashDomain[SinglePlacement, ResourcePolicy](HashSinglePlacement{}, HashResourcePolicy{}),
+		NewPair(sp1, policies1[0]), NewPair(sp1, policies1[1]))
+	if !SetEqual[Pair[SinglePlacement, ResourcePolicy]](expectedResourcePolicies, ResourcePolicies) {
+		t.Fatalf("Wrong ResourcePolicies: expected %v, got %v",
+			VisitableToSlice[Pair[SinglePlacement, ResourcePolicy]](expectedResourcePolicies),
+			VisitableToSlice[Pair[SinglePlacement, ResourcePolicy]](ResourcePolicies))
+	}
 	expectedNamespaceDistributions := NewMapSet[NamespaceDistributionTuple]()
 	expectedNamespacedResourceDistributions := NewMapSet[NamespacedResourceDistributionTuple]()
 	expectedNamespacedModes := NewMapMap[ProjectionModeKey, ProjectionModeVal](nil)
 
 	rd2 := ResourceDetails{Namespaced: true, SupportsInformers: true, PreferredVersion: workloadPartDetails2.APIVersion}
-	rw2 := ResolvedWhat{parts2, ups2}
+	rw2 := ResolvedWhat{parts2, ups2, ignores2, policies2}
 	t.Logf("Setting epRef=%v, ResolvedWhat=%v", ep1Ref, rw2)
 	logger.Info("Setting ResolvedWhat", "epRef", ep1Ref, "resolvedWhat", rw2)
 	whatReceiver.Put(ep1Ref, rw2)
@@ -186,4 +215,14 @@ func exerciseSetBinder(t *testing.T, logger klog.Logger, resourceDiscoveryReceiv
 			VisitableToSlice[Pair[SinglePlacement, edgeapi.UpsyncSet]](expectedUpsyncs),
 			VisitableToSlice[Pair[SinglePlacement, edgeapi.UpsyncSet]](Upsyncs))
 	}
+	expectedIgnoreDifferences.Remove(NewPair(sp1, ignores1[0]))
+	if !SetEqual[Pair[SinglePlacement, edgeapi.IgnoreDifferences]](expectedIgnoreDifferences, IgnoreDifferences) {
+		t.Errorf("Wrong IgnoreDifferences: expected %v, got %v", expectedIgnoreDifferences, IgnoreDifferences)
+	}
+	expectedResourcePolicies.Remove(NewPair(sp1, policies1[0]))
+	if !SetEqual[Pair[SinglePlacement, ResourcePolicy]](expectedResourcePolicies, ResourcePolicies) {
+		t.Errorf("Wrong ResourcePolicies: expected %v, got %v",
+			VisitableToSlice[Pair[SinglePlacement, ResourcePolicy]](expectedResourcePolicies),
+			VisitableToSlice[Pair[SinglePlacement, ResourcePolicy]](ResourcePolicies))
+	}
 }
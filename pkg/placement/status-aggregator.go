@@ -0,0 +1,674 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placement
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	k8scache "k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	kcpcache "github.com/kcp-dev/apimachinery/v2/pkg/cache"
+	tenancyv1a1listers "github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	edgeapi "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+	edgeclusterclientset "github.com/kubestellar/kubestellar/pkg/client/clientset/versioned/cluster"
+	edgev1a1listers "github.com/kubestellar/kubestellar/pkg/client/listers/edge/v1alpha1"
+	"github.com/kubestellar/kubestellar/pkg/syncer/shared"
+)
+
+// MaxReportedSyncFailures bounds the length of EdgePlacementStatus.SyncFailures
+const MaxReportedSyncFailures = 20
+
+// statusAggregator maintains the sync summary in the status of each EdgePlacement.
+// It is kept appraised of the "what" and "where" resolutions, and reads the
+// per-object sync outcomes that the syncers report in the status of the
+// SyncerConfig objects in the mailbox workspaces.
+// An object status counts for an EdgePlacement if the SyncTarget is one of
+// the EdgePlacement's destinations and the object is one of the EdgePlacement's
+// downsynced parts (for a namespaced object: its namespace is one of those parts)
+// or matches one of its UpsyncSets (for an UpsyncSet with selectors, the syncer
+// reports which selectors the object was found selected by).
+// For each destination it also counts the projected objects in the mailbox workspace,
+// and aggregates the readiness conditions in their reported state.
+// The projected objects are read from the workload projector's informers, and an
+// EdgePlacement is reconsidered when they change; a SyncerConfig update
+// matters only if it changes the spec or the per-object sync outcomes,
+// not when it carries nothing but a heartbeat.
+type statusAggregator struct {
+	ctx        context.Context
+	logger     klog.Logger
+	numThreads int
+	queue      workqueue.RateLimitingInterface
+
+	epLister             edgev1a1listers.EdgePlacementClusterLister
+	syncfgClusterLister  edgev1a1listers.SyncerConfigClusterLister
+	mbwsLister           tenancyv1a1listers.WorkspaceLister
+	edgeClusterClientset edgeclusterclientset.ClusterInterface
+	projected            projectedObjectSource
+
+	sync.Mutex
+	whats  map[ExternalName]ResolvedWhat
+	wheres map[ExternalName]ResolvedWhere
+}
+
+var _ Runnable = &statusAggregator{}
+
+func newStatusAggregator(
+	ctx context.Context,
+	numThreads int,
+	epLister edgev1a1listers.EdgePlacementClusterLister,
+	syncfgClusterInformer kcpcache.ScopeableSharedIndexInformer,
+	syncfgClusterLister edgev1a1listers.SyncerConfigClusterLister,
+	mbwsLister tenancyv1a1listers.WorkspaceLister,
+	edgeClusterClientset edgeclusterclientset.ClusterInterface,
+	projected projectedObjectSource,
+) *statusAggregator {
+	controllerName := "status-aggregator"
+	logger := klog.FromContext(ctx).WithValues("part", controllerName)
+	sa := &statusAggregator{
+		ctx:                  klog.NewContext(ctx, logger),
+		logger:               logger,
+		numThreads:           numThreads,
+		queue:                workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName),
+		epLister:             epLister,
+		syncfgClusterLister:  syncfgClusterLister,
+		mbwsLister:           mbwsLister,
+		edgeClusterClientset: edgeClusterClientset,
+		projected:            projected,
+		whats:                map[ExternalName]ResolvedWhat{},
+		wheres:               map[ExternalName]ResolvedWhere{},
+	}
+	syncfgClusterInformer.AddEventHandler(k8scache.ResourceEventHandlerFuncs{
+		AddFunc: sa.enqueueForSyncerConfig,
+		UpdateFunc: func(oldObj, newObj any) {
+			if syncerConfigChangeMatters(oldObj, newObj) {
+				sa.enqueueForSyncerConfig(newObj)
+			}
+		},
+		DeleteFunc: sa.enqueueForSyncerConfig,
+	})
+	projected.addProjectedObjectHandler(sa.enqueueForDestination)
+	return sa
+}
+
+// WhatReceiver returns the receiver of the "what" resolutions.
+func (sa *statusAggregator) WhatReceiver() MappingReceiver[ExternalName, ResolvedWhat] {
+	return NewMappingReceiverFuncs(
+		func(epName ExternalName, what ResolvedWhat) {
+			sa.Lock()
+			defer sa.Unlock()
+			sa.whats[epName] = what
+			sa.queue.Add(epName)
+		},
+		func(epName ExternalName) {
+			sa.Lock()
+			defer sa.Unlock()
+			delete(sa.whats, epName)
+			sa.queue.Add(epName)
+		})
+}
+
+// WhereReceiver returns the receiver of the "where" resolutions.
+func (sa *statusAggregator) WhereReceiver() MappingReceiver[ExternalName, ResolvedWhere] {
+	return NewMappingReceiverFuncs(
+		func(epName ExternalName, where ResolvedWhere) {
+			sa.Lock()
+			defer sa.Unlock()
+			sa.wheres[epName] = where
+			sa.queue.Add(epName)
+		},
+		func(epName ExternalName) {
+			sa.Lock()
+			defer sa.Unlock()
+			delete(sa.wheres, epName)
+			sa.queue.Add(epName)
+		})
+}
+
+func (sa *statusAggregator) enqueueForSyncerConfig(obj any) {
+	if dfu, ok := obj.(k8scache.DeletedFinalStateUnknown); ok {
+		obj = dfu.Obj
+	}
+	syncfg := obj.(*edgeapi.SyncerConfig)
+	if syncfg.Name != SyncerConfigName {
+		return
+	}
+	cluster := logicalcluster.From(syncfg)
+	mbwsName, err := mailboxWorkspaceNameOf(sa.mbwsLister, cluster)
+	if err != nil {
+		sa.logger.Error(err, "Failed to list mailbox workspaces")
+		return
+	}
+	if mbwsName == "" {
+		sa.logger.V(4).Info("Ignoring SyncerConfig not in a known mailbox workspace", "cluster", cluster)
+		return
+	}
+	sa.Lock()
+	defer sa.Unlock()
+	for epName, where := range sa.wheres {
+		if whereIncludesMailbox(where, mbwsName) {
+			sa.logger.V(4).Info("Enqueuing EdgePlacement due to SyncerConfig", "epName", epName, "mbwsName", mbwsName)
+			sa.queue.Add(epName)
+		}
+	}
+}
+
+// enqueueForDestination enqueues the EdgePlacements that have the given destination,
+// whose projected objects have changed.
+func (sa *statusAggregator) enqueueForDestination(destination SinglePlacement) {
+	sa.Lock()
+	defer sa.Unlock()
+	for epName, where := range sa.wheres {
+		if whereIncludesDestination(where, destination) {
+			sa.queue.Add(epName)
+		}
+	}
+}
+
+// syncerConfigChangeMatters tells whether the given update of a SyncerConfig changes
+// what the status controllers read from it. The syncer's heartbeat, which also carries the
+// edge cluster's capacity, does not.
+func syncerConfigChangeMatters(oldObj, newObj any) bool {
+	oldSyncfg, ok1 := oldObj.(*edgeapi.SyncerConfig)
+	newSyncfg, ok2 := newObj.(*edgeapi.SyncerConfig)
+	if !ok1 || !ok2 {
+		return true
+	}
+	return !apiequality.Semantic.DeepEqual(oldSyncfg.Spec, newSyncfg.Spec) ||
+		!apiequality.Semantic.DeepEqual(oldSyncfg.Status.ObjectCounts, newSyncfg.Status.ObjectCounts) ||
+		!apiequality.Semantic.DeepEqual(oldSyncfg.Status.ObjectStatuses, newSyncfg.Status.ObjectStatuses)
+}
+
+// mailboxWorkspaceNameOf returns the name of the mailbox workspace whose
+// logical cluster is the given one, or the empty string if there is none.
+func mailboxWorkspaceNameOf(mbwsLister tenancyv1a1listers.WorkspaceLister, cluster logicalcluster.Name) (string, error) {
+	mbwsList, err := mbwsLister.List(labels.Everything())
+	if err != nil {
+		return "", err
+	}
+	for _, mbws := range mbwsList {
+		if mbws.Spec.Cluster == cluster.String() {
+			return mbws.Name, nil
+		}
+	}
+	return "", nil
+}
+
+func whereIncludesMailbox(where ResolvedWhere, mbwsName string) bool {
+	for _, sps := range where {
+		for _, destination := range sps.Destinations {
+			if SPMailboxWorkspaceName(destination) == mbwsName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (sa *statusAggregator) Run(ctx context.Context) {
+	defer sa.queue.ShutDown()
+	var wg sync.WaitGroup
+	wg.Add(sa.numThreads)
+	for i := 0; i < sa.numThreads; i++ {
+		go func() {
+			wait.Until(sa.runWorker, time.Second, ctx.Done())
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+}
+
+func (sa *statusAggregator) runWorker() {
+	for sa.processNextWorkItem() {
+	}
+}
+
+func (sa *statusAggregator) processNextWorkItem() bool {
+	itemAny, quit := sa.queue.Get()
+	if quit {
+		return false
+	}
+	defer sa.queue.Done(itemAny)
+	epName := itemAny.(ExternalName)
+
+	logger := sa.logger.WithValues("epName", epName)
+	ctx := klog.NewContext(sa.ctx, logger)
+	logger.V(4).Info("processing EdgePlacement")
+
+	if sa.process(ctx, epName) {
+		sa.queue.Forget(itemAny)
+	} else {
+		sa.queue.AddRateLimited(itemAny)
+	}
+	return true
+}
+
+// process returns true on success or unrecoverable error, false to retry
+func (sa *statusAggregator) process(ctx context.Context, epName ExternalName) bool {
+	logger := klog.FromContext(ctx)
+	ep, err := sa.epLister.Cluster(epName.Cluster).Get(epName.Name)
+	if err != nil {
+		if !k8sapierrors.IsNotFound(err) {
+			logger.Error(err, "Failed to fetch EdgePlacement from local cache")
+		}
+		return true
+	}
+	sa.Lock()
+	what := sa.whats[epName]
+	where := sa.wheres[epName]
+	sa.Unlock()
+
+	oldDestinations := map[Pair[string, string]]edgeapi.EdgePlacementDestinationStatus{}
+	for _, destStatus := range ep.Status.Destinations {
+		oldDestinations[NewPair(destStatus.Cluster, destStatus.SyncTargetName)] = destStatus
+	}
+	newStatus := ep.Status.DeepCopy()
+	newStatus.SyncedObjectCount = 0
+	newStatus.FailedObjectCount = 0
+	newStatus.SyncFailures = nil
+	newStatus.Destinations = nil
+	for _, sps := range where {
+		for _, destination := range sps.Destinations {
+			destStatus := edgeapi.EdgePlacementDestinationStatus{
+				Cluster:        destination.Cluster,
+				LocationName:   destination.LocationName,
+				SyncTargetName: destination.SyncTargetName,
+			}
+			_, syncfg := getMailbox(sa.mbwsLister, sa.syncfgClusterLister, destination)
+			if syncfg == nil {
+				newStatus.Destinations = append(newStatus.Destinations, destStatus)
+				continue
+			}
+			// The syncer counts all the outcomes, but reports only some of the failures and drift
+			var problemCount int
+			for _, count := range syncfg.Status.ObjectCounts {
+				problemCount += int(count.Failed + count.Drifted)
+				if !whatIncludesCount(what, count) {
+					continue
+				}
+				newStatus.SyncedObjectCount += count.Succeeded
+				newStatus.FailedObjectCount += count.Failed + count.Drifted
+			}
+			truncated := problemCount > len(syncfg.Status.ObjectStatuses)
+			reported := map[syncedObjectRef]bool{} // downsynced objects with a reported failure or drift
+			for _, objStatus := range syncfg.Status.ObjectStatuses {
+				if !whatIncludesObject(what, objStatus) || objStatus.Outcome == edgeapi.SyncOutcomeSucceeded {
+					continue
+				}
+				if objStatus.Direction == edgeapi.SyncDirectionDown {
+					reported[syncedObjectRefOf(objStatus)] = true
+				}
+				newStatus.SyncFailures = append(newStatus.SyncFailures, edgeapi.EdgePlacementSyncFailure{
+					Cluster:            destination.Cluster,
+					SyncTargetName:     destination.SyncTargetName,
+					SyncedObjectStatus: objStatus,
+				})
+				if destStatus.LastErrorTime == nil || destStatus.LastErrorTime.Before(&objStatus.LastSyncTime) {
+					lastErrorTime := objStatus.LastSyncTime
+					destStatus.LastError = objStatus.Message
+					destStatus.LastErrorTime = &lastErrorTime
+				}
+			}
+			projected, ok := readProjectedObjects(sa.projected, destination, syncfg, what, nil)
+			if !ok {
+				return false
+			}
+			unreported := map[syncedObjectRef]bool{} // resource and namespace (no name) of failures or drift that may be unreported
+			if truncated {
+				for _, count := range syncfg.Status.ObjectCounts {
+					if count.Direction == edgeapi.SyncDirectionDown && count.Failed+count.Drifted > 0 {
+						unreported[syncedObjectRef{group: count.APIGroup, resource: count.Resource, namespace: count.Namespace}] = true
+					}
+				}
+			}
+			// The syncer puts its finalizer on an object once it has delivered the object
+			finalizer := shared.FinalizerForSyncTarget(destination.SyncTargetName)
+			destStatus.ProjectedObjectCount = int32(len(projected))
+			for ref, obj := range projected {
+				if reported[ref] || unreported[syncedObjectRef{group: ref.group, resource: ref.resource, namespace: ref.namespace}] {
+					continue
+				}
+				if SliceContains(obj.GetFinalizers(), finalizer) {
+					destStatus.ConfirmedObjectCount++
+				}
+			}
+			oldConditions := oldDestinations[NewPair(destination.Cluster, destination.SyncTargetName)].Conditions
+			destStatus.Conditions = aggregateReadiness(oldConditions, projected)
+			newStatus.Destinations = append(newStatus.Destinations, destStatus)
+		}
+	}
+	// Report the most recent failures
+	sort.SliceStable(newStatus.SyncFailures, func(i, j int) bool {
+		return newStatus.SyncFailures[j].LastSyncTime.Before(&newStatus.SyncFailures[i].LastSyncTime)
+	})
+	if len(newStatus.SyncFailures) > MaxReportedSyncFailures {
+		newStatus.SyncFailures = newStatus.SyncFailures[:MaxReportedSyncFailures]
+	}
+	if apiequality.Semantic.DeepEqual(ep.Status, *newStatus) {
+		return true
+	}
+	// Only the fields written here are patched, so that the rollout status written by the rollout controller is kept
+	err = patchEdgePlacementStatus(ctx, sa.edgeClusterClientset, epName, map[string]interface{}{
+		"syncedObjectCount": newStatus.SyncedObjectCount,
+		"failedObjectCount": newStatus.FailedObjectCount,
+		"syncFailures":      newStatus.SyncFailures,
+		"destinations":      newStatus.Destinations,
+	})
+	if err != nil {
+		logger.Error(err, "Failed to update EdgePlacement status")
+		return k8sapierrors.IsNotFound(err)
+	}
+	logger.V(3).Info("Updated EdgePlacement status", "syncedObjectCount", newStatus.SyncedObjectCount, "failedObjectCount", newStatus.FailedObjectCount)
+	return true
+}
+
+// patchEdgePlacementStatus sets the given fields of the status of the named EdgePlacement
+// by a JSON merge patch of its status subresource; a nil value removes the field.
+// The other status fields are left as they are, so that each writer of the status
+// changes only the fields that it owns.
+func patchEdgePlacementStatus(ctx context.Context, edgeClusterClientset edgeclusterclientset.ClusterInterface, epName ExternalName, fields map[string]interface{}) error {
+	patch, err := json.Marshal(map[string]interface{}{"status": fields})
+	if err != nil {
+		return err
+	}
+	_, err = edgeClusterClientset.EdgeV1alpha1().EdgePlacements().Cluster(epName.Cluster.Path()).Patch(ctx, epName.Name, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: FieldManager}, "status")
+	return err
+}
+
+// getMailbox returns the logical cluster of the given destination's mailbox workspace
+// and the SyncerConfig there, or a nil SyncerConfig if they are not known.
+func getMailbox(mbwsLister tenancyv1a1listers.WorkspaceLister, syncfgClusterLister edgev1a1listers.SyncerConfigClusterLister, destination SinglePlacement) (logicalcluster.Name, *edgeapi.SyncerConfig) {
+	mbws, err := mbwsLister.Get(SPMailboxWorkspaceName(destination))
+	if err != nil || mbws.Spec.Cluster == "" {
+		return "", nil
+	}
+	mbCluster := logicalcluster.Name(mbws.Spec.Cluster)
+	syncfg, err := syncfgClusterLister.Cluster(mbCluster).Get(SyncerConfigName)
+	if err != nil {
+		return mbCluster, nil
+	}
+	return mbCluster, syncfg
+}
+
+// syncedObjectRef identifies an object in a mailbox workspace
+type syncedObjectRef struct {
+	group     string
+	resource  string
+	namespace string
+	name      string
+}
+
+func syncedObjectRefOf(objStatus edgeapi.SyncedObjectStatus) syncedObjectRef {
+	return syncedObjectRef{objStatus.APIGroup, objStatus.Resource, objStatus.Namespace, objStatus.Name}
+}
+
+// readProjectedObjects reads, from the given source, the projected objects at the given
+// destination that are among the given downsynced parts and the given SyncerConfig's spec.
+// If includeResource is not nil then only the resources that it accepts are read.
+// Returns false if the objects are not known yet.
+func readProjectedObjects(source projectedObjectSource, destination SinglePlacement, syncfg *edgeapi.SyncerConfig, what ResolvedWhat, includeResource func(metav1.GroupResource) bool) (map[syncedObjectRef]*unstructured.Unstructured, bool) {
+	ans := map[syncedObjectRef]*unstructured.Unstructured{}
+	for _, namespace := range syncfg.Spec.NamespaceScope.Namespaces {
+		if _, found := what.Downsync[WorkloadPartID{APIGroup: "", Resource: "namespaces", Name: namespace}]; !found {
+			continue
+		}
+		for _, nsResource := range syncfg.Spec.NamespaceScope.Resources {
+			gr := metav1.GroupResource{Group: nsResource.Group, Resource: nsResource.Resource}
+			if includeResource != nil && !includeResource(gr) {
+				continue
+			}
+			objs, ok := source.projectedObjects(destination, gr, namespace)
+			if !ok {
+				return nil, false
+			}
+			for _, obj := range objs {
+				ans[syncedObjectRef{gr.Group, gr.Resource, namespace, obj.GetName()}] = obj
+			}
+		}
+	}
+	for _, csResource := range syncfg.Spec.ClusterScope {
+		gr := metav1.GroupResource{Group: csResource.Group, Resource: csResource.Resource}
+		if includeResource != nil && !includeResource(gr) {
+			continue
+		}
+		wanted := NewEmptyMapSet[string]()
+		for _, name := range csResource.Objects {
+			if _, found := what.Downsync[WorkloadPartID{APIGroup: gr.Group, Resource: gr.Resource, Name: name}]; found {
+				wanted.Add(name)
+			}
+		}
+		if wanted.Len() == 0 {
+			continue
+		}
+		objs, ok := source.projectedObjects(destination, gr, "")
+		if !ok {
+			return nil, false
+		}
+		for _, obj := range objs {
+			if wanted.Has(obj.GetName()) {
+				ans[syncedObjectRef{gr.Group, gr.Resource, "", obj.GetName()}] = obj
+			}
+		}
+	}
+	return ans, true
+}
+
+// ReadinessConditionTypes are the types of condition, in the reported state of projected objects,
+// that are aggregated into EdgePlacementDestinationStatus.Conditions.
+var ReadinessConditionTypes = []string{"Ready", "Available"}
+
+// aggregateReadiness computes the aggregated readiness conditions of the given objects.
+// The given old conditions supply the last transition times.
+func aggregateReadiness(oldConditions []metav1.Condition, objects map[syncedObjectRef]*unstructured.Unstructured) []metav1.Condition {
+	var ans []metav1.Condition
+	for _, conditionType := range ReadinessConditionTypes {
+		var numTrue, numFalse, numUnknown int
+		var notTrue []string
+		for ref, obj := range objects {
+			conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+			for _, conditionAny := range conditions {
+				condition, ok := conditionAny.(map[string]any)
+				if !ok || condition["type"] != conditionType {
+					continue
+				}
+				switch condition["status"] {
+				case string(metav1.ConditionTrue):
+					numTrue++
+				case string(metav1.ConditionFalse):
+					numFalse++
+					notTrue = append(notTrue, ref.String())
+				default:
+					numUnknown++
+					notTrue = append(notTrue, ref.String())
+				}
+			}
+		}
+		numHaving := numTrue + numFalse + numUnknown
+		if numHaving == 0 {
+			continue
+		}
+		sort.Strings(notTrue)
+		condition := metav1.Condition{
+			Type:    conditionType,
+			Status:  metav1.ConditionTrue,
+			Reason:  "AllTrue",
+			Message: fmt.Sprintf("%d of %d objects report %s=True", numTrue, numHaving, conditionType),
+		}
+		if numFalse > 0 {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = "SomeFalse"
+		} else if numUnknown > 0 {
+			condition.Status = metav1.ConditionUnknown
+			condition.Reason = "SomeUnknown"
+		}
+		if len(notTrue) > 0 {
+			const maxListed = 5
+			if len(notTrue) > maxListed {
+				notTrue = append(notTrue[:maxListed], "...")
+			}
+			condition.Message += "; not: " + strings.Join(notTrue, ", ")
+		}
+		condition.LastTransitionTime = metav1.Now()
+		if old := apimeta.FindStatusCondition(oldConditions, conditionType); old != nil && old.Status == condition.Status {
+			condition.LastTransitionTime = old.LastTransitionTime
+		}
+		ans = append(ans, condition)
+	}
+	return ans
+}
+
+func (ref syncedObjectRef) String() string {
+	if ref.namespace == "" {
+		return ref.resource + "." + ref.group + "/" + ref.name
+	}
+	return ref.resource + "." + ref.group + "/" + ref.namespace + "/" + ref.name
+}
+
+func whatIncludesObject(what ResolvedWhat, objStatus edgeapi.SyncedObjectStatus) bool {
+	switch objStatus.Direction {
+	case edgeapi.SyncDirectionDown:
+		if objStatus.Namespace != "" {
+			_, found := what.Downsync[WorkloadPartID{APIGroup: "", Resource: "namespaces", Name: objStatus.Namespace}]
+			return found
+		}
+		_, found := what.Downsync[WorkloadPartID{APIGroup: objStatus.APIGroup, Resource: objStatus.Resource, Name: objStatus.Name}]
+		return found
+	case edgeapi.SyncDirectionUp:
+		for _, upsync := range what.Upsync {
+			if upsyncSetIncludesObject(upsync, objStatus) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// whatIncludesCount tells whether the objects counted by the given count can be
+// among those of the given ResolvedWhat. A count does not identify its objects, so
+// the names of a cluster-scoped downsynced resource, and the names and selectors
+// of an UpsyncSet, are not distinguished.
+func whatIncludesCount(what ResolvedWhat, count edgeapi.SyncedObjectCount) bool {
+	switch count.Direction {
+	case edgeapi.SyncDirectionDown:
+		if count.Namespace != "" {
+			_, found := what.Downsync[WorkloadPartID{APIGroup: "", Resource: "namespaces", Name: count.Namespace}]
+			return found
+		}
+		for partID := range what.Downsync {
+			if partID.APIGroup == count.APIGroup && partID.Resource == count.Resource {
+				return true
+			}
+		}
+	case edgeapi.SyncDirectionUp:
+		matches := func(patterns []string, value string) bool {
+			return SliceContains(patterns, "*") || SliceContains(patterns, value)
+		}
+		for _, upsync := range what.Upsync {
+			if upsync.APIGroup != count.APIGroup || !matches(upsync.Resources, count.Resource) {
+				continue
+			}
+			if count.Namespace == "" || matches(upsync.Namespaces, count.Namespace) || upsync.NamespaceSelector != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// upsyncSetIncludesObject tells whether the given upsynced object is in the given UpsyncSet.
+// The placement translator does not see the objects in the edge cluster, so the
+// selectors are not evaluated here; rather, the selectors must be among those
+// that the syncer reports the object was found selected by.
+func upsyncSetIncludesObject(upsync edgeapi.UpsyncSet, objStatus edgeapi.SyncedObjectStatus) bool {
+	matches := func(patterns []string, value string) bool {
+		return SliceContains(patterns, "*") || SliceContains(patterns, value)
+	}
+	if upsync.APIGroup != objStatus.APIGroup || !matches(upsync.Resources, objStatus.Resource) ||
+		!matches(upsyncSetNames(upsync), objStatus.Name) {
+		return false
+	}
+	inListedNamespace := objStatus.Namespace == "" || matches(upsync.Namespaces, objStatus.Namespace)
+	if !upsyncSetHasSelector(upsync) {
+		return inListedNamespace
+	}
+	selection, err := upsyncSelectionOf(upsync)
+	if err != nil {
+		return false
+	}
+	for _, reported := range objStatus.UpsyncSelections {
+		if reported.LabelSelector != selection.LabelSelector || reported.FieldSelector != selection.FieldSelector {
+			continue
+		}
+		if inListedNamespace || selection.NamespaceSelector != "" && reported.NamespaceSelector == selection.NamespaceSelector {
+			return true
+		}
+	}
+	return false
+}
+
+func upsyncSetHasSelector(upsync edgeapi.UpsyncSet) bool {
+	return upsync.LabelSelector != nil || upsync.FieldSelector != "" || upsync.NamespaceSelector != nil
+}
+
+// upsyncSetNames returns the names of the given UpsyncSet, where an empty list
+// means all names if the set has a selector.
+func upsyncSetNames(upsync edgeapi.UpsyncSet) []string {
+	if len(upsync.Names) == 0 && upsyncSetHasSelector(upsync) {
+		return []string{"*"}
+	}
+	return upsync.Names
+}
+
+// upsyncSelectionOf returns the selectors of the given UpsyncSet in the form that the syncer reports them.
+func upsyncSelectionOf(upsync edgeapi.UpsyncSet) (edgeapi.UpsyncSelection, error) {
+	selectorString := func(labelSelector *metav1.LabelSelector) (string, error) {
+		if labelSelector == nil {
+			return "", nil
+		}
+		selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+		if err != nil {
+			return "", err
+		}
+		return selector.String(), nil
+	}
+	labelSelector, err := selectorString(upsync.LabelSelector)
+	if err != nil {
+		return edgeapi.UpsyncSelection{}, err
+	}
+	namespaceSelector, err := selectorString(upsync.NamespaceSelector)
+	if err != nil {
+		return edgeapi.UpsyncSelection{}, err
+	}
+	return edgeapi.UpsyncSelection{LabelSelector: labelSelector, FieldSelector: upsync.FieldSelector, NamespaceSelector: namespaceSelector}, nil
+}
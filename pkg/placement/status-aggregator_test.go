@@ -0,0 +1,260 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placement
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	edgeapi "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+)
+
+func TestWhatIncludesObject(t *testing.T) {
+	what := ResolvedWhat{
+		Downsync: WorkloadParts{
+			{APIGroup: "", Resource: "namespaces", Name: "ns1"}:                               {APIVersion: "v1"},
+			{APIGroup: "rbac.authorization.k8s.io", Resource: "clusterroles", Name: "reader"}: {APIVersion: "v1"},
+		},
+		Upsync: []edgeapi.UpsyncSet{
+			{APIGroup: "wgpolicyk8s.io", Resources: []string{"policyreports"}, Namespaces: []string{"*"}, Names: []string{"*"}},
+		},
+	}
+	for _, testCase := range []struct {
+		objStatus edgeapi.SyncedObjectStatus
+		expected  bool
+	}{
+		{edgeapi.SyncedObjectStatus{Direction: edgeapi.SyncDirectionDown, APIGroup: "apps", Resource: "deployments", Namespace: "ns1", Name: "d1"}, true},
+		{edgeapi.SyncedObjectStatus{Direction: edgeapi.SyncDirectionDown, APIGroup: "apps", Resource: "deployments", Namespace: "ns2", Name: "d1"}, false},
+		{edgeapi.SyncedObjectStatus{Direction: edgeapi.SyncDirectionDown, Resource: "namespaces", Name: "ns1"}, true},
+		{edgeapi.SyncedObjectStatus{Direction: edgeapi.SyncDirectionDown, APIGroup: "rbac.authorization.k8s.io", Resource: "clusterroles", Name: "reader"}, true},
+		{edgeapi.SyncedObjectStatus{Direction: edgeapi.SyncDirectionDown, APIGroup: "rbac.authorization.k8s.io", Resource: "clusterroles", Name: "writer"}, false},
+		{edgeapi.SyncedObjectStatus{Direction: edgeapi.SyncDirectionUp, APIGroup: "wgpolicyk8s.io", Resource: "policyreports", Namespace: "ns3", Name: "r1"}, true},
+		{edgeapi.SyncedObjectStatus{Direction: edgeapi.SyncDirectionUp, APIGroup: "wgpolicyk8s.io", Resource: "clusterpolicyreports", Name: "r1"}, false},
+		{edgeapi.SyncedObjectStatus{Direction: edgeapi.SyncDirectionUp, APIGroup: "apps", Resource: "deployments", Namespace: "ns1", Name: "d1"}, false},
+	} {
+		if actual := whatIncludesObject(what, testCase.objStatus); actual != testCase.expected {
+			t.Errorf("whatIncludesObject(%+v) = %v, expected %v", testCase.objStatus, actual, testCase.expected)
+		}
+	}
+}
+
+func TestUpsyncSetIncludesObject(t *testing.T) {
+	reportSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"report": "true"}}
+	teamSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"team": "x"}}
+	bySelectors := edgeapi.UpsyncSet{APIGroup: "wgpolicyk8s.io", Resources: []string{"policyreports"},
+		Namespaces: []string{"ns1"}, LabelSelector: reportSelector, NamespaceSelector: teamSelector}
+	upsynced := func(namespace string, selections ...edgeapi.UpsyncSelection) edgeapi.SyncedObjectStatus {
+		return edgeapi.SyncedObjectStatus{Direction: edgeapi.SyncDirectionUp, APIGroup: "wgpolicyk8s.io", Resource: "policyreports",
+			Namespace: namespace, Name: "r1", UpsyncSelections: selections}
+	}
+	fromListed := edgeapi.UpsyncSelection{LabelSelector: "report=true"}
+	fromSelected := edgeapi.UpsyncSelection{LabelSelector: "report=true", NamespaceSelector: "team=x"}
+	other := edgeapi.UpsyncSelection{LabelSelector: "report=false"}
+	for idx, testCase := range []struct {
+		objStatus edgeapi.SyncedObjectStatus
+		expected  bool
+	}{
+		{upsynced("ns1", fromListed), true},
+		{upsynced("ns1", fromSelected), true},
+		{upsynced("ns2", fromSelected), true},
+		{upsynced("ns2", fromListed), false},
+		{upsynced("ns1", other), false},
+		{upsynced("ns1"), false},
+	} {
+		if actual := upsyncSetIncludesObject(bySelectors, testCase.objStatus); actual != testCase.expected {
+			t.Errorf("Case %d: upsyncSetIncludesObject(%+v) = %v, expected %v", idx, testCase.objStatus, actual, testCase.expected)
+		}
+	}
+	byNames := edgeapi.UpsyncSet{APIGroup: "wgpolicyk8s.io", Resources: []string{"policyreports"}, Namespaces: []string{"ns1"}, Names: []string{"r1"}}
+	if !upsyncSetIncludesObject(byNames, upsynced("ns1")) {
+		t.Error("Expected an UpsyncSet without selectors to include an object by name")
+	}
+	byNames.Names = nil
+	if upsyncSetIncludesObject(byNames, upsynced("ns1")) {
+		t.Error("Expected an UpsyncSet without selectors or names to include nothing")
+	}
+}
+
+func TestAggregateReadiness(t *testing.T) {
+	withConditions := func(conditions ...map[string]any) *unstructured.Unstructured {
+		conditionsAny := []any{}
+		for _, condition := range conditions {
+			conditionsAny = append(conditionsAny, condition)
+		}
+		return &unstructured.Unstructured{Object: map[string]any{"status": map[string]any{"conditions": conditionsAny}}}
+	}
+	objects := map[syncedObjectRef]*unstructured.Unstructured{
+		{"apps", "deployments", "ns1", "d1"}: withConditions(map[string]any{"type": "Available", "status": "True"}),
+		{"apps", "deployments", "ns1", "d2"}: withConditions(map[string]any{"type": "Available", "status": "False"}),
+		{"", "pods", "ns1", "p1"}:            withConditions(map[string]any{"type": "Ready", "status": "True"}),
+		{"", "configmaps", "ns1", "cm1"}:     withConditions(),
+	}
+	past := metav1.NewTime(time.Now().Add(-time.Hour))
+	oldConditions := []metav1.Condition{
+		{Type: "Ready", Status: metav1.ConditionTrue, LastTransitionTime: past},
+		{Type: "Available", Status: metav1.ConditionTrue, LastTransitionTime: past},
+	}
+	conditions := aggregateReadiness(oldConditions, objects)
+	if len(conditions) != 2 {
+		t.Fatalf("Expected 2 conditions, got %#v", conditions)
+	}
+	ready := apimeta.FindStatusCondition(conditions, "Ready")
+	if ready == nil || ready.Status != metav1.ConditionTrue || !ready.LastTransitionTime.Equal(&past) {
+		t.Errorf("Expected Ready=True since the past, got %#v", ready)
+	}
+	available := apimeta.FindStatusCondition(conditions, "Available")
+	if available == nil || available.Status != metav1.ConditionFalse || available.LastTransitionTime.Equal(&past) {
+		t.Errorf("Expected Available=False since now, got %#v", available)
+	}
+	if available != nil && !strings.Contains(available.Message, "deployments.apps/ns1/d2") {
+		t.Errorf("Expected Available message to name the unavailable object, got %q", available.Message)
+	}
+}
+
+// fakeProjectedObjects is a projectedObjectSource that holds the objects at one destination.
+type fakeProjectedObjects struct {
+	destination SinglePlacement
+	objects     map[metav1.GroupResource][]*unstructured.Unstructured
+	unsynced    bool
+	handlers    []func(SinglePlacement)
+}
+
+func (fpo *fakeProjectedObjects) projectedObjects(destination SinglePlacement, gr metav1.GroupResource, namespace string) ([]*unstructured.Unstructured, bool) {
+	if fpo.unsynced {
+		return nil, false
+	}
+	if destination != fpo.destination {
+		return nil, true
+	}
+	ans := []*unstructured.Unstructured{}
+	for _, obj := range fpo.objects[gr] {
+		if namespace == "" || obj.GetNamespace() == namespace {
+			ans = append(ans, obj)
+		}
+	}
+	return ans, true
+}
+
+func (fpo *fakeProjectedObjects) addProjectedObjectHandler(handler func(SinglePlacement)) {
+	fpo.handlers = append(fpo.handlers, handler)
+}
+
+func projectedObject(namespace, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]any{}}
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
+
+func TestReadProjectedObjects(t *testing.T) {
+	destination := SinglePlacement{Cluster: "inv", SyncTargetName: "st1"}
+	deployments := metav1.GroupResource{Group: "apps", Resource: "deployments"}
+	clusterRoles := metav1.GroupResource{Group: "rbac.authorization.k8s.io", Resource: "clusterroles"}
+	source := &fakeProjectedObjects{destination: destination, objects: map[metav1.GroupResource][]*unstructured.Unstructured{
+		deployments:  {projectedObject("ns1", "d1"), projectedObject("ns2", "d2")},
+		clusterRoles: {projectedObject("", "reader"), projectedObject("", "writer")},
+	}}
+	syncfg := &edgeapi.SyncerConfig{Spec: edgeapi.SyncerConfigSpec{
+		NamespaceScope: edgeapi.NamespaceScopeDownsyncs{
+			Namespaces: []string{"ns1", "ns2"},
+			Resources:  []edgeapi.NamespaceScopeDownsyncResource{{GroupResource: deployments, APIVersion: "v1"}},
+		},
+		ClusterScope: []edgeapi.ClusterScopeDownsyncResource{{GroupResource: clusterRoles, APIVersion: "v1", Objects: []string{"reader", "writer"}}},
+	}}
+	what := ResolvedWhat{Downsync: WorkloadParts{
+		{APIGroup: "", Resource: "namespaces", Name: "ns1"}:                               {APIVersion: "v1"},
+		{APIGroup: "rbac.authorization.k8s.io", Resource: "clusterroles", Name: "reader"}: {APIVersion: "v1"},
+	}}
+	projected, ok := readProjectedObjects(source, destination, syncfg, what, nil)
+	if !ok {
+		t.Fatal("Expected the objects to be known")
+	}
+	expected := []syncedObjectRef{{"apps", "deployments", "ns1", "d1"}, {"rbac.authorization.k8s.io", "clusterroles", "", "reader"}}
+	if len(projected) != len(expected) {
+		t.Errorf("Expected %v, got %v", expected, projected)
+	}
+	for _, ref := range expected {
+		if projected[ref] == nil {
+			t.Errorf("Expected %v among %v", ref, projected)
+		}
+	}
+	projected, _ = readProjectedObjects(source, destination, syncfg, what, func(gr metav1.GroupResource) bool { return gr == deployments })
+	if len(projected) != 1 {
+		t.Errorf("Expected only the deployment, got %v", projected)
+	}
+	source.unsynced = true
+	if _, ok := readProjectedObjects(source, destination, syncfg, what, nil); ok {
+		t.Error("Expected the objects to be unknown before the informers sync")
+	}
+}
+
+func TestSyncerConfigChangeMatters(t *testing.T) {
+	syncfg := &edgeapi.SyncerConfig{Status: edgeapi.SyncerConfigStatus{
+		ObjectStatuses: []edgeapi.SyncedObjectStatus{{Direction: edgeapi.SyncDirectionDown, Resource: "configmaps", Namespace: "ns1", Name: "cm1", Outcome: edgeapi.SyncOutcomeSucceeded}},
+	}}
+	heartbeat := syncfg.DeepCopy()
+	now := metav1.Now()
+	heartbeat.Status.LastSyncerHeartbeatTime = &now
+	if syncerConfigChangeMatters(syncfg, heartbeat) {
+		t.Error("Expected a heartbeat not to matter")
+	}
+	failed := heartbeat.DeepCopy()
+	failed.Status.ObjectStatuses[0].Outcome = edgeapi.SyncOutcomeFailed
+	if !syncerConfigChangeMatters(heartbeat, failed) {
+		t.Error("Expected a changed outcome to matter")
+	}
+	recounted := heartbeat.DeepCopy()
+	recounted.Status.ObjectCounts = []edgeapi.SyncedObjectCount{{Direction: edgeapi.SyncDirectionDown, Resource: "configmaps", Namespace: "ns1", Succeeded: 1}}
+	if !syncerConfigChangeMatters(heartbeat, recounted) {
+		t.Error("Expected changed counts to matter")
+	}
+	respecced := heartbeat.DeepCopy()
+	respecced.Spec.NamespaceScope.Namespaces = []string{"ns1"}
+	if !syncerConfigChangeMatters(heartbeat, respecced) {
+		t.Error("Expected a changed spec to matter")
+	}
+}
+
+func TestWhatIncludesCount(t *testing.T) {
+	what := ResolvedWhat{
+		Downsync: WorkloadParts{
+			{APIGroup: "", Resource: "namespaces", Name: "ns1"}:                               {APIVersion: "v1"},
+			{APIGroup: "rbac.authorization.k8s.io", Resource: "clusterroles", Name: "reader"}: {APIVersion: "v1"},
+		},
+		Upsync: []edgeapi.UpsyncSet{{APIGroup: "apps", Resources: []string{"*"}, Namespaces: []string{"ns2"}, Names: []string{"d1"}}},
+	}
+	for _, tc := range []struct {
+		count    edgeapi.SyncedObjectCount
+		expected bool
+	}{
+		{edgeapi.SyncedObjectCount{Direction: edgeapi.SyncDirectionDown, Resource: "configmaps", Namespace: "ns1"}, true},
+		{edgeapi.SyncedObjectCount{Direction: edgeapi.SyncDirectionDown, Resource: "configmaps", Namespace: "ns2"}, false},
+		{edgeapi.SyncedObjectCount{Direction: edgeapi.SyncDirectionDown, APIGroup: "rbac.authorization.k8s.io", Resource: "clusterroles"}, true},
+		{edgeapi.SyncedObjectCount{Direction: edgeapi.SyncDirectionDown, APIGroup: "rbac.authorization.k8s.io", Resource: "clusterrolebindings"}, false},
+		{edgeapi.SyncedObjectCount{Direction: edgeapi.SyncDirectionUp, APIGroup: "apps", Resource: "deployments", Namespace: "ns2"}, true},
+		{edgeapi.SyncedObjectCount{Direction: edgeapi.SyncDirectionUp, APIGroup: "apps", Resource: "deployments", Namespace: "ns1"}, false},
+	} {
+		if actual := whatIncludesCount(what, tc.count); actual != tc.expected {
+			t.Errorf("Expected %v for %+v, got %v", tc.expected, tc.count, actual)
+		}
+	}
+}
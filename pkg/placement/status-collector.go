@@ -0,0 +1,454 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placement
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	k8scache "k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	kcpcache "github.com/kcp-dev/apimachinery/v2/pkg/cache"
+	tenancyv1a1listers "github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	edgeapi "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+	edgeclusterclientset "github.com/kubestellar/kubestellar/pkg/client/clientset/versioned/cluster"
+	edgev1a1listers "github.com/kubestellar/kubestellar/pkg/client/listers/edge/v1alpha1"
+	"github.com/kubestellar/kubestellar/pkg/jsonpath"
+)
+
+// statusCollectorController maintains the status of each StatusCollector.
+// It is kept appraised of the "what" and "where" resolutions, so that it
+// can find the copies, in the mailbox workspaces, of the objects downsynced
+// by the EdgePlacements in the StatusCollector's workspace.
+// The copies are read from the workload projector's informers. The syncers
+// return the reported state into those copies, so a StatusCollector is
+// re-evaluated whenever a copy at a relevant destination changes, and
+// whenever a relevant SyncerConfig changes other than by a heartbeat.
+type statusCollectorController struct {
+	ctx        context.Context
+	logger     klog.Logger
+	numThreads int
+	queue      workqueue.RateLimitingInterface
+
+	stcClusterLister     edgev1a1listers.StatusCollectorClusterLister
+	syncfgClusterLister  edgev1a1listers.SyncerConfigClusterLister
+	mbwsLister           tenancyv1a1listers.WorkspaceLister
+	edgeClusterClientset edgeclusterclientset.ClusterInterface
+	projected            projectedObjectSource
+
+	sync.Mutex
+	whats  map[ExternalName]ResolvedWhat
+	wheres map[ExternalName]ResolvedWhere
+}
+
+var _ Runnable = &statusCollectorController{}
+
+func newStatusCollectorController(
+	ctx context.Context,
+	numThreads int,
+	stcClusterInformer kcpcache.ScopeableSharedIndexInformer,
+	stcClusterLister edgev1a1listers.StatusCollectorClusterLister,
+	syncfgClusterInformer kcpcache.ScopeableSharedIndexInformer,
+	syncfgClusterLister edgev1a1listers.SyncerConfigClusterLister,
+	mbwsLister tenancyv1a1listers.WorkspaceLister,
+	edgeClusterClientset edgeclusterclientset.ClusterInterface,
+	projected projectedObjectSource,
+) *statusCollectorController {
+	controllerName := "status-collector"
+	logger := klog.FromContext(ctx).WithValues("part", controllerName)
+	scc := &statusCollectorController{
+		ctx:                  klog.NewContext(ctx, logger),
+		logger:               logger,
+		numThreads:           numThreads,
+		queue:                workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName),
+		stcClusterLister:     stcClusterLister,
+		syncfgClusterLister:  syncfgClusterLister,
+		mbwsLister:           mbwsLister,
+		edgeClusterClientset: edgeClusterClientset,
+		projected:            projected,
+		whats:                map[ExternalName]ResolvedWhat{},
+		wheres:               map[ExternalName]ResolvedWhere{},
+	}
+	stcClusterInformer.AddEventHandler(k8scache.ResourceEventHandlerFuncs{
+		AddFunc:    scc.enqueueStatusCollector,
+		UpdateFunc: func(oldObj, newObj any) { scc.enqueueStatusCollector(newObj) },
+	})
+	syncfgClusterInformer.AddEventHandler(k8scache.ResourceEventHandlerFuncs{
+		AddFunc: scc.enqueueForSyncerConfig,
+		UpdateFunc: func(oldObj, newObj any) {
+			if syncerConfigChangeMatters(oldObj, newObj) {
+				scc.enqueueForSyncerConfig(newObj)
+			}
+		},
+		DeleteFunc: scc.enqueueForSyncerConfig,
+	})
+	projected.addProjectedObjectHandler(scc.enqueueForDestination)
+	return scc
+}
+
+// WhatReceiver returns the receiver of the "what" resolutions.
+func (scc *statusCollectorController) WhatReceiver() MappingReceiver[ExternalName, ResolvedWhat] {
+	return NewMappingReceiverFuncs(
+		func(epName ExternalName, what ResolvedWhat) {
+			scc.Lock()
+			defer scc.Unlock()
+			scc.whats[epName] = what
+			scc.enqueueCluster(epName.Cluster)
+		},
+		func(epName ExternalName) {
+			scc.Lock()
+			defer scc.Unlock()
+			delete(scc.whats, epName)
+			scc.enqueueCluster(epName.Cluster)
+		})
+}
+
+// WhereReceiver returns the receiver of the "where" resolutions.
+func (scc *statusCollectorController) WhereReceiver() MappingReceiver[ExternalName, ResolvedWhere] {
+	return NewMappingReceiverFuncs(
+		func(epName ExternalName, where ResolvedWhere) {
+			scc.Lock()
+			defer scc.Unlock()
+			scc.wheres[epName] = where
+			scc.enqueueCluster(epName.Cluster)
+		},
+		func(epName ExternalName) {
+			scc.Lock()
+			defer scc.Unlock()
+			delete(scc.wheres, epName)
+			scc.enqueueCluster(epName.Cluster)
+		})
+}
+
+func (scc *statusCollectorController) enqueueStatusCollector(obj any) {
+	stc := obj.(*edgeapi.StatusCollector)
+	scc.queue.Add(ExternalName{Cluster: logicalcluster.From(stc), Name: stc.Name})
+}
+
+// enqueueCluster enqueues all the StatusCollectors in the given workload management workspace.
+func (scc *statusCollectorController) enqueueCluster(cluster logicalcluster.Name) {
+	stcs, err := scc.stcClusterLister.Cluster(cluster).List(labels.Everything())
+	if err != nil {
+		scc.logger.Error(err, "Failed to list StatusCollectors", "cluster", cluster)
+		return
+	}
+	for _, stc := range stcs {
+		scc.queue.Add(ExternalName{Cluster: cluster, Name: stc.Name})
+	}
+}
+
+func (scc *statusCollectorController) enqueueForSyncerConfig(obj any) {
+	if dfu, ok := obj.(k8scache.DeletedFinalStateUnknown); ok {
+		obj = dfu.Obj
+	}
+	syncfg := obj.(*edgeapi.SyncerConfig)
+	if syncfg.Name != SyncerConfigName {
+		return
+	}
+	mbwsName, err := mailboxWorkspaceNameOf(scc.mbwsLister, logicalcluster.From(syncfg))
+	if err != nil {
+		scc.logger.Error(err, "Failed to list mailbox workspaces")
+		return
+	}
+	if mbwsName == "" {
+		return
+	}
+	scc.Lock()
+	defer scc.Unlock()
+	clusters := map[logicalcluster.Name]bool{}
+	for epName, where := range scc.wheres {
+		if !clusters[epName.Cluster] && whereIncludesMailbox(where, mbwsName) {
+			clusters[epName.Cluster] = true
+			scc.enqueueCluster(epName.Cluster)
+		}
+	}
+}
+
+// enqueueForDestination enqueues the StatusCollectors in the workload management workspaces
+// of the EdgePlacements that have the given destination, whose projected objects have changed.
+func (scc *statusCollectorController) enqueueForDestination(destination SinglePlacement) {
+	scc.Lock()
+	defer scc.Unlock()
+	clusters := map[logicalcluster.Name]bool{}
+	for epName, where := range scc.wheres {
+		if !clusters[epName.Cluster] && whereIncludesDestination(where, destination) {
+			clusters[epName.Cluster] = true
+			scc.enqueueCluster(epName.Cluster)
+		}
+	}
+}
+
+func (scc *statusCollectorController) Run(ctx context.Context) {
+	defer scc.queue.ShutDown()
+	var wg sync.WaitGroup
+	wg.Add(scc.numThreads)
+	for i := 0; i < scc.numThreads; i++ {
+		go func() {
+			wait.Until(scc.runWorker, time.Second, ctx.Done())
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+}
+
+func (scc *statusCollectorController) runWorker() {
+	for scc.processNextWorkItem() {
+	}
+}
+
+func (scc *statusCollectorController) processNextWorkItem() bool {
+	itemAny, quit := scc.queue.Get()
+	if quit {
+		return false
+	}
+	defer scc.queue.Done(itemAny)
+	stcName := itemAny.(ExternalName)
+
+	logger := scc.logger.WithValues("stcName", stcName)
+	ctx := klog.NewContext(scc.ctx, logger)
+	logger.V(4).Info("processing StatusCollector")
+
+	if scc.process(ctx, stcName) {
+		scc.queue.Forget(itemAny)
+	} else {
+		scc.queue.AddRateLimited(itemAny)
+	}
+	return true
+}
+
+// collectedObject is a copy, in a mailbox workspace, of a downsynced object.
+type collectedObject struct {
+	cluster        string
+	syncTargetName string
+	ref            syncedObjectRef
+	obj            *unstructured.Unstructured
+}
+
+// parsedStatusCollectorField is a StatusCollectorField with its JSONPath parsed
+type parsedStatusCollectorField struct {
+	edgeapi.StatusCollectorField
+	path jsonpath.Parsed
+}
+
+// process returns true on success or unrecoverable error, false to retry
+func (scc *statusCollectorController) process(ctx context.Context, stcName ExternalName) bool {
+	logger := klog.FromContext(ctx)
+	stc, err := scc.stcClusterLister.Cluster(stcName.Cluster).Get(stcName.Name)
+	if err != nil {
+		if !k8sapierrors.IsNotFound(err) {
+			logger.Error(err, "Failed to fetch StatusCollector from local cache")
+		}
+		return true
+	}
+	newStatus := edgeapi.StatusCollectorStatus{ObservedGeneration: stc.Generation}
+	fields := []parsedStatusCollectorField{}
+	for _, field := range stc.Spec.Fields {
+		path, err := jsonpath.ParseString(field.JSONPath)
+		if err != nil {
+			newStatus.Errors = append(newStatus.Errors, fmt.Sprintf("field %q: %s", field.Name, err.Error()))
+			continue
+		}
+		fields = append(fields, parsedStatusCollectorField{field, path})
+	}
+	objects, ok := scc.collectObjects(stcName.Cluster, stc.Spec)
+	if !ok {
+		return false
+	}
+	summarizeCollectedObjects(&newStatus, fields, objects)
+	if apiequality.Semantic.DeepEqual(stc.Status, newStatus) {
+		return true
+	}
+	stcCopy := stc.DeepCopy()
+	stcCopy.Status = newStatus
+	_, err = scc.edgeClusterClientset.EdgeV1alpha1().StatusCollectors().Cluster(stcName.Cluster.Path()).UpdateStatus(ctx, stcCopy, metav1.UpdateOptions{FieldManager: FieldManager})
+	if err != nil {
+		logger.Error(err, "Failed to update StatusCollector status")
+		return k8sapierrors.IsNotFound(err)
+	}
+	logger.V(3).Info("Updated StatusCollector status", "objectCount", newStatus.ObjectCount, "destinationCount", newStatus.DestinationCount)
+	return true
+}
+
+// collectObjects reads the copies selected by the given spec, from the mailbox workspaces
+// of the destinations of the EdgePlacements in the given workload management workspace.
+// Returns false if the copies are not known yet.
+func (scc *statusCollectorController) collectObjects(wmwCluster logicalcluster.Name, spec edgeapi.StatusCollectorSpec) ([]collectedObject, bool) {
+	type resolution struct {
+		what  ResolvedWhat
+		where ResolvedWhere
+	}
+	scc.Lock()
+	resolutions := []resolution{}
+	for epName, where := range scc.wheres {
+		if epName.Cluster == wmwCluster {
+			resolutions = append(resolutions, resolution{scc.whats[epName], where})
+		}
+	}
+	scc.Unlock()
+
+	includeResource := func(gr metav1.GroupResource) bool {
+		return gr.Group == spec.APIGroup && gr.Resource == spec.Resource
+	}
+	matches := func(patterns []string, value string) bool {
+		return len(patterns) == 0 || SliceContains(patterns, "*") || SliceContains(patterns, value)
+	}
+	seen := map[Pair[SinglePlacement, syncedObjectRef]]bool{}
+	ans := []collectedObject{}
+	for _, resolution := range resolutions {
+		for _, sps := range resolution.where {
+			for _, destination := range sps.Destinations {
+				_, syncfg := getMailbox(scc.mbwsLister, scc.syncfgClusterLister, destination)
+				if syncfg == nil {
+					continue
+				}
+				projected, ok := readProjectedObjects(scc.projected, destination, syncfg, resolution.what, includeResource)
+				if !ok {
+					return nil, false
+				}
+				for ref, obj := range projected {
+					if !(ref.namespace == "" || matches(spec.Namespaces, ref.namespace)) || !matches(spec.Names, ref.name) {
+						continue
+					}
+					key := NewPair(destination, ref)
+					if seen[key] {
+						continue
+					}
+					seen[key] = true
+					ans = append(ans, collectedObject{destination.Cluster, destination.SyncTargetName, ref, obj})
+				}
+			}
+		}
+	}
+	return ans, true
+}
+
+// summarizeCollectedObjects fills in the given status with the values,
+// of the given fields, collected from the given objects.
+func summarizeCollectedObjects(status *edgeapi.StatusCollectorStatus, fields []parsedStatusCollectorField, objects []collectedObject) {
+	sort.Slice(objects, func(i, j int) bool {
+		a, b := objects[i], objects[j]
+		if a.cluster != b.cluster {
+			return a.cluster < b.cluster
+		}
+		if a.syncTargetName != b.syncTargetName {
+			return a.syncTargetName < b.syncTargetName
+		}
+		if a.ref.namespace != b.ref.namespace {
+			return a.ref.namespace < b.ref.namespace
+		}
+		return a.ref.name < b.ref.name
+	})
+	destinations := map[Pair[string, string]]bool{}
+	fieldValues := make([][]jsonpath.JSONValue, len(fields))
+	for _, object := range objects {
+		destinations[NewPair(object.cluster, object.syncTargetName)] = true
+		objStatus := edgeapi.CollectedObjectStatus{
+			Cluster:        object.cluster,
+			SyncTargetName: object.syncTargetName,
+			Namespace:      object.ref.namespace,
+			Name:           object.ref.name,
+		}
+		for idx, field := range fields {
+			values := jsonpath.Query(object.obj.Object, field.path)
+			if len(values) == 0 {
+				continue
+			}
+			fieldValues[idx] = append(fieldValues[idx], values...)
+			var toEncode any = values
+			if len(values) == 1 {
+				toEncode = values[0]
+			}
+			encoded, err := json.Marshal(toEncode)
+			if err != nil {
+				continue
+			}
+			objStatus.Values = append(objStatus.Values, edgeapi.CollectedValue{Name: field.Name, Value: string(encoded)})
+		}
+		if len(status.Objects) < edgeapi.MaxCollectedObjects {
+			status.Objects = append(status.Objects, objStatus)
+		}
+	}
+	status.ObjectCount = int32(len(objects))
+	status.DestinationCount = int32(len(destinations))
+	for idx, field := range fields {
+		status.Fields = append(status.Fields, reduceCollectedValues(field.StatusCollectorField, fieldValues[idx]))
+	}
+}
+
+// reduceCollectedValues applies the given field's reductions to the numeric members of the given values.
+func reduceCollectedValues(field edgeapi.StatusCollectorField, values []jsonpath.JSONValue) edgeapi.CollectedFieldSummary {
+	summary := edgeapi.CollectedFieldSummary{Name: field.Name, Count: int32(len(values))}
+	var min, max, sum float64
+	for _, value := range values {
+		number, ok := numberOf(value)
+		if !ok {
+			continue
+		}
+		if summary.NumericCount == 0 || number < min {
+			min = number
+		}
+		if summary.NumericCount == 0 || number > max {
+			max = number
+		}
+		sum += number
+		summary.NumericCount++
+	}
+	if summary.NumericCount == 0 {
+		return summary
+	}
+	format := func(number float64) string { return strconv.FormatFloat(number, 'f', -1, 64) }
+	for _, reduction := range field.Reductions {
+		switch reduction {
+		case edgeapi.StatusReductionMin:
+			summary.Min = format(min)
+		case edgeapi.StatusReductionMax:
+			summary.Max = format(max)
+		case edgeapi.StatusReductionSum:
+			summary.Sum = format(sum)
+		}
+	}
+	return summary
+}
+
+func numberOf(value jsonpath.JSONValue) (float64, bool) {
+	switch typed := value.(type) {
+	case int64:
+		return float64(typed), true
+	case int32:
+		return float64(typed), true
+	case int:
+		return float64(typed), true
+	case float64:
+		return typed, true
+	}
+	return 0, false
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placement
+
+import (
+	"context"
+	"testing"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	edgeapi "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+	edgefakeclient "github.com/kubestellar/kubestellar/pkg/client/clientset/versioned/cluster/fake"
+	edgeinformers "github.com/kubestellar/kubestellar/pkg/client/informers/externalversions"
+	"github.com/kubestellar/kubestellar/pkg/jsonpath"
+)
+
+func TestSummarizeCollectedObjects(t *testing.T) {
+	deployment := func(readyReplicas any) *unstructured.Unstructured {
+		status := map[string]any{}
+		if readyReplicas != nil {
+			status["readyReplicas"] = readyReplicas
+		}
+		return &unstructured.Unstructured{Object: map[string]any{"status": status}}
+	}
+	field := edgeapi.StatusCollectorField{
+		Name:       "ready",
+		JSONPath:   "$.status.readyReplicas",
+		Reductions: []edgeapi.StatusReduction{edgeapi.StatusReductionMin, edgeapi.StatusReductionMax, edgeapi.StatusReductionSum},
+	}
+	path, err := jsonpath.ParseString(field.JSONPath)
+	if err != nil {
+		t.Fatalf("Failed to parse %q: %v", field.JSONPath, err)
+	}
+	objects := []collectedObject{
+		{"c1", "st2", syncedObjectRef{"apps", "deployments", "ns1", "d1"}, deployment(int64(1))},
+		{"c1", "st1", syncedObjectRef{"apps", "deployments", "ns1", "d1"}, deployment(int64(3))},
+		{"c1", "st1", syncedObjectRef{"apps", "deployments", "ns1", "d2"}, deployment(nil)},
+		{"c2", "st1", syncedObjectRef{"apps", "deployments", "ns1", "d1"}, deployment(2.5)},
+		{"c2", "st1", syncedObjectRef{"apps", "deployments", "ns1", "d3"}, deployment("many")},
+	}
+	status := edgeapi.StatusCollectorStatus{}
+	summarizeCollectedObjects(&status, []parsedStatusCollectorField{{field, path}}, objects)
+	expected := edgeapi.StatusCollectorStatus{
+		DestinationCount: 3,
+		ObjectCount:      5,
+		Objects: []edgeapi.CollectedObjectStatus{
+			{Cluster: "c1", SyncTargetName: "st1", Namespace: "ns1", Name: "d1", Values: []edgeapi.CollectedValue{{Name: "ready", Value: "3"}}},
+			{Cluster: "c1", SyncTargetName: "st1", Namespace: "ns1", Name: "d2"},
+			{Cluster: "c1", SyncTargetName: "st2", Namespace: "ns1", Name: "d1", Values: []edgeapi.CollectedValue{{Name: "ready", Value: "1"}}},
+			{Cluster: "c2", SyncTargetName: "st1", Namespace: "ns1", Name: "d1", Values: []edgeapi.CollectedValue{{Name: "ready", Value: "2.5"}}},
+			{Cluster: "c2", SyncTargetName: "st1", Namespace: "ns1", Name: "d3", Values: []edgeapi.CollectedValue{{Name: "ready", Value: `"many"`}}},
+		},
+		Fields: []edgeapi.CollectedFieldSummary{
+			{Name: "ready", Count: 4, NumericCount: 3, Min: "1", Max: "3", Sum: "6.5"},
+		},
+	}
+	if !apiequality.Semantic.DeepEqual(status, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, status)
+	}
+}
+
+func TestReduceCollectedValuesOnlyRequested(t *testing.T) {
+	field := edgeapi.StatusCollectorField{Name: "x", Reductions: []edgeapi.StatusReduction{edgeapi.StatusReductionSum}}
+	summary := reduceCollectedValues(field, []jsonpath.JSONValue{int64(2), "two", int64(5)})
+	expected := edgeapi.CollectedFieldSummary{Name: "x", Count: 3, NumericCount: 2, Sum: "7"}
+	if summary != expected {
+		t.Errorf("Expected %+v, got %+v", expected, summary)
+	}
+}
+
+func TestStatusCollectorReactsToProjectedObjects(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dest1 := SinglePlacement{Cluster: "inv", LocationName: "loc", SyncTargetName: "st1"}
+	dest2 := SinglePlacement{Cluster: "inv", LocationName: "loc", SyncTargetName: "st2"}
+	informerFactory := edgeinformers.NewSharedInformerFactory(edgefakeclient.NewSimpleClientset(), 0)
+	stcPreInformer := informerFactory.Edge().V1alpha1().StatusCollectors()
+	syncfgPreInformer := informerFactory.Edge().V1alpha1().SyncerConfigs()
+	for _, cluster := range []string{"wmw1", "wmw2"} {
+		stc := &edgeapi.StatusCollector{ObjectMeta: metav1.ObjectMeta{Name: "replicas",
+			Annotations: map[string]string{logicalcluster.AnnotationKey: cluster}}}
+		if err := stcPreInformer.Informer().GetIndexer().Add(stc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	source := &fakeProjectedObjects{}
+	scc := newStatusCollectorController(ctx, 1, stcPreInformer.Informer(), stcPreInformer.Lister(),
+		syncfgPreInformer.Informer(), syncfgPreInformer.Lister(), nil, nil, source)
+	defer scc.queue.ShutDown()
+	whereReceiver := scc.WhereReceiver()
+	whereReceiver.Put(ExternalName{Cluster: "wmw1", Name: "ep1"}, ResolvedWhere{{Destinations: []SinglePlacement{dest1}}})
+	whereReceiver.Put(ExternalName{Cluster: "wmw2", Name: "ep2"}, ResolvedWhere{{Destinations: []SinglePlacement{dest2}}})
+	for scc.queue.Len() > 0 {
+		item, _ := scc.queue.Get()
+		scc.queue.Done(item)
+	}
+
+	if len(source.handlers) != 1 {
+		t.Fatalf("Expected the controller to watch the projected objects, got %d handlers", len(source.handlers))
+	}
+	source.handlers[0](dest2)
+	if scc.queue.Len() != 1 {
+		t.Fatalf("Expected one StatusCollector to be enqueued, got %d", scc.queue.Len())
+	}
+	item, _ := scc.queue.Get()
+	if expected := (ExternalName{Cluster: "wmw2", Name: "replicas"}); item != expected {
+		t.Errorf("Expected %v to be enqueued, got %v", expected, item)
+	}
+}
@@ -232,12 +232,16 @@ func (wr *whatResolver) Get(placement ExternalName, kont func(WorkloadParts)) {
 func (wr *whatResolver) getPartsLocked(wldCluster logicalcluster.Name, epName string) ResolvedWhat {
 	parts := WorkloadParts{}
 	var upsyncs []edgeapi.UpsyncSet
+	var ignoreDifferences []edgeapi.IgnoreDifferences
+	var resourcePolicies []ResourcePolicy
 	wsDetails, found := wr.workspaceDetails[wldCluster]
 	if !found {
-		return ResolvedWhat{parts, upsyncs}
+		return ResolvedWhat{parts, upsyncs, ignoreDifferences, resourcePolicies}
 	}
 	if ep, found := wsDetails.placements[epName]; found {
 		upsyncs = ep.Spec.Upsync
+		ignoreDifferences = ep.Spec.IgnoreDifferences
+		resourcePolicies = resourcePoliciesOf(ep.Spec)
 	}
 	for _, rr := range wsDetails.resources {
 		for objName, objDetails := range rr.byObjName {
@@ -251,7 +255,22 @@ func (wr *whatResolver) getPartsLocked(wldCluster logicalcluster.Name, epName st
 			parts[partID] = partDetails
 		}
 	}
-	return ResolvedWhat{parts, upsyncs}
+	return ResolvedWhat{parts, upsyncs, ignoreDifferences, resourcePolicies}
+}
+
+// resourcePoliciesOf returns the per-resource policies of the given EdgePlacement spec.
+func resourcePoliciesOf(spec edgeapi.EdgePlacementSpec) []ResourcePolicy {
+	ans := []ResourcePolicy{}
+	for idx := range spec.DriftPolicies {
+		ans = append(ans, ResourcePolicy{Drift: &spec.DriftPolicies[idx]})
+	}
+	for idx := range spec.DeletionPolicies {
+		ans = append(ans, ResourcePolicy{Deletion: &spec.DeletionPolicies[idx]})
+	}
+	for idx := range spec.Subresources {
+		ans = append(ans, ResourcePolicy{Subresources: &spec.Subresources[idx]})
+	}
+	return ans
 }
 
 func (wr *whatResolver) notifyReceiversOfPlacements(cluster logicalcluster.Name, placements k8ssets.String) {
@@ -529,6 +548,10 @@ func (wr *whatResolver) processEdgePlacement(ctx context.Context, cluster logica
 			apiequality.Semantic.DeepEqual(prevEp.Spec.NonNamespacedObjects, ep.Spec.NonNamespacedObjects))
 		if whatPredicateUnChanged {
 			logger.V(4).Info(`No change in "what" predicate`)
+			if !apiequality.Semantic.DeepEqual(prevEp.Spec.IgnoreDifferences, ep.Spec.IgnoreDifferences) ||
+				!apiequality.Semantic.DeepEqual(resourcePoliciesOf(prevEp.Spec), resourcePoliciesOf(ep.Spec)) {
+				wr.notifyReceivers(cluster, epName)
+			}
 			return true
 		}
 	}
@@ -677,7 +700,7 @@ var GRsForciblyDenatured = NewMapSet(
 	mkgr("flowcontrol.apiserver.k8s.io", "flowschemas"),
 	mkgr("flowcontrol.apiserver.k8s.io", "prioritylevelconfigurations"),
 	mkgr("rbac.authorization.k8s.io", "clusterroles"),
-	mkgr("rbac.authorization.k8s.io", "clusterrolebindingss"),
+	mkgr("rbac.authorization.k8s.io", "clusterrolebindings"),
 	mkgr("rbac.authorization.k8s.io", "roles"),
 	mkgr("rbac.authorization.k8s.io", "rolebindings"),
 	mkgr("", "limitranges"),
@@ -685,6 +708,32 @@ var GRsForciblyDenatured = NewMapSet(
 	mkgr("", "serviceaccounts"),
 )
 
+// DenaturedGroupSuffix is what is appended to the API group of a resource that is
+// ForciblyDenatured to get the API group under which its objects are stored, denatured,
+// in the center. The syncer renatures them on their way to the edge.
+const DenaturedGroupSuffix = "denatured.edge.kubestellar.io"
+
+// DenaturedGroupResource returns the resource under which the objects of the given
+// resource are stored denatured in the center.
+func DenaturedGroupResource(gr schema.GroupResource) schema.GroupResource {
+	if gr.Group == "" {
+		return schema.GroupResource{Group: DenaturedGroupSuffix, Resource: gr.Resource}
+	}
+	return schema.GroupResource{Group: gr.Group + "." + DenaturedGroupSuffix, Resource: gr.Resource}
+}
+
+// NaturedGroupResource is the inverse of DenaturedGroupResource.
+// The returned bool is false if the given resource is not a denatured one.
+func NaturedGroupResource(gr schema.GroupResource) (schema.GroupResource, bool) {
+	if gr.Group == DenaturedGroupSuffix {
+		return schema.GroupResource{Resource: gr.Resource}, true
+	}
+	if group := strings.TrimSuffix(gr.Group, "."+DenaturedGroupSuffix); group != gr.Group {
+		return schema.GroupResource{Group: group, Resource: gr.Resource}, true
+	}
+	return gr, false
+}
+
 var GRsNaturedInBoth = NewMapSet(
 	mkgr("apiextensions.k8s.io", "customresourcedefinitions"),
 	mkgr("", "namespaces"),
@@ -731,6 +780,9 @@ var GRsNotForEdge = NewMapSet(
 
 func DefaultResourceModes(mgr metav1.GroupResource) ResourceMode {
 	sgr := MetaGroupResourceToSchema(mgr)
+	if natured, isDenatured := NaturedGroupResource(sgr); isDenatured && GRsForciblyDenatured.Has(natured) {
+		return ResourceMode{GoesToEdge, ForciblyDenatured, true}
+	}
 	builtin := strings.HasSuffix(sgr.Group, ".k8s.io") || !strings.Contains(sgr.Group, ".")
 	switch {
 	case GRsForciblyDenatured.Has(sgr):
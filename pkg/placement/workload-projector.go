@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -122,6 +123,9 @@ func NewWorkloadProjector(
 
 		upsyncs: NewHashRelation2[SinglePlacement, edgeapi.UpsyncSet](
 			HashSinglePlacement{}, HashUpsyncSet{}),
+		ignoreDifferences: NewMapRelation2[SinglePlacement, edgeapi.IgnoreDifferences](),
+		resourcePolicies: NewHashRelation2[SinglePlacement, ResourcePolicy](
+			HashSinglePlacement{}, HashResourcePolicy{}),
 	}
 	wp.nsDistributionsForProj = NewGenericIndexedSet[NamespaceDistributionTuple, logicalcluster.Name, Pair[NamespaceName, SinglePlacement],
 		wpPerSourceNSDistributions, wpPerSourceNSDistributions](
@@ -351,6 +355,13 @@ type workloadProjector struct {
 	nsClusterPreInformer      kcpkubecorev1informers.NamespaceClusterInformer
 	nsClusterClient           kcpkubecorev1client.NamespaceClusterInterface
 
+	// rolloutGate, if not nil, may hold back changes to objects in mailbox workspaces
+	rolloutGate rolloutGate
+
+	// projectedObjectHandlers are called with the destination whenever a projected object
+	// there changes. They are added before the projector runs.
+	projectedObjectHandlers []func(SinglePlacement)
+
 	mbwsNameToCluster MutableMap[string /*mailbox workspace name*/, logicalcluster.Name]
 	clusterToMBWSName MutableMap[logicalcluster.Name, string /*mailbox workspace name*/]
 	mbwsNameToSP      MutableMap[string /*mailbox workspace name*/, SinglePlacement]
@@ -394,6 +405,10 @@ type workloadProjector struct {
 	nnsModesForSync FactoredMap[ProjectionModeKey, SinglePlacement, metav1.GroupResource, ProjectionModeVal]
 
 	upsyncs SingleIndexedRelation2[SinglePlacement, edgeapi.UpsyncSet]
+
+	ignoreDifferences SingleIndexedRelation2[SinglePlacement, edgeapi.IgnoreDifferences]
+
+	resourcePolicies SingleIndexedRelation2[SinglePlacement, ResourcePolicy]
 }
 
 type GroupResourceInstance = Pair[metav1.GroupResource, string /*object name*/]
@@ -951,6 +966,10 @@ func (wp *workloadProjector) syncSourceToDestLocked(ctx context.Context, logger
 			rscClient = duo.client.Namespace(soRef.namespace)
 		}
 		if deleted { // propagate deletion
+			if wp.rolloutGate != nil && !wp.rolloutGate.AdmitChange(soRef, destination) {
+				logger.V(3).Info("Holding back deletion of object in mailbox workspace until its rollout wave")
+				return false
+			}
 			time.Sleep(wp.delay)
 			err := rscClient.Delete(ctx, soRef.name, metav1.DeleteOptions{})
 			if err == nil {
@@ -1000,6 +1019,10 @@ func (wp *workloadProjector) syncSourceToDestLocked(ctx context.Context, logger
 				logger.V(4).Info("No need to update object in mailbox workspace")
 				return false
 			}
+			if wp.rolloutGate != nil && !wp.rolloutGate.AdmitChange(soRef, destination) {
+				logger.V(3).Info("Holding back update of object in mailbox workspace until its rollout wave")
+				return false
+			}
 			time.Sleep(wp.delay)
 			asUpdated, err := rscClient.Update(ctx, revisedDestObj, metav1.UpdateOptions{FieldManager: FieldManager})
 			if err != nil {
@@ -1014,6 +1037,10 @@ func (wp *workloadProjector) syncSourceToDestLocked(ctx context.Context, logger
 				"newResourceVersion", asUpdated.GetResourceVersion())
 			return false
 		}
+		if wp.rolloutGate != nil && !wp.rolloutGate.AdmitChange(soRef, destination) {
+			logger.V(3).Info("Holding back creation of object in mailbox workspace until its rollout wave")
+			return false
+		}
 		destObj = wpd.wp.xformForDestination(soRef.cluster, destination, srcMRObject)
 		time.Sleep(time.Second)
 		asCreated, err := rscClient.Create(ctx, destObj, metav1.CreateOptions{FieldManager: FieldManager})
@@ -1179,7 +1206,14 @@ func (wp *workloadProjector) Transact(xn func(WorkloadProjectionSections)) {
 			recordPart(recordLogger, "nns.src", changedDestinations, factorNonNamespacedDistributionTupleForSync1),
 			recordPart(recordLogger, "nns.dest", &changedSources, factorNonNamespacedDistributionTupleForProj1)),
 		NewMappingReceiverFork[ProjectionModeKey, ProjectionModeVal](wp.nnsModesForSync, wp.nnsModesForProj),
-		wp.upsyncs})
+		wp.upsyncs,
+		SetWriterFork[Pair[SinglePlacement, edgeapi.IgnoreDifferences]](false,
+			wp.ignoreDifferences,
+			recordPart(recordLogger, "ignore.dest", changedDestinations, PairFactorer[SinglePlacement, edgeapi.IgnoreDifferences]())),
+		SetWriterFork[Pair[SinglePlacement, ResourcePolicy]](false,
+			wp.resourcePolicies,
+			recordPart(recordLogger, "policy.dest", changedDestinations, PairFactorer[SinglePlacement, ResourcePolicy]())),
+	})
 	logger.V(3).Info("Transaction response",
 		"changedDestinations", VisitableToSlice[SinglePlacement](*changedDestinations),
 		"changedSources", VisitableToSlice[logicalcluster.Name](changedSources))
@@ -1382,6 +1416,64 @@ func (wpd *wpPerDestination) enqueueDestinationObject(gr metav1.GroupResource, n
 	ref := destinationObjectRef{wpd.destination, gr, namespace, objm.GetName()}
 	wpd.logger.V(4).Info("Enqueuing reference to destination object", "ref", ref)
 	wpd.wp.queue.Add(ref)
+	for _, handler := range wpd.wp.projectedObjectHandlers {
+		handler(wpd.destination)
+	}
+}
+
+// projectedObjectSource gives the projected objects in the mailbox workspaces,
+// from the informers that the workload projector maintains there.
+type projectedObjectSource interface {
+	// projectedObjects returns the projected objects of the given resource at the given
+	// destination; for a namespaced resource, only those in the given namespace.
+	// The second result is false if the objects are not known yet.
+	// The returned objects must not be modified.
+	projectedObjects(destination SinglePlacement, gr metav1.GroupResource, namespace string) ([]*unstructured.Unstructured, bool)
+
+	// addProjectedObjectHandler adds a function to call, with the destination,
+	// whenever a projected object there changes.
+	addProjectedObjectHandler(handler func(SinglePlacement))
+}
+
+var _ projectedObjectSource = &workloadProjector{}
+
+func (wp *workloadProjector) projectedObjects(destination SinglePlacement, gr metav1.GroupResource, namespace string) ([]*unstructured.Unstructured, bool) {
+	wp.Lock()
+	var duo dynamicDuo
+	wpd, have := wp.perDestination.Get(destination)
+	if have {
+		duo, have = wpd.preInformers.Get(gr)
+	}
+	wp.Unlock()
+	if !have || duo.preInformer == nil {
+		// Nothing of this resource has been projected to this destination
+		return nil, true
+	}
+	if !duo.preInformer.Informer().HasSynced() {
+		return nil, false
+	}
+	var objs []machruntime.Object
+	var err error
+	if duo.namespaced && namespace != "" {
+		objs, err = duo.preInformer.Lister().ByNamespace(namespace).List(labels.Everything())
+	} else {
+		objs, err = duo.preInformer.Lister().List(labels.Everything())
+	}
+	if err != nil {
+		klog.FromContext(wp.ctx).Error(err, "Failed to list projected objects from informer", "destination", destination, "groupResource", gr)
+		return nil, false
+	}
+	ans := make([]*unstructured.Unstructured, 0, len(objs))
+	for _, obj := range objs {
+		if obju, ok := obj.(*unstructured.Unstructured); ok {
+			ans = append(ans, obju)
+		}
+	}
+	return ans, true
+}
+
+func (wp *workloadProjector) addProjectedObjectHandler(handler func(SinglePlacement)) {
+	wp.projectedObjectHandlers = append(wp.projectedObjectHandlers, handler)
 }
 
 func ObjectIsSystem(objm metav1.Object) bool {
@@ -1488,6 +1580,10 @@ type syncerConfigSpecRelations struct {
 	namespacedResources  Set[edgeapi.NamespaceScopeDownsyncResource]
 	clusterScopedObjects MutableMap[metav1.GroupResource, Pair[ProjectionModeVal, MutableSet[string /*object name*/]]]
 	upsyncs              Set[edgeapi.UpsyncSet]
+	conversions          Set[edgeapi.ResourceConversion]
+	ignoreDifferences    Set[edgeapi.IgnoreDifferences]
+	resourcePolicies     Set[ResourcePolicy]
+	locationName         string
 }
 
 func (wp *workloadProjector) syncerConfigRelations(destination SinglePlacement) syncerConfigSpecRelations {
@@ -1495,8 +1591,11 @@ func (wp *workloadProjector) syncerConfigRelations(destination SinglePlacement)
 	wp.Lock()
 	defer wp.Unlock()
 	nsds, have := wp.nsDistributionsForSync.GetIndex1to2().Get(destination)
+	conversions := NewEmptyMapSet[edgeapi.ResourceConversion]()
 	ans := syncerConfigSpecRelations{
 		clusterScopedObjects: NewMapMap[metav1.GroupResource, Pair[ProjectionModeVal, MutableSet[string /*object name*/]]](nil),
+		conversions:          conversions,
+		locationName:         destination.LocationName,
 	}
 	if have {
 		nses := MapKeySet(nsds.GetIndex1to2())
@@ -1512,6 +1611,12 @@ func (wp *workloadProjector) syncerConfigRelations(destination SinglePlacement)
 			nsms = NewMapMap[metav1.GroupResource, ProjectionModeVal](nil)
 		}
 		nsrs := MapKeySet(nsrds.GetIndex1to2())
+		nsrs.Visit(func(gr metav1.GroupResource) error {
+			if conversion, needed := wp.conversionFor(gr); needed {
+				conversions.Add(conversion)
+			}
+			return nil
+		})
 		ans.namespacedResources = MapSetCopy(TransformVisitable[metav1.GroupResource, edgeapi.NamespaceScopeDownsyncResource](nsrs, func(gr metav1.GroupResource) edgeapi.NamespaceScopeDownsyncResource {
 			pmv, ok := nsms.Get(gr)
 			if !ok {
@@ -1541,6 +1646,9 @@ func (wp *workloadProjector) syncerConfigRelations(destination SinglePlacement)
 			if !ok {
 				logger.Error(nil, "Missing API version", "obj", gri)
 			}
+			if conversion, needed := wp.conversionFor(gr); needed {
+				conversions.Add(conversion)
+			}
 			cso := MapGetAdd(ans.clusterScopedObjects, gr,
 				true, func(metav1.GroupResource) Pair[ProjectionModeVal, MutableSet[string /*object name*/]] {
 					return NewPair[ProjectionModeVal, MutableSet[string]](pmv, NewEmptyMapSet[string /*object name*/]())
@@ -1554,6 +1662,16 @@ func (wp *workloadProjector) syncerConfigRelations(destination SinglePlacement)
 		upsyncs = NewHashSet[edgeapi.UpsyncSet](HashUpsyncSet{})
 	}
 	ans.upsyncs = HashSetCopy[edgeapi.UpsyncSet](HashUpsyncSet{})(upsyncs)
+	ignoreDifferences, haveIgnores := wp.ignoreDifferences.GetIndex1to2().Get(destination)
+	if !haveIgnores {
+		ignoreDifferences = NewEmptyMapSet[edgeapi.IgnoreDifferences]()
+	}
+	ans.ignoreDifferences = MapSetCopy[edgeapi.IgnoreDifferences](ignoreDifferences)
+	resourcePolicies, havePolicies := wp.resourcePolicies.GetIndex1to2().Get(destination)
+	if !havePolicies {
+		resourcePolicies = NewHashSet[ResourcePolicy](HashResourcePolicy{})
+	}
+	ans.resourcePolicies = HashSetCopy[ResourcePolicy](HashResourcePolicy{})(resourcePolicies)
 	return ans
 }
 
@@ -1571,17 +1689,56 @@ func (wp *workloadProjector) syncerConfigSpecFromRelations(specRelations syncerC
 					Objects:       VisitableToSlice[string](val.Second),
 				}
 			}),
-		Upsync: VisitableToSlice[edgeapi.UpsyncSet](specRelations.upsyncs),
+		Upsync:            VisitableToSlice[edgeapi.UpsyncSet](specRelations.upsyncs),
+		Conversions:       VisitableToSlice[edgeapi.ResourceConversion](specRelations.conversions),
+		IgnoreDifferences: VisitableToSlice[edgeapi.IgnoreDifferences](specRelations.ignoreDifferences),
+		LocationName:      specRelations.locationName,
+	}
+	// The syncer follows the first entry for a resource, so do not let the order depend on the set's
+	resourcePolicies := VisitableToSlice[ResourcePolicy](specRelations.resourcePolicies)
+	sort.Slice(resourcePolicies, func(i, j int) bool {
+		return resourcePolicyKey(resourcePolicies[i]) < resourcePolicyKey(resourcePolicies[j])
+	})
+	for _, policy := range resourcePolicies {
+		switch {
+		case policy.Drift != nil:
+			ans.DriftPolicies = append(ans.DriftPolicies, *policy.Drift)
+		case policy.Deletion != nil:
+			ans.DeletionPolicies = append(ans.DeletionPolicies, *policy.Deletion)
+		case policy.Subresources != nil:
+			ans.Subresources = append(ans.Subresources, *policy.Subresources)
+		}
 	}
 	return ans
 }
 
+// conversionFor returns the conversion, if any, that the syncer has to apply
+// to the objects of the given resource.
+// Those are the objects of a ForciblyDenatured resource stored denatured in the center.
+func (wp *workloadProjector) conversionFor(gr metav1.GroupResource) (edgeapi.ResourceConversion, bool) {
+	if wp.resourceModes(gr).NatureMode != ForciblyDenatured {
+		return edgeapi.ResourceConversion{}, false
+	}
+	natured, isDenatured := NaturedGroupResource(MetaGroupResourceToSchema(gr))
+	if !isDenatured {
+		return edgeapi.ResourceConversion{}, false
+	}
+	return edgeapi.ResourceConversion{
+		Upstream:   gr,
+		Downstream: metav1.GroupResource{Group: natured.Group, Resource: natured.Resource},
+	}, true
+}
+
 func (wp *workloadProjector) syncerConfigIsGood(destination SinglePlacement, configRef ExternalName, syncfg *edgeapi.SyncerConfig, goodSpecRelations syncerConfigSpecRelations) bool {
 	spec := syncfg.Spec
 	haveNamespaces := NewMapSet(spec.NamespaceScope.Namespaces...)
 	logger := klog.FromContext(wp.ctx)
 	logger = logger.WithValues("destination", destination, "syncerConfig", configRef, "resourceVersion", syncfg.ResourceVersion)
 	good := true
+	if spec.LocationName != goodSpecRelations.locationName {
+		logger.V(4).Info("SyncerConfig has wrong locationName", "good", goodSpecRelations.locationName, "have", spec.LocationName)
+		good = false
+	}
 	SetEnumerateDifferences[string](goodSpecRelations.namespaces, haveNamespaces, SetWriterFuncs[string]{
 		OnAdd: func(namespace string) bool {
 			logger.V(4).Info("SyncerConfig has excess namespace", "namespace", namespace)
@@ -1626,6 +1783,49 @@ func (wp *workloadProjector) syncerConfigIsGood(destination SinglePlacement, con
 			good = false
 		},
 	})
+	haveConversions := NewMapSet(spec.Conversions...)
+	SetEnumerateDifferences[edgeapi.ResourceConversion](goodSpecRelations.conversions, haveConversions, SetWriterFuncs[edgeapi.ResourceConversion]{
+		OnAdd: func(conversion edgeapi.ResourceConversion) bool {
+			logger.V(4).Info("SyncerConfig has excess ResourceConversion", "conversion", conversion)
+			good = false
+			return false
+		},
+		OnRemove: func(conversion edgeapi.ResourceConversion) bool {
+			logger.V(4).Info("SyncerConfig lacks ResourceConversion", "conversion", conversion)
+			good = false
+			return false
+		},
+	})
+	haveIgnores := NewMapSet(spec.IgnoreDifferences...)
+	SetEnumerateDifferences[edgeapi.IgnoreDifferences](goodSpecRelations.ignoreDifferences, haveIgnores, SetWriterFuncs[edgeapi.IgnoreDifferences]{
+		OnAdd: func(ignore edgeapi.IgnoreDifferences) bool {
+			logger.V(4).Info("SyncerConfig has excess IgnoreDifferences", "ignoreDifferences", ignore)
+			good = false
+			return false
+		},
+		OnRemove: func(ignore edgeapi.IgnoreDifferences) bool {
+			logger.V(4).Info("SyncerConfig lacks IgnoreDifferences", "ignoreDifferences", ignore)
+			good = false
+			return false
+		},
+	})
+	havePolicies := NewHashSet[ResourcePolicy](HashResourcePolicy{}, resourcePoliciesOf(edgeapi.EdgePlacementSpec{
+		DriftPolicies:    spec.DriftPolicies,
+		DeletionPolicies: spec.DeletionPolicies,
+		Subresources:     spec.Subresources,
+	})...)
+	SetEnumerateDifferences[ResourcePolicy](goodSpecRelations.resourcePolicies, havePolicies, SetWriterFuncs[ResourcePolicy]{
+		OnAdd: func(policy ResourcePolicy) bool {
+			logger.V(4).Info("SyncerConfig has excess ResourcePolicy", "policy", policy)
+			good = false
+			return false
+		},
+		OnRemove: func(policy ResourcePolicy) bool {
+			logger.V(4).Info("SyncerConfig lacks ResourcePolicy", "policy", policy)
+			good = false
+			return false
+		},
+	})
 	haveUpsyncs := NewHashSet[edgeapi.UpsyncSet](HashUpsyncSet{}, spec.Upsync...)
 	SetEnumerateDifferences[edgeapi.UpsyncSet](goodSpecRelations.upsyncs, haveUpsyncs, SetWriterFuncs[edgeapi.UpsyncSet]{
 		OnAdd: func(upsync edgeapi.UpsyncSet) bool {
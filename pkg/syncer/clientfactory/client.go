@@ -18,18 +18,90 @@ package clientfactory
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
 
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
 
 	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+	"github.com/kubestellar/kubestellar/pkg/syncer/localstore"
+	"github.com/kubestellar/kubestellar/pkg/syncer/shared"
 )
 
+// FieldManagerPrefix is the prefix of the field manager that a syncer uses for server-side apply.
+// The rest of the field manager is the name of the syncer's SyncTarget.
+const FieldManagerPrefix = "kubestellar-syncer-"
+
+// FieldManagerForSyncTarget returns the field manager that the syncer for the named SyncTarget uses for server-side apply.
+func FieldManagerForSyncTarget(syncTargetName string) string {
+	return FieldManagerPrefix + syncTargetName
+}
+
 type Client struct {
 	ResourceClient dynamic.NamespaceableResourceInterface
+	resource       schema.GroupVersionResource
 	scope          meta.RESTScope
+	fieldManager   string
+
+	// store, if not nil, is kept up to date with what is read and is used while the API server is unreachable
+	store *localstore.Store
+	// caches holds the informers whose caches, once synced, are read instead of the API server
+	caches *readCaches
+}
+
+// GroupVersionResource returns the resource that the client reads and writes.
+func (c *Client) GroupVersionResource() schema.GroupVersionResource {
+	return c.resource
+}
+
+// FieldManager returns the field manager that the client uses for server-side apply.
+func (c *Client) FieldManager() string {
+	return c.fieldManager
+}
+
+// ApplyConflictError reports that a server-side apply was rejected because
+// some of the applied fields are owned by another field manager.
+// Retrying the same apply will not help; the conflict has to be resolved by a person or by a change upstream.
+type ApplyConflictError struct {
+	// Object identifies the object that was being applied
+	Object string
+	Err    error
+}
+
+func (e *ApplyConflictError) Error() string {
+	return fmt.Sprintf("conflict applying %s: %v", e.Object, e.Err)
+}
+
+func (e *ApplyConflictError) Unwrap() error {
+	return e.Err
+}
+
+// IsApplyConflict tells whether the given error is an ApplyConflictError,
+// or an aggregate of only ApplyConflictErrors.
+func IsApplyConflict(err error) bool {
+	if agg, ok := err.(utilerrors.Aggregate); ok {
+		errs := agg.Errors()
+		for _, err := range errs {
+			if !IsApplyConflict(err) {
+				return false
+			}
+		}
+		return len(errs) > 0
+	}
+	var conflictErr *ApplyConflictError
+	return errors.As(err, &conflictErr)
 }
 
 func (c *Client) IsNamespaced() bool {
@@ -47,28 +119,234 @@ func (c *Client) Create(resource edgev1alpha1.EdgeSyncConfigResource, unstObj *u
 	return createdObj, err
 }
 
+// Get reads the object, from the cache of the factory's informer on the resource if that has synced.
+// Otherwise, while the API server is unreachable, the local store (if any) is used instead;
+// if the object is not there then the error is a NotStoredError.
 func (c *Client) Get(resource edgev1alpha1.EdgeSyncConfigResource) (*unstructured.Unstructured, error) {
 	var unstObj *unstructured.Unstructured
 	var err error
+	if indexer := c.caches.indexerFor(c.resource); indexer != nil {
+		unstObj, err = c.getCached(indexer, resource)
+		c.remember(c.resource, c.namespaceOf(resource), resource.Name, unstObj, err)
+		return unstObj, err
+	}
 	if c.IsNamespaced() {
 		unstObj, err = c.ResourceClient.Namespace(resource.Namespace).Get(context.Background(), resource.Name, v1.GetOptions{})
 	} else {
 		unstObj, err = c.ResourceClient.Get(context.Background(), resource.Name, v1.GetOptions{})
 	}
+	if c.store != nil && IsUnreachable(err) {
+		if stored, found := c.store.GetObject(c.resource, c.namespaceOf(resource), resource.Name); found {
+			return stored, nil
+		}
+		return nil, &NotStoredError{Resource: c.resource, Namespace: c.namespaceOf(resource), Name: resource.Name, Err: err}
+	}
+	c.remember(c.resource, c.namespaceOf(resource), resource.Name, unstObj, err)
 	return unstObj, err
 }
 
+// ListPageSize is the most objects that one request made by List or EachListItem asks for.
+var ListPageSize int64 = 500
+
+// maxListRestarts is how many times a paged list is started over when the snapshot being paged through expires.
+const maxListRestarts = 3
+
+// List lists the objects that the given resource selects, in pages of at most ListPageSize objects.
+// If the snapshot being paged through expires, the paged list is started over.
+// If the factory's informer on the resource has synced then its cache is listed instead.
+// While the API server is unreachable, the objects in the local store (if any) are listed instead.
 func (c *Client) List(resource edgev1alpha1.EdgeSyncConfigResource) (*unstructured.UnstructuredList, error) {
-	var unstListObj *unstructured.UnstructuredList
-	var err error
-	if c.IsNamespaced() {
-		unstListObj, err = c.ResourceClient.Namespace(resource.Namespace).List(context.Background(), v1.ListOptions{})
-	} else {
-		unstListObj, err = c.ResourceClient.List(context.Background(), v1.ListOptions{})
+	if indexer := c.caches.indexerFor(c.resource); indexer != nil {
+		unstListObj, err := c.listCached(indexer, resource)
+		if err == nil {
+			c.rememberList(resource, unstListObj)
+		}
+		return unstListObj, err
+	}
+	unstListObj := &unstructured.UnstructuredList{}
+	restart := func() { unstListObj = &unstructured.UnstructuredList{} }
+	err := c.eachListPage(resource, restart, func(page *unstructured.UnstructuredList) error {
+		if unstListObj.Object == nil {
+			unstListObj.Object = page.Object
+		}
+		unstListObj.Items = append(unstListObj.Items, page.Items...)
+		return nil
+	})
+	if err == nil {
+		unstListObj.SetContinue("")
+	}
+	if c.store != nil {
+		if IsUnreachable(err) {
+			return c.listStored(resource)
+		}
+	}
+	if err == nil {
+		c.rememberList(resource, unstListObj)
 	}
 	return unstListObj, err
 }
 
+// EachListItem calls fn on each object that the given resource selects, listing them in pages of
+// at most ListPageSize objects so that no more than one page is held at a time.
+// It stops at the first error from fn or from the API server. If the snapshot being paged through
+// expires, the paged list is started over and fn is not called again on the objects it has seen.
+// If the factory's informer on the resource has synced then its cache is used instead,
+// copying one object at a time.
+// While the API server is unreachable, the objects in the local store (if any) are used instead.
+func (c *Client) EachListItem(resource edgev1alpha1.EdgeSyncConfigResource, fn func(*unstructured.Unstructured) error) error {
+	if indexer := c.caches.indexerFor(c.resource); indexer != nil {
+		return c.eachCached(indexer, resource, fn)
+	}
+	started := false
+	seen := map[string]bool{}
+	err := c.eachListPage(resource, func() {}, func(page *unstructured.UnstructuredList) error {
+		started = true
+		for idx := range page.Items {
+			item := &page.Items[idx]
+			key := item.GetNamespace() + "/" + item.GetName()
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			c.remember(c.resource, item.GetNamespace(), item.GetName(), item, nil)
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		c.rememberEach(resource, func(namespace, name string) bool { return seen[namespace+"/"+name] })
+	}
+	if c.store != nil && !started && IsUnreachable(err) {
+		stored, err := c.listStored(resource)
+		if err != nil {
+			return err
+		}
+		for idx := range stored.Items {
+			if err := fn(&stored.Items[idx]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return err
+}
+
+// eachListPage lists the objects that the given resource selects, in pages of at most ListPageSize objects,
+// following continue tokens, and calls fn on each page. If the snapshot being paged through expires,
+// restart is called and the paged list is started over, at most maxListRestarts times.
+func (c *Client) eachListPage(resource edgev1alpha1.EdgeSyncConfigResource, restart func(), fn func(*unstructured.UnstructuredList) error) error {
+	listOptions := v1.ListOptions{LabelSelector: resource.LabelSelector, Limit: ListPageSize}
+	restarts := 0
+	for {
+		var page *unstructured.UnstructuredList
+		var err error
+		if c.IsNamespaced() {
+			page, err = c.ResourceClient.Namespace(resource.Namespace).List(context.Background(), listOptions)
+		} else {
+			page, err = c.ResourceClient.List(context.Background(), listOptions)
+		}
+		if k8serrors.IsResourceExpired(err) && listOptions.Continue != "" && restarts < maxListRestarts {
+			restarts++
+			listOptions.Continue = ""
+			restart()
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(page); err != nil {
+			return err
+		}
+		if page.GetContinue() == "" {
+			return nil
+		}
+		listOptions.Continue = page.GetContinue()
+	}
+}
+
+// getCached reads the object from the given informer cache.
+func (c *Client) getCached(indexer cache.Indexer, resource edgev1alpha1.EdgeSyncConfigResource) (*unstructured.Unstructured, error) {
+	key := resource.Name
+	if c.IsNamespaced() {
+		key = resource.Namespace + "/" + resource.Name
+	}
+	item, exists, err := indexer.GetByKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, k8serrors.NewNotFound(c.resource.GroupResource(), resource.Name)
+	}
+	return item.(*unstructured.Unstructured).DeepCopy(), nil
+}
+
+// listCached lists the objects that the given resource selects from the given informer cache,
+// sorted by namespace and name like the API server lists them.
+func (c *Client) listCached(indexer cache.Indexer, resource edgev1alpha1.EdgeSyncConfigResource) (*unstructured.UnstructuredList, error) {
+	ans := &unstructured.UnstructuredList{}
+	err := c.eachCached(indexer, resource, func(obj *unstructured.Unstructured) error {
+		ans.Items = append(ans.Items, *obj)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(ans.Items, func(i, j int) bool {
+		if ans.Items[i].GetNamespace() != ans.Items[j].GetNamespace() {
+			return ans.Items[i].GetNamespace() < ans.Items[j].GetNamespace()
+		}
+		return ans.Items[i].GetName() < ans.Items[j].GetName()
+	})
+	return ans, nil
+}
+
+// eachCached calls fn on a copy of each object that the given resource selects in the given informer cache,
+// stopping at the first error from fn.
+func (c *Client) eachCached(indexer cache.Indexer, resource edgev1alpha1.EdgeSyncConfigResource, fn func(*unstructured.Unstructured) error) error {
+	selector, err := labels.Parse(resource.LabelSelector)
+	if err != nil {
+		return err
+	}
+	namespace := c.namespaceOf(resource)
+	if namespace == "*" {
+		namespace = v1.NamespaceAll
+	}
+	var fnErr error
+	err = cache.ListAllByNamespace(indexer, namespace, selector, func(item interface{}) {
+		if fnErr == nil {
+			fnErr = fn(item.(*unstructured.Unstructured).DeepCopy())
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return fnErr
+}
+
+// listStored lists the stored objects that the given resource selects.
+func (c *Client) listStored(resource edgev1alpha1.EdgeSyncConfigResource) (*unstructured.UnstructuredList, error) {
+	selector, err := labels.Parse(resource.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+	ans := &unstructured.UnstructuredList{}
+	for _, obj := range c.store.ListObjects(c.resource, c.namespaceOf(resource)) {
+		if selector.Matches(labels.Set(obj.GetLabels())) {
+			ans.Items = append(ans.Items, obj)
+		}
+	}
+	return ans, nil
+}
+
+func (c *Client) namespaceOf(resource edgev1alpha1.EdgeSyncConfigResource) string {
+	if c.IsNamespaced() {
+		return resource.Namespace
+	}
+	return ""
+}
+
 func (c *Client) Update(resource edgev1alpha1.EdgeSyncConfigResource, unstObj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
 	var updatedObj *unstructured.Unstructured
 	var err error
@@ -81,22 +359,181 @@ func (c *Client) Update(resource edgev1alpha1.EdgeSyncConfigResource, unstObj *u
 	return updatedObj, err
 }
 
+// Apply does a server-side apply of the given object, using the syncer's field manager and without forcing.
+// Server-maintained metadata, and the status, are not applied;
+// the applied object holds only the fields that the syncer wants to own.
+// A rejection due to fields owned by another manager is returned as an *ApplyConflictError.
+// While the API server is unreachable, the apply is deferred if there is a local store.
+func (c *Client) Apply(resource edgev1alpha1.EdgeSyncConfigResource, unstObj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	appliedObj, err := c.onlineApply(context.Background(), resource, unstObj)
+	if c.store != nil && IsUnreachable(err) {
+		return nil, c.deferWrite(localstore.WriteOpApply, c.namespaceOf(resource), unstObj.GetName(), unstObj)
+	}
+	if err == nil {
+		c.wroteOnline(localstore.WriteOpApply, c.namespaceOf(resource), unstObj.GetName(), appliedObj)
+	}
+	return appliedObj, err
+}
+
+// ApplySubresource does a server-side apply of the given object through the named subresource,
+// in the same way as Apply. Unlike Apply, it is never deferred.
+func (c *Client) ApplySubresource(resource edgev1alpha1.EdgeSyncConfigResource, unstObj *unstructured.Unstructured, subresource string) (*unstructured.Unstructured, error) {
+	return c.onlineApply(context.Background(), resource, unstObj, subresource)
+}
+
+// finalizerFieldManagerSuffix is appended to the syncer's field manager to make the one with which
+// it applies its finalizer, so that the syncer's other applies to the same object do not drop it.
+const finalizerFieldManagerSuffix = "-finalizer"
+
+// AddFinalizer adds the given finalizer to the given object by a server-side apply of just that
+// finalizer, with a field manager of its own; the rest of the object is not written.
+// Unlike Apply, it is never deferred.
+func (c *Client) AddFinalizer(resource edgev1alpha1.EdgeSyncConfigResource, unstObj *unstructured.Unstructured, finalizer string) (*unstructured.Unstructured, error) {
+	if c.fieldManager == "" {
+		return nil, errors.New("apply requires a field manager but none is set")
+	}
+	applyObj := &unstructured.Unstructured{}
+	applyObj.SetAPIVersion(unstObj.GetAPIVersion())
+	applyObj.SetKind(unstObj.GetKind())
+	applyObj.SetNamespace(unstObj.GetNamespace())
+	applyObj.SetName(unstObj.GetName())
+	applyObj.SetFinalizers([]string{finalizer})
+	data, err := applyObj.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	force := false
+	options := v1.PatchOptions{FieldManager: c.fieldManager + finalizerFieldManagerSuffix, Force: &force}
+	if c.IsNamespaced() {
+		return c.ResourceClient.Namespace(resource.Namespace).Patch(context.Background(), applyObj.GetName(), types.ApplyPatchType, data, options)
+	}
+	return c.ResourceClient.Patch(context.Background(), applyObj.GetName(), types.ApplyPatchType, data, options)
+}
+
+// RemoveFinalizer removes the given finalizer from the given object, if it is there, by a JSON patch
+// that fails if the object's finalizers have changed meanwhile; the rest of the object is not written.
+// Unlike Delete, it is never deferred.
+func (c *Client) RemoveFinalizer(resource edgev1alpha1.EdgeSyncConfigResource, unstObj *unstructured.Unstructured, finalizer string) error {
+	patch, found := shared.RemoveFinalizerPatch(unstObj.GetFinalizers(), finalizer)
+	if !found {
+		return nil
+	}
+	var err error
+	if c.IsNamespaced() {
+		_, err = c.ResourceClient.Namespace(resource.Namespace).Patch(context.Background(), unstObj.GetName(), types.JSONPatchType, patch, v1.PatchOptions{})
+	} else {
+		_, err = c.ResourceClient.Patch(context.Background(), unstObj.GetName(), types.JSONPatchType, patch, v1.PatchOptions{})
+	}
+	return err
+}
+
+// GetScale reads the scale subresource of the named object.
+func (c *Client) GetScale(resource edgev1alpha1.EdgeSyncConfigResource) (*autoscalingv1.Scale, error) {
+	var unstScale *unstructured.Unstructured
+	var err error
+	if c.IsNamespaced() {
+		unstScale, err = c.ResourceClient.Namespace(resource.Namespace).Get(context.Background(), resource.Name, v1.GetOptions{}, "scale")
+	} else {
+		unstScale, err = c.ResourceClient.Get(context.Background(), resource.Name, v1.GetOptions{}, "scale")
+	}
+	if err != nil {
+		return nil, err
+	}
+	scale := &autoscalingv1.Scale{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstScale.Object, scale); err != nil {
+		return nil, fmt.Errorf("failed to parse the scale of %s %s: %w", c.resource.Resource, resource.Name, err)
+	}
+	return scale, nil
+}
+
+func (c *Client) onlineApply(ctx context.Context, resource edgev1alpha1.EdgeSyncConfigResource, unstObj *unstructured.Unstructured, subresources ...string) (*unstructured.Unstructured, error) {
+	if c.fieldManager == "" {
+		return nil, errors.New("apply requires a field manager but none is set")
+	}
+	applyObj := unstObj.DeepCopy()
+	applyObj.SetResourceVersion("")
+	applyObj.SetUID("")
+	applyObj.SetManagedFields(nil)
+	applyObj.SetGeneration(0)
+	applyObj.SetSelfLink("")
+	unstructured.RemoveNestedField(applyObj.Object, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(applyObj.Object, "status")
+	data, err := applyObj.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	force := false
+	options := v1.PatchOptions{FieldManager: c.fieldManager, Force: &force}
+	var appliedObj *unstructured.Unstructured
+	if c.IsNamespaced() {
+		appliedObj, err = c.ResourceClient.Namespace(resource.Namespace).Patch(ctx, applyObj.GetName(), types.ApplyPatchType, data, options, subresources...)
+	} else {
+		appliedObj, err = c.ResourceClient.Patch(ctx, applyObj.GetName(), types.ApplyPatchType, data, options, subresources...)
+	}
+	if k8serrors.IsConflict(err) {
+		objectName := applyObj.GetName()
+		if ns := applyObj.GetNamespace(); ns != "" {
+			objectName = ns + "/" + objectName
+		}
+		return nil, &ApplyConflictError{Object: fmt.Sprintf("%s %s", applyObj.GetKind(), objectName), Err: err}
+	}
+	return appliedObj, err
+}
+
+// UpdateStatus writes the status of the given object.
+// While the API server is unreachable, the write is deferred if there is a local store.
 func (c *Client) UpdateStatus(resource edgev1alpha1.EdgeSyncConfigResource, unstObj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	updatedObj, err := c.onlineUpdateStatus(context.Background(), resource, unstObj)
+	if c.store != nil && IsUnreachable(err) {
+		return nil, c.deferWrite(localstore.WriteOpUpdateStatus, c.namespaceOf(resource), unstObj.GetName(), unstObj)
+	}
+	if err == nil {
+		c.wroteOnline(localstore.WriteOpUpdateStatus, c.namespaceOf(resource), unstObj.GetName(), updatedObj)
+	}
+	return updatedObj, err
+}
+
+func (c *Client) onlineUpdateStatus(ctx context.Context, resource edgev1alpha1.EdgeSyncConfigResource, unstObj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
 	var updatedObj *unstructured.Unstructured
 	var err error
 	if c.IsNamespaced() {
 		// updatedObj, err = c.ResourceClient.Namespace(resource.Namespace).Apply(context.Background(), unstObj.GetName(), unstObj, v1.ApplyOptions{FieldManager: "application/apply-patch"})
-		updatedObj, err = c.ResourceClient.Namespace(resource.Namespace).UpdateStatus(context.Background(), unstObj, v1.UpdateOptions{})
+		updatedObj, err = c.ResourceClient.Namespace(resource.Namespace).UpdateStatus(ctx, unstObj, v1.UpdateOptions{})
 	} else {
-		updatedObj, err = c.ResourceClient.UpdateStatus(context.Background(), unstObj, v1.UpdateOptions{})
+		updatedObj, err = c.ResourceClient.UpdateStatus(ctx, unstObj, v1.UpdateOptions{})
 	}
 	return updatedObj, err
 }
 
+// Delete deletes the named object.
+// While the API server is unreachable, the delete is deferred if there is a local store.
 func (c *Client) Delete(resource edgev1alpha1.EdgeSyncConfigResource, name string) error {
+	return c.DeleteWithPropagation(resource, name, "")
+}
+
+// DeleteWithPropagation deletes the named object using the given propagation policy;
+// the empty policy means the API server's default for the resource.
+// While the API server is unreachable, the delete is deferred if there is a local store;
+// a deferred delete is made with the default policy.
+func (c *Client) DeleteWithPropagation(resource edgev1alpha1.EdgeSyncConfigResource, name string, propagationPolicy v1.DeletionPropagation) error {
+	err := c.onlineDelete(context.Background(), resource, name, propagationPolicy)
+	if c.store != nil && IsUnreachable(err) {
+		return c.deferWrite(localstore.WriteOpDelete, c.namespaceOf(resource), name, nil)
+	}
+	if err == nil {
+		c.wroteOnline(localstore.WriteOpDelete, c.namespaceOf(resource), name, nil)
+	}
+	return err
+}
+
+func (c *Client) onlineDelete(ctx context.Context, resource edgev1alpha1.EdgeSyncConfigResource, name string, propagationPolicy v1.DeletionPropagation) error {
+	options := v1.DeleteOptions{}
+	if propagationPolicy != "" {
+		options.PropagationPolicy = &propagationPolicy
+	}
 	if c.IsNamespaced() {
-		return c.ResourceClient.Namespace(resource.Namespace).Delete(context.Background(), name, v1.DeleteOptions{})
+		return c.ResourceClient.Namespace(resource.Namespace).Delete(ctx, name, options)
 	} else {
-		return c.ResourceClient.Delete(context.Background(), name, v1.DeleteOptions{})
+		return c.ResourceClient.Delete(ctx, name, options)
 	}
 }
@@ -0,0 +1,248 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clientfactory
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+
+	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+	"github.com/kubestellar/kubestellar/pkg/syncer/localstore"
+)
+
+// pagingServer serves a list of ConfigMaps in pages, like an API server does.
+type pagingServer struct {
+	sync.Mutex
+	numItems int
+	// expireContinue, if not empty, is a continue token that is answered once with an Expired error
+	expireContinue string
+	limits         []int64
+}
+
+func (ps *pagingServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	ps.Lock()
+	defer ps.Unlock()
+	query := req.URL.Query()
+	limit, _ := strconv.ParseInt(query.Get("limit"), 10, 64)
+	ps.limits = append(ps.limits, limit)
+	continueToken := query.Get("continue")
+	w.Header().Set("Content-Type", "application/json")
+	if continueToken != "" && continueToken == ps.expireContinue {
+		ps.expireContinue = ""
+		status := k8serrors.NewResourceExpired("continue token expired").ErrStatus
+		status.APIVersion, status.Kind = "v1", "Status"
+		w.WriteHeader(http.StatusGone)
+		_ = json.NewEncoder(w).Encode(status)
+		return
+	}
+	start, _ := strconv.Atoi(continueToken)
+	end := ps.numItems
+	if limit > 0 && start+int(limit) < end {
+		end = start + int(limit)
+	}
+	items := []map[string]interface{}{}
+	for idx := start; idx < end; idx++ {
+		items = append(items, map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"namespace": "ns1", "name": fmt.Sprintf("cm%d", idx)},
+		})
+	}
+	metadata := map[string]interface{}{"resourceVersion": "10"}
+	if end < ps.numItems {
+		metadata["continue"] = strconv.Itoa(end)
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMapList",
+		"metadata":   metadata,
+		"items":      items,
+	})
+}
+
+func TestListPages(t *testing.T) {
+	defer func(old int64) { ListPageSize = old }(ListPageSize)
+	ListPageSize = 10
+	resource := edgev1alpha1.EdgeSyncConfigResource{Kind: "ConfigMap", Version: "v1", Namespace: "ns1", Name: "*"}
+
+	tests := []struct {
+		name           string
+		numItems       int
+		expireContinue string
+		expectedLimits []int64
+	}{
+		{name: "one page", numItems: 7, expectedLimits: []int64{10}},
+		{name: "exact pages", numItems: 30, expectedLimits: []int64{10, 10, 10}},
+		{name: "partial last page", numItems: 43, expectedLimits: []int64{10, 10, 10, 10, 10}},
+		{name: "expired continue", numItems: 25, expireContinue: "20", expectedLimits: []int64{10, 10, 10, 10, 10, 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &pagingServer{numItems: tt.numItems, expireContinue: tt.expireContinue}
+			httpServer := httptest.NewServer(server)
+			defer httpServer.Close()
+			dynamicClient, err := dynamic.NewForConfig(&rest.Config{Host: httpServer.URL})
+			require.NoError(t, err)
+			gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+			client := Client{ResourceClient: dynamicClient.Resource(gvr), resource: gvr, scope: meta.RESTScopeNamespace}
+
+			list, err := client.List(resource)
+			require.NoError(t, err)
+			names := map[string]bool{}
+			for _, item := range list.Items {
+				assert.False(t, names[item.GetName()], "%s listed more than once", item.GetName())
+				names[item.GetName()] = true
+			}
+			assert.Len(t, names, tt.numItems)
+			assert.Empty(t, list.GetContinue())
+			assert.Equal(t, tt.expectedLimits, server.limits)
+		})
+	}
+}
+
+func TestEachListItem(t *testing.T) {
+	defer func(old int64) { ListPageSize = old }(ListPageSize)
+	ListPageSize = 10
+	resource := edgev1alpha1.EdgeSyncConfigResource{Kind: "ConfigMap", Version: "v1", Namespace: "ns1", Name: "*"}
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+	server := &pagingServer{numItems: 35}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	dynamicClient, err := dynamic.NewForConfig(&rest.Config{Host: httpServer.URL})
+	require.NoError(t, err)
+	client := Client{ResourceClient: dynamicClient.Resource(gvr), resource: gvr, scope: meta.RESTScopeNamespace}
+
+	names := []string{}
+	err = client.EachListItem(resource, func(obj *unstructured.Unstructured) error {
+		names = append(names, obj.GetName())
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Len(t, names, 35)
+	assert.Equal(t, "cm34", names[34])
+	assert.Equal(t, []int64{10, 10, 10, 10}, server.limits)
+
+	// An expired snapshot starts the paged list over, without repeating objects
+	server.limits = nil
+	server.expireContinue = "10"
+	seen := map[string]int{}
+	err = client.EachListItem(resource, func(obj *unstructured.Unstructured) error {
+		seen[obj.GetName()]++
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Len(t, seen, 35)
+	for name, count := range seen {
+		assert.Equal(t, 1, count, "%s seen more than once", name)
+	}
+	assert.Equal(t, []int64{10, 10, 10, 10, 10, 10}, server.limits)
+}
+
+func TestSubresources(t *testing.T) {
+	requests := []string{}
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests = append(requests, req.Method+" "+req.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case req.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"apiVersion": "autoscaling/v1",
+				"kind":       "Scale",
+				"metadata":   map[string]interface{}{"namespace": "ns1", "name": "dep1"},
+				"spec":       map[string]interface{}{"replicas": 5},
+				"status":     map[string]interface{}{"replicas": 4, "selector": "app=dep1"},
+			})
+		case req.Header.Get("Content-Type") == string(types.ApplyPatchType):
+			assert.Equal(t, "syncer", req.URL.Query().Get("fieldManager"))
+			_, _ = io.Copy(w, req.Body)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer httpServer.Close()
+	dynamicClient, err := dynamic.NewForConfig(&rest.Config{Host: httpServer.URL})
+	require.NoError(t, err)
+	gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	client := Client{ResourceClient: dynamicClient.Resource(gvr), resource: gvr, scope: meta.RESTScopeNamespace, fieldManager: "syncer"}
+	resource := edgev1alpha1.EdgeSyncConfigResource{Group: "apps", Kind: "Deployment", Version: "v1", Namespace: "ns1", Name: "dep1"}
+
+	scale, err := client.GetScale(resource)
+	require.NoError(t, err)
+	assert.Equal(t, int32(5), scale.Spec.Replicas)
+	assert.Equal(t, int32(4), scale.Status.Replicas)
+	assert.Equal(t, "app=dep1", scale.Status.Selector)
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"namespace": "ns1", "name": "dep1"},
+	}}
+	_, err = client.ApplySubresource(resource, obj, "ephemeralcontainers")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"GET /apis/apps/v1/namespaces/ns1/deployments/dep1/scale",
+		"PATCH /apis/apps/v1/namespaces/ns1/deployments/dep1/ephemeralcontainers",
+	}, requests)
+}
+
+func TestGetWhileUnreachable(t *testing.T) {
+	store, err := localstore.Open(t.TempDir())
+	require.NoError(t, err)
+	// A server that is no longer listening, so requests cannot reach it
+	httpServer := httptest.NewServer(http.NotFoundHandler())
+	httpServer.Close()
+	dynamicClient, err := dynamic.NewForConfig(&rest.Config{Host: httpServer.URL})
+	require.NoError(t, err)
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	client := Client{ResourceClient: dynamicClient.Resource(gvr), resource: gvr, scope: meta.RESTScopeNamespace, store: store}
+
+	stored := &unstructured.Unstructured{}
+	stored.SetAPIVersion("v1")
+	stored.SetKind("ConfigMap")
+	stored.SetNamespace("ns1")
+	stored.SetName("cm1")
+	require.NoError(t, store.PutObject(gvr, stored))
+
+	obj, err := client.Get(edgev1alpha1.EdgeSyncConfigResource{Kind: "ConfigMap", Version: "v1", Namespace: "ns1", Name: "cm1"})
+	require.NoError(t, err)
+	assert.Equal(t, "cm1", obj.GetName())
+
+	// An object that is not in the store may or may not exist, so it is not reported as NotFound
+	_, err = client.Get(edgev1alpha1.EdgeSyncConfigResource{Kind: "ConfigMap", Version: "v1", Namespace: "ns1", Name: "cm2"})
+	require.Error(t, err)
+	assert.True(t, IsNotStored(err))
+	assert.True(t, IsUnreachable(err))
+	assert.False(t, k8serrors.IsNotFound(err))
+}
@@ -18,12 +18,20 @@ package clientfactory
 
 import (
 	"fmt"
+	"sync"
+	"time"
 
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
+
+	"github.com/kubestellar/kubestellar/pkg/syncer/localstore"
 )
 
 var BURST = 1024
@@ -32,6 +40,31 @@ type ClientFactory struct {
 	logger          klog.Logger
 	discoveryClient discovery.DiscoveryInterface
 	dyClient        dynamic.Interface
+	fieldManager    string
+	store           *localstore.Store
+	caches          *readCaches
+}
+
+// readCaches holds the informers whose caches the Clients read from, by resource.
+// It is shared by the copies of a ClientFactory.
+type readCaches struct {
+	sync.RWMutex
+	informers map[schema.GroupVersionResource]cache.SharedIndexInformer
+}
+
+// indexerFor returns the cache of the informer on the given resource,
+// or nil if there is no such informer or it has not synced yet.
+func (rc *readCaches) indexerFor(resource schema.GroupVersionResource) cache.Indexer {
+	if rc == nil {
+		return nil
+	}
+	rc.RLock()
+	defer rc.RUnlock()
+	informer, ok := rc.informers[resource]
+	if !ok || !informer.HasSynced() {
+		return nil
+	}
+	return informer.GetIndexer()
 }
 
 func NewClientFactory(logger klog.Logger, dyClient dynamic.Interface, discoveryClient discovery.DiscoveryInterface) (ClientFactory, error) {
@@ -39,17 +72,87 @@ func NewClientFactory(logger klog.Logger, dyClient dynamic.Interface, discoveryC
 		logger:          logger,
 		discoveryClient: discoveryClient,
 		dyClient:        dyClient,
+		caches:          &readCaches{informers: map[schema.GroupVersionResource]cache.SharedIndexInformer{}},
 	}
 	return clientFactory, nil
 }
 
+// SetFieldManager sets the field manager that the Clients made afterward use for server-side apply.
+func (cf *ClientFactory) SetFieldManager(fieldManager string) {
+	cf.fieldManager = fieldManager
+}
+
+// GetAPIGroupResources returns the API discovery information.
+// While the API server is unreachable, the information in the local store (if any) is used instead.
 func (cf *ClientFactory) GetAPIGroupResources() ([]*restmapper.APIGroupResources, error) {
-	return restmapper.GetAPIGroupResources(cf.discoveryClient)
+	groupResources, err := restmapper.GetAPIGroupResources(cf.discoveryClient)
+	if cf.store == nil {
+		return groupResources, err
+	}
+	if err == nil {
+		if storeErr := cf.store.PutDiscovery(groupResources); storeErr != nil {
+			cf.logger.Error(storeErr, "failed to store API discovery information")
+		}
+		return groupResources, nil
+	}
+	if IsUnreachable(err) {
+		stored, found, storeErr := cf.store.GetDiscovery()
+		if storeErr != nil {
+			cf.logger.Error(storeErr, "failed to read stored API discovery information")
+		} else if found {
+			return stored, nil
+		}
+	}
+	return groupResources, err
 }
 
 func (cf *ClientFactory) GetResourceClient(group string, kind string) (Client, error) {
 	var resourceClient Client
-	var client dynamic.NamespaceableResourceInterface
+	mapping, err := cf.getRESTMapping(group, kind)
+	if err != nil {
+		return resourceClient, err
+	}
+	client := cf.dyClient.Resource(mapping.Resource)
+	resourceClient = Client{
+		ResourceClient: client,
+		resource:       mapping.Resource,
+		scope:          mapping.Scope,
+		fieldManager:   cf.fieldManager,
+		store:          cf.store,
+		caches:         cf.caches,
+	}
+	return resourceClient, nil
+}
+
+// NewInformer returns a new, not yet started, informer on all the objects of the given kind.
+// Once the informer has synced, the Clients of this factory read the objects of that kind
+// from its cache rather than from the API server, until RemoveInformer is called.
+func (cf *ClientFactory) NewInformer(group string, kind string, resyncPeriod time.Duration) (cache.SharedIndexInformer, error) {
+	mapping, err := cf.getRESTMapping(group, kind)
+	if err != nil {
+		return nil, err
+	}
+	informer := dynamicinformer.NewFilteredDynamicInformer(cf.dyClient, mapping.Resource, metav1.NamespaceAll, resyncPeriod,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, nil).Informer()
+	cf.caches.Lock()
+	defer cf.caches.Unlock()
+	cf.caches.informers[mapping.Resource] = informer
+	return informer, nil
+}
+
+// RemoveInformer stops the Clients of this factory from reading from the cache of the given informer,
+// which was returned by NewInformer. It is called when the informer is stopped.
+func (cf *ClientFactory) RemoveInformer(informer cache.SharedIndexInformer) {
+	cf.caches.Lock()
+	defer cf.caches.Unlock()
+	for resource, cached := range cf.caches.informers {
+		if cached == informer {
+			delete(cf.caches.informers, resource)
+		}
+	}
+}
+
+func (cf *ClientFactory) getRESTMapping(group string, kind string) (*meta.RESTMapping, error) {
 	gk := schema.GroupKind{
 		Group: group,
 		Kind:  kind,
@@ -57,23 +160,18 @@ func (cf *ClientFactory) GetResourceClient(group string, kind string) (Client, e
 	groupResources, err := cf.GetAPIGroupResources()
 	if err != nil {
 		cf.logger.Error(err, "failed to get APIGroupResource")
-		return resourceClient, err
+		return nil, err
 	}
 	restMapper := restmapper.NewDiscoveryRESTMapper(groupResources)
 	mappings, err := restMapper.RESTMappings(gk)
 	if err != nil {
 		cf.logger.Error(err, fmt.Sprintf("failed to get restMapping %s", gk.String()))
-		return resourceClient, err
+		return nil, err
 	}
 	if len(mappings) == 0 {
-		cf.logger.Error(err, fmt.Sprintf("no restMapping %s", gk.String()))
-		return resourceClient, err
+		err = fmt.Errorf("no restMapping %s", gk.String())
+		cf.logger.Error(err, "failed to get restMapping")
+		return nil, err
 	}
-	mapping := mappings[0]
-	client = cf.dyClient.Resource(mapping.Resource)
-	resourceClient = Client{
-		ResourceClient: client,
-		scope:          mapping.Scope,
-	}
-	return resourceClient, nil
+	return mappings[0], nil
 }
@@ -0,0 +1,242 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clientfactory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+
+	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+	"github.com/kubestellar/kubestellar/pkg/syncer/localstore"
+)
+
+// IsUnreachable tells whether the given error means that the API server could not be reached,
+// as opposed to the API server rejecting the request.
+func IsUnreachable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var apiStatus k8serrors.APIStatus
+	if errors.As(err, &apiStatus) {
+		return k8serrors.IsServiceUnavailable(err) || k8serrors.IsServerTimeout(err) || k8serrors.IsTimeout(err)
+	}
+	var urlErr *url.Error
+	var netErr net.Error
+	return errors.As(err, &urlErr) || errors.As(err, &netErr)
+}
+
+// NotStoredError is returned by Client.Get while the API server is unreachable and
+// the object is not in the local store, so there is no telling whether the object exists.
+// A caller must not take it to mean that the object is gone.
+type NotStoredError struct {
+	Resource  schema.GroupVersionResource
+	Namespace string
+	Name      string
+	// Err is the error from the unreachable API server
+	Err error
+}
+
+func (e *NotStoredError) Error() string {
+	return fmt.Sprintf("%s %s/%s is not in the local store and the API server is unreachable: %v", e.Resource, e.Namespace, e.Name, e.Err)
+}
+
+func (e *NotStoredError) Unwrap() error {
+	return e.Err
+}
+
+// IsNotStored tells whether the given error is a NotStoredError.
+func IsNotStored(err error) bool {
+	var notStoredErr *NotStoredError
+	return errors.As(err, &notStoredErr)
+}
+
+// WriteConflict reports that a deferred write was not made because the object
+// changed in the mailbox workspace while the write was deferred.
+type WriteConflict struct {
+	Write   localstore.PendingWrite
+	Message string
+}
+
+// SetLocalStore makes the Clients made afterward, and GetAPIGroupResources,
+// keep the given store up to date with what they read and fall back on it when
+// the API server is unreachable.
+// While the API server is unreachable, writes are deferred by recording them in
+// the store; ReplayPendingWrites makes them.
+func (cf *ClientFactory) SetLocalStore(store *localstore.Store) {
+	cf.store = store
+}
+
+// HasPendingWrites tells whether there are deferred writes to replay.
+func (cf *ClientFactory) HasPendingWrites() bool {
+	return cf.store != nil && cf.store.HasPendingWrites()
+}
+
+// ReplayPendingWrites makes the deferred writes, in the order of their keys.
+// A deferred write whose object changed in the meantime is not made but is
+// returned as a conflict.
+// Replay stops at the first error, which is returned; the unmade writes stay pending.
+func (cf *ClientFactory) ReplayPendingWrites(ctx context.Context) ([]WriteConflict, error) {
+	if cf.store == nil {
+		return nil, nil
+	}
+	conflicts := []WriteConflict{}
+	for _, pw := range cf.store.PendingWrites() {
+		conflict, err := cf.replay(ctx, pw)
+		if err != nil {
+			return conflicts, err
+		}
+		if conflict != "" {
+			cf.logger.Info("Not replaying write that conflicts with a change made while disconnected",
+				"op", pw.Op, "resource", pw.Resource, "namespace", pw.Namespace, "name", pw.Name, "conflict", conflict)
+			conflicts = append(conflicts, WriteConflict{Write: pw, Message: conflict})
+		}
+		if err := cf.store.RemovePendingWrite(pw.Key()); err != nil {
+			return conflicts, err
+		}
+	}
+	return conflicts, nil
+}
+
+// replay makes one deferred write, unless it conflicts.
+// The returned string describes the conflict, if any.
+func (cf *ClientFactory) replay(ctx context.Context, pw localstore.PendingWrite) (string, error) {
+	var client dynamic.ResourceInterface = cf.dyClient.Resource(pw.Resource)
+	if pw.Namespace != "" {
+		client = cf.dyClient.Resource(pw.Resource).Namespace(pw.Namespace)
+	}
+	current, err := client.Get(ctx, pw.Name, v1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		current, err = nil, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	currentRV := ""
+	if current != nil {
+		currentRV = current.GetResourceVersion()
+	}
+	if currentRV != pw.BaseResourceVersion {
+		if pw.BaseResourceVersion == "" {
+			return "object was created in the meantime", nil
+		}
+		if current == nil {
+			return "object was deleted in the meantime", nil
+		}
+		return fmt.Sprintf("object changed in the meantime (resourceVersion %s, was %s)", currentRV, pw.BaseResourceVersion), nil
+	}
+	scope := meta.RESTScopeRoot
+	if pw.Namespace != "" {
+		scope = meta.RESTScopeNamespace
+	}
+	resourceClient := Client{ResourceClient: cf.dyClient.Resource(pw.Resource), resource: pw.Resource, scope: scope, fieldManager: cf.fieldManager, store: cf.store}
+	resource := edgev1alpha1.EdgeSyncConfigResource{Namespace: pw.Namespace, Name: pw.Name}
+	switch pw.Op {
+	case localstore.WriteOpApply:
+		_, err = resourceClient.onlineApply(ctx, resource, pw.Object)
+	case localstore.WriteOpUpdateStatus:
+		if current == nil {
+			return "", nil
+		}
+		obj := current.DeepCopy()
+		obj.Object["status"] = pw.Object.Object["status"]
+		_, err = resourceClient.onlineUpdateStatus(ctx, resource, obj)
+	case localstore.WriteOpDelete:
+		if current == nil {
+			return "", nil
+		}
+		err = resourceClient.onlineDelete(ctx, resource, pw.Name, "")
+	}
+	if IsApplyConflict(err) {
+		return err.Error(), nil
+	}
+	return "", err
+}
+
+// remember updates the store with the outcome of reading the given object.
+func (c *Client) remember(resource schema.GroupVersionResource, namespace, name string, obj *unstructured.Unstructured, err error) {
+	if c.store == nil {
+		return
+	}
+	var storeErr error
+	if err == nil && obj != nil {
+		storeErr = c.store.PutObject(resource, obj)
+	} else if k8serrors.IsNotFound(err) {
+		storeErr = c.store.DeleteObject(resource, namespace, name)
+	}
+	if storeErr != nil {
+		klog.ErrorS(storeErr, "failed to update local store", "resource", resource, "namespace", namespace, "name", name)
+	}
+}
+
+// rememberList updates the local store, if any, with the result of listing the given resource.
+func (c *Client) rememberList(resource edgev1alpha1.EdgeSyncConfigResource, list *unstructured.UnstructuredList) {
+	// A filtered list does not say what else is in the store
+	if c.store == nil || resource.LabelSelector != "" {
+		return
+	}
+	if storeErr := c.store.ReplaceObjects(c.resource, c.namespaceOf(resource), list.Items); storeErr != nil {
+		klog.ErrorS(storeErr, "failed to update local store", "resource", c.resource, "namespace", resource.Namespace)
+	}
+}
+
+// rememberEach updates the local store, if any, with the objects seen by listing the given resource
+// one at a time; the given function tells whether an object with the given namespace and name was seen.
+func (c *Client) rememberEach(resource edgev1alpha1.EdgeSyncConfigResource, seen func(namespace, name string) bool) {
+	// A filtered list does not say what else is in the store
+	if c.store == nil || resource.LabelSelector != "" {
+		return
+	}
+	if storeErr := c.store.RetainObjects(c.resource, c.namespaceOf(resource), seen); storeErr != nil {
+		klog.ErrorS(storeErr, "failed to update local store", "resource", c.resource, "namespace", resource.Namespace)
+	}
+}
+
+// deferWrite records a write that could not be made because the API server is unreachable.
+func (c *Client) deferWrite(op localstore.WriteOp, namespace, name string, obj *unstructured.Unstructured) error {
+	pw := localstore.PendingWrite{Op: op, Resource: c.resource, Namespace: namespace, Name: name}
+	if obj != nil {
+		pw.Object = obj.DeepCopy()
+	}
+	return c.store.AddPendingWrite(pw)
+}
+
+// wroteOnline notes that a write has been made, superseding any deferred one.
+func (c *Client) wroteOnline(op localstore.WriteOp, namespace, name string, obj *unstructured.Unstructured) {
+	if c.store == nil {
+		return
+	}
+	pw := localstore.PendingWrite{Op: op, Resource: c.resource, Namespace: namespace, Name: name}
+	if err := c.store.RemovePendingWrite(pw.Key()); err != nil {
+		klog.ErrorS(err, "failed to update local store", "resource", c.resource, "namespace", namespace, "name", name)
+	}
+	if op == localstore.WriteOpDelete {
+		c.remember(c.resource, namespace, name, nil, k8serrors.NewNotFound(c.resource.GroupResource(), name))
+	} else {
+		c.remember(c.resource, namespace, name, obj, nil)
+	}
+}
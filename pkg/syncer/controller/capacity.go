@@ -0,0 +1,46 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// sumNodeResources returns the sum of the capacities of the given nodes and
+// the sum of the allocatable resources of those that are schedulable.
+func sumNodeResources(nodes []*corev1.Node) (capacity, allocatable corev1.ResourceList) {
+	capacity = corev1.ResourceList{}
+	allocatable = corev1.ResourceList{}
+	for _, node := range nodes {
+		addResources(capacity, node.Status.Capacity)
+		if !node.Spec.Unschedulable {
+			addResources(allocatable, node.Status.Allocatable)
+		}
+	}
+	return
+}
+
+func addResources(sum, addend corev1.ResourceList) {
+	for name, quantity := range addend {
+		if current, found := sum[name]; found {
+			current.Add(quantity)
+			sum[name] = current
+		} else {
+			sum[name] = quantity.DeepCopy()
+		}
+	}
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+
+	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+	edgev1alpha1typed "github.com/kubestellar/kubestellar/pkg/client/clientset/versioned/typed/edge/v1alpha1"
+	edgev1alpha1listers "github.com/kubestellar/kubestellar/pkg/client/listers/edge/v1alpha1"
+	"github.com/kubestellar/kubestellar/pkg/syncer/syncers"
+)
+
+// NewHeartbeater returns a Heartbeater that, once Run, writes the current time into
+// Status.LastSyncerHeartbeatTime of every SyncerConfig in the syncer's mailbox workspace
+// every heartbeatInterval.
+// Status.ObjectCounts, which counts the latest sync outcomes held in the given SyncStatusStore,
+// Status.ObjectStatuses, which reports the most recent of those that are failures or drift
+// (at most syncers.MaxReportedObjectStatuses of them), and, when a node lister is given,
+// the summed capacity and allocatable resources of the edge cluster's nodes are written
+// along with a heartbeat only when they have changed; otherwise a heartbeat patches just the timestamp.
+// The syncer has no access to its SyncTarget, which lives in an inventory workspace;
+// the mailbox controller copies the heartbeat from the SyncerConfig to the SyncTarget
+// and maintains the SyncTarget's HeartbeatHealthy condition.
+func NewHeartbeater(
+	logger klog.Logger,
+	syncerConfigClient edgev1alpha1typed.SyncerConfigInterface,
+	syncerConfigLister edgev1alpha1listers.SyncerConfigLister,
+	syncStatusStore *syncers.SyncStatusStore,
+	nodeLister corev1listers.NodeLister,
+	heartbeatInterval time.Duration,
+) *Heartbeater {
+	return &Heartbeater{
+		logger:             logger,
+		syncerConfigClient: syncerConfigClient,
+		syncerConfigLister: syncerConfigLister,
+		syncStatusStore:    syncStatusStore,
+		nodeLister:         nodeLister,
+		heartbeatInterval:  heartbeatInterval,
+	}
+}
+
+type Heartbeater struct {
+	logger             klog.Logger
+	syncerConfigClient edgev1alpha1typed.SyncerConfigInterface
+	syncerConfigLister edgev1alpha1listers.SyncerConfigLister
+	syncStatusStore    *syncers.SyncStatusStore
+	nodeLister         corev1listers.NodeLister
+	heartbeatInterval  time.Duration
+}
+
+// Run sends heartbeats until the context is done.
+func (h *Heartbeater) Run(ctx context.Context) {
+	h.logger.V(2).Info(fmt.Sprintf("Start heartbeat with interval: %v", h.heartbeatInterval))
+	wait.UntilWithContext(ctx, h.beat, h.heartbeatInterval)
+}
+
+func (h *Heartbeater) beat(ctx context.Context) {
+	syncerConfigs, err := h.syncerConfigLister.List(labels.Everything())
+	if err != nil {
+		h.logger.Error(err, "failed to list SyncerConfigs for heartbeat")
+		return
+	}
+	objectCounts := h.syncStatusStore.Counts()
+	objectStatuses := h.syncStatusStore.Problems(syncers.MaxReportedObjectStatuses)
+	var capacity, allocatable corev1.ResourceList
+	if h.nodeLister != nil {
+		nodes, err := h.nodeLister.List(labels.Everything())
+		if err != nil {
+			h.logger.Error(err, "failed to list Nodes for heartbeat")
+		} else {
+			capacity, allocatable = sumNodeResources(nodes)
+		}
+	}
+	for _, syncerConfig := range syncerConfigs {
+		name := syncerConfig.Name
+		heartbeatTime := metav1.Now()
+		var err error
+		if apiequality.Semantic.DeepEqual(syncerConfig.Status.ObjectCounts, objectCounts) &&
+			sameObjectStatuses(syncerConfig.Status.ObjectStatuses, objectStatuses) &&
+			(capacity == nil || apiequality.Semantic.DeepEqual(syncerConfig.Status.Capacity, capacity) && apiequality.Semantic.DeepEqual(syncerConfig.Status.Allocatable, allocatable)) {
+			err = h.patchHeartbeat(ctx, name, heartbeatTime)
+		} else {
+			err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+				latest, err := h.syncerConfigClient.Get(ctx, name, metav1.GetOptions{})
+				if err != nil {
+					return err
+				}
+				latest = latest.DeepCopy()
+				latest.Status.LastSyncerHeartbeatTime = &heartbeatTime
+				latest.Status.ObjectCounts = objectCounts
+				latest.Status.ObjectStatuses = objectStatuses
+				if capacity != nil {
+					latest.Status.Capacity = capacity
+					latest.Status.Allocatable = allocatable
+				}
+				_, err = h.syncerConfigClient.UpdateStatus(ctx, latest, metav1.UpdateOptions{})
+				return err
+			})
+		}
+		if err != nil {
+			h.logger.Error(err, fmt.Sprintf("failed to send heartbeat to SyncerConfig %s", name))
+			continue
+		}
+		h.logger.V(4).Info(fmt.Sprintf("sent heartbeat to SyncerConfig %s", name))
+	}
+}
+
+// patchHeartbeat writes only the heartbeat time into the status of the named SyncerConfig.
+func (h *Heartbeater) patchHeartbeat(ctx context.Context, name string, heartbeatTime metav1.Time) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{"lastSyncerHeartbeatTime": heartbeatTime},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = h.syncerConfigClient.Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{}, "status")
+	return err
+}
+
+// sameObjectStatuses tells whether the given lists of sync outcomes differ only in when the
+// syncs were attempted, so that a resync that changes nothing else does not rewrite the status.
+func sameObjectStatuses(reported, current []edgev1alpha1.SyncedObjectStatus) bool {
+	if len(reported) != len(current) {
+		return false
+	}
+	for idx := range reported {
+		a, b := reported[idx], current[idx]
+		a.LastSyncTime, b.LastSyncTime = metav1.Time{}, metav1.Time{}
+		if !apiequality.Semantic.DeepEqual(a, b) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubeinformers "k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/klog/v2"
+
+	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+	edgefakeclient "github.com/kubestellar/kubestellar/pkg/client/clientset/versioned/fake"
+	edgeinformers "github.com/kubestellar/kubestellar/pkg/client/informers/externalversions"
+	"github.com/kubestellar/kubestellar/pkg/syncer/syncers"
+)
+
+func TestHeartbeater(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := klog.FromContext(ctx)
+
+	syncerConfigClientSet := edgefakeclient.NewSimpleClientset(syncerConfig("the-one", types.UID("uid")))
+	syncerConfigClient := syncerConfigClientSet.EdgeV1alpha1().SyncerConfigs()
+	syncerConfigInformerFactory := edgeinformers.NewSharedScopedInformerFactoryWithOptions(syncerConfigClientSet, 0)
+	syncerConfigInformer := syncerConfigInformerFactory.Edge().V1alpha1().SyncerConfigs()
+	syncStatusStore := syncers.NewSyncStatusStore()
+	syncStatusStore.Record(edgev1alpha1.SyncDirectionDown, configMapGVR, "default", "cm-1", 1, nil)
+	syncStatusStore.RecordScale(edgev1alpha1.SyncDirectionDown, configMapGVR, "default", "cm-1", &edgev1alpha1.ObservedScale{Replicas: 3, StatusReplicas: 2})
+	syncStatusStore.Record(edgev1alpha1.SyncDirectionDown, configMapGVR, "default", "cm-1", 2, errors.New("failed"))
+	syncStatusStore.RecordScale(edgev1alpha1.SyncDirectionDown, configMapGVR, "default", "cm-2", &edgev1alpha1.ObservedScale{Replicas: 1})
+	syncStatusStore.Record(edgev1alpha1.SyncDirectionDown, configMapGVR, "default", "cm-3", 1, nil)
+	node := func(name, cpu string, unschedulable bool) *corev1.Node {
+		resources := corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+			Status:     corev1.NodeStatus{Capacity: resources, Allocatable: resources},
+		}
+	}
+	kubeClient := kubefake.NewSimpleClientset(node("n1", "2", false), node("n2", "500m", false), node("n3", "4", true))
+	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeClient, 0)
+	heartbeater := NewHeartbeater(logger, syncerConfigClient, syncerConfigInformer.Lister(), syncStatusStore, kubeInformerFactory.Core().V1().Nodes().Lister(), 100*time.Millisecond)
+	syncerConfigInformerFactory.Start(ctx.Done())
+	syncerConfigInformerFactory.WaitForCacheSync(ctx.Done())
+	kubeInformerFactory.Start(ctx.Done())
+	kubeInformerFactory.WaitForCacheSync(ctx.Done())
+
+	before := time.Now().Add(-time.Second)
+	go heartbeater.Run(ctx)
+
+	var first metav1.Time
+	require.Eventually(t, func() bool {
+		sc, err := syncerConfigClient.Get(ctx, "the-one", metav1.GetOptions{})
+		require.NoError(t, err)
+		if sc.Status.LastSyncerHeartbeatTime == nil || sc.Status.LastSyncerHeartbeatTime.Time.Before(before) {
+			return false
+		}
+		first = *sc.Status.LastSyncerHeartbeatTime
+		require.Equal(t, []edgev1alpha1.SyncedObjectCount{{Direction: edgev1alpha1.SyncDirectionDown, Resource: "configmaps", Namespace: "default", Succeeded: 1, Failed: 1}}, sc.Status.ObjectCounts)
+		require.Len(t, sc.Status.ObjectStatuses, 1, "only the failure is reported")
+		require.Equal(t, "cm-1", sc.Status.ObjectStatuses[0].Name)
+		require.Equal(t, edgev1alpha1.SyncOutcomeFailed, sc.Status.ObjectStatuses[0].Outcome)
+		require.Equal(t, &edgev1alpha1.ObservedScale{Replicas: 3, StatusReplicas: 2}, sc.Status.ObjectStatuses[0].Scale, "the scale is kept with later outcomes")
+		require.Equal(t, "6500m", sc.Status.Capacity.Cpu().String())
+		require.Equal(t, "2500m", sc.Status.Allocatable.Cpu().String())
+		return true
+	}, wait.ForeverTestTimeout, 50*time.Millisecond)
+
+	require.Eventually(t, func() bool {
+		sc, err := syncerConfigClient.Get(ctx, "the-one", metav1.GetOptions{})
+		require.NoError(t, err)
+		return first.Before(sc.Status.LastSyncerHeartbeatTime)
+	}, wait.ForeverTestTimeout, 50*time.Millisecond)
+
+	// Once the outcomes are reported, a resync that changes only the sync times is not written
+	require.Eventually(t, func() bool {
+		sc, err := syncerConfigInformer.Lister().Get("the-one")
+		require.NoError(t, err)
+		return len(sc.Status.ObjectStatuses) == 1
+	}, wait.ForeverTestTimeout, 50*time.Millisecond)
+	syncStatusStore.Record(edgev1alpha1.SyncDirectionDown, configMapGVR, "default", "cm-1", 2, errors.New("failed"))
+	syncerConfigClientSet.ClearActions()
+	require.Eventually(t, func() bool {
+		return len(syncerConfigClientSet.Actions()) >= 2
+	}, wait.ForeverTestTimeout, 50*time.Millisecond)
+	for _, action := range syncerConfigClientSet.Actions() {
+		if action.GetVerb() == "update" || action.GetVerb() == "patch" {
+			require.Equal(t, "patch", action.GetVerb(), "only the heartbeat time should be written")
+			require.Equal(t, "status", action.GetSubresource())
+		}
+	}
+
+	syncStatusStore.Record(edgev1alpha1.SyncDirectionDown, configMapGVR, "default", "cm-1", 3, errors.New("failed"))
+	require.Eventually(t, func() bool {
+		sc, err := syncerConfigClient.Get(ctx, "the-one", metav1.GetOptions{})
+		require.NoError(t, err)
+		return len(sc.Status.ObjectStatuses) == 1 && sc.Status.ObjectStatuses[0].ObservedGeneration == 3
+	}, wait.ForeverTestTimeout, 50*time.Millisecond)
+
+	// A success is only counted
+	syncStatusStore.Record(edgev1alpha1.SyncDirectionDown, configMapGVR, "default", "cm-1", 3, nil)
+	require.Eventually(t, func() bool {
+		sc, err := syncerConfigClient.Get(ctx, "the-one", metav1.GetOptions{})
+		require.NoError(t, err)
+		return len(sc.Status.ObjectStatuses) == 0 && len(sc.Status.ObjectCounts) == 1 && sc.Status.ObjectCounts[0].Succeeded == 2
+	}, wait.ForeverTestTimeout, 50*time.Millisecond)
+}
+
+func TestHeartbeaterBoundsObjectStatuses(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := klog.FromContext(ctx)
+
+	syncerConfigClientSet := edgefakeclient.NewSimpleClientset(syncerConfig("the-one", types.UID("uid")))
+	syncerConfigClient := syncerConfigClientSet.EdgeV1alpha1().SyncerConfigs()
+	syncerConfigInformerFactory := edgeinformers.NewSharedScopedInformerFactoryWithOptions(syncerConfigClientSet, 0)
+	syncerConfigInformer := syncerConfigInformerFactory.Edge().V1alpha1().SyncerConfigs()
+	syncStatusStore := syncers.NewSyncStatusStore()
+	for ns := 0; ns < 10; ns++ {
+		namespace := fmt.Sprintf("ns-%d", ns)
+		for idx := 0; idx < 1000; idx++ {
+			syncStatusStore.Record(edgev1alpha1.SyncDirectionDown, configMapGVR, namespace, fmt.Sprintf("cm-%d", idx), 1, nil)
+		}
+		for idx := 0; idx < 5; idx++ {
+			syncStatusStore.Record(edgev1alpha1.SyncDirectionDown, configMapGVR, namespace, fmt.Sprintf("failing-%d", idx), 1, errors.New("failed"))
+		}
+		syncStatusStore.RecordDrift(edgev1alpha1.SyncDirectionDown, configMapGVR, namespace, "drifting", 1, []string{".data.key"}, false)
+	}
+	heartbeater := NewHeartbeater(logger, syncerConfigClient, syncerConfigInformer.Lister(), syncStatusStore, nil, time.Hour)
+	syncerConfigInformerFactory.Start(ctx.Done())
+	syncerConfigInformerFactory.WaitForCacheSync(ctx.Done())
+
+	heartbeater.beat(ctx)
+	sc, err := syncerConfigClient.Get(ctx, "the-one", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, sc.Status.ObjectCounts, 10)
+	for _, count := range sc.Status.ObjectCounts {
+		require.Equal(t, edgev1alpha1.SyncedObjectCount{Direction: edgev1alpha1.SyncDirectionDown, Resource: "configmaps", Namespace: count.Namespace, Succeeded: 1000, Failed: 5, Drifted: 1}, count)
+	}
+	require.Len(t, sc.Status.ObjectStatuses, syncers.MaxReportedObjectStatuses)
+	for _, objStatus := range sc.Status.ObjectStatuses {
+		require.NotEqual(t, edgev1alpha1.SyncOutcomeSucceeded, objStatus.Outcome)
+	}
+}
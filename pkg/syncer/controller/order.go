@@ -0,0 +1,118 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+	"github.com/kubestellar/kubestellar/pkg/syncer/syncers"
+)
+
+// applyRank is the position of a kind of object in the order in which objects are applied.
+// Objects of lower rank are applied first, because objects of higher rank may depend on them;
+// objects are deleted in the reverse order.
+type applyRank int
+
+const (
+	rankNamespacesAndCRDs applyRank = iota
+	rankRBAC
+	rankServiceAccounts
+	rankConfiguration
+	rankWorkloads
+)
+
+var crdGroupKind = schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}
+
+// rankOf returns the applyRank of the given kind of object.
+func rankOf(gk schema.GroupKind) applyRank {
+	switch {
+	case gk == schema.GroupKind{Kind: "Namespace"} || gk == crdGroupKind:
+		return rankNamespacesAndCRDs
+	case gk.Group == "rbac.authorization.k8s.io":
+		return rankRBAC
+	case gk == schema.GroupKind{Kind: "ServiceAccount"}:
+		return rankServiceAccounts
+	case gk == schema.GroupKind{Kind: "ConfigMap"} || gk == schema.GroupKind{Kind: "Secret"}:
+		return rankConfiguration
+	}
+	return rankWorkloads
+}
+
+// downstreamGroupKind returns the kind that the given resource has in the downstream cluster.
+func downstreamGroupKind(resource edgev1alpha1.EdgeSyncConfigResource, conversions []edgev1alpha1.EdgeSynConversion) schema.GroupKind {
+	resourceForDown := syncers.ConvertToDownstream(resource, conversions)
+	return schema.GroupKind{Group: resourceForDown.Group, Kind: resourceForDown.Kind}
+}
+
+// sortForApply returns a copy of the given resources, sorted into the order in which they are applied.
+// Resources of the same rank are sorted by kind, namespace and name, so that the order is stable.
+func sortForApply(resources []edgev1alpha1.EdgeSyncConfigResource, conversions []edgev1alpha1.EdgeSynConversion) []edgev1alpha1.EdgeSyncConfigResource {
+	sorted := append([]edgev1alpha1.EdgeSyncConfigResource{}, resources...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		iGK, jGK := downstreamGroupKind(sorted[i], conversions), downstreamGroupKind(sorted[j], conversions)
+		if iRank, jRank := rankOf(iGK), rankOf(jGK); iRank != jRank {
+			return iRank < jRank
+		}
+		if iGK != jGK {
+			return iGK.String() < jGK.String()
+		}
+		if sorted[i].Namespace != sorted[j].Namespace {
+			return sorted[i].Namespace < sorted[j].Namespace
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+// sortForDelete returns a copy of the given resources, sorted into the order in which they are deleted.
+func sortForDelete(resources []edgev1alpha1.EdgeSyncConfigResource, conversions []edgev1alpha1.EdgeSynConversion) []edgev1alpha1.EdgeSyncConfigResource {
+	sorted := sortForApply(resources, conversions)
+	for i, j := 0, len(sorted)-1; i < j; i, j = i+1, j-1 {
+		sorted[i], sorted[j] = sorted[j], sorted[i]
+	}
+	return sorted
+}
+
+// unestablishedKinds returns the kinds defined by the given CustomResourceDefinitions
+// that are not Established yet.
+func unestablishedKinds(crds []unstructured.Unstructured) map[schema.GroupKind]bool {
+	ans := map[schema.GroupKind]bool{}
+	for _, crd := range crds {
+		group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+		kind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+		if !crdIsEstablished(&crd) {
+			ans[schema.GroupKind{Group: group, Kind: kind}] = true
+		}
+	}
+	return ans
+}
+
+// crdIsEstablished tells whether the given CustomResourceDefinition has condition Established=True.
+func crdIsEstablished(crd *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+	for _, condition := range conditions {
+		conditionMap, ok := condition.(map[string]interface{})
+		if ok && conditionMap["type"] == "Established" && conditionMap["status"] == "True" {
+			return true
+		}
+	}
+	return false
+}
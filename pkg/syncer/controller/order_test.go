@@ -0,0 +1,80 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+)
+
+func TestSortForApply(t *testing.T) {
+	deployment := edgev1alpha1.EdgeSyncConfigResource{Group: "apps", Kind: "Deployment", Namespace: "ns", Name: "web"}
+	cheddar := edgev1alpha1.EdgeSyncConfigResource{Group: "cheese.testing.k8s.io", Kind: "Cheddar", Namespace: "ns", Name: "aged"}
+	configMap := edgev1alpha1.EdgeSyncConfigResource{Kind: "ConfigMap", Namespace: "ns", Name: "cm"}
+	secret := edgev1alpha1.EdgeSyncConfigResource{Kind: "Secret", Namespace: "ns", Name: "creds"}
+	serviceAccount := edgev1alpha1.EdgeSyncConfigResource{Kind: "ServiceAccount", Namespace: "ns", Name: "sa"}
+	roleBinding := edgev1alpha1.EdgeSyncConfigResource{Group: "rbac.authorization.k8s.io", Kind: "RoleBinding", Namespace: "ns", Name: "rb"}
+	crd := edgev1alpha1.EdgeSyncConfigResource{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition", Name: "cheddars.cheese.testing.k8s.io"}
+	namespace := edgev1alpha1.EdgeSyncConfigResource{Kind: "Namespace", Name: "ns"}
+
+	resources := []edgev1alpha1.EdgeSyncConfigResource{deployment, cheddar, configMap, secret, serviceAccount, roleBinding, namespace, crd}
+	expected := []edgev1alpha1.EdgeSyncConfigResource{crd, namespace, roleBinding, serviceAccount, configMap, secret, cheddar, deployment}
+	require.Equal(t, expected, sortForApply(resources, nil))
+
+	reversed := []edgev1alpha1.EdgeSyncConfigResource{deployment, cheddar, secret, configMap, serviceAccount, roleBinding, namespace, crd}
+	require.Equal(t, reversed, sortForDelete(resources, nil))
+	require.Equal(t, deployment, resources[0], "the given slice is not modified")
+
+	// The rank of a converted resource is that of its downstream kind
+	conversions := []edgev1alpha1.EdgeSynConversion{{
+		Upstream:   edgev1alpha1.EdgeSyncConfigResource{Group: "edge.kubestellar.io", Kind: "DenaturedNamespace"},
+		Downstream: edgev1alpha1.EdgeSyncConfigResource{Kind: "Namespace"},
+	}}
+	require.Equal(t, []edgev1alpha1.EdgeSyncConfigResource{namespace, configMap}, sortForApply([]edgev1alpha1.EdgeSyncConfigResource{configMap, namespace}, conversions))
+}
+
+func TestUnestablishedKinds(t *testing.T) {
+	crd := func(group, kind string, conditions ...interface{}) unstructured.Unstructured {
+		return unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apiextensions.k8s.io/v1",
+			"kind":       "CustomResourceDefinition",
+			"spec": map[string]interface{}{
+				"group": group,
+				"names": map[string]interface{}{"kind": kind},
+			},
+			"status": map[string]interface{}{"conditions": conditions},
+		}}
+	}
+	established := map[string]interface{}{"type": "Established", "status": "True"}
+	notEstablished := map[string]interface{}{"type": "Established", "status": "False"}
+	namesAccepted := map[string]interface{}{"type": "NamesAccepted", "status": "True"}
+
+	got := unestablishedKinds([]unstructured.Unstructured{
+		crd("cheese.testing.k8s.io", "Cheddar", namesAccepted, established),
+		crd("cheese.testing.k8s.io", "Gouda", namesAccepted, notEstablished),
+		crd("cheese.testing.k8s.io", "Brie"),
+	})
+	require.Equal(t, map[schema.GroupKind]bool{
+		{Group: "cheese.testing.k8s.io", Kind: "Gouda"}: true,
+		{Group: "cheese.testing.k8s.io", Kind: "Brie"}:  true,
+	}, got)
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+	"github.com/kubestellar/kubestellar/pkg/syncer/clientfactory"
+	"github.com/kubestellar/kubestellar/pkg/syncer/localstore"
+	"github.com/kubestellar/kubestellar/pkg/syncer/syncers"
+)
+
+// NewPendingWriteReplayer returns a PendingWriteReplayer that, once Run, tries every
+// interval to make the writes to the mailbox workspace that were deferred while it
+// was unreachable.
+// A deferred upsync or deletion that conflicts with a change made in the mailbox
+// workspace in the meantime is not made; it is recorded as a failure in the given
+// SyncStatusStore, so that the heartbeat reports it.
+func NewPendingWriteReplayer(
+	logger klog.Logger,
+	upstreamClientFactory clientfactory.ClientFactory,
+	syncStatusStore *syncers.SyncStatusStore,
+	interval time.Duration,
+) *PendingWriteReplayer {
+	return &PendingWriteReplayer{
+		logger:                logger,
+		upstreamClientFactory: upstreamClientFactory,
+		syncStatusStore:       syncStatusStore,
+		interval:              interval,
+	}
+}
+
+type PendingWriteReplayer struct {
+	logger                klog.Logger
+	upstreamClientFactory clientfactory.ClientFactory
+	syncStatusStore       *syncers.SyncStatusStore
+	interval              time.Duration
+}
+
+// Run replays deferred writes until the context is done.
+func (r *PendingWriteReplayer) Run(ctx context.Context) {
+	r.logger.V(2).Info(fmt.Sprintf("Start replaying deferred writes with interval: %v", r.interval))
+	wait.UntilWithContext(ctx, r.replay, r.interval)
+}
+
+func (r *PendingWriteReplayer) replay(ctx context.Context) {
+	if !r.upstreamClientFactory.HasPendingWrites() {
+		return
+	}
+	conflicts, err := r.upstreamClientFactory.ReplayPendingWrites(ctx)
+	for _, conflict := range conflicts {
+		write := conflict.Write
+		if write.Op == localstore.WriteOpUpdateStatus {
+			// The next status sync recomputes the status anyway
+			continue
+		}
+		r.syncStatusStore.Record(edgev1alpha1.SyncDirectionUp, write.Resource, write.Namespace, write.Name, 0,
+			fmt.Errorf("deferred %s not made: %s", write.Op, conflict.Message))
+	}
+	if clientfactory.IsUnreachable(err) {
+		r.logger.V(3).Info("Mailbox workspace still unreachable, will retry deferred writes", "error", err)
+	} else if err != nil {
+		r.logger.Error(err, "failed to replay deferred writes")
+	}
+}
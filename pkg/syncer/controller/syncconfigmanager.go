@@ -18,11 +18,15 @@ package controller
 
 import (
 	"fmt"
+	"reflect"
+	"sort"
 	"sync"
 
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/klog/v2"
 
 	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+	"github.com/kubestellar/kubestellar/pkg/syncer/syncers"
 )
 
 type SyncConfigManager struct {
@@ -34,6 +38,7 @@ type SyncConfigManager struct {
 	indexedDownUnsyncedResources _indexedSyncedResources
 	indexedUpUnsyncedResources   _indexedSyncedResources
 	conversions                  []edgev1alpha1.EdgeSynConversion
+	changeHandlers               []func()
 }
 
 type _indexedSyncedResources struct {
@@ -50,12 +55,9 @@ func createIndexedSyncedResources(resources ...edgev1alpha1.EdgeSyncConfigResour
 		_, ok := isr.index[resource]
 		if !ok {
 			isr.index[resource] = true
+			isr.syncedResources = append(isr.syncedResources, resource)
 		}
 	}
-	isr.syncedResources = []edgev1alpha1.EdgeSyncConfigResource{}
-	for key := range isr.index {
-		isr.syncedResources = append(isr.syncedResources, key)
-	}
 	return isr
 }
 
@@ -76,8 +78,11 @@ func (s *SyncConfigManager) upsert(syncConfig edgev1alpha1.EdgeSyncConfig) {
 	defer s.Unlock()
 	newSyncConfig := copySyncConfigMap(s.syncConfigMap)
 	newSyncConfig[key] = syncConfig
-	s.refresh(newSyncConfig)
+	changed := s.refresh(newSyncConfig)
 	s.syncConfigMap = newSyncConfig
+	if changed {
+		s.notifyChange()
+	}
 }
 
 func (s *SyncConfigManager) delete(key string) {
@@ -86,16 +91,33 @@ func (s *SyncConfigManager) delete(key string) {
 	defer s.Unlock()
 	newSyncConfig := copySyncConfigMap(s.syncConfigMap)
 	delete(newSyncConfig, key)
-	s.refresh(newSyncConfig)
+	changed := s.refresh(newSyncConfig)
 	s.syncConfigMap = newSyncConfig
+	if changed {
+		s.notifyChange()
+	}
 }
 
-func (s *SyncConfigManager) refresh(newSyncConfig map[string]edgev1alpha1.EdgeSyncConfig) {
-	conversions := []edgev1alpha1.EdgeSynConversion{}
-	for _, _syncConfig := range s.syncConfigMap {
-		conversions = append(conversions, _syncConfig.Spec.Conversions...)
+// AddChangeHandler registers a func to call whenever the set of synced or unsynced resources,
+// or the set of conversions, changes.
+// The func is called while the SyncConfigManager is locked, so it must not call back into it.
+func (s *SyncConfigManager) AddChangeHandler(handler func()) {
+	s.Lock()
+	defer s.Unlock()
+	s.changeHandlers = append(s.changeHandlers, handler)
+}
+
+func (s *SyncConfigManager) notifyChange() {
+	for _, handler := range s.changeHandlers {
+		handler()
 	}
-	s.conversions = conversions
+}
+
+// refresh recomputes the indexes from the given new set of EdgeSyncConfigs
+// and returns whether anything changed.
+func (s *SyncConfigManager) refresh(newSyncConfig map[string]edgev1alpha1.EdgeSyncConfig) bool {
+	before := []interface{}{s.indexedDownSyncedResources.index, s.indexedDownUnsyncedResources.index, s.indexedUpSyncedResources.index, s.indexedUpUnsyncedResources.index, s.conversions}
+	s.conversions = collectConversions(newSyncConfig)
 
 	currentIndexedDownSyncedResources, currentIndexedUpSyncedResources := createIndexedDownAndUpSyncedResources(s.syncConfigMap)
 	newIndexedDownSyncedResources, newIndexedUpSyncedResources := createIndexedDownAndUpSyncedResources(newSyncConfig)
@@ -107,18 +129,48 @@ func (s *SyncConfigManager) refresh(newSyncConfig map[string]edgev1alpha1.EdgeSy
 	s.indexedUpUnsyncedResources = updateUnsyncedResources(s.indexedUpUnsyncedResources, currentIndexedUpSyncedResources, newIndexedUpSyncedResources)
 
 	s.logger.V(3).Info("refreshed syncConfigManager")
+	after := []interface{}{s.indexedDownSyncedResources.index, s.indexedDownUnsyncedResources.index, s.indexedUpSyncedResources.index, s.indexedUpUnsyncedResources.index, s.conversions}
+	return !reflect.DeepEqual(before, after)
 }
 
 func createIndexedDownAndUpSyncedResources(syncConfigMap map[string]edgev1alpha1.EdgeSyncConfig) (_indexedSyncedResources, _indexedSyncedResources) {
 	downSyncedResources := []edgev1alpha1.EdgeSyncConfigResource{}
 	upSyncedResources := []edgev1alpha1.EdgeSyncConfigResource{}
-	for _, _syncConfig := range syncConfigMap {
+	// Visit the EdgeSyncConfigs in order of name, so that the resulting order is stable
+	names := make([]string, 0, len(syncConfigMap))
+	for name := range syncConfigMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		_syncConfig := syncConfigMap[name]
 		downSyncedResources = append(downSyncedResources, _syncConfig.Spec.DownSyncedResources...)
 		upSyncedResources = append(upSyncedResources, _syncConfig.Spec.UpSyncedResources...)
 	}
 	return createIndexedSyncedResources(downSyncedResources...), createIndexedSyncedResources(upSyncedResources...)
 }
 
+// collectConversions returns the conversions of the given EdgeSyncConfigs,
+// without duplicates and in a stable order.
+func collectConversions(syncConfigMap map[string]edgev1alpha1.EdgeSyncConfig) []edgev1alpha1.EdgeSynConversion {
+	names := make([]string, 0, len(syncConfigMap))
+	for name := range syncConfigMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	conversions := []edgev1alpha1.EdgeSynConversion{}
+	seen := map[edgev1alpha1.EdgeSynConversion]bool{}
+	for _, name := range names {
+		for _, conversion := range syncConfigMap[name].Spec.Conversions {
+			if !seen[conversion] {
+				seen[conversion] = true
+				conversions = append(conversions, conversion)
+			}
+		}
+	}
+	return conversions
+}
+
 func updateUnsyncedResources(currentIndexedUnsyncedResources _indexedSyncedResources, currentIndexedSyncedResources _indexedSyncedResources, newIndexedSyncedResources _indexedSyncedResources) _indexedSyncedResources {
 	unsyncedResources := []edgev1alpha1.EdgeSyncConfigResource{}
 	// Current unsynced resources (exclude synced resources listed in new SyncConfig)
@@ -148,21 +200,41 @@ func copySyncConfigMap(syncConfigMap map[string]edgev1alpha1.EdgeSyncConfig) map
 }
 
 func (s *SyncConfigManager) GetDownSyncedResources() []edgev1alpha1.EdgeSyncConfigResource {
+	s.Lock()
+	defer s.Unlock()
 	return s.indexedDownSyncedResources.syncedResources
 }
 
 func (s *SyncConfigManager) GetUpSyncedResources() []edgev1alpha1.EdgeSyncConfigResource {
+	s.Lock()
+	defer s.Unlock()
 	return s.indexedUpSyncedResources.syncedResources
 }
 
 func (s *SyncConfigManager) GetDownUnsyncedResources() []edgev1alpha1.EdgeSyncConfigResource {
+	s.Lock()
+	defer s.Unlock()
 	return s.indexedDownUnsyncedResources.syncedResources
 }
 
 func (s *SyncConfigManager) GetUpUnsyncedResources() []edgev1alpha1.EdgeSyncConfigResource {
+	s.Lock()
+	defer s.Unlock()
 	return s.indexedUpUnsyncedResources.syncedResources
 }
 
+// IsDownsynced tells whether the given upstream object is in a resource that is downsynced.
+func (s *SyncConfigManager) IsDownsynced(object *unstructured.Unstructured) bool {
+	for _, resource := range s.GetDownSyncedResources() {
+		if matchesObject(syncers.ConvertToUpstream(resource, s.GetConversions()), object) {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *SyncConfigManager) GetConversions() []edgev1alpha1.EdgeSynConversion {
+	s.Lock()
+	defer s.Unlock()
 	return s.conversions
 }
@@ -0,0 +1,529 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+	"github.com/kubestellar/kubestellar/pkg/syncer/clientfactory"
+	"github.com/kubestellar/kubestellar/pkg/syncer/metrics"
+	"github.com/kubestellar/kubestellar/pkg/syncer/shared"
+	"github.com/kubestellar/kubestellar/pkg/syncer/syncers"
+)
+
+type syncAction string
+
+// crdEstablishedPollInterval is how long to wait before checking again whether
+// a CustomResourceDefinition has become Established.
+const crdEstablishedPollInterval = 2 * time.Second
+
+// informerSyncPollInterval is how long to wait before checking again whether
+// the informers that an item is read from have synced.
+const informerSyncPollInterval = time.Second
+
+const (
+	// syncActionRefresh re-reads the configuration, (re)starts and stops informers
+	// as needed, and enqueues a full sync of every configured resource.
+	// The full sync reads from the informer caches, not from the API servers.
+	syncActionRefresh  syncAction = "Refresh"
+	syncActionDownSync syncAction = "DownSync"
+	// syncActionDownUnsync releases the objects of a resource that is no longer downsynced.
+	syncActionDownUnsync syncAction = "DownUnsync"
+	syncActionBackStatus syncAction = "BackStatus"
+	syncActionUpSync     syncAction = "UpSync"
+)
+
+// syncQueueItem is an item in the work queue of the syncController.
+// The resource may have "*" for name and/or namespace,
+// in which case the item is processed with SyncMany rather than SyncOne.
+type syncQueueItem struct {
+	action   syncAction
+	resource edgev1alpha1.EdgeSyncConfigResource
+}
+
+type clusterSide string
+
+const (
+	upstreamSide   clusterSide = "upstream"
+	downstreamSide clusterSide = "downstream"
+)
+
+type informerKey struct {
+	side clusterSide
+	gk   schema.GroupKind
+}
+
+type runningInformer struct {
+	informer cache.SharedIndexInformer
+	stop     chan struct{}
+}
+
+// StatusSyncer is a SyncerInterface that also copies status back in bulk,
+// and releases the objects of the resources that are no longer synced.
+type StatusSyncer interface {
+	syncers.SyncerInterface
+	BackStatusMany(resource edgev1alpha1.EdgeSyncConfigResource, conversions []edgev1alpha1.EdgeSynConversion) error
+	UnsyncOne(resource edgev1alpha1.EdgeSyncConfigResource, conversions []edgev1alpha1.EdgeSynConversion) error
+	UnsyncMany(resource edgev1alpha1.EdgeSyncConfigResource, conversions []edgev1alpha1.EdgeSynConversion) error
+}
+
+// UpsyncingSyncer is a SyncerInterface that also relates the upsynced copies to the edge cluster
+// objects that they are copies of, and tells which edge cluster objects the selectors select.
+type UpsyncingSyncer interface {
+	syncers.SyncerInterface
+	EdgeNamespaceAndName(resource edgev1alpha1.EdgeSyncConfigResource, conversions []edgev1alpha1.EdgeSynConversion, upsyncedCopy *unstructured.Unstructured) (string, string)
+	Selects(resource edgev1alpha1.EdgeSyncConfigResource, obj *unstructured.Unstructured) (bool, error)
+}
+
+// NewSyncController returns a controller that drives the given syncers from
+// notifications by dynamic informers on the upstream and downstream clusters.
+// There is one informer per GroupKind on each side that the SyncConfigManager
+// says to sync; informers are started and stopped as that configuration changes.
+// The syncers read the objects from the caches of these informers.
+// Every resyncInterval the SyncerConfigManager is refreshed and a full sync of
+// every configured resource is done from those caches, as a safety net for missed notifications.
+func NewSyncController(
+	logger klog.Logger,
+	syncConfigManager *SyncConfigManager,
+	syncerConfigManager *SyncerConfigManager,
+	upstreamClientFactory clientfactory.ClientFactory,
+	downstreamClientFactory clientfactory.ClientFactory,
+	upSyncer UpsyncingSyncer,
+	downSyncer StatusSyncer,
+	resyncInterval time.Duration,
+) *syncController {
+	controllerName := "kubestellar-syncer-sync-controller"
+	c := &syncController{
+		name:                    controllerName,
+		logger:                  logger,
+		queue:                   workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName),
+		syncConfigManager:       syncConfigManager,
+		syncerConfigManager:     syncerConfigManager,
+		upstreamClientFactory:   upstreamClientFactory,
+		downstreamClientFactory: downstreamClientFactory,
+		upSyncer:                upSyncer,
+		downSyncer:              downSyncer,
+		resyncInterval:          resyncInterval,
+		informers:               map[informerKey]*runningInformer{},
+	}
+	syncConfigManager.AddChangeHandler(c.enqueueRefresh)
+	return c
+}
+
+type syncController struct {
+	name                    string
+	logger                  klog.Logger
+	queue                   workqueue.RateLimitingInterface
+	syncConfigManager       *SyncConfigManager
+	syncerConfigManager     *SyncerConfigManager
+	upstreamClientFactory   clientfactory.ClientFactory
+	downstreamClientFactory clientfactory.ClientFactory
+	upSyncer                UpsyncingSyncer
+	downSyncer              StatusSyncer
+	resyncInterval          time.Duration
+
+	informersLock sync.Mutex
+	informers     map[informerKey]*runningInformer
+	stopped       bool
+}
+
+// Run the controller workers and the periodic resync.
+func (c *syncController) Run(ctx context.Context, numThreads int) {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+	defer c.stopInformers()
+
+	logger := shared.WithReconciler(klog.FromContext(ctx), c.name)
+	ctx = klog.NewContext(ctx, logger)
+	logger.Info("Starting controller")
+	defer logger.Info("Shutting down controller")
+
+	for i := 0; i < numThreads; i++ {
+		go wait.UntilWithContext(ctx, c.runWorker, time.Second)
+	}
+	go wait.UntilWithContext(ctx, c.resync, c.resyncInterval)
+
+	<-ctx.Done()
+}
+
+func (c *syncController) resync(ctx context.Context) {
+	klog.FromContext(ctx).V(2).Info(fmt.Sprintf("Resync with interval: %v", c.resyncInterval))
+	if c.syncerConfigManager != nil {
+		c.syncerConfigManager.Refresh()
+	}
+	c.enqueueRefresh()
+}
+
+func (c *syncController) enqueueRefresh() {
+	c.queue.Add(syncQueueItem{action: syncActionRefresh})
+}
+
+func (c *syncController) runWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *syncController) processNextWorkItem(ctx context.Context) bool {
+	i, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	item := i.(syncQueueItem)
+
+	logger := klog.FromContext(ctx).WithValues("action", item.action, "resource", item.resource)
+	ctx = klog.NewContext(ctx, logger)
+	logger.V(4).Info("processing item")
+
+	// No matter what, tell the queue we're done with this item, to unblock
+	// other workers.
+	defer c.queue.Done(item)
+
+	if !c.informersSynced(item) {
+		logger.V(4).Info("waiting for informers to sync")
+		c.queue.AddAfter(item, informerSyncPollInterval)
+		return true
+	}
+
+	started := time.Now()
+	err := c.process(ctx, item)
+	metrics.ObserveSync(string(item.action), started, err)
+	if err != nil {
+		if clientfactory.IsApplyConflict(err) {
+			// Retrying will not resolve a conflict; it is retried only after the next change or resync
+			logger.Error(err, "conflict with another field manager, not retrying")
+			c.queue.Forget(item)
+			return true
+		}
+		if retryAfter, pending := syncers.DeletionPending(err); pending {
+			// The downstream informer's notification of the end of the deletion brings the item back;
+			// retrying after the deletion timeout runs out sees to the policy for unfinished deletions
+			logger.V(3).Info("waiting for deletion to finish in the edge cluster", "retryAfter", retryAfter)
+			c.queue.Forget(item)
+			if retryAfter > 0 {
+				c.queue.AddAfter(item, retryAfter)
+			}
+			return true
+		}
+		runtime.HandleError(fmt.Errorf("%q controller failed to process %v, err: %w", c.name, item, err))
+		c.queue.AddRateLimited(item)
+		return true
+	}
+
+	c.queue.Forget(item)
+	return true
+}
+
+func (c *syncController) process(ctx context.Context, item syncQueueItem) error {
+	conversions := c.syncConfigManager.GetConversions()
+	resource := item.resource
+	many := resource.Name == "*" || resource.Namespace == "*"
+	switch item.action {
+	case syncActionRefresh:
+		return c.refresh(ctx)
+	case syncActionDownSync:
+		if gk := downstreamGroupKind(resource, conversions); c.crdIsUnestablished(gk) {
+			klog.FromContext(ctx).V(3).Info("Waiting for CustomResourceDefinition to be Established", "groupKind", gk.String())
+			c.queue.AddAfter(item, crdEstablishedPollInterval)
+			return nil
+		}
+		if many {
+			return c.downSyncer.SyncMany(resource, conversions)
+		}
+		return c.downSyncer.SyncOne(resource, conversions)
+	case syncActionDownUnsync:
+		if many {
+			return c.downSyncer.UnsyncMany(resource, conversions)
+		}
+		return c.downSyncer.UnsyncOne(resource, conversions)
+	case syncActionBackStatus:
+		if many {
+			return c.downSyncer.BackStatusMany(resource, conversions)
+		}
+		return c.downSyncer.BackStatusOne(resource, conversions)
+	case syncActionUpSync:
+		if many {
+			return c.upSyncer.SyncMany(resource, conversions)
+		}
+		return c.upSyncer.SyncOne(resource, conversions)
+	}
+	return nil
+}
+
+// refresh brings the clients and informers in line with the current configuration
+// and enqueues a full sync of everything that is configured.
+func (c *syncController) refresh(ctx context.Context) error {
+	logger := klog.FromContext(ctx)
+	downSyncedResources := c.syncConfigManager.GetDownSyncedResources()
+	downUnsyncedResources := c.syncConfigManager.GetDownUnsyncedResources()
+	upSyncedResources := c.syncConfigManager.GetUpSyncedResources()
+	upUnsyncedResources := c.syncConfigManager.GetUpUnsyncedResources()
+	conversions := c.syncConfigManager.GetConversions()
+
+	errs := []error{}
+	if err := c.downSyncer.ReInitializeClients(downSyncedResources, conversions); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.upSyncer.ReInitializeClients(upSyncedResources, conversions); err != nil {
+		errs = append(errs, err)
+	}
+
+	wanted := map[informerKey]bool{}
+	for _, resources := range [][]edgev1alpha1.EdgeSyncConfigResource{downSyncedResources, upSyncedResources} {
+		for _, resource := range resources {
+			upstreamResource := syncers.ConvertToUpstream(resource, conversions)
+			downstreamResource := syncers.ConvertToDownstream(resource, conversions)
+			wanted[informerKey{side: upstreamSide, gk: schema.GroupKind{Group: upstreamResource.Group, Kind: upstreamResource.Kind}}] = true
+			wanted[informerKey{side: downstreamSide, gk: schema.GroupKind{Group: downstreamResource.Group, Kind: downstreamResource.Kind}}] = true
+		}
+	}
+	errs = append(errs, c.ensureInformers(logger, wanted)...)
+
+	// The downsyncs are enqueued in dependency order, and the no longer configured resources
+	// in the reverse order; an object whose dependencies are not there yet is retried.
+	for _, resource := range sortForApply(downSyncedResources, conversions) {
+		c.queue.Add(syncQueueItem{action: syncActionDownSync, resource: resource})
+	}
+	for _, resource := range sortForDelete(downUnsyncedResources, conversions) {
+		c.queue.Add(syncQueueItem{action: syncActionDownUnsync, resource: resource})
+	}
+	for _, resource := range downSyncedResources {
+		c.queue.Add(syncQueueItem{action: syncActionBackStatus, resource: resource})
+	}
+	for _, resource := range upSyncedResources {
+		c.queue.Add(syncQueueItem{action: syncActionUpSync, resource: resource})
+	}
+	for _, resource := range upUnsyncedResources {
+		c.queue.Add(syncQueueItem{action: syncActionUpSync, resource: resource})
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// informersSynced tells whether the running informers that the given item is read from have synced.
+// A kind without a running informer is read from the API server.
+func (c *syncController) informersSynced(item syncQueueItem) bool {
+	if item.action == syncActionRefresh {
+		return true
+	}
+	conversions := c.syncConfigManager.GetConversions()
+	upstreamResource := syncers.ConvertToUpstream(item.resource, conversions)
+	downstreamResource := syncers.ConvertToDownstream(item.resource, conversions)
+	c.informersLock.Lock()
+	defer c.informersLock.Unlock()
+	for _, key := range []informerKey{
+		{side: upstreamSide, gk: schema.GroupKind{Group: upstreamResource.Group, Kind: upstreamResource.Kind}},
+		{side: downstreamSide, gk: schema.GroupKind{Group: downstreamResource.Group, Kind: downstreamResource.Kind}},
+	} {
+		if running, ok := c.informers[key]; ok && !running.informer.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+// crdIsUnestablished tells whether the given kind is defined by a downstream CustomResourceDefinition
+// that is not Established yet. Only the CustomResourceDefinitions that are synced are considered,
+// from the cache of the downstream informer on them.
+func (c *syncController) crdIsUnestablished(gk schema.GroupKind) bool {
+	if rankOf(gk) <= rankNamespacesAndCRDs {
+		return false
+	}
+	c.informersLock.Lock()
+	running, ok := c.informers[informerKey{side: downstreamSide, gk: crdGroupKind}]
+	c.informersLock.Unlock()
+	if !ok {
+		return false
+	}
+	crds := []unstructured.Unstructured{}
+	for _, obj := range running.informer.GetStore().List() {
+		if crd, ok := obj.(*unstructured.Unstructured); ok {
+			crds = append(crds, *crd)
+		}
+	}
+	return unestablishedKinds(crds)[gk]
+}
+
+// ensureInformers starts the wanted informers that are not running and stops the running ones that are not wanted.
+func (c *syncController) ensureInformers(logger klog.Logger, wanted map[informerKey]bool) []error {
+	c.informersLock.Lock()
+	defer c.informersLock.Unlock()
+	if c.stopped {
+		return nil
+	}
+	errs := []error{}
+	for key, running := range c.informers {
+		if !wanted[key] {
+			logger.V(2).Info(fmt.Sprintf("stop %s informer for %s", key.side, key.gk.String()))
+			c.stopInformerLocked(key, running)
+		}
+	}
+	for key := range wanted {
+		if _, ok := c.informers[key]; ok {
+			continue
+		}
+		clientFactory := c.clientFactoryFor(key.side)
+		informer, err := clientFactory.NewInformer(key.gk.Group, key.gk.Kind, 0)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to create %s informer for %s: %w", key.side, key.gk.String(), err))
+			continue
+		}
+		key := key
+		informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { c.enqueueForObject(key.side, nil, obj, false) },
+			UpdateFunc: func(old, obj interface{}) { c.enqueueForObject(key.side, old, obj, false) },
+			DeleteFunc: func(obj interface{}) { c.enqueueForObject(key.side, nil, obj, true) },
+		})
+		running := &runningInformer{informer: informer, stop: make(chan struct{})}
+		c.informers[key] = running
+		logger.V(2).Info(fmt.Sprintf("start %s informer for %s", key.side, key.gk.String()))
+		go informer.Run(running.stop)
+	}
+	return errs
+}
+
+func (c *syncController) stopInformers() {
+	c.informersLock.Lock()
+	defer c.informersLock.Unlock()
+	c.stopped = true
+	for key, running := range c.informers {
+		c.stopInformerLocked(key, running)
+	}
+}
+
+// stopInformerLocked stops the given informer, after which the syncers read its objects from the API server.
+// The caller holds informersLock.
+func (c *syncController) stopInformerLocked(key informerKey, running *runningInformer) {
+	clientFactory := c.clientFactoryFor(key.side)
+	clientFactory.RemoveInformer(running.informer)
+	close(running.stop)
+	delete(c.informers, key)
+}
+
+func (c *syncController) clientFactoryFor(side clusterSide) clientfactory.ClientFactory {
+	if side == downstreamSide {
+		return c.downstreamClientFactory
+	}
+	return c.upstreamClientFactory
+}
+
+// enqueueForObject enqueues the work implied by a notification about the given object
+// from an informer on the given side. The old object is the one replaced by an update, otherwise nil.
+func (c *syncController) enqueueForObject(side clusterSide, old, obj interface{}, deleted bool) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	object, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("unexpected object type %T", obj))
+		return
+	}
+	conversions := c.syncConfigManager.GetConversions()
+	convert := syncers.ConvertToUpstream
+	if side == downstreamSide {
+		convert = syncers.ConvertToDownstream
+	}
+	for _, resource := range c.syncConfigManager.GetDownSyncedResources() {
+		if !matchesObject(convert(resource, conversions), object) {
+			continue
+		}
+		if side == upstreamSide || deleted {
+			c.queue.Add(syncQueueItem{action: syncActionDownSync, resource: narrowToObject(resource, object)})
+		}
+		if side == downstreamSide && !deleted {
+			c.queue.Add(syncQueueItem{action: syncActionBackStatus, resource: narrowToObject(resource, object)})
+		}
+	}
+	oldObject, _ := old.(*unstructured.Unstructured)
+	for _, resource := range c.syncConfigManager.GetUpSyncedResources() {
+		if !matchesGroupKind(convert(resource, conversions), object) {
+			continue
+		}
+		if side == upstreamSide {
+			if !deleted {
+				continue
+			}
+			// The upsynced copy may be named differently from the edge cluster object it is a copy of
+			namespace, name := c.upSyncer.EdgeNamespaceAndName(resource, conversions, object)
+			if matchesNamespaceAndName(resource, namespace, name) {
+				c.queue.Add(syncQueueItem{action: syncActionUpSync, resource: narrowTo(resource, namespace, name)})
+			}
+			continue
+		}
+		if !matchesNamespaceAndName(resource, object.GetNamespace(), object.GetName()) {
+			continue
+		}
+		// An object that an update takes out of the selection is synced, so that its copy goes away
+		if c.upsyncSelects(resource, object) || oldObject != nil && c.upsyncSelects(resource, oldObject) {
+			c.queue.Add(syncQueueItem{action: syncActionUpSync, resource: narrowToObject(resource, object)})
+		}
+	}
+	shared.WithQueueKey(c.logger, object.GetNamespace()+"/"+object.GetName()).V(4).Info(fmt.Sprintf("handled %s notification for %s", side, object.GroupVersionKind().GroupKind().String()))
+}
+
+// upsyncSelects tells whether the selectors of the given upsynced resource select the given edge cluster object.
+// When they can not be evaluated, the object is taken to be selected and the syncing reports the problem.
+func (c *syncController) upsyncSelects(resource edgev1alpha1.EdgeSyncConfigResource, object *unstructured.Unstructured) bool {
+	selected, err := c.upSyncer.Selects(resource, object)
+	return selected || err != nil
+}
+
+// matchesObject tells whether the given object is in the set of objects described by the given resource,
+// not considering the selectors.
+func matchesObject(resource edgev1alpha1.EdgeSyncConfigResource, object *unstructured.Unstructured) bool {
+	return matchesGroupKind(resource, object) && matchesNamespaceAndName(resource, object.GetNamespace(), object.GetName())
+}
+
+// matchesGroupKind tells whether the given object is of the group and kind of the given resource.
+func matchesGroupKind(resource edgev1alpha1.EdgeSyncConfigResource, object *unstructured.Unstructured) bool {
+	gvk := object.GroupVersionKind()
+	return resource.Group == gvk.Group && resource.Kind == gvk.Kind
+}
+
+// matchesNamespaceAndName tells whether the given resource describes objects with the given namespace and name.
+func matchesNamespaceAndName(resource edgev1alpha1.EdgeSyncConfigResource, namespace, name string) bool {
+	return (resource.Name == "*" || resource.Name == name) &&
+		(resource.Namespace == "*" || resource.Namespace == namespace)
+}
+
+// narrowToObject replaces the wildcards in the given resource with the name and namespace of the given object.
+// The group, kind and version are kept, because they may differ from the object's under a conversion.
+func narrowToObject(resource edgev1alpha1.EdgeSyncConfigResource, object *unstructured.Unstructured) edgev1alpha1.EdgeSyncConfigResource {
+	return narrowTo(resource, object.GetNamespace(), object.GetName())
+}
+
+// narrowTo replaces the wildcards in the given resource with the given namespace and name.
+func narrowTo(resource edgev1alpha1.EdgeSyncConfigResource, namespace, name string) edgev1alpha1.EdgeSyncConfigResource {
+	if resource.Name == "*" {
+		resource.Name = name
+	}
+	if resource.Namespace == "*" {
+		resource.Namespace = namespace
+	}
+	return resource
+}
@@ -0,0 +1,1064 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clientset "k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/klog/v2"
+
+	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+	"github.com/kubestellar/kubestellar/pkg/syncer/clientfactory"
+	"github.com/kubestellar/kubestellar/pkg/syncer/localstore"
+	"github.com/kubestellar/kubestellar/pkg/syncer/syncers"
+)
+
+var configMapGVR = corev1.SchemeGroupVersion.WithResource("configmaps")
+var namespaceGVR = corev1.SchemeGroupVersion.WithResource("namespaces")
+
+func TestSyncControllerFollowsNotifications(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := klog.FromContext(ctx)
+
+	upstreamDynamicClient := dynamicfake.NewSimpleDynamicClient(scheme, configMap("default", "cm-1", "a"))
+	upstreamDiscoveryClient := clientset.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+	upstreamDiscoveryClient.Resources = testAPIResourceList
+	upstreamClientFactory, err := clientfactory.NewClientFactory(logger, upstreamDynamicClient, upstreamDiscoveryClient)
+	require.NoError(t, err)
+	upstreamClientFactory.SetFieldManager(clientfactory.FieldManagerForSyncTarget("test"))
+
+	downstreamDynamicClient := dynamicfake.NewSimpleDynamicClient(scheme)
+	addApplyReactor(downstreamDynamicClient)
+	downstreamDiscoveryClient := clientset.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+	downstreamDiscoveryClient.Resources = testAPIResourceList
+	downstreamClientFactory, err := clientfactory.NewClientFactory(logger, downstreamDynamicClient, downstreamDiscoveryClient)
+	require.NoError(t, err)
+	downstreamClientFactory.SetFieldManager(clientfactory.FieldManagerForSyncTarget("test"))
+
+	upSyncer, err := syncers.NewUpSyncer(logger, upstreamClientFactory, downstreamClientFactory, []edgev1alpha1.EdgeSyncConfigResource{}, []edgev1alpha1.EdgeSynConversion{})
+	require.NoError(t, err)
+	downSyncer, err := syncers.NewDownSyncer(logger, upstreamClientFactory, downstreamClientFactory, []edgev1alpha1.EdgeSyncConfigResource{}, []edgev1alpha1.EdgeSynConversion{})
+	require.NoError(t, err)
+	syncStatusStore := syncers.NewSyncStatusStore()
+	downSyncer.SetStatusStore(syncStatusStore)
+
+	syncConfigManager := NewSyncConfigManager(logger)
+	// The resync interval is long enough that only notifications can explain the syncing below, after the initial one.
+	controller := NewSyncController(logger, syncConfigManager, nil, upstreamClientFactory, downstreamClientFactory, upSyncer, downSyncer, time.Hour)
+	syncConfigManager.upsert(edgev1alpha1.EdgeSyncConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-sync-config"},
+		Spec: edgev1alpha1.EdgeSyncConfigSpec{
+			DownSyncedResources: []edgev1alpha1.EdgeSyncConfigResource{
+				{Group: "", Version: "v1", Kind: "ConfigMap", Namespace: "default", Name: "*"},
+			},
+		},
+	})
+	go controller.Run(ctx, 1)
+
+	eventuallyDownstreamData(t, downstreamDynamicClient, "cm-1", "a")
+	require.Eventually(t, func() bool {
+		statuses := syncStatusStore.List()
+		return len(statuses) == 1 && statuses[0].Name == "cm-1" && statuses[0].Outcome == edgev1alpha1.SyncOutcomeSucceeded
+	}, wait.ForeverTestTimeout, 100*time.Millisecond)
+
+	_, err = upstreamDynamicClient.Resource(configMapGVR).Namespace("default").Create(ctx, configMap("default", "cm-2", "b"), metav1.CreateOptions{})
+	require.NoError(t, err)
+	eventuallyDownstreamData(t, downstreamDynamicClient, "cm-2", "b")
+
+	_, err = upstreamDynamicClient.Resource(configMapGVR).Namespace("default").Update(ctx, configMap("default", "cm-2", "c"), metav1.UpdateOptions{})
+	require.NoError(t, err)
+	eventuallyDownstreamData(t, downstreamDynamicClient, "cm-2", "c")
+
+	err = downstreamDynamicClient.Resource(configMapGVR).Namespace("default").Delete(ctx, "cm-1", metav1.DeleteOptions{})
+	require.NoError(t, err)
+	eventuallyDownstreamData(t, downstreamDynamicClient, "cm-1", "a")
+
+	err = upstreamDynamicClient.Resource(configMapGVR).Namespace("default").Delete(ctx, "cm-2", metav1.DeleteOptions{})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		_, err := downstreamDynamicClient.Resource(configMapGVR).Namespace("default").Get(ctx, "cm-2", metav1.GetOptions{})
+		return errors.IsNotFound(err)
+	}, wait.ForeverTestTimeout, 100*time.Millisecond)
+	require.Eventually(t, func() bool {
+		statuses := syncStatusStore.List()
+		return len(statuses) == 1 && statuses[0].Name == "cm-1"
+	}, wait.ForeverTestTimeout, 100*time.Millisecond)
+
+	syncConfigManager.delete("test-sync-config")
+	require.Eventually(t, func() bool {
+		controller.informersLock.Lock()
+		defer controller.informersLock.Unlock()
+		return len(controller.informers) == 0
+	}, wait.ForeverTestTimeout, 100*time.Millisecond)
+}
+
+func TestSyncControllerReadsFromInformerCaches(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := klog.FromContext(ctx)
+
+	upstreamDynamicClient := dynamicfake.NewSimpleDynamicClient(scheme, configMap("default", "cm-1", "a"))
+	upstreamDiscoveryClient := clientset.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+	upstreamDiscoveryClient.Resources = testAPIResourceList
+	upstreamClientFactory, err := clientfactory.NewClientFactory(logger, upstreamDynamicClient, upstreamDiscoveryClient)
+	require.NoError(t, err)
+	upstreamClientFactory.SetFieldManager(clientfactory.FieldManagerForSyncTarget("test"))
+
+	downstreamDynamicClient := dynamicfake.NewSimpleDynamicClient(scheme)
+	addApplyReactor(downstreamDynamicClient)
+	downstreamDiscoveryClient := clientset.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+	downstreamDiscoveryClient.Resources = testAPIResourceList
+	downstreamClientFactory, err := clientfactory.NewClientFactory(logger, downstreamDynamicClient, downstreamDiscoveryClient)
+	require.NoError(t, err)
+	downstreamClientFactory.SetFieldManager(clientfactory.FieldManagerForSyncTarget("test"))
+
+	upSyncer, err := syncers.NewUpSyncer(logger, upstreamClientFactory, downstreamClientFactory, []edgev1alpha1.EdgeSyncConfigResource{}, []edgev1alpha1.EdgeSynConversion{})
+	require.NoError(t, err)
+	downSyncer, err := syncers.NewDownSyncer(logger, upstreamClientFactory, downstreamClientFactory, []edgev1alpha1.EdgeSyncConfigResource{}, []edgev1alpha1.EdgeSynConversion{})
+	require.NoError(t, err)
+
+	resource := edgev1alpha1.EdgeSyncConfigResource{Group: "", Version: "v1", Kind: "ConfigMap", Namespace: "default", Name: "*"}
+	syncConfigManager := NewSyncConfigManager(logger)
+	controller := NewSyncController(logger, syncConfigManager, nil, upstreamClientFactory, downstreamClientFactory, upSyncer, downSyncer, time.Hour)
+	syncConfigManager.upsert(edgev1alpha1.EdgeSyncConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-sync-config"},
+		Spec:       edgev1alpha1.EdgeSyncConfigSpec{DownSyncedResources: []edgev1alpha1.EdgeSyncConfigResource{resource}},
+	})
+	go controller.Run(ctx, 1)
+	eventuallyDownstreamData(t, downstreamDynamicClient, "cm-1", "a")
+	require.Eventually(t, func() bool {
+		return controller.informersSynced(syncQueueItem{action: syncActionDownSync, resource: resource})
+	}, wait.ForeverTestTimeout, 100*time.Millisecond)
+
+	// What a resync and a notification do reads only the informer caches
+	upstreamDynamicClient.ClearActions()
+	downstreamDynamicClient.ClearActions()
+	require.NoError(t, downSyncer.SyncMany(resource, nil))
+	require.NoError(t, downSyncer.BackStatusMany(resource, nil))
+	require.NoError(t, downSyncer.SyncOne(edgev1alpha1.EdgeSyncConfigResource{Version: "v1", Kind: "ConfigMap", Namespace: "default", Name: "cm-1"}, nil))
+	for _, client := range []*dynamicfake.FakeDynamicClient{upstreamDynamicClient, downstreamDynamicClient} {
+		for _, action := range client.Actions() {
+			require.NotContains(t, []string{"get", "list"}, action.GetVerb(), "%s of %s went to the API server", action.GetVerb(), action.GetResource())
+		}
+	}
+}
+
+// addApplyReactor makes the fake client handle server-side apply patches,
+// approximately: the applied object replaces the existing one, if any.
+func addApplyReactor(client *dynamicfake.FakeDynamicClient) {
+	client.PrependReactor("patch", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		patchAction := action.(clienttesting.PatchAction)
+		if patchAction.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(patchAction.GetPatch()); err != nil {
+			return true, nil, err
+		}
+		gvr, ns := patchAction.GetResource(), patchAction.GetNamespace()
+		existing, err := client.Tracker().Get(gvr, ns, patchAction.GetName())
+		if errors.IsNotFound(err) {
+			return true, obj, client.Tracker().Create(gvr, obj, ns)
+		} else if err != nil {
+			return true, nil, err
+		}
+		existingMeta, err := meta.Accessor(existing)
+		if err != nil {
+			return true, nil, err
+		}
+		obj.SetResourceVersion(existingMeta.GetResourceVersion())
+		return true, obj, client.Tracker().Update(gvr, obj, ns)
+	})
+}
+
+// addFinalizerApplyReactor makes the given fake client take server-side applies of finalizers,
+// which the fake does not support, by adding the applied finalizers to the existing object.
+func addFinalizerApplyReactor(client *dynamicfake.FakeDynamicClient) {
+	client.PrependReactor("patch", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		patchAction := action.(clienttesting.PatchAction)
+		if patchAction.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+		applied := &unstructured.Unstructured{}
+		if err := applied.UnmarshalJSON(patchAction.GetPatch()); err != nil {
+			return true, nil, err
+		}
+		gvr, ns := patchAction.GetResource(), patchAction.GetNamespace()
+		existing, err := client.Tracker().Get(gvr, ns, patchAction.GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		existingMeta, err := meta.Accessor(existing)
+		if err != nil {
+			return true, nil, err
+		}
+		finalizers := existingMeta.GetFinalizers()
+		for _, finalizer := range applied.GetFinalizers() {
+			if !sets.NewString(finalizers...).Has(finalizer) {
+				finalizers = append(finalizers, finalizer)
+			}
+		}
+		existingMeta.SetFinalizers(finalizers)
+		return true, existing, client.Tracker().Update(gvr, existing, ns)
+	})
+}
+
+func configMap(namespace, name, data string) *unstructured.Unstructured {
+	cm := &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Data:       map[string]string{"key": data},
+	}
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cm)
+	if err != nil {
+		panic(err)
+	}
+	return &unstructured.Unstructured{Object: object}
+}
+
+func eventuallyDownstreamData(t *testing.T, client dynamic.Interface, name, data string) {
+	require.Eventually(t, func() bool {
+		cm, err := client.Resource(configMapGVR).Namespace("default").Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			return false
+		}
+		value, _, _ := unstructured.NestedString(cm.Object, "data", "key")
+		return value == data
+	}, wait.ForeverTestTimeout, 100*time.Millisecond)
+}
+
+func TestDenaturedObjectsRoundTrip(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := klog.FromContext(ctx)
+
+	denaturedGVR := schema.GroupVersionResource{Group: "denatured.edge.kubestellar.io", Version: "v1", Resource: "configmaps"}
+	denatured := configMap("default", "cm-1", "a")
+	denatured.SetAPIVersion(denaturedGVR.GroupVersion().String())
+	upstreamDynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme,
+		map[schema.GroupVersionResource]string{denaturedGVR: "ConfigMapList"}, denatured)
+	upstreamDiscoveryClient := clientset.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+	upstreamDiscoveryClient.Resources = append(testAPIResourceList, &metav1.APIResourceList{
+		GroupVersion: denaturedGVR.GroupVersion().String(),
+		APIResources: []metav1.APIResource{{Name: "configmaps", Namespaced: true, Kind: "ConfigMap"}},
+	})
+	upstreamClientFactory, err := clientfactory.NewClientFactory(logger, upstreamDynamicClient, upstreamDiscoveryClient)
+	require.NoError(t, err)
+	upstreamClientFactory.SetFieldManager(clientfactory.FieldManagerForSyncTarget("test"))
+
+	downstreamDynamicClient := dynamicfake.NewSimpleDynamicClient(scheme)
+	addApplyReactor(downstreamDynamicClient)
+	downstreamDiscoveryClient := clientset.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+	downstreamDiscoveryClient.Resources = testAPIResourceList
+	downstreamClientFactory, err := clientfactory.NewClientFactory(logger, downstreamDynamicClient, downstreamDiscoveryClient)
+	require.NoError(t, err)
+	downstreamClientFactory.SetFieldManager(clientfactory.FieldManagerForSyncTarget("test"))
+
+	resource := edgev1alpha1.EdgeSyncConfigResource{Group: denaturedGVR.Group, Version: "v1", Kind: "ConfigMap", Namespace: "default", Name: "*"}
+	conversions := []edgev1alpha1.EdgeSynConversion{{
+		Upstream:   edgev1alpha1.EdgeSyncConfigResource{Group: denaturedGVR.Group, Kind: "ConfigMap"},
+		Downstream: edgev1alpha1.EdgeSyncConfigResource{Group: "", Kind: "ConfigMap"},
+	}}
+	downSyncer, err := syncers.NewDownSyncer(logger, upstreamClientFactory, downstreamClientFactory, []edgev1alpha1.EdgeSyncConfigResource{resource}, conversions)
+	require.NoError(t, err)
+	downSyncer.SetStatusStore(syncers.NewSyncStatusStore())
+
+	require.NoError(t, downSyncer.SyncMany(resource, conversions))
+	natured, err := downstreamDynamicClient.Resource(configMapGVR).Namespace("default").Get(ctx, "cm-1", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "v1", natured.GetAPIVersion())
+
+	natured.Object["status"] = map[string]interface{}{"phase": "Happy"}
+	_, err = downstreamDynamicClient.Resource(configMapGVR).Namespace("default").Update(ctx, natured, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, downSyncer.BackStatusMany(resource, conversions))
+	returned, err := upstreamDynamicClient.Resource(denaturedGVR).Namespace("default").Get(ctx, "cm-1", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, denaturedGVR.GroupVersion().String(), returned.GetAPIVersion())
+	require.Equal(t, "cm-1", returned.GetName())
+	phase, _, _ := unstructured.NestedString(returned.Object, "status", "phase")
+	require.Equal(t, "Happy", phase)
+}
+
+func TestDownsyncDrift(t *testing.T) {
+	for _, policy := range []edgev1alpha1.DriftPolicy{edgev1alpha1.DriftPolicyEnforce, edgev1alpha1.DriftPolicyReport} {
+		t.Run(string(policy), func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			logger := klog.FromContext(ctx)
+
+			upstreamDynamicClient := dynamicfake.NewSimpleDynamicClient(scheme, configMap("default", "cm-1", "a"))
+			upstreamDiscoveryClient := clientset.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+			upstreamDiscoveryClient.Resources = testAPIResourceList
+			upstreamClientFactory, err := clientfactory.NewClientFactory(logger, upstreamDynamicClient, upstreamDiscoveryClient)
+			require.NoError(t, err)
+			upstreamClientFactory.SetFieldManager(clientfactory.FieldManagerForSyncTarget("test"))
+
+			downstreamDynamicClient := dynamicfake.NewSimpleDynamicClient(scheme)
+			addApplyReactor(downstreamDynamicClient)
+			downstreamDiscoveryClient := clientset.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+			downstreamDiscoveryClient.Resources = testAPIResourceList
+			downstreamClientFactory, err := clientfactory.NewClientFactory(logger, downstreamDynamicClient, downstreamDiscoveryClient)
+			require.NoError(t, err)
+			downstreamClientFactory.SetFieldManager(clientfactory.FieldManagerForSyncTarget("test"))
+
+			resource := edgev1alpha1.EdgeSyncConfigResource{Group: "", Version: "v1", Kind: "ConfigMap", Namespace: "default", Name: "*"}
+			downSyncer, err := syncers.NewDownSyncer(logger, upstreamClientFactory, downstreamClientFactory, []edgev1alpha1.EdgeSyncConfigResource{resource}, nil)
+			require.NoError(t, err)
+			syncStatusStore := syncers.NewSyncStatusStore()
+			downSyncer.SetStatusStore(syncStatusStore)
+			downSyncer.SetDriftPolicies(func(gr schema.GroupResource) edgev1alpha1.DriftPolicy {
+				require.Equal(t, schema.GroupResource{Resource: "configmaps"}, gr)
+				return policy
+			})
+			onlyStatus := func() edgev1alpha1.SyncedObjectStatus {
+				statuses := syncStatusStore.List()
+				require.Len(t, statuses, 1)
+				return statuses[0]
+			}
+			setData := func(client dynamic.Interface, data string) {
+				cm, err := client.Resource(configMapGVR).Namespace("default").Get(ctx, "cm-1", metav1.GetOptions{})
+				require.NoError(t, err)
+				require.NoError(t, unstructured.SetNestedField(cm.Object, data, "data", "key"))
+				_, err = client.Resource(configMapGVR).Namespace("default").Update(ctx, cm, metav1.UpdateOptions{})
+				require.NoError(t, err)
+			}
+
+			require.NoError(t, downSyncer.SyncMany(resource, nil))
+			eventuallyDownstreamData(t, downstreamDynamicClient, "cm-1", "a")
+			require.Equal(t, edgev1alpha1.SyncOutcomeSucceeded, onlyStatus().Outcome)
+
+			// A change upstream is not drift
+			setData(upstreamDynamicClient, "b")
+			require.NoError(t, downSyncer.SyncMany(resource, nil))
+			eventuallyDownstreamData(t, downstreamDynamicClient, "cm-1", "b")
+			require.Empty(t, onlyStatus().DriftedFields)
+
+			// A change in the edge cluster is
+			setData(downstreamDynamicClient, "edited")
+			require.NoError(t, downSyncer.SyncMany(resource, nil))
+			status := onlyStatus()
+			require.Equal(t, []string{"data.key"}, status.DriftedFields)
+			if policy == edgev1alpha1.DriftPolicyEnforce {
+				eventuallyDownstreamData(t, downstreamDynamicClient, "cm-1", "b")
+				require.Equal(t, edgev1alpha1.SyncOutcomeSucceeded, status.Outcome)
+			} else {
+				eventuallyDownstreamData(t, downstreamDynamicClient, "cm-1", "edited")
+				require.Equal(t, edgev1alpha1.SyncOutcomeDrifted, status.Outcome)
+			}
+
+			// The same goes for syncing one object
+			require.NoError(t, downSyncer.SyncOne(edgev1alpha1.EdgeSyncConfigResource{Group: "", Version: "v1", Kind: "ConfigMap", Namespace: "default", Name: "cm-1"}, nil))
+			if policy == edgev1alpha1.DriftPolicyEnforce {
+				require.Empty(t, onlyStatus().DriftedFields)
+			} else {
+				require.Equal(t, edgev1alpha1.SyncOutcomeDrifted, onlyStatus().Outcome)
+				eventuallyDownstreamData(t, downstreamDynamicClient, "cm-1", "edited")
+			}
+		})
+	}
+}
+
+func TestDownsyncIgnoreDifferences(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := klog.FromContext(ctx)
+
+	cm := configMap("default", "cm-1", "a")
+	require.NoError(t, unstructured.SetNestedField(cm.Object, "x", "data", "other"))
+	upstreamDynamicClient := dynamicfake.NewSimpleDynamicClient(scheme, cm)
+	upstreamDiscoveryClient := clientset.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+	upstreamDiscoveryClient.Resources = testAPIResourceList
+	upstreamClientFactory, err := clientfactory.NewClientFactory(logger, upstreamDynamicClient, upstreamDiscoveryClient)
+	require.NoError(t, err)
+	upstreamClientFactory.SetFieldManager(clientfactory.FieldManagerForSyncTarget("test"))
+
+	downstreamDynamicClient := dynamicfake.NewSimpleDynamicClient(scheme)
+	addApplyReactor(downstreamDynamicClient)
+	downstreamDiscoveryClient := clientset.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+	downstreamDiscoveryClient.Resources = testAPIResourceList
+	downstreamClientFactory, err := clientfactory.NewClientFactory(logger, downstreamDynamicClient, downstreamDiscoveryClient)
+	require.NoError(t, err)
+	downstreamClientFactory.SetFieldManager(clientfactory.FieldManagerForSyncTarget("test"))
+
+	resource := edgev1alpha1.EdgeSyncConfigResource{Group: "", Version: "v1", Kind: "ConfigMap", Namespace: "default", Name: "*"}
+	downSyncer, err := syncers.NewDownSyncer(logger, upstreamClientFactory, downstreamClientFactory, []edgev1alpha1.EdgeSyncConfigResource{resource}, nil)
+	require.NoError(t, err)
+	syncStatusStore := syncers.NewSyncStatusStore()
+	downSyncer.SetStatusStore(syncStatusStore)
+	downSyncer.SetIgnoreDifferences(func(gr schema.GroupResource) []edgev1alpha1.IgnoreDifferences {
+		return []edgev1alpha1.IgnoreDifferences{
+			{GroupResource: metav1.GroupResource{Resource: "configmaps"}, JSONPath: "$.data.key"},
+			{GroupResource: metav1.GroupResource{Resource: "configmaps"}, ManagedFieldsManager: "local-operator"},
+		}
+	})
+	require.NoError(t, downSyncer.SyncMany(resource, nil))
+	eventuallyDownstreamData(t, downstreamDynamicClient, "cm-1", "a")
+
+	// Change both fields in the edge cluster, one of them by a field manager that owns it
+	edgeCM, err := downstreamDynamicClient.Resource(configMapGVR).Namespace("default").Get(ctx, "cm-1", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NoError(t, unstructured.SetNestedField(edgeCM.Object, "edge", "data", "key"))
+	require.NoError(t, unstructured.SetNestedField(edgeCM.Object, "operated", "data", "other"))
+	edgeCM.SetManagedFields([]metav1.ManagedFieldsEntry{{
+		Manager:    "local-operator",
+		Operation:  metav1.ManagedFieldsOperationUpdate,
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:data":{"f:other":{}}}`)},
+	}})
+	_, err = downstreamDynamicClient.Resource(configMapGVR).Namespace("default").Update(ctx, edgeCM, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// An upstream change is delivered without overwriting the ignored fields
+	upCM, err := upstreamDynamicClient.Resource(configMapGVR).Namespace("default").Get(ctx, "cm-1", metav1.GetOptions{})
+	require.NoError(t, err)
+	upCM.SetLabels(map[string]string{"changed": "yes"})
+	_, err = upstreamDynamicClient.Resource(configMapGVR).Namespace("default").Update(ctx, upCM, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, downSyncer.SyncMany(resource, nil))
+	edgeCM, err = downstreamDynamicClient.Resource(configMapGVR).Namespace("default").Get(ctx, "cm-1", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "yes", edgeCM.GetLabels()["changed"])
+	data, _, _ := unstructured.NestedStringMap(edgeCM.Object, "data")
+	require.Equal(t, map[string]string{"key": "edge", "other": "operated"}, data)
+	require.Empty(t, syncStatusStore.List()[0].DriftedFields)
+}
+
+func TestDownsyncDeletionFinalizer(t *testing.T) {
+	const finalizer = "workload.kcp.io/syncer-test"
+	for _, blocked := range []bool{false, true} {
+		t.Run(fmt.Sprintf("blocked=%v", blocked), func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			logger := klog.FromContext(ctx)
+
+			upstreamDynamicClient := dynamicfake.NewSimpleDynamicClient(scheme, configMap("default", "cm-1", "a"))
+			addFinalizerApplyReactor(upstreamDynamicClient)
+			upstreamDiscoveryClient := clientset.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+			upstreamDiscoveryClient.Resources = testAPIResourceList
+			upstreamClientFactory, err := clientfactory.NewClientFactory(logger, upstreamDynamicClient, upstreamDiscoveryClient)
+			require.NoError(t, err)
+			upstreamClientFactory.SetFieldManager(clientfactory.FieldManagerForSyncTarget("test"))
+
+			downstreamDynamicClient := dynamicfake.NewSimpleDynamicClient(scheme)
+			addApplyReactor(downstreamDynamicClient)
+			// When blocked, deletes leave the object in place and terminating, as a finalizer would
+			downstreamDynamicClient.PrependReactor("delete", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				if !blocked {
+					return false, nil, nil
+				}
+				deleteAction := action.(clienttesting.DeleteAction)
+				obj, err := downstreamDynamicClient.Tracker().Get(deleteAction.GetResource(), deleteAction.GetNamespace(), deleteAction.GetName())
+				if err != nil {
+					return true, nil, err
+				}
+				objMeta, err := meta.Accessor(obj)
+				if err != nil {
+					return true, nil, err
+				}
+				now := metav1.Now()
+				objMeta.SetDeletionTimestamp(&now)
+				return true, nil, downstreamDynamicClient.Tracker().Update(deleteAction.GetResource(), obj, deleteAction.GetNamespace())
+			})
+			var propagationPolicy metav1.DeletionPropagation
+			recordingClient := deleteRecorder{Interface: downstreamDynamicClient, record: func(options metav1.DeleteOptions) {
+				if options.PropagationPolicy != nil {
+					propagationPolicy = *options.PropagationPolicy
+				}
+			}}
+			downstreamDiscoveryClient := clientset.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+			downstreamDiscoveryClient.Resources = testAPIResourceList
+			downstreamClientFactory, err := clientfactory.NewClientFactory(logger, recordingClient, downstreamDiscoveryClient)
+			require.NoError(t, err)
+			downstreamClientFactory.SetFieldManager(clientfactory.FieldManagerForSyncTarget("test"))
+
+			resource := edgev1alpha1.EdgeSyncConfigResource{Group: "", Version: "v1", Kind: "ConfigMap", Namespace: "default", Name: "*"}
+			downSyncer, err := syncers.NewDownSyncer(logger, upstreamClientFactory, downstreamClientFactory, []edgev1alpha1.EdgeSyncConfigResource{resource}, nil)
+			require.NoError(t, err)
+			syncStatusStore := syncers.NewSyncStatusStore()
+			downSyncer.SetStatusStore(syncStatusStore)
+			downSyncer.SetFinalizer(finalizer)
+			force := false
+			downSyncer.SetDeletionPolicies(func(gr schema.GroupResource) edgev1alpha1.DeletionPolicy {
+				return edgev1alpha1.DeletionPolicy{PropagationPolicy: metav1.DeletePropagationForeground, Timeout: &metav1.Duration{Duration: time.Minute}, Force: force}
+			})
+
+			// Delivery puts the finalizer on the upstream object
+			require.NoError(t, downSyncer.SyncMany(resource, nil))
+			eventuallyDownstreamData(t, downstreamDynamicClient, "cm-1", "a")
+			upCM, err := upstreamDynamicClient.Resource(configMapGVR).Namespace("default").Get(ctx, "cm-1", metav1.GetOptions{})
+			require.NoError(t, err)
+			require.Equal(t, []string{finalizer}, upCM.GetFinalizers())
+
+			// Deletion upstream, which the finalizer holds up; it started long enough ago to time out
+			deletionTimestamp := metav1.NewTime(time.Now().Add(-time.Hour))
+			upCM.SetDeletionTimestamp(&deletionTimestamp)
+			_, err = upstreamDynamicClient.Resource(configMapGVR).Namespace("default").Update(ctx, upCM, metav1.UpdateOptions{})
+			require.NoError(t, err)
+
+			err = downSyncer.SyncMany(resource, nil)
+			require.Equal(t, metav1.DeletePropagationForeground, propagationPolicy)
+			_, getErr := downstreamDynamicClient.Resource(configMapGVR).Namespace("default").Get(ctx, "cm-1", metav1.GetOptions{})
+			upCM, upErr := upstreamDynamicClient.Resource(configMapGVR).Namespace("default").Get(ctx, "cm-1", metav1.GetOptions{})
+			require.NoError(t, upErr)
+			if !blocked {
+				require.NoError(t, err)
+				require.True(t, errors.IsNotFound(getErr))
+				require.Empty(t, upCM.GetFinalizers())
+				require.Empty(t, syncStatusStore.List())
+				return
+			}
+			// The deletion in the edge cluster did not finish within the timeout, so the finalizer stays until forced
+			require.Error(t, err)
+			_, pending := syncers.DeletionPending(err)
+			require.False(t, pending)
+			require.NoError(t, getErr)
+			require.Equal(t, []string{finalizer}, upCM.GetFinalizers())
+			require.Equal(t, edgev1alpha1.SyncOutcomeFailed, syncStatusStore.List()[0].Outcome)
+
+			force = true
+			require.NoError(t, downSyncer.SyncOne(edgev1alpha1.EdgeSyncConfigResource{Version: "v1", Kind: "ConfigMap", Namespace: "default", Name: "cm-1"}, nil))
+			upCM, err = upstreamDynamicClient.Resource(configMapGVR).Namespace("default").Get(ctx, "cm-1", metav1.GetOptions{})
+			require.NoError(t, err)
+			require.Empty(t, upCM.GetFinalizers())
+		})
+	}
+}
+
+func TestDownsyncDeletionFollowsNotifications(t *testing.T) {
+	const finalizer = "workload.kcp.io/syncer-test"
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := klog.FromContext(ctx)
+
+	upstreamDynamicClient := dynamicfake.NewSimpleDynamicClient(scheme, configMap("default", "cm-1", "a"))
+	addFinalizerApplyReactor(upstreamDynamicClient)
+	upstreamDiscoveryClient := clientset.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+	upstreamDiscoveryClient.Resources = testAPIResourceList
+	upstreamClientFactory, err := clientfactory.NewClientFactory(logger, upstreamDynamicClient, upstreamDiscoveryClient)
+	require.NoError(t, err)
+	upstreamClientFactory.SetFieldManager(clientfactory.FieldManagerForSyncTarget("test"))
+
+	downstreamDynamicClient := dynamicfake.NewSimpleDynamicClient(scheme)
+	addApplyReactor(downstreamDynamicClient)
+	// Deletes leave the object terminating, as a finalizer in the edge cluster would
+	downstreamDynamicClient.PrependReactor("delete", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		deleteAction := action.(clienttesting.DeleteAction)
+		obj, err := downstreamDynamicClient.Tracker().Get(deleteAction.GetResource(), deleteAction.GetNamespace(), deleteAction.GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		objMeta, err := meta.Accessor(obj)
+		if err != nil {
+			return true, nil, err
+		}
+		now := metav1.Now()
+		objMeta.SetDeletionTimestamp(&now)
+		return true, nil, downstreamDynamicClient.Tracker().Update(deleteAction.GetResource(), obj, deleteAction.GetNamespace())
+	})
+	downstreamDiscoveryClient := clientset.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+	downstreamDiscoveryClient.Resources = testAPIResourceList
+	downstreamClientFactory, err := clientfactory.NewClientFactory(logger, downstreamDynamicClient, downstreamDiscoveryClient)
+	require.NoError(t, err)
+	downstreamClientFactory.SetFieldManager(clientfactory.FieldManagerForSyncTarget("test"))
+
+	upSyncer, err := syncers.NewUpSyncer(logger, upstreamClientFactory, downstreamClientFactory, []edgev1alpha1.EdgeSyncConfigResource{}, []edgev1alpha1.EdgeSynConversion{})
+	require.NoError(t, err)
+	downSyncer, err := syncers.NewDownSyncer(logger, upstreamClientFactory, downstreamClientFactory, []edgev1alpha1.EdgeSyncConfigResource{}, []edgev1alpha1.EdgeSynConversion{})
+	require.NoError(t, err)
+	downSyncer.SetFinalizer(finalizer)
+
+	syncConfigManager := NewSyncConfigManager(logger)
+	// The resync interval is long enough that only notifications can explain the finish of the deletion
+	controller := NewSyncController(logger, syncConfigManager, nil, upstreamClientFactory, downstreamClientFactory, upSyncer, downSyncer, time.Hour)
+	syncConfigManager.upsert(edgev1alpha1.EdgeSyncConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-sync-config"},
+		Spec: edgev1alpha1.EdgeSyncConfigSpec{
+			DownSyncedResources: []edgev1alpha1.EdgeSyncConfigResource{
+				{Group: "", Version: "v1", Kind: "ConfigMap", Namespace: "default", Name: "*"},
+			},
+		},
+	})
+	go controller.Run(ctx, 1)
+	eventuallyDownstreamData(t, downstreamDynamicClient, "cm-1", "a")
+	var upCM *unstructured.Unstructured
+	require.Eventually(t, func() bool {
+		upCM, err = upstreamDynamicClient.Resource(configMapGVR).Namespace("default").Get(ctx, "cm-1", metav1.GetOptions{})
+		return err == nil && len(upCM.GetFinalizers()) > 0
+	}, wait.ForeverTestTimeout, 100*time.Millisecond)
+
+	// Deletion upstream starts the deletion in the edge cluster, which the finalizer waits for
+	deletionTimestamp := metav1.Now()
+	upCM.SetDeletionTimestamp(&deletionTimestamp)
+	_, err = upstreamDynamicClient.Resource(configMapGVR).Namespace("default").Update(ctx, upCM, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		edgeCM, err := downstreamDynamicClient.Resource(configMapGVR).Namespace("default").Get(ctx, "cm-1", metav1.GetOptions{})
+		return err == nil && edgeCM.GetDeletionTimestamp() != nil
+	}, wait.ForeverTestTimeout, 100*time.Millisecond)
+	upCM, err = upstreamDynamicClient.Resource(configMapGVR).Namespace("default").Get(ctx, "cm-1", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{finalizer}, upCM.GetFinalizers())
+
+	// The end of the deletion in the edge cluster releases the upstream object
+	require.NoError(t, downstreamDynamicClient.Tracker().Delete(configMapGVR, "default", "cm-1"))
+	require.Eventually(t, func() bool {
+		upCM, err := upstreamDynamicClient.Resource(configMapGVR).Namespace("default").Get(ctx, "cm-1", metav1.GetOptions{})
+		return err == nil && len(upCM.GetFinalizers()) == 0
+	}, wait.ForeverTestTimeout, 100*time.Millisecond)
+}
+
+func TestDownsyncUnsyncReleasesFinalizer(t *testing.T) {
+	const finalizer = "workload.kcp.io/syncer-test"
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := klog.FromContext(ctx)
+
+	upstreamDynamicClient := dynamicfake.NewSimpleDynamicClient(scheme, configMap("default", "cm-1", "a"), configMap("default", "cm-2", "b"))
+	addFinalizerApplyReactor(upstreamDynamicClient)
+	upstreamDiscoveryClient := clientset.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+	upstreamDiscoveryClient.Resources = testAPIResourceList
+	upstreamClientFactory, err := clientfactory.NewClientFactory(logger, upstreamDynamicClient, upstreamDiscoveryClient)
+	require.NoError(t, err)
+	upstreamClientFactory.SetFieldManager(clientfactory.FieldManagerForSyncTarget("test"))
+
+	downstreamDynamicClient := dynamicfake.NewSimpleDynamicClient(scheme)
+	addApplyReactor(downstreamDynamicClient)
+	downstreamDiscoveryClient := clientset.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+	downstreamDiscoveryClient.Resources = testAPIResourceList
+	downstreamClientFactory, err := clientfactory.NewClientFactory(logger, downstreamDynamicClient, downstreamDiscoveryClient)
+	require.NoError(t, err)
+	downstreamClientFactory.SetFieldManager(clientfactory.FieldManagerForSyncTarget("test"))
+
+	resource := edgev1alpha1.EdgeSyncConfigResource{Group: "", Version: "v1", Kind: "ConfigMap", Namespace: "default", Name: "*"}
+	downSyncer, err := syncers.NewDownSyncer(logger, upstreamClientFactory, downstreamClientFactory, []edgev1alpha1.EdgeSyncConfigResource{resource}, nil)
+	require.NoError(t, err)
+	downSyncer.SetStatusStore(syncers.NewSyncStatusStore())
+	downSyncer.SetFinalizer(finalizer)
+	// cm-2 is still downsynced through another resource
+	downSyncer.SetStillDownsynced(func(obj *unstructured.Unstructured) bool { return obj.GetName() == "cm-2" })
+
+	require.NoError(t, downSyncer.SyncMany(resource, nil))
+	eventuallyDownstreamData(t, downstreamDynamicClient, "cm-1", "a")
+	for _, name := range []string{"cm-1", "cm-2"} {
+		upCM, err := upstreamDynamicClient.Resource(configMapGVR).Namespace("default").Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+		require.Equal(t, []string{finalizer}, upCM.GetFinalizers())
+	}
+
+	// The resource is no longer downsynced: its objects are released, and their edge copies kept
+	require.NoError(t, downSyncer.UnsyncMany(resource, nil))
+	upCM, err := upstreamDynamicClient.Resource(configMapGVR).Namespace("default").Get(ctx, "cm-1", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Empty(t, upCM.GetFinalizers())
+	upCM, err = upstreamDynamicClient.Resource(configMapGVR).Namespace("default").Get(ctx, "cm-2", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{finalizer}, upCM.GetFinalizers())
+	_, err = downstreamDynamicClient.Resource(configMapGVR).Namespace("default").Get(ctx, "cm-1", metav1.GetOptions{})
+	require.NoError(t, err)
+}
+
+func TestDownsyncWhileUpstreamUnreachable(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := klog.FromContext(ctx)
+
+	store, err := localstore.Open(t.TempDir())
+	require.NoError(t, err)
+	upstreamDynamicClient := dynamicfake.NewSimpleDynamicClient(scheme, configMap("default", "cm-1", "a"), configMap("default", "cm-2", "b"))
+	upstreamDiscoveryClient := clientset.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+	upstreamDiscoveryClient.Resources = testAPIResourceList
+	upstreamClientFactory, err := clientfactory.NewClientFactory(logger, upstreamDynamicClient, upstreamDiscoveryClient)
+	require.NoError(t, err)
+	upstreamClientFactory.SetFieldManager(clientfactory.FieldManagerForSyncTarget("test"))
+	upstreamClientFactory.SetLocalStore(store)
+
+	downstreamDynamicClient := dynamicfake.NewSimpleDynamicClient(scheme)
+	addApplyReactor(downstreamDynamicClient)
+	downstreamDiscoveryClient := clientset.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+	downstreamDiscoveryClient.Resources = testAPIResourceList
+	downstreamClientFactory, err := clientfactory.NewClientFactory(logger, downstreamDynamicClient, downstreamDiscoveryClient)
+	require.NoError(t, err)
+	downstreamClientFactory.SetFieldManager(clientfactory.FieldManagerForSyncTarget("test"))
+
+	resource := edgev1alpha1.EdgeSyncConfigResource{Group: "", Version: "v1", Kind: "ConfigMap", Namespace: "default", Name: "*"}
+	downSyncer, err := syncers.NewDownSyncer(logger, upstreamClientFactory, downstreamClientFactory, []edgev1alpha1.EdgeSyncConfigResource{resource}, nil)
+	require.NoError(t, err)
+	require.NoError(t, downSyncer.SyncMany(resource, nil))
+	eventuallyDownstreamData(t, downstreamDynamicClient, "cm-1", "a")
+	eventuallyDownstreamData(t, downstreamDynamicClient, "cm-2", "b")
+
+	// The upstream becomes unreachable, and cm-2 is not in the local store
+	require.NoError(t, store.DeleteObject(configMapGVR, "default", "cm-2"))
+	upstreamDynamicClient.PrependReactor("*", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, &url.Error{Op: "Get", URL: "https://upstream", Err: fmt.Errorf("connection refused")}
+	})
+
+	for _, name := range []string{"cm-1", "cm-2"} {
+		require.NoError(t, downSyncer.SyncOne(edgev1alpha1.EdgeSyncConfigResource{Version: "v1", Kind: "ConfigMap", Namespace: "default", Name: name}, nil))
+		_, err = downstreamDynamicClient.Resource(configMapGVR).Namespace("default").Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err, "%s was deleted from the edge cluster", name)
+	}
+}
+
+// deleteRecorder is a dynamic client that reports the options of deletes,
+// which the fake dynamic client does not keep.
+type deleteRecorder struct {
+	dynamic.Interface
+	record func(metav1.DeleteOptions)
+}
+
+func (d deleteRecorder) Resource(resource schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return deleteRecordingResource{NamespaceableResourceInterface: d.Interface.Resource(resource), record: d.record}
+}
+
+type deleteRecordingResource struct {
+	dynamic.NamespaceableResourceInterface
+	record func(metav1.DeleteOptions)
+}
+
+func (r deleteRecordingResource) Namespace(namespace string) dynamic.ResourceInterface {
+	return deleteRecordingNamespacedResource{ResourceInterface: r.NamespaceableResourceInterface.Namespace(namespace), record: r.record}
+}
+
+func (r deleteRecordingResource) Delete(ctx context.Context, name string, options metav1.DeleteOptions, subresources ...string) error {
+	r.record(options)
+	return r.NamespaceableResourceInterface.Delete(ctx, name, options, subresources...)
+}
+
+type deleteRecordingNamespacedResource struct {
+	dynamic.ResourceInterface
+	record func(metav1.DeleteOptions)
+}
+
+func (r deleteRecordingNamespacedResource) Delete(ctx context.Context, name string, options metav1.DeleteOptions, subresources ...string) error {
+	r.record(options)
+	return r.ResourceInterface.Delete(ctx, name, options, subresources...)
+}
+
+func TestUpsyncCollisionPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		policy    edgev1alpha1.UpsyncCollisionPolicy
+		namespace string
+		name      string
+	}{
+		{edgev1alpha1.UpsyncCollisionPolicyMerge, "default", "cm-1"},
+		{edgev1alpha1.UpsyncCollisionPolicyPrefix, "default", "edge1-cm-1"},
+		{edgev1alpha1.UpsyncCollisionPolicySuffix, "default", "cm-1-edge1"},
+		{edgev1alpha1.UpsyncCollisionPolicyPerClusterNamespace, "edge1-default", "cm-1"},
+	} {
+		t.Run(string(tc.policy), func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			logger := klog.FromContext(ctx)
+
+			upstreamDynamicClient := dynamicfake.NewSimpleDynamicClient(scheme)
+			addApplyReactor(upstreamDynamicClient)
+			upstreamDiscoveryClient := clientset.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+			upstreamDiscoveryClient.Resources = testAPIResourceList
+			upstreamClientFactory, err := clientfactory.NewClientFactory(logger, upstreamDynamicClient, upstreamDiscoveryClient)
+			require.NoError(t, err)
+			upstreamClientFactory.SetFieldManager(clientfactory.FieldManagerForSyncTarget("edge1"))
+
+			downstreamDynamicClient := dynamicfake.NewSimpleDynamicClient(scheme, configMap("default", "cm-1", "a"))
+			downstreamDiscoveryClient := clientset.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+			downstreamDiscoveryClient.Resources = testAPIResourceList
+			downstreamClientFactory, err := clientfactory.NewClientFactory(logger, downstreamDynamicClient, downstreamDiscoveryClient)
+			require.NoError(t, err)
+
+			resource := edgev1alpha1.EdgeSyncConfigResource{Group: "", Version: "v1", Kind: "ConfigMap", Namespace: "default", Name: "*"}
+			upSyncer, err := syncers.NewUpSyncer(logger, upstreamClientFactory, downstreamClientFactory, []edgev1alpha1.EdgeSyncConfigResource{resource}, nil)
+			require.NoError(t, err)
+			upSyncer.SetStatusStore(syncers.NewSyncStatusStore())
+			upSyncer.SetSource("edge1", func() string { return "loc1" })
+			upSyncer.SetCollisionPolicies(func(gr schema.GroupResource, namespace, name string, labels map[string]string) edgev1alpha1.UpsyncCollisionPolicy {
+				require.Equal(t, schema.GroupResource{Resource: "configmaps"}, gr)
+				return tc.policy
+			})
+			getUpsynced := func() (*unstructured.Unstructured, error) {
+				return upstreamDynamicClient.Resource(configMapGVR).Namespace(tc.namespace).Get(ctx, tc.name, metav1.GetOptions{})
+			}
+
+			require.NoError(t, upSyncer.SyncMany(resource, nil))
+			upsynced, err := getUpsynced()
+			require.NoError(t, err)
+			require.Equal(t, map[string]string{
+				edgev1alpha1.SourceSyncTargetLabelKey: "edge1",
+				edgev1alpha1.SourceLocationLabelKey:   "loc1",
+			}, upsynced.GetLabels())
+			require.Equal(t, "default/cm-1", upsynced.GetAnnotations()[edgev1alpha1.SourceObjectAnnotationKey])
+
+			// The copy's namespace is created when the policy gives it one of its own
+			if tc.namespace != "default" {
+				ns, err := upstreamDynamicClient.Resource(namespaceGVR).Get(ctx, tc.namespace, metav1.GetOptions{})
+				require.NoError(t, err)
+				require.Equal(t, "edge1", ns.GetLabels()[edgev1alpha1.SourceSyncTargetLabelKey])
+			}
+
+			// Syncing one object finds the same copy
+			require.NoError(t, downstreamDynamicClient.Tracker().Update(configMapGVR, configMap("default", "cm-1", "b"), "default"))
+			require.NoError(t, upSyncer.SyncOne(edgev1alpha1.EdgeSyncConfigResource{Group: "", Version: "v1", Kind: "ConfigMap", Namespace: "default", Name: "cm-1"}, nil))
+			upsynced, err = getUpsynced()
+			require.NoError(t, err)
+			value, _, _ := unstructured.NestedString(upsynced.Object, "data", "key")
+			require.Equal(t, "b", value)
+
+			// and so does the deletion
+			require.NoError(t, downstreamDynamicClient.Tracker().Delete(configMapGVR, "default", "cm-1"))
+			require.NoError(t, upSyncer.SyncMany(resource, nil))
+			_, err = getUpsynced()
+			require.True(t, errors.IsNotFound(err))
+		})
+	}
+}
+
+func TestUpsyncLongNames(t *testing.T) {
+	for _, tc := range []struct {
+		policy        edgev1alpha1.UpsyncCollisionPolicy
+		edgeNamespace string
+		edgeName      string
+		maxNamespace  int
+		maxName       int
+	}{
+		{edgev1alpha1.UpsyncCollisionPolicyPrefix, "default", strings.Repeat("c", 250), 63, 253},
+		{edgev1alpha1.UpsyncCollisionPolicySuffix, "default", strings.Repeat("c", 250), 63, 253},
+		{edgev1alpha1.UpsyncCollisionPolicyPerClusterNamespace, strings.Repeat("n", 60), "cm-1", 63, 253},
+	} {
+		t.Run(string(tc.policy), func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			logger := klog.FromContext(ctx)
+
+			upstreamDynamicClient := dynamicfake.NewSimpleDynamicClient(scheme)
+			addApplyReactor(upstreamDynamicClient)
+			upstreamDiscoveryClient := clientset.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+			upstreamDiscoveryClient.Resources = testAPIResourceList
+			upstreamClientFactory, err := clientfactory.NewClientFactory(logger, upstreamDynamicClient, upstreamDiscoveryClient)
+			require.NoError(t, err)
+			upstreamClientFactory.SetFieldManager(clientfactory.FieldManagerForSyncTarget("edge1"))
+
+			downstreamDynamicClient := dynamicfake.NewSimpleDynamicClient(scheme, configMap(tc.edgeNamespace, tc.edgeName, "a"))
+			downstreamDiscoveryClient := clientset.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+			downstreamDiscoveryClient.Resources = testAPIResourceList
+			downstreamClientFactory, err := clientfactory.NewClientFactory(logger, downstreamDynamicClient, downstreamDiscoveryClient)
+			require.NoError(t, err)
+
+			resource := edgev1alpha1.EdgeSyncConfigResource{Group: "", Version: "v1", Kind: "ConfigMap", Namespace: tc.edgeNamespace, Name: "*"}
+			upSyncer, err := syncers.NewUpSyncer(logger, upstreamClientFactory, downstreamClientFactory, []edgev1alpha1.EdgeSyncConfigResource{resource}, nil)
+			require.NoError(t, err)
+			upSyncer.SetStatusStore(syncers.NewSyncStatusStore())
+			upSyncer.SetSource("edge1", nil)
+			upSyncer.SetCollisionPolicies(func(gr schema.GroupResource, namespace, name string, labels map[string]string) edgev1alpha1.UpsyncCollisionPolicy {
+				return tc.policy
+			})
+
+			require.NoError(t, upSyncer.SyncMany(resource, nil))
+			list, err := upstreamDynamicClient.Resource(configMapGVR).List(ctx, metav1.ListOptions{})
+			require.NoError(t, err)
+			require.Len(t, list.Items, 1)
+			upsynced := &list.Items[0]
+			require.LessOrEqual(t, len(upsynced.GetNamespace()), tc.maxNamespace)
+			require.LessOrEqual(t, len(upsynced.GetName()), tc.maxName)
+			_, err = upstreamDynamicClient.Resource(namespaceGVR).Get(ctx, upsynced.GetNamespace(), metav1.GetOptions{})
+			require.Equal(t, tc.policy == edgev1alpha1.UpsyncCollisionPolicyPerClusterNamespace, err == nil)
+
+			// The edge cluster object is found from its copy
+			namespace, name := upSyncer.EdgeNamespaceAndName(resource, nil, upsynced)
+			require.Equal(t, tc.edgeNamespace, namespace)
+			require.Equal(t, tc.edgeName, name)
+
+			// and the copy goes away with it
+			narrowed := resource
+			narrowed.Name = tc.edgeName
+			require.NoError(t, downstreamDynamicClient.Tracker().Delete(configMapGVR, tc.edgeNamespace, tc.edgeName))
+			require.NoError(t, upSyncer.SyncOne(narrowed, nil))
+			list, err = upstreamDynamicClient.Resource(configMapGVR).List(ctx, metav1.ListOptions{})
+			require.NoError(t, err)
+			require.Empty(t, list.Items)
+		})
+	}
+}
+
+func TestUpsyncSelectors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := klog.FromContext(ctx)
+
+	namespace := func(name string, labels map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		}
+	}
+	labeledConfigMap := func(namespace, name, data string, labels map[string]string) *unstructured.Unstructured {
+		cm := configMap(namespace, name, data)
+		cm.SetLabels(labels)
+		return cm
+	}
+	report := map[string]string{"report": "true"}
+
+	upstreamDynamicClient := dynamicfake.NewSimpleDynamicClient(scheme)
+	addApplyReactor(upstreamDynamicClient)
+	upstreamDiscoveryClient := clientset.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+	upstreamDiscoveryClient.Resources = testAPIResourceList
+	upstreamClientFactory, err := clientfactory.NewClientFactory(logger, upstreamDynamicClient, upstreamDiscoveryClient)
+	require.NoError(t, err)
+	upstreamClientFactory.SetFieldManager(clientfactory.FieldManagerForSyncTarget("test"))
+
+	downstreamDynamicClient := dynamicfake.NewSimpleDynamicClient(scheme,
+		namespace("ns-a", map[string]string{"team": "x"}),
+		namespace("ns-b", nil),
+		labeledConfigMap("ns-a", "cm-1", "a", report),
+		labeledConfigMap("ns-a", "cm-2", "a", nil),
+		labeledConfigMap("ns-a", "cm-3", "b", report),
+		labeledConfigMap("ns-b", "cm-4", "a", report),
+	)
+	downstreamDiscoveryClient := clientset.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+	downstreamDiscoveryClient.Resources = testAPIResourceList
+	downstreamClientFactory, err := clientfactory.NewClientFactory(logger, downstreamDynamicClient, downstreamDiscoveryClient)
+	require.NoError(t, err)
+
+	resource := edgev1alpha1.EdgeSyncConfigResource{Group: "", Version: "v1", Kind: "ConfigMap", Namespace: "*", Name: "*",
+		LabelSelector: "report=true", FieldSelector: "data.key=a", NamespaceSelector: "team=x"}
+	nsResource := edgev1alpha1.EdgeSyncConfigResource{Group: "", Version: "v1", Kind: "Namespace", Name: "*"}
+	upSyncer, err := syncers.NewUpSyncer(logger, upstreamClientFactory, downstreamClientFactory, []edgev1alpha1.EdgeSyncConfigResource{resource, nsResource}, nil)
+	require.NoError(t, err)
+	statusStore := syncers.NewSyncStatusStore()
+	upSyncer.SetStatusStore(statusStore)
+	upsyncedNames := func() []string {
+		list, err := upstreamDynamicClient.Resource(configMapGVR).List(ctx, metav1.ListOptions{})
+		require.NoError(t, err)
+		names := []string{}
+		for _, item := range list.Items {
+			names = append(names, item.GetNamespace()+"/"+item.GetName())
+		}
+		return names
+	}
+
+	require.NoError(t, upSyncer.SyncMany(resource, nil))
+	require.Equal(t, []string{"ns-a/cm-1"}, upsyncedNames())
+
+	// An object that stops matching is treated as gone
+	require.NoError(t, downstreamDynamicClient.Tracker().Update(configMapGVR, labeledConfigMap("ns-a", "cm-1", "a", nil), "ns-a"))
+	narrowed := resource
+	narrowed.Namespace, narrowed.Name = "ns-a", "cm-1"
+	require.NoError(t, upSyncer.SyncOne(narrowed, nil))
+	require.Empty(t, upsyncedNames())
+
+	// and one that starts matching is upsynced
+	require.NoError(t, downstreamDynamicClient.Tracker().Update(configMapGVR, labeledConfigMap("ns-a", "cm-3", "a", report), "ns-a"))
+	require.NoError(t, upSyncer.SyncMany(resource, nil))
+	require.Equal(t, []string{"ns-a/cm-3"}, upsyncedNames())
+
+	// The status reports the selectors under which the object was upsynced
+	statuses := statusStore.List()
+	require.Len(t, statuses, 1)
+	require.Equal(t, "cm-3", statuses[0].Name)
+	require.Equal(t, []edgev1alpha1.UpsyncSelection{{LabelSelector: "report=true", FieldSelector: "data.key=a", NamespaceSelector: "team=x"}},
+		statuses[0].UpsyncSelections)
+}
+
+func TestSyncControllerEnqueuesUpsyncedObjects(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := klog.FromContext(ctx)
+
+	labeledConfigMap := func(namespace, name string, labels map[string]string) *unstructured.Unstructured {
+		cm := configMap(namespace, name, "a")
+		cm.SetLabels(labels)
+		return cm
+	}
+	report := map[string]string{"report": "true"}
+
+	upstreamDynamicClient := dynamicfake.NewSimpleDynamicClient(scheme)
+	upstreamDiscoveryClient := clientset.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+	upstreamDiscoveryClient.Resources = testAPIResourceList
+	upstreamClientFactory, err := clientfactory.NewClientFactory(logger, upstreamDynamicClient, upstreamDiscoveryClient)
+	require.NoError(t, err)
+
+	downstreamDynamicClient := dynamicfake.NewSimpleDynamicClient(scheme)
+	downstreamDiscoveryClient := clientset.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+	downstreamDiscoveryClient.Resources = testAPIResourceList
+	downstreamClientFactory, err := clientfactory.NewClientFactory(logger, downstreamDynamicClient, downstreamDiscoveryClient)
+	require.NoError(t, err)
+
+	resource := edgev1alpha1.EdgeSyncConfigResource{Group: "", Version: "v1", Kind: "ConfigMap", Namespace: "default", Name: "cm-1", LabelSelector: "report=true"}
+	upSyncer, err := syncers.NewUpSyncer(logger, upstreamClientFactory, downstreamClientFactory, []edgev1alpha1.EdgeSyncConfigResource{resource}, nil)
+	require.NoError(t, err)
+	upSyncer.SetSource("edge1", nil)
+	upSyncer.SetCollisionPolicies(func(gr schema.GroupResource, namespace, name string, labels map[string]string) edgev1alpha1.UpsyncCollisionPolicy {
+		return edgev1alpha1.UpsyncCollisionPolicyPrefix
+	})
+	downSyncer, err := syncers.NewDownSyncer(logger, upstreamClientFactory, downstreamClientFactory, []edgev1alpha1.EdgeSyncConfigResource{}, []edgev1alpha1.EdgeSynConversion{})
+	require.NoError(t, err)
+
+	syncConfigManager := NewSyncConfigManager(logger)
+	controller := NewSyncController(logger, syncConfigManager, nil, upstreamClientFactory, downstreamClientFactory, upSyncer, downSyncer, time.Hour)
+	syncConfigManager.upsert(edgev1alpha1.EdgeSyncConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-sync-config"},
+		Spec:       edgev1alpha1.EdgeSyncConfigSpec{UpSyncedResources: []edgev1alpha1.EdgeSyncConfigResource{resource}},
+	})
+	// Take out the refresh that the configuration change enqueued
+	for controller.queue.Len() > 0 {
+		item, _ := controller.queue.Get()
+		controller.queue.Done(item)
+	}
+	enqueued := func() []syncQueueItem {
+		items := []syncQueueItem{}
+		for controller.queue.Len() > 0 {
+			item, _ := controller.queue.Get()
+			controller.queue.Done(item)
+			items = append(items, item.(syncQueueItem))
+		}
+		return items
+	}
+
+	// The deletion of a renamed copy enqueues the upsync of the edge cluster object it is a copy of
+	controller.enqueueForObject(upstreamSide, nil, labeledConfigMap("default", "edge1-cm-1", report), true)
+	require.Equal(t, []syncQueueItem{{action: syncActionUpSync, resource: resource}}, enqueued())
+
+	// An edge cluster object that the selectors do not select is left alone
+	controller.enqueueForObject(downstreamSide, nil, labeledConfigMap("default", "cm-1", nil), false)
+	require.Empty(t, enqueued())
+
+	// unless an update takes it out of the selection
+	controller.enqueueForObject(downstreamSide, labeledConfigMap("default", "cm-1", report), labeledConfigMap("default", "cm-1", nil), false)
+	require.Equal(t, []syncQueueItem{{action: syncActionUpSync, resource: resource}}, enqueued())
+}
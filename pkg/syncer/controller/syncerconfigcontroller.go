@@ -20,11 +20,13 @@ import (
 	"context"
 	"time"
 
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 
+	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
 	edgev1alpha1typed "github.com/kubestellar/kubestellar/pkg/client/clientset/versioned/typed/edge/v1alpha1"
 	edgev1alpha1informers "github.com/kubestellar/kubestellar/pkg/client/informers/externalversions/edge/v1alpha1"
 	edgev1alpha1listers "github.com/kubestellar/kubestellar/pkg/client/listers/edge/v1alpha1"
@@ -62,8 +64,16 @@ func NewSyncerConfigController(
 			return err == nil
 		},
 		Handler: cache.ResourceEventHandlerFuncs{
-			AddFunc:    func(obj interface{}) { c.enqueue(obj, logger) },
-			UpdateFunc: func(old, obj interface{}) { c.enqueue(obj, logger) },
+			AddFunc: func(obj interface{}) { c.enqueue(obj, logger) },
+			UpdateFunc: func(old, obj interface{}) {
+				oldSyncerConfig, oldOK := old.(*edgev1alpha1.SyncerConfig)
+				newSyncerConfig, newOK := obj.(*edgev1alpha1.SyncerConfig)
+				if oldOK && newOK && apiequality.Semantic.DeepEqual(oldSyncerConfig.Spec, newSyncerConfig.Spec) {
+					// Nothing to do for a status-only change, such as a heartbeat
+					return
+				}
+				c.enqueue(obj, logger)
+			},
 			DeleteFunc: func(obj interface{}) { c.enqueue(obj, logger) },
 		},
 	})
@@ -97,6 +107,7 @@ func (c *syncerConfigController) process(ctx context.Context, key string) error
 		return err
 	}
 	c.syncerConfigManager.upsert(*syncerConfig)
+	c.syncerConfigManager.Refresh()
 
 	return nil
 }
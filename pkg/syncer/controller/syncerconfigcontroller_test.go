@@ -29,6 +29,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	fakediscovery "k8s.io/client-go/discovery/fake"
@@ -109,6 +110,16 @@ func TestSyncerConfig(t *testing.T) {
 						Names:      []string{"*"},
 					},
 				},
+				Conversions: []edgev1alpha1.ResourceConversion{
+					{
+						Upstream:   metav1.GroupResource{Group: "cheese.testing.k8s.io", Resource: "goudas"},
+						Downstream: metav1.GroupResource{Group: "", Resource: "configmaps"},
+					},
+					{
+						Upstream:   metav1.GroupResource{Group: "cheese.testing.k8s.io", Resource: "brie"},
+						Downstream: metav1.GroupResource{Group: "", Resource: "configmaps"},
+					},
+				},
 			},
 			expected: Expected{
 				downSyncedResources: []edgev1alpha1.EdgeSyncConfigResource{
@@ -124,11 +135,60 @@ func TestSyncerConfig(t *testing.T) {
 					{Group: "", Version: "v1", Kind: "Namespace", Name: "*"},
 				},
 				conversions: []edgev1alpha1.EdgeSynConversion{{
-					Upstream:   upSyncedResource,
-					Downstream: downSyncedResource,
+					Upstream:   edgev1alpha1.EdgeSyncConfigResource{Group: "cheese.testing.k8s.io", Kind: "Gouda"},
+					Downstream: edgev1alpha1.EdgeSyncConfigResource{Group: "", Kind: "ConfigMap"},
 				}},
 			},
 		},
+		{
+			description:  "Syncer upsyncs the objects chosen by selectors",
+			syncerConfig: syncerConfig("test-sync-config-selectors", types.UID("uid-selectors")),
+			syncerConfigSpec: edgev1alpha1.SyncerConfigSpec{
+				Upsync: []edgev1alpha1.UpsyncSet{
+					{
+						APIGroup:          "",
+						Resources:         []string{"configmaps"},
+						Namespaces:        []string{"default"},
+						Names:             []string{"*"},
+						LabelSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"report": "true"}},
+						FieldSelector:     "data.key=a",
+						NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "x"}},
+					},
+				},
+			},
+			expected: Expected{
+				downSyncedResources: []edgev1alpha1.EdgeSyncConfigResource{},
+				upSyncedResources: []edgev1alpha1.EdgeSyncConfigResource{
+					{Group: "", Version: "v1", Kind: "ConfigMap", Namespace: "default", Name: "*", LabelSelector: "report=true", FieldSelector: "data.key=a"},
+					{Group: "", Version: "v1", Kind: "ConfigMap", Namespace: "*", Name: "*", LabelSelector: "report=true", FieldSelector: "data.key=a", NamespaceSelector: "team=x"},
+					{Group: "", Version: "v1", Kind: "Namespace", Name: "default"},
+					{Group: "", Version: "v1", Kind: "Namespace", Name: "*", LabelSelector: "team=x"},
+				},
+				conversions: []edgev1alpha1.EdgeSynConversion{},
+			},
+		},
+		{
+			description:  "Syncer upsyncs all names when a selector is given without names",
+			syncerConfig: syncerConfig("test-sync-config-selector-only", types.UID("uid-selector-only")),
+			syncerConfigSpec: edgev1alpha1.SyncerConfigSpec{
+				Upsync: []edgev1alpha1.UpsyncSet{
+					{
+						APIGroup:      "",
+						Resources:     []string{"configmaps"},
+						Namespaces:    []string{"default"},
+						LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"report": "true"}},
+					},
+				},
+			},
+			expected: Expected{
+				downSyncedResources: []edgev1alpha1.EdgeSyncConfigResource{},
+				upSyncedResources: []edgev1alpha1.EdgeSyncConfigResource{
+					{Group: "", Version: "v1", Kind: "ConfigMap", Namespace: "default", Name: "*", LabelSelector: "report=true"},
+					{Group: "", Version: "v1", Kind: "Namespace", Name: "default"},
+				},
+				conversions: []edgev1alpha1.EdgeSynConversion{},
+			},
+		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.description, func(t *testing.T) {
@@ -201,6 +261,7 @@ func TestSyncerConfig(t *testing.T) {
 			assertEqualArrayWithouOrder(t, tc.expected.downSyncedResources, downsyncedResources)
 			upsyncedResources := syncConfigManager.GetUpSyncedResources()
 			assertEqualArrayWithouOrder(t, tc.expected.upSyncedResources, upsyncedResources)
+			assertEqualArrayWithouOrder(t, tc.expected.conversions, syncConfigManager.GetConversions())
 
 			newSyncerConfig := *tc.syncerConfig.DeepCopy()
 			emptySyncerConfigSpec := edgev1alpha1.SyncerConfigSpec{
@@ -217,6 +278,7 @@ func TestSyncerConfig(t *testing.T) {
 				upsyncedResources := syncConfigManager.GetUpSyncedResources()
 				return len(downsyncedResources) == 0 && len(upsyncedResources) == 0
 			}, wait.ForeverTestTimeout, 1*time.Second)
+			assert.Empty(t, syncConfigManager.GetConversions())
 
 			deleteDownsyncedResources := syncConfigManager.GetDownUnsyncedResources()
 			assertEqualArrayWithouOrder(t, tc.expected.downSyncedResources, deleteDownsyncedResources)
@@ -226,6 +288,47 @@ func TestSyncerConfig(t *testing.T) {
 	}
 }
 
+func TestUpsyncCollisionPolicyForSelectors(t *testing.T) {
+	logger := klog.Background()
+	syncerConfigManager := NewSyncerConfigManager(logger, nil, clientfactory.ClientFactory{}, clientfactory.ClientFactory{})
+	config := syncerConfig("test-syncer-config", "uid")
+	config.Spec.Upsync = []edgev1alpha1.UpsyncSet{
+		{
+			Resources:       []string{"configmaps"},
+			Namespaces:      []string{"default"},
+			LabelSelector:   &metav1.LabelSelector{MatchLabels: map[string]string{"app": "edge"}},
+			CollisionPolicy: edgev1alpha1.UpsyncCollisionPolicyPrefix,
+		},
+		{
+			Resources:       []string{"configmaps"},
+			Namespaces:      []string{"default"},
+			Names:           []string{"cm-merge"},
+			CollisionPolicy: edgev1alpha1.UpsyncCollisionPolicyMerge,
+		},
+	}
+	syncerConfigManager.syncerConfigMap[config.Name] = *config
+	configMaps := schema.GroupResource{Resource: "configmaps"}
+
+	for _, tc := range []struct {
+		description string
+		name        string
+		labels      map[string]string
+		expected    edgev1alpha1.UpsyncCollisionPolicy
+	}{
+		{"selected, with no names in the UpsyncSet", "cm-1", map[string]string{"app": "edge"}, edgev1alpha1.UpsyncCollisionPolicyPrefix},
+		{"not selected", "cm-1", map[string]string{"app": "other"}, edgev1alpha1.UpsyncCollisionPolicyMerge},
+		{"no labels", "cm-1", map[string]string{}, edgev1alpha1.UpsyncCollisionPolicyMerge},
+		{"labels not at hand", "cm-1", nil, edgev1alpha1.UpsyncCollisionPolicyPrefix},
+		{"selected, and named by another UpsyncSet", "cm-merge", map[string]string{"app": "edge"}, edgev1alpha1.UpsyncCollisionPolicyPrefix},
+		{"not selected, and named by another UpsyncSet", "cm-merge", map[string]string{}, edgev1alpha1.UpsyncCollisionPolicyMerge},
+	} {
+		t.Run(tc.description, func(t *testing.T) {
+			policy := syncerConfigManager.UpsyncCollisionPolicyFor(configMaps, "default", tc.name, tc.labels)
+			assert.Equal(t, tc.expected, policy)
+		})
+	}
+}
+
 func syncerConfig(name string, uid types.UID) *edgev1alpha1.SyncerConfig {
 	return &edgev1alpha1.SyncerConfig{
 		ObjectMeta: metav1.ObjectMeta{
@@ -20,13 +20,19 @@ import (
 	"fmt"
 	"sync"
 
+	"k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/restmapper"
 	"k8s.io/klog/v2"
 
 	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+	edgev1alpha1listers "github.com/kubestellar/kubestellar/pkg/client/listers/edge/v1alpha1"
 	"github.com/kubestellar/kubestellar/pkg/syncer/clientfactory"
+	"github.com/kubestellar/kubestellar/pkg/syncer/localstore"
 )
 
 const (
@@ -52,6 +58,207 @@ type SyncerConfigManager struct {
 	syncerConfigMap         map[string]edgev1alpha1.SyncerConfig
 	upstreamClientFactory   clientfactory.ClientFactory
 	downstreamClientFactory clientfactory.ClientFactory
+	localStore              *localstore.Store
+}
+
+// SetLocalStore makes the manager keep the given store up to date with the SyncerConfigs,
+// and starts the manager off with the SyncerConfigs previously stored there.
+// This lets the syncer start syncing while its mailbox workspace is unreachable.
+func (s *SyncerConfigManager) SetLocalStore(store *localstore.Store) {
+	s.localStore = store
+	for _, syncerConfig := range store.ListSyncerConfigs() {
+		s.logger.V(2).Info("loaded stored syncerConfig", "syncerConfigName", syncerConfig.Name)
+		s.upsert(*syncerConfig)
+	}
+}
+
+// DropUnlisted forgets the SyncerConfigs that the given lister does not have.
+// Call this once the lister's informer has synced, to drop the stored
+// SyncerConfigs that were deleted while the mailbox workspace was unreachable.
+func (s *SyncerConfigManager) DropUnlisted(lister edgev1alpha1listers.SyncerConfigLister) {
+	s.Lock()
+	names := make([]string, 0, len(s.syncerConfigMap))
+	for name := range s.syncerConfigMap {
+		names = append(names, name)
+	}
+	s.Unlock()
+	for _, name := range names {
+		if _, err := lister.Get(name); errors.IsNotFound(err) {
+			s.delete(name)
+		}
+	}
+}
+
+// DriftPolicyFor returns the drift policy that the SyncerConfigs set for the given
+// mailbox workspace resource, or the empty string if they set none.
+func (s *SyncerConfigManager) DriftPolicyFor(gr schema.GroupResource) edgev1alpha1.DriftPolicy {
+	s.Lock()
+	defer s.Unlock()
+	for _, syncerConfig := range s.syncerConfigMap {
+		for _, driftPolicy := range syncerConfig.Spec.DriftPolicies {
+			if driftPolicy.Group == gr.Group && driftPolicy.Resource == gr.Resource {
+				return driftPolicy.Policy
+			}
+		}
+	}
+	return ""
+}
+
+// DeletionPolicyFor returns the deletion policy that the SyncerConfigs set for the given
+// mailbox workspace resource, or the zero value if they set none.
+func (s *SyncerConfigManager) DeletionPolicyFor(gr schema.GroupResource) edgev1alpha1.DeletionPolicy {
+	s.Lock()
+	defer s.Unlock()
+	for _, syncerConfig := range s.syncerConfigMap {
+		for _, deletionPolicy := range syncerConfig.Spec.DeletionPolicies {
+			if deletionPolicy.Group == gr.Group && deletionPolicy.Resource == gr.Resource {
+				return deletionPolicy.DeletionPolicy
+			}
+		}
+	}
+	return edgev1alpha1.DeletionPolicy{}
+}
+
+// IgnoreDifferencesFor returns the ignoreDifferences rules that the SyncerConfigs
+// have for the given mailbox workspace resource.
+func (s *SyncerConfigManager) IgnoreDifferencesFor(gr schema.GroupResource) []edgev1alpha1.IgnoreDifferences {
+	s.Lock()
+	defer s.Unlock()
+	ans := []edgev1alpha1.IgnoreDifferences{}
+	for _, syncerConfig := range s.syncerConfigMap {
+		for _, rule := range syncerConfig.Spec.IgnoreDifferences {
+			if rule.Group == gr.Group && rule.Resource == gr.Resource {
+				ans = append(ans, rule)
+			}
+		}
+	}
+	return ans
+}
+
+// SubresourcesFor returns what the SyncerConfigs say to do with the subresources of the
+// given mailbox workspace resource: the first `scale` that they give, and all the `names`.
+func (s *SyncerConfigManager) SubresourcesFor(gr schema.GroupResource) edgev1alpha1.ResourceSubresources {
+	s.Lock()
+	defer s.Unlock()
+	ans := edgev1alpha1.ResourceSubresources{GroupResource: v1.GroupResource{Group: gr.Group, Resource: gr.Resource}}
+	names := sets.NewString()
+	for _, syncerConfig := range s.syncerConfigMap {
+		for _, subresources := range syncerConfig.Spec.Subresources {
+			if subresources.Group != gr.Group || subresources.Resource != gr.Resource {
+				continue
+			}
+			if ans.Scale == nil {
+				ans.Scale = subresources.Scale
+			}
+			names.Insert(subresources.Names...)
+		}
+	}
+	if names.Len() > 0 {
+		ans.Names = names.List()
+	}
+	return ans
+}
+
+// LocationName returns the name of the Location that the SyncerConfigs give,
+// or the empty string if they give none.
+func (s *SyncerConfigManager) LocationName() string {
+	s.Lock()
+	defer s.Unlock()
+	for _, syncerConfig := range s.syncerConfigMap {
+		if syncerConfig.Spec.LocationName != "" {
+			return syncerConfig.Spec.LocationName
+		}
+	}
+	return ""
+}
+
+// collisionPolicyPrecedence orders the upsync collision policies, for choosing
+// among several UpsyncSets that match the same objects.
+var collisionPolicyPrecedence = []edgev1alpha1.UpsyncCollisionPolicy{
+	edgev1alpha1.UpsyncCollisionPolicyPerClusterNamespace,
+	edgev1alpha1.UpsyncCollisionPolicyPrefix,
+	edgev1alpha1.UpsyncCollisionPolicySuffix,
+}
+
+// UpsyncCollisionPolicyFor returns the collision policy for upsyncing the edge cluster objects
+// of the given resource with the given namespace (empty for cluster-scoped objects), name and labels.
+// The namespace and name may be "*", meaning all; that matches only UpsyncSets that match all.
+// As for upsyncing, an UpsyncSet with no names but a selector matches every name.
+// The label selector of an UpsyncSet is evaluated against the given labels; when those are nil,
+// because the object is not at hand, the label selector is taken to match.
+// Field selectors are not evaluated here, and an UpsyncSet with a namespaceSelector is taken
+// to match every namespace.
+// When several UpsyncSets match, the first of their policies in collisionPolicyPrecedence is returned.
+// A Namespace object not matched by any UpsyncSet, but whose objects an UpsyncSet with
+// the PerClusterNamespace policy matches, gets that policy.
+// The default is Merge.
+func (s *SyncerConfigManager) UpsyncCollisionPolicyFor(gr schema.GroupResource, namespace, name string, objLabels map[string]string) edgev1alpha1.UpsyncCollisionPolicy {
+	s.Lock()
+	defer s.Unlock()
+	matched := sets.NewString()
+	for _, syncerConfig := range s.syncerConfigMap {
+		for _, upsync := range syncerConfig.Spec.Upsync {
+			if upsync.APIGroup != gr.Group || !matchesPattern(upsync.Resources, gr.Resource) {
+				continue
+			}
+			if namespace != "" && !matchesPattern(upsync.Namespaces, namespace) && upsync.NamespaceSelector == nil {
+				continue
+			}
+			hasSelector := upsync.LabelSelector != nil || upsync.FieldSelector != "" || upsync.NamespaceSelector != nil
+			if !matchesPattern(upsync.Names, name) && !(len(upsync.Names) == 0 && hasSelector) {
+				continue
+			}
+			if upsync.LabelSelector != nil && objLabels != nil {
+				selector, err := v1.LabelSelectorAsSelector(upsync.LabelSelector)
+				if err != nil {
+					s.logger.Error(err, "invalid label selector in UpsyncSet", "syncerConfigName", syncerConfig.Name)
+					continue
+				}
+				if !selector.Matches(labels.Set(objLabels)) {
+					continue
+				}
+			}
+			matched.Insert(string(upsync.CollisionPolicy))
+		}
+	}
+	if matched.Len() == 0 && gr == (schema.GroupResource{Resource: "namespaces"}) {
+		for _, syncerConfig := range s.syncerConfigMap {
+			for _, upsync := range syncerConfig.Spec.Upsync {
+				if upsync.CollisionPolicy == edgev1alpha1.UpsyncCollisionPolicyPerClusterNamespace && (matchesPattern(upsync.Namespaces, name) || upsync.NamespaceSelector != nil) {
+					return edgev1alpha1.UpsyncCollisionPolicyPerClusterNamespace
+				}
+			}
+		}
+	}
+	for _, policy := range collisionPolicyPrecedence {
+		if matched.Has(string(policy)) {
+			return policy
+		}
+	}
+	return edgev1alpha1.UpsyncCollisionPolicyMerge
+}
+
+// selectorString returns the string form of the given label selector,
+// which is empty for a nil selector.
+func selectorString(labelSelector *v1.LabelSelector) (string, error) {
+	if labelSelector == nil {
+		return "", nil
+	}
+	selector, err := v1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return "", err
+	}
+	return selector.String(), nil
+}
+
+// matchesPattern tells whether the given list of names, in which "*" means all, includes the given name.
+func matchesPattern(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if pattern == "*" || pattern == name {
+			return true
+		}
+	}
+	return false
 }
 
 func (s *SyncerConfigManager) upsert(syncerConfig edgev1alpha1.SyncerConfig) {
@@ -60,6 +267,11 @@ func (s *SyncerConfigManager) upsert(syncerConfig edgev1alpha1.SyncerConfig) {
 	defer s.Unlock()
 	s.syncerConfigMap[syncerConfig.Name] = syncerConfig
 	logger.V(3).Info("upsert syncerConfig")
+	if s.localStore != nil {
+		if err := s.localStore.PutSyncerConfig(&syncerConfig); err != nil {
+			logger.Error(err, "failed to store syncerConfig")
+		}
+	}
 }
 
 func (s *SyncerConfigManager) Refresh() {
@@ -78,13 +290,14 @@ func (s *SyncerConfigManager) Refresh() {
 			logger.Error(err, "Failed to get API Group resources from downstream. Skip upsert operation")
 			return
 		}
-		s.upsertNamespaceScoped(syncerConfig, upstreamGroupResourcesList)
-		s.upsertClusterScoped(syncerConfig, upstreamGroupResourcesList)
-		s.upsertUpsync(syncerConfig, downstreamGroupResourcesList)
+		conversions := s.conversionsOf(syncerConfig, upstreamGroupResourcesList, downstreamGroupResourcesList)
+		s.upsertNamespaceScoped(syncerConfig, upstreamGroupResourcesList, conversions)
+		s.upsertClusterScoped(syncerConfig, upstreamGroupResourcesList, conversions)
+		s.upsertUpsync(syncerConfig, downstreamGroupResourcesList, conversions)
 	}
 }
 
-func (s *SyncerConfigManager) upsertNamespaceScoped(syncerConfig edgev1alpha1.SyncerConfig, upstreamGroupResourcesList []*restmapper.APIGroupResources) {
+func (s *SyncerConfigManager) upsertNamespaceScoped(syncerConfig edgev1alpha1.SyncerConfig, upstreamGroupResourcesList []*restmapper.APIGroupResources, conversions []edgev1alpha1.EdgeSynConversion) {
 	s.logger.V(3).Info("upsert namespace scoped resources as syncerConfig to syncConfigManager stores", "syncerConfigName", syncerConfig.Name, "numNamespaces", len(syncerConfig.Spec.NamespaceScope.Namespaces))
 	if lgr := s.logger.V(4); lgr.Enabled() {
 		for _, agrs := range upstreamGroupResourcesList {
@@ -124,12 +337,13 @@ func (s *SyncerConfigManager) upsertNamespaceScoped(syncerConfig edgev1alpha1.Sy
 		},
 		Spec: edgev1alpha1.EdgeSyncConfigSpec{
 			DownSyncedResources: edgeSyncConfigResources,
+			Conversions:         conversions,
 		},
 	}
 	s.syncConfigManager.upsert(edgeSyncConfig)
 }
 
-func (s *SyncerConfigManager) upsertClusterScoped(syncerConfig edgev1alpha1.SyncerConfig, upstreamGroupResourcesList []*restmapper.APIGroupResources) {
+func (s *SyncerConfigManager) upsertClusterScoped(syncerConfig edgev1alpha1.SyncerConfig, upstreamGroupResourcesList []*restmapper.APIGroupResources, conversions []edgev1alpha1.EdgeSynConversion) {
 	s.logger.V(3).Info(fmt.Sprintf("upsert clusterscoped resources as syncerConfig %s to syncConfigManager stores", syncerConfig.Name))
 	edgeSyncConfigResources := []edgev1alpha1.EdgeSyncConfigResource{}
 	for _, clusterScope := range syncerConfig.Spec.ClusterScope {
@@ -170,17 +384,24 @@ func (s *SyncerConfigManager) upsertClusterScoped(syncerConfig edgev1alpha1.Sync
 		},
 		Spec: edgev1alpha1.EdgeSyncConfigSpec{
 			DownSyncedResources: edgeSyncConfigResources,
+			Conversions:         conversions,
 		},
 	}
 	s.syncConfigManager.upsert(edgeSyncConfig)
 }
 
-func (s *SyncerConfigManager) upsertUpsync(syncerConfig edgev1alpha1.SyncerConfig, downstreamGroupResourcesList []*restmapper.APIGroupResources) {
+func (s *SyncerConfigManager) upsertUpsync(syncerConfig edgev1alpha1.SyncerConfig, downstreamGroupResourcesList []*restmapper.APIGroupResources, conversions []edgev1alpha1.EdgeSynConversion) {
 	s.logger.V(3).Info(fmt.Sprintf("upsert upsynced resources as syncerConfig %s to syncConfigManager stores", syncerConfig.Name))
 	edgeSyncConfigResources := []edgev1alpha1.EdgeSyncConfigResource{}
 	upsyncedNamespaces := sets.String{}
+	namespaceSelectors := sets.String{}
 	for _, upsync := range syncerConfig.Spec.Upsync {
 		upsyncedNamespaces.Insert(upsync.Namespaces...)
+		if upsync.NamespaceSelector != nil {
+			if namespaceSelector, err := selectorString(upsync.NamespaceSelector); err == nil {
+				namespaceSelectors.Insert(namespaceSelector)
+			}
+		}
 	}
 	for _, namespace := range upsyncedNamespaces.List() {
 		edgeSyncConfigResource := edgev1alpha1.EdgeSyncConfigResource{
@@ -191,18 +412,51 @@ func (s *SyncerConfigManager) upsertUpsync(syncerConfig edgev1alpha1.SyncerConfi
 		}
 		edgeSyncConfigResources = append(edgeSyncConfigResources, edgeSyncConfigResource)
 	}
+	for _, namespaceSelector := range namespaceSelectors.List() {
+		edgeSyncConfigResource := edgev1alpha1.EdgeSyncConfigResource{
+			Group:         "",
+			Version:       "v1",
+			Kind:          "Namespace",
+			Name:          "*",
+			LabelSelector: namespaceSelector,
+		}
+		edgeSyncConfigResources = append(edgeSyncConfigResources, edgeSyncConfigResource)
+	}
 	for _, upsync := range syncerConfig.Spec.Upsync {
 		group := upsync.APIGroup
 		resources := upsync.Resources
 		namespaces := upsync.Namespaces
 		names := upsync.Names
+		if len(names) == 0 && (upsync.LabelSelector != nil || upsync.FieldSelector != "" || upsync.NamespaceSelector != nil) {
+			// With a selector, no names means all names
+			names = []string{"*"}
+		}
+		labelSelector, err := selectorString(upsync.LabelSelector)
+		if err != nil {
+			s.logger.Error(err, "ignoring upsync set with invalid labelSelector", "syncerConfigName", syncerConfig.Name, "upsyncSet", upsync)
+			continue
+		}
+		if _, err := fields.ParseSelector(upsync.FieldSelector); err != nil {
+			s.logger.Error(err, "ignoring upsync set with invalid fieldSelector", "syncerConfigName", syncerConfig.Name, "upsyncSet", upsync)
+			continue
+		}
+		var namespaceSelector string
+		if upsync.NamespaceSelector != nil {
+			namespaceSelector, err = selectorString(upsync.NamespaceSelector)
+			if err != nil {
+				s.logger.Error(err, "ignoring upsync set with invalid namespaceSelector", "syncerConfigName", syncerConfig.Name, "upsyncSet", upsync)
+				continue
+			}
+		}
 		for _, resource := range resources {
 			versionedResources := findVersionedResourcesByGV(group, resource, downstreamGroupResourcesList, s.logger)
 			for _, versionedResource := range versionedResources {
 				edgeSyncConfigResource := edgev1alpha1.EdgeSyncConfigResource{
-					Group:   group,
-					Version: versionedResource.Version,
-					Kind:    versionedResource.Kind,
+					Group:         group,
+					Version:       versionedResource.Version,
+					Kind:          versionedResource.Kind,
+					LabelSelector: labelSelector,
+					FieldSelector: upsync.FieldSelector,
 				}
 				if versionedResource.Namespaced {
 					for _, namespace := range namespaces {
@@ -212,6 +466,14 @@ func (s *SyncerConfigManager) upsertUpsync(syncerConfig edgev1alpha1.SyncerConfi
 							edgeSyncConfigResources = append(edgeSyncConfigResources, edgeSyncConfigResource)
 						}
 					}
+					if upsync.NamespaceSelector != nil {
+						for _, name := range names {
+							edgeSyncConfigResource.Namespace = "*"
+							edgeSyncConfigResource.NamespaceSelector = namespaceSelector
+							edgeSyncConfigResource.Name = name
+							edgeSyncConfigResources = append(edgeSyncConfigResources, edgeSyncConfigResource)
+						}
+					}
 				} else {
 					for _, name := range names {
 						edgeSyncConfigResource.Name = name
@@ -227,11 +489,33 @@ func (s *SyncerConfigManager) upsertUpsync(syncerConfig edgev1alpha1.SyncerConfi
 		},
 		Spec: edgev1alpha1.EdgeSyncConfigSpec{
 			UpSyncedResources: edgeSyncConfigResources,
+			Conversions:       conversions,
 		},
 	}
 	s.syncConfigManager.upsert(edgeSyncConfig)
 }
 
+// conversionsOf translates the SyncerConfig's conversions, which are in terms of resources,
+// into EdgeSynConversions, which are in terms of kinds.
+// A conversion whose resources are not known on both sides is skipped.
+func (s *SyncerConfigManager) conversionsOf(syncerConfig edgev1alpha1.SyncerConfig, upstreamGroupResourcesList, downstreamGroupResourcesList []*restmapper.APIGroupResources) []edgev1alpha1.EdgeSynConversion {
+	conversions := []edgev1alpha1.EdgeSynConversion{}
+	for _, conversion := range syncerConfig.Spec.Conversions {
+		upstreamResources := findVersionedResourcesByGV(conversion.Upstream.Group, conversion.Upstream.Resource, upstreamGroupResourcesList, s.logger)
+		downstreamResources := findVersionedResourcesByGV(conversion.Downstream.Group, conversion.Downstream.Resource, downstreamGroupResourcesList, s.logger)
+		if len(upstreamResources) == 0 || len(downstreamResources) == 0 {
+			s.logger.V(2).Info("Skipping conversion of unknown resource", "syncerConfigName", syncerConfig.Name, "conversion", conversion,
+				"upstreamKnown", len(upstreamResources) > 0, "downstreamKnown", len(downstreamResources) > 0)
+			continue
+		}
+		conversions = append(conversions, edgev1alpha1.EdgeSynConversion{
+			Upstream:   edgev1alpha1.EdgeSyncConfigResource{Group: conversion.Upstream.Group, Kind: upstreamResources[0].Kind},
+			Downstream: edgev1alpha1.EdgeSyncConfigResource{Group: conversion.Downstream.Group, Kind: downstreamResources[0].Kind},
+		})
+	}
+	return conversions
+}
+
 func (s *SyncerConfigManager) delete(key string) {
 	logger := s.logger.WithValues("syncerConfigName", key)
 	logger.V(3).Info("delete syncConfigs for syncerConfig from syncConfigManager stores")
@@ -241,6 +525,11 @@ func (s *SyncerConfigManager) delete(key string) {
 	s.syncConfigManager.delete(key + DOWNSYNC_NAMESPACED_SUFFIX)
 	s.syncConfigManager.delete(key + DOWNSYNC_CLUSTERSCOPED_SUFFIX)
 	s.syncConfigManager.delete(key + UPSYNC_SUFFIX)
+	if s.localStore != nil {
+		if err := s.localStore.DeleteSyncerConfig(key); err != nil {
+			logger.Error(err, "failed to forget stored syncerConfig")
+		}
+	}
 }
 
 func findVersionedResourcesByGVR(group string, version string, resource string, apiGroupResourcesList []*restmapper.APIGroupResources, logger klog.Logger) []v1.APIResource {
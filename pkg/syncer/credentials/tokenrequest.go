@@ -0,0 +1,141 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package credentials provides refreshable credentials for the syncer's connection to its mailbox workspace.
+package credentials
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/transport"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+)
+
+// TokenRequestSource is an oauth2.TokenSource that gets short-lived tokens for a ServiceAccount
+// in the mailbox workspace through the TokenRequest API.
+// Every token is requested with the bootstrap credential (the token or token file of the given config),
+// never with a previous token, so that a leaked short-lived token cannot be used to get more of them.
+// The bootstrap credential should itself be bounded, such as a projected or bound ServiceAccount token.
+type TokenRequestSource struct {
+	lock      sync.Mutex
+	namespace string
+	name      string
+	lifetime  time.Duration
+	bootstrap func() (string, error)
+
+	// newClient makes the client that requests a token with the given token; a variable for testing
+	newClient func(token string) (corev1client.ServiceAccountsGetter, error)
+}
+
+var _ oauth2.TokenSource = &TokenRequestSource{}
+
+// NewTokenRequestSource returns a source of tokens, each valid for the given lifetime, for the named
+// ServiceAccount in the given logical cluster of the server of the given config.
+func NewTokenRequestSource(config *rest.Config, clusterPath logicalcluster.Path, namespace, name string, lifetime time.Duration) *TokenRequestSource {
+	bootstrapToken, bootstrapTokenFile := config.BearerToken, config.BearerTokenFile
+	requestConfig := rest.AnonymousClientConfig(config)
+	requestConfig.Host = strings.TrimSuffix(config.Host, "/") + clusterPath.RequestPath()
+	requestConfig = rest.AddUserAgent(requestConfig, "kubestellar-syncer-token-request")
+	return &TokenRequestSource{
+		namespace: namespace,
+		name:      name,
+		lifetime:  lifetime,
+		bootstrap: func() (string, error) {
+			if bootstrapTokenFile == "" {
+				return bootstrapToken, nil
+			}
+			// Read the file every time, so that a rotated bootstrap credential is picked up
+			tokenBytes, err := os.ReadFile(bootstrapTokenFile)
+			if err != nil {
+				return "", err
+			}
+			return strings.TrimSpace(string(tokenBytes)), nil
+		},
+		newClient: func(token string) (corev1client.ServiceAccountsGetter, error) {
+			tokenConfig := rest.CopyConfig(requestConfig)
+			tokenConfig.BearerToken = token
+			client, err := kubernetes.NewForConfig(tokenConfig)
+			if err != nil {
+				return nil, err
+			}
+			return client.CoreV1(), nil
+		},
+	}
+}
+
+// Use makes the clients made from the given config authenticate with tokens from this source
+// instead of the config's own token or token file.
+func (s *TokenRequestSource) Use(config *rest.Config) {
+	config.BearerToken = ""
+	config.BearerTokenFile = ""
+	config.Wrap(transport.ResettableTokenSourceWrapTransport(transport.NewCachedTokenSource(s)))
+}
+
+// Token requests a new token with the bootstrap credential.
+// The returned token expires, as far as callers are concerned, after four fifths of its lifetime,
+// so that it is replaced well before the server stops accepting it.
+func (s *TokenRequestSource) Token() (*oauth2.Token, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	bootstrap, err := s.bootstrap()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the bootstrap credential: %w", err)
+	}
+	if bootstrap == "" {
+		return nil, errors.New("no bootstrap credential to request a token with")
+	}
+	token, expiry, err := s.request(bootstrap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request a token for ServiceAccount %s/%s: %w", s.namespace, s.name, err)
+	}
+	now := time.Now()
+	return &oauth2.Token{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		Expiry:      now.Add(expiry.Sub(now) * 4 / 5),
+	}, nil
+}
+
+func (s *TokenRequestSource) request(credential string) (string, time.Time, error) {
+	client, err := s.newClient(credential)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	expirationSeconds := int64(s.lifetime / time.Second)
+	tokenRequest, err := client.ServiceAccounts(s.namespace).CreateToken(ctx, s.name, &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: &expirationSeconds},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return tokenRequest.Status.Token, tokenRequest.Status.ExpirationTimestamp.Time, nil
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+)
+
+// tokenServer mints tokens for requests made with an accepted token.
+type tokenServer struct {
+	accepted map[string]bool
+	minted   int
+	// usedCredentials lists the credentials that the requests were made with
+	usedCredentials []string
+}
+
+func (ts *tokenServer) newClient(token string) (corev1client.ServiceAccountsGetter, error) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "serviceaccounts", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "token" {
+			return false, nil, nil
+		}
+		ts.usedCredentials = append(ts.usedCredentials, token)
+		if !ts.accepted[token] {
+			return true, nil, k8serrors.NewUnauthorized("token rejected")
+		}
+		request := action.(clienttesting.CreateAction).GetObject().(*authenticationv1.TokenRequest)
+		ts.minted++
+		minted := fmt.Sprintf("minted-%d", ts.minted)
+		ts.accepted[minted] = true
+		request.Status = authenticationv1.TokenRequestStatus{
+			Token:               minted,
+			ExpirationTimestamp: metav1.NewTime(time.Now().Add(time.Duration(*request.Spec.ExpirationSeconds) * time.Second)),
+		}
+		return true, request, nil
+	})
+	return client.CoreV1(), nil
+}
+
+func TestTokenRequestSource(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("bootstrap-1\n"), 0o600))
+	server := &tokenServer{accepted: map[string]bool{"bootstrap-1": true, "bootstrap-2": true}}
+	source := NewTokenRequestSource(&rest.Config{Host: "https://kcp.example.com", BearerTokenFile: tokenFile},
+		logicalcluster.NewPath("root:mb"), "default", "syncer", time.Hour)
+	source.newClient = server.newClient
+
+	token, err := source.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "minted-1", token.AccessToken)
+	assert.WithinDuration(t, time.Now().Add(48*time.Minute), token.Expiry, time.Minute, "refreshed after four fifths of the lifetime")
+
+	token, err = source.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "minted-2", token.AccessToken)
+	assert.Equal(t, []string{"bootstrap-1", "bootstrap-1"}, server.usedCredentials, "every token is requested with the bootstrap credential")
+
+	// The bootstrap credential is rotated and the old one revoked
+	server.accepted = map[string]bool{"bootstrap-2": true}
+	server.usedCredentials = nil
+	require.NoError(t, os.WriteFile(tokenFile, []byte("bootstrap-2\n"), 0o600))
+	token, err = source.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "minted-3", token.AccessToken)
+	assert.Equal(t, []string{"bootstrap-2"}, server.usedCredentials)
+
+	server.accepted = map[string]bool{}
+	_, err = source.Token()
+	assert.Error(t, err)
+}
+
+func TestUse(t *testing.T) {
+	config := &rest.Config{Host: "https://kcp.example.com", BearerToken: "bootstrap"}
+	source := NewTokenRequestSource(config, logicalcluster.NewPath("root:mb"), "default", "syncer", time.Hour)
+	source.Use(config)
+	assert.Empty(t, config.BearerToken)
+	assert.Empty(t, config.BearerTokenFile)
+	assert.NotNil(t, config.WrapTransport)
+	bootstrap, err := source.bootstrap()
+	require.NoError(t, err)
+	assert.Equal(t, "bootstrap", bootstrap, "the bootstrap credential is kept")
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/apiserver/pkg/server/healthz"
+	"k8s.io/apiserver/pkg/server/mux"
+
+	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+	"github.com/kubestellar/kubestellar/pkg/syncer/controller"
+	"github.com/kubestellar/kubestellar/pkg/syncer/metrics"
+	"github.com/kubestellar/kubestellar/pkg/syncer/syncers"
+)
+
+// DebugStatePath is where the state of the syncer is served.
+const DebugStatePath = "/debug/syncer/state"
+
+// Health tells how a running syncer is doing, through the /healthz, /readyz
+// and /debug/syncer/state endpoints.
+// It is filled in by RunSyncer; until then the syncer is not ready.
+type Health struct {
+	lock               sync.Mutex
+	standby            bool
+	cachesSynced       bool
+	upstreamStaleAfter time.Duration
+	syncConfigManager  *controller.SyncConfigManager
+	statusStore        *syncers.SyncStatusStore
+
+	// lastUpstreamSuccess returns the time the upstream last answered; a variable for testing
+	lastUpstreamSuccess func() time.Time
+}
+
+func NewHealth() *Health {
+	return &Health{lastUpstreamSuccess: metrics.LastUpstreamSuccess}
+}
+
+// State is what the /debug/syncer/state endpoint serves.
+type State struct {
+	DownSyncedResources   []edgev1alpha1.EdgeSyncConfigResource `json:"downSyncedResources"`
+	UpSyncedResources     []edgev1alpha1.EdgeSyncConfigResource `json:"upSyncedResources"`
+	DownUnsyncedResources []edgev1alpha1.EdgeSyncConfigResource `json:"downUnsyncedResources"`
+	UpUnsyncedResources   []edgev1alpha1.EdgeSyncConfigResource `json:"upUnsyncedResources"`
+	Conversions           []edgev1alpha1.EdgeSynConversion      `json:"conversions"`
+	ObjectStatuses        []edgev1alpha1.SyncedObjectStatus     `json:"objectStatuses"`
+}
+
+// InstallHandlers installs the /healthz, /readyz and /debug/syncer/state endpoints on the given mux.
+// /healthz only tells that the process is serving.
+// /readyz tells whether the initial sync of the caches is done and the upstream is reachable.
+func (h *Health) InstallHandlers(mymux *mux.PathRecorderMux) {
+	healthz.InstallHandler(mymux)
+	healthz.InstallReadyzHandler(mymux,
+		healthz.NamedCheck("caches-synced", h.checkCachesSynced),
+		healthz.NamedCheck("upstream-reachable", h.checkUpstreamReachable))
+	mymux.HandleFunc(DebugStatePath, h.serveState)
+}
+
+// SetStandby sets whether this replica is waiting to become the leader.
+// A replica on standby is ready, since it is ready to take over.
+func (h *Health) SetStandby(standby bool) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.standby = standby
+}
+
+func (h *Health) setComponents(syncConfigManager *controller.SyncConfigManager, statusStore *syncers.SyncStatusStore, upstreamStaleAfter time.Duration) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.syncConfigManager = syncConfigManager
+	h.statusStore = statusStore
+	h.upstreamStaleAfter = upstreamStaleAfter
+}
+
+func (h *Health) setCachesSynced() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.cachesSynced = true
+}
+
+func (h *Health) checkCachesSynced(_ *http.Request) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if !h.cachesSynced && !h.standby {
+		return errors.New("the initial sync of the caches is not done yet")
+	}
+	return nil
+}
+
+// checkUpstreamReachable fails if the upstream has not answered for longer than upstreamStaleAfter.
+// The heartbeat makes sure that the syncer talks to the upstream more often than that.
+func (h *Health) checkUpstreamReachable(_ *http.Request) error {
+	h.lock.Lock()
+	staleAfter, standby := h.upstreamStaleAfter, h.standby
+	h.lock.Unlock()
+	if standby {
+		return nil
+	}
+	last := h.lastUpstreamSuccess()
+	if last.IsZero() {
+		return errors.New("the upstream has not answered yet")
+	}
+	if staleAfter > 0 && time.Since(last) > staleAfter {
+		return fmt.Errorf("the upstream last answered %v ago", time.Since(last).Round(time.Second))
+	}
+	return nil
+}
+
+func (h *Health) serveState(w http.ResponseWriter, _ *http.Request) {
+	h.lock.Lock()
+	syncConfigManager, statusStore := h.syncConfigManager, h.statusStore
+	h.lock.Unlock()
+	if syncConfigManager == nil || statusStore == nil {
+		http.Error(w, "the syncer is not running yet", http.StatusServiceUnavailable)
+		return
+	}
+	state := State{
+		DownSyncedResources:   syncConfigManager.GetDownSyncedResources(),
+		UpSyncedResources:     syncConfigManager.GetUpSyncedResources(),
+		DownUnsyncedResources: syncConfigManager.GetDownUnsyncedResources(),
+		UpUnsyncedResources:   syncConfigManager.GetUpUnsyncedResources(),
+		Conversions:           syncConfigManager.GetConversions(),
+		ObjectStatuses:        statusStore.List(),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(state); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apiserver/pkg/server/mux"
+	"k8s.io/klog/v2"
+
+	"github.com/kubestellar/kubestellar/pkg/syncer/controller"
+	"github.com/kubestellar/kubestellar/pkg/syncer/syncers"
+)
+
+func TestHealth(t *testing.T) {
+	lastUpstreamSuccess := time.Time{}
+	health := NewHealth()
+	health.lastUpstreamSuccess = func() time.Time { return lastUpstreamSuccess }
+	mymux := mux.NewPathRecorderMux("test")
+	health.InstallHandlers(mymux)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		mymux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		return recorder
+	}
+
+	assert.Equal(t, http.StatusOK, get("/healthz").Code)
+	assert.Equal(t, http.StatusInternalServerError, get("/readyz").Code, "not ready before RunSyncer")
+	assert.Equal(t, http.StatusServiceUnavailable, get(DebugStatePath).Code)
+
+	health.SetStandby(true)
+	assert.Equal(t, http.StatusOK, get("/readyz").Code, "ready while waiting to become the leader")
+	health.SetStandby(false)
+
+	health.setComponents(controller.NewSyncConfigManager(klog.Background()), syncers.NewSyncStatusStore(), time.Minute)
+	lastUpstreamSuccess = time.Now()
+	assert.Equal(t, http.StatusInternalServerError, get("/readyz").Code, "not ready before the caches are synced")
+
+	health.setCachesSynced()
+	assert.Equal(t, http.StatusOK, get("/readyz").Code)
+
+	lastUpstreamSuccess = time.Now().Add(-2 * time.Minute)
+	recorder := get("/readyz?verbose")
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code, "not ready when the upstream is stale")
+	assert.Contains(t, recorder.Body.String(), "upstream-reachable failed")
+
+	recorder = get(DebugStatePath)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	state := State{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &state))
+	assert.Empty(t, state.DownSyncedResources)
+	assert.Empty(t, state.ObjectStatuses)
+}
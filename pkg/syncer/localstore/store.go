@@ -0,0 +1,451 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package localstore holds, in a local directory, the last known desired state
+// that the syncer read from its mailbox workspace, and the writes to the mailbox
+// workspace that could not be made while it was unreachable.
+// This lets the syncer keep enforcing the desired state on the edge cluster,
+// and keep recording upsync and status changes, while disconnected;
+// even across a restart of the syncer.
+package localstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/restmapper"
+
+	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+)
+
+const (
+	objectsDir       = "objects"
+	syncerConfigsDir = "syncerconfigs"
+	pendingDir       = "pending"
+	discoveryFile    = "discovery.json"
+)
+
+// WriteOp identifies the sort of a pending write
+type WriteOp string
+
+const (
+	WriteOpApply        WriteOp = "Apply"
+	WriteOpUpdateStatus WriteOp = "UpdateStatus"
+	WriteOpDelete       WriteOp = "Delete"
+)
+
+// PendingWrite is a write to the mailbox workspace that was deferred
+// because the mailbox workspace was unreachable.
+type PendingWrite struct {
+	Op        WriteOp                     `json:"op"`
+	Resource  schema.GroupVersionResource `json:"resource"`
+	Namespace string                      `json:"namespace,omitempty"`
+	Name      string                      `json:"name"`
+
+	// BaseResourceVersion is the resourceVersion of the object, as last read
+	// from the mailbox workspace, when the first of the writes that this one
+	// supersedes was deferred. Empty means that the object was not known to exist.
+	BaseResourceVersion string `json:"baseResourceVersion,omitempty"`
+
+	// Object is the object to apply or whose status to write; nil for a delete.
+	Object *unstructured.Unstructured `json:"object,omitempty"`
+}
+
+// Key identifies the object and aspect written.
+// A later pending write with the same key supersedes an earlier one.
+func (pw *PendingWrite) Key() string {
+	aspect := "spec"
+	if pw.Op == WriteOpUpdateStatus {
+		aspect = "status"
+	}
+	return aspect + "/" + objectKey(pw.Resource, pw.Namespace, pw.Name)
+}
+
+// Store is a durable cache of the syncer's view of its mailbox workspace.
+// The contents are kept in memory and written through to files in a directory.
+type Store struct {
+	dir string
+
+	lock          sync.Mutex
+	objects       map[string]*unstructured.Unstructured // key is from objectKey
+	syncerConfigs map[string]*edgev1alpha1.SyncerConfig
+	pending       map[string]*PendingWrite // key is the file name made from PendingWrite.Key
+}
+
+// Open returns a Store that keeps its files in the given directory,
+// loaded with whatever was stored there previously.
+func Open(dir string) (*Store, error) {
+	store := &Store{
+		dir:           dir,
+		objects:       map[string]*unstructured.Unstructured{},
+		syncerConfigs: map[string]*edgev1alpha1.SyncerConfig{},
+		pending:       map[string]*PendingWrite{},
+	}
+	for _, subdir := range []string{objectsDir, syncerConfigsDir, pendingDir} {
+		if err := os.MkdirAll(filepath.Join(dir, subdir), 0o700); err != nil {
+			return nil, err
+		}
+	}
+	if err := loadDir(filepath.Join(dir, objectsDir), func(name string, data []byte) error {
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(data); err != nil {
+			return err
+		}
+		store.objects[name] = obj
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if err := loadDir(filepath.Join(dir, syncerConfigsDir), func(name string, data []byte) error {
+		syncerConfig := &edgev1alpha1.SyncerConfig{}
+		if err := json.Unmarshal(data, syncerConfig); err != nil {
+			return err
+		}
+		store.syncerConfigs[name] = syncerConfig
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if err := loadDir(filepath.Join(dir, pendingDir), func(name string, data []byte) error {
+		pw := &PendingWrite{}
+		if err := json.Unmarshal(data, pw); err != nil {
+			return err
+		}
+		store.pending[name] = pw
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// GetObject returns the stored copy of the given object, if any.
+func (s *Store) GetObject(resource schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	obj, found := s.objects[objectKey(resource, namespace, name)]
+	if !found {
+		return nil, false
+	}
+	return obj.DeepCopy(), true
+}
+
+// ListObjects returns the stored copies of the objects of the given resource
+// in the given namespace; all namespaces if namespace is empty.
+func (s *Store) ListObjects(resource schema.GroupVersionResource, namespace string) []unstructured.Unstructured {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	ans := []unstructured.Unstructured{}
+	for key, obj := range s.objects {
+		if objectMatches(key, obj, resource, namespace) {
+			ans = append(ans, *obj.DeepCopy())
+		}
+	}
+	sort.Slice(ans, func(i, j int) bool {
+		return ans[i].GetNamespace() < ans[j].GetNamespace() ||
+			ans[i].GetNamespace() == ans[j].GetNamespace() && ans[i].GetName() < ans[j].GetName()
+	})
+	return ans
+}
+
+// PutObject stores a copy of the given object, read from the mailbox workspace.
+func (s *Store) PutObject(resource schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.putObjectLocked(resource, obj)
+}
+
+func (s *Store) putObjectLocked(resource schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+	key := objectKey(resource, obj.GetNamespace(), obj.GetName())
+	if current, found := s.objects[key]; found && current.GetResourceVersion() == obj.GetResourceVersion() && obj.GetResourceVersion() != "" {
+		return nil
+	}
+	obj = obj.DeepCopy()
+	obj.SetManagedFields(nil)
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	if err := writeFile(filepath.Join(s.dir, objectsDir, key), data); err != nil {
+		return err
+	}
+	s.objects[key] = obj
+	return nil
+}
+
+// DeleteObject forgets the given object.
+func (s *Store) DeleteObject(resource schema.GroupVersionResource, namespace, name string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.deleteObjectLocked(objectKey(resource, namespace, name))
+}
+
+func (s *Store) deleteObjectLocked(key string) error {
+	if _, found := s.objects[key]; !found {
+		return nil
+	}
+	if err := removeFile(filepath.Join(s.dir, objectsDir, key)); err != nil {
+		return err
+	}
+	delete(s.objects, key)
+	return nil
+}
+
+// ReplaceObjects makes the stored objects of the given resource in the given namespace
+// (all namespaces if empty) be exactly the given ones.
+func (s *Store) ReplaceObjects(resource schema.GroupVersionResource, namespace string, objs []unstructured.Unstructured) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	keep := map[string]bool{}
+	for idx := range objs {
+		obj := &objs[idx]
+		keep[objectKey(resource, obj.GetNamespace(), obj.GetName())] = true
+		if err := s.putObjectLocked(resource, obj); err != nil {
+			return err
+		}
+	}
+	for key, obj := range s.objects {
+		if !keep[key] && objectMatches(key, obj, resource, namespace) {
+			if err := s.deleteObjectLocked(key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RetainObjects forgets the stored objects of the given resource in the given namespace
+// (all namespaces if empty) for which the given function returns false.
+func (s *Store) RetainObjects(resource schema.GroupVersionResource, namespace string, keep func(namespace, name string) bool) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for key, obj := range s.objects {
+		if objectMatches(key, obj, resource, namespace) && !keep(obj.GetNamespace(), obj.GetName()) {
+			if err := s.deleteObjectLocked(key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ListSyncerConfigs returns the stored SyncerConfigs.
+func (s *Store) ListSyncerConfigs() []*edgev1alpha1.SyncerConfig {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	ans := make([]*edgev1alpha1.SyncerConfig, 0, len(s.syncerConfigs))
+	for _, syncerConfig := range s.syncerConfigs {
+		ans = append(ans, syncerConfig.DeepCopy())
+	}
+	sort.Slice(ans, func(i, j int) bool { return ans[i].Name < ans[j].Name })
+	return ans
+}
+
+// PutSyncerConfig stores a copy of the given SyncerConfig.
+// Only its spec is meaningful to the syncer, so the status is not stored.
+func (s *Store) PutSyncerConfig(syncerConfig *edgev1alpha1.SyncerConfig) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	syncerConfig = syncerConfig.DeepCopy()
+	syncerConfig.ManagedFields = nil
+	syncerConfig.Status = edgev1alpha1.SyncerConfigStatus{}
+	data, err := json.Marshal(syncerConfig)
+	if err != nil {
+		return err
+	}
+	key := url.QueryEscape(syncerConfig.Name)
+	if err := writeFile(filepath.Join(s.dir, syncerConfigsDir, key), data); err != nil {
+		return err
+	}
+	s.syncerConfigs[key] = syncerConfig
+	return nil
+}
+
+// DeleteSyncerConfig forgets the named SyncerConfig.
+func (s *Store) DeleteSyncerConfig(name string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	key := url.QueryEscape(name)
+	if _, found := s.syncerConfigs[key]; !found {
+		return nil
+	}
+	if err := removeFile(filepath.Join(s.dir, syncerConfigsDir, key)); err != nil {
+		return err
+	}
+	delete(s.syncerConfigs, key)
+	return nil
+}
+
+// PutDiscovery stores the API discovery information of the mailbox workspace.
+func (s *Store) PutDiscovery(groupResources []*restmapper.APIGroupResources) error {
+	data, err := json.Marshal(groupResources)
+	if err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return writeFile(filepath.Join(s.dir, discoveryFile), data)
+}
+
+// GetDiscovery returns the stored API discovery information, if any.
+func (s *Store) GetDiscovery() ([]*restmapper.APIGroupResources, bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	data, err := os.ReadFile(filepath.Join(s.dir, discoveryFile))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+	ans := []*restmapper.APIGroupResources{}
+	if err := json.Unmarshal(data, &ans); err != nil {
+		return nil, false, err
+	}
+	return ans, true, nil
+}
+
+// AddPendingWrite records a deferred write, superseding any earlier one with the same key.
+// The superseding write keeps the base resourceVersion of the superseded one,
+// so that a conflict is detected against the state from before the first deferral.
+func (s *Store) AddPendingWrite(pw PendingWrite) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	fileName := url.QueryEscape(pw.Key())
+	if earlier, found := s.pending[fileName]; found {
+		pw.BaseResourceVersion = earlier.BaseResourceVersion
+	} else if obj, found := s.objects[objectKey(pw.Resource, pw.Namespace, pw.Name)]; found {
+		pw.BaseResourceVersion = obj.GetResourceVersion()
+	} else {
+		pw.BaseResourceVersion = ""
+	}
+	data, err := json.Marshal(&pw)
+	if err != nil {
+		return err
+	}
+	if err := writeFile(filepath.Join(s.dir, pendingDir, fileName), data); err != nil {
+		return err
+	}
+	s.pending[fileName] = &pw
+	return nil
+}
+
+// PendingWrites returns the deferred writes, in a stable order.
+func (s *Store) PendingWrites() []PendingWrite {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	ans := make([]PendingWrite, 0, len(s.pending))
+	for _, pw := range s.pending {
+		pwCopy := *pw
+		if pw.Object != nil {
+			pwCopy.Object = pw.Object.DeepCopy()
+		}
+		ans = append(ans, pwCopy)
+	}
+	sort.Slice(ans, func(i, j int) bool { return ans[i].Key() < ans[j].Key() })
+	return ans
+}
+
+// HasPendingWrites tells whether there are any deferred writes.
+func (s *Store) HasPendingWrites() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return len(s.pending) > 0
+}
+
+// RemovePendingWrite forgets the deferred write with the given key, if any.
+func (s *Store) RemovePendingWrite(key string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	fileName := url.QueryEscape(key)
+	if _, found := s.pending[fileName]; !found {
+		return nil
+	}
+	if err := removeFile(filepath.Join(s.dir, pendingDir, fileName)); err != nil {
+		return err
+	}
+	delete(s.pending, fileName)
+	return nil
+}
+
+// objectKey makes the key, which is also a file name, for an object
+func objectKey(resource schema.GroupVersionResource, namespace, name string) string {
+	return url.QueryEscape(strings.Join([]string{resource.Group, resource.Version, resource.Resource, namespace, name}, "/"))
+}
+
+// objectMatches tells whether the object stored under the given key is
+// of the given resource and in the given namespace (any namespace if empty)
+func objectMatches(key string, obj *unstructured.Unstructured, resource schema.GroupVersionResource, namespace string) bool {
+	prefix := url.QueryEscape(strings.Join([]string{resource.Group, resource.Version, resource.Resource, ""}, "/"))
+	return strings.HasPrefix(key, prefix) && (namespace == "" || obj.GetNamespace() == namespace)
+}
+
+func loadDir(dir string, load func(name string, data []byte) error) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		if err := load(entry.Name(), data); err != nil {
+			return fmt.Errorf("failed to load %s: %w", filepath.Join(dir, entry.Name()), err)
+		}
+	}
+	return nil
+}
+
+// writeFile writes the file atomically, so that a crash leaves either the old or the new contents
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func removeFile(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package localstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+)
+
+var configMapGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+func configMap(namespace, name, resourceVersion string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.SetResourceVersion(resourceVersion)
+	return obj
+}
+
+func TestStoreSurvivesReopen(t *testing.T) {
+	dir := t.TempDir()
+	store, err := Open(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, store.PutObject(configMapGVR, configMap("ns1", "cm1", "1")))
+	require.NoError(t, store.PutObject(configMapGVR, configMap("ns2", "cm2", "2")))
+	require.NoError(t, store.PutObject(configMapGVR, configMap("ns2", "gone", "3")))
+	require.NoError(t, store.DeleteObject(configMapGVR, "ns2", "gone"))
+	require.NoError(t, store.PutSyncerConfig(&edgev1alpha1.SyncerConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "the-one"},
+		Status:     edgev1alpha1.SyncerConfigStatus{LastSyncerHeartbeatTime: &metav1.Time{}},
+	}))
+
+	reopened, err := Open(dir)
+	require.NoError(t, err)
+	obj, found := reopened.GetObject(configMapGVR, "ns1", "cm1")
+	require.True(t, found)
+	require.Equal(t, "1", obj.GetResourceVersion())
+	_, found = reopened.GetObject(configMapGVR, "ns2", "gone")
+	require.False(t, found)
+	require.Len(t, reopened.ListObjects(configMapGVR, ""), 2)
+	require.Len(t, reopened.ListObjects(configMapGVR, "ns2"), 1)
+	require.Empty(t, reopened.ListObjects(schema.GroupVersionResource{Version: "v1", Resource: "secrets"}, ""))
+	syncerConfigs := reopened.ListSyncerConfigs()
+	require.Len(t, syncerConfigs, 1)
+	require.Equal(t, "the-one", syncerConfigs[0].Name)
+	require.Nil(t, syncerConfigs[0].Status.LastSyncerHeartbeatTime, "status should not be stored")
+}
+
+func TestRetainObjects(t *testing.T) {
+	store, err := Open(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, store.PutObject(configMapGVR, configMap("ns1", "cm1", "1")))
+	require.NoError(t, store.PutObject(configMapGVR, configMap("ns1", "cm2", "2")))
+	require.NoError(t, store.PutObject(configMapGVR, configMap("ns2", "cm3", "3")))
+
+	require.NoError(t, store.RetainObjects(configMapGVR, "ns1", func(namespace, name string) bool { return name == "cm1" }))
+	_, found := store.GetObject(configMapGVR, "ns1", "cm1")
+	require.True(t, found)
+	_, found = store.GetObject(configMapGVR, "ns1", "cm2")
+	require.False(t, found)
+	// Other namespaces are left alone
+	_, found = store.GetObject(configMapGVR, "ns2", "cm3")
+	require.True(t, found)
+}
+
+func TestPendingWrites(t *testing.T) {
+	dir := t.TempDir()
+	store, err := Open(dir)
+	require.NoError(t, err)
+	require.False(t, store.HasPendingWrites())
+	require.NoError(t, store.PutObject(configMapGVR, configMap("ns1", "cm1", "5")))
+
+	first := PendingWrite{Op: WriteOpApply, Resource: configMapGVR, Namespace: "ns1", Name: "cm1", Object: configMap("ns1", "cm1", "")}
+	require.NoError(t, store.AddPendingWrite(first))
+	// A later read must not move the base of the deferred write
+	require.NoError(t, store.PutObject(configMapGVR, configMap("ns1", "cm1", "6")))
+	second := PendingWrite{Op: WriteOpApply, Resource: configMapGVR, Namespace: "ns1", Name: "cm1", Object: configMap("ns1", "cm1", "")}
+	second.Object.SetLabels(map[string]string{"second": "true"})
+	require.NoError(t, store.AddPendingWrite(second))
+	status := PendingWrite{Op: WriteOpUpdateStatus, Resource: configMapGVR, Namespace: "ns1", Name: "cm1", Object: configMap("ns1", "cm1", "")}
+	require.NoError(t, store.AddPendingWrite(status))
+	created := PendingWrite{Op: WriteOpApply, Resource: configMapGVR, Namespace: "ns1", Name: "new", Object: configMap("ns1", "new", "")}
+	require.NoError(t, store.AddPendingWrite(created))
+
+	reopened, err := Open(dir)
+	require.NoError(t, err)
+	require.True(t, reopened.HasPendingWrites())
+	pending := reopened.PendingWrites()
+	require.Len(t, pending, 3)
+	byKey := map[string]PendingWrite{}
+	for _, pw := range pending {
+		byKey[pw.Key()] = pw
+	}
+	specWrite := byKey[first.Key()]
+	require.Equal(t, "5", specWrite.BaseResourceVersion)
+	require.Equal(t, "true", specWrite.Object.GetLabels()["second"])
+	require.Equal(t, "6", byKey[status.Key()].BaseResourceVersion)
+	require.Equal(t, "", byKey[created.Key()].BaseResourceVersion)
+
+	for _, pw := range pending {
+		require.NoError(t, reopened.RemovePendingWrite(pw.Key()))
+	}
+	require.False(t, reopened.HasPendingWrites())
+	reopened, err = Open(dir)
+	require.NoError(t, err)
+	require.False(t, reopened.HasPendingWrites())
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics defines the Prometheus metrics of the KubeStellar-Syncer.
+// They are registered in the legacy registry, which is served at /metrics.
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/rest"
+	k8smetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+
+	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+	"github.com/kubestellar/kubestellar/pkg/syncer/clientfactory"
+)
+
+const namespace = "kubestellar_syncer"
+
+// Operation is a kind of write that the syncer makes to an object.
+type Operation string
+
+const (
+	OperationCreate Operation = "create"
+	OperationUpdate Operation = "update"
+	OperationDelete Operation = "delete"
+)
+
+// Side identifies which cluster the syncer is talking to.
+type Side string
+
+const (
+	// SideUpstream is the mailbox workspace
+	SideUpstream Side = "upstream"
+	// SideDownstream is the edge cluster
+	SideDownstream Side = "downstream"
+)
+
+var (
+	syncDuration = k8smetrics.NewHistogramVec(
+		&k8smetrics.HistogramOpts{
+			Namespace:      namespace,
+			Name:           "sync_duration_seconds",
+			Help:           "Time taken to process one item of the sync loop, by action.",
+			Buckets:        k8smetrics.ExponentialBuckets(0.001, 2, 15),
+			StabilityLevel: k8smetrics.ALPHA,
+		},
+		[]string{"action"},
+	)
+
+	syncErrors = k8smetrics.NewCounterVec(
+		&k8smetrics.CounterOpts{
+			Namespace:      namespace,
+			Name:           "sync_errors_total",
+			Help:           "Number of items of the sync loop that failed, by action and reason.",
+			StabilityLevel: k8smetrics.ALPHA,
+		},
+		[]string{"action", "reason"},
+	)
+
+	objectWrites = k8smetrics.NewCounterVec(
+		&k8smetrics.CounterOpts{
+			Namespace:      namespace,
+			Name:           "object_writes_total",
+			Help:           "Number of objects created, updated and deleted, by direction of sync and kind of object.",
+			StabilityLevel: k8smetrics.ALPHA,
+		},
+		[]string{"direction", "group", "version", "kind", "operation"},
+	)
+
+	statusUpdates = k8smetrics.NewCounterVec(
+		&k8smetrics.CounterOpts{
+			Namespace:      namespace,
+			Name:           "status_updates_total",
+			Help:           "Number of statuses of edge cluster objects written back to the mailbox workspace, by kind of object.",
+			StabilityLevel: k8smetrics.ALPHA,
+		},
+		[]string{"group", "version", "kind"},
+	)
+
+	apiRequestDuration = k8smetrics.NewHistogramVec(
+		&k8smetrics.HistogramOpts{
+			Namespace:      namespace,
+			Name:           "api_request_duration_seconds",
+			Help:           "Latency of requests to the upstream and downstream API servers, by side, verb and status code.",
+			Buckets:        k8smetrics.ExponentialBuckets(0.001, 2, 15),
+			StabilityLevel: k8smetrics.ALPHA,
+		},
+		[]string{"side", "verb", "code"},
+	)
+
+	upstreamLastSuccess = k8smetrics.NewGauge(
+		&k8smetrics.GaugeOpts{
+			Namespace:      namespace,
+			Name:           "upstream_last_success_timestamp_seconds",
+			Help:           "Unix time of the latest request to the upstream API server that got a response other than a server error. The time since then tells how long the syncer has been out of touch with its mailbox workspace.",
+			StabilityLevel: k8smetrics.ALPHA,
+		},
+	)
+)
+
+// upstreamLastSuccessNanos is the Unix time, in nanoseconds, of the latest success
+// recorded in upstreamLastSuccess; zero if there has been none.
+var upstreamLastSuccessNanos int64
+
+// LastUpstreamSuccess returns the time of the latest request to the upstream API server
+// that got a response other than a server error, or the zero time if there has been none.
+func LastUpstreamSuccess() time.Time {
+	nanos := atomic.LoadInt64(&upstreamLastSuccessNanos)
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+var registerOnce sync.Once
+
+// Register registers the syncer's metrics in the legacy registry.
+// It is safe to call more than once.
+func Register() {
+	registerOnce.Do(func() {
+		legacyregistry.MustRegister(syncDuration, syncErrors, objectWrites, statusUpdates, apiRequestDuration, upstreamLastSuccess)
+	})
+}
+
+// ObserveSync records the duration and, if it failed, the failure of one item of the sync loop.
+func ObserveSync(action string, started time.Time, err error) {
+	syncDuration.WithLabelValues(action).Observe(time.Since(started).Seconds())
+	if err != nil {
+		syncErrors.WithLabelValues(action, ReasonFor(err)).Inc()
+	}
+}
+
+// ReasonFor returns a short, bounded description of why the given error happened.
+func ReasonFor(err error) string {
+	var aggregate utilerrors.Aggregate
+	if errors.As(err, &aggregate) && len(aggregate.Errors()) > 0 {
+		err = aggregate.Errors()[0]
+	}
+	switch {
+	case clientfactory.IsUnreachable(err):
+		return "Unreachable"
+	case clientfactory.IsApplyConflict(err):
+		return "ApplyConflict"
+	}
+	if reason := k8serrors.ReasonForError(err); reason != "" {
+		return string(reason)
+	}
+	return "Unknown"
+}
+
+// RecordWrite counts a write of the given operation to an object of the given resource,
+// made in syncing in the given direction.
+func RecordWrite(direction edgev1alpha1.SyncDirection, resource edgev1alpha1.EdgeSyncConfigResource, operation Operation) {
+	objectWrites.WithLabelValues(string(direction), resource.Group, resource.Version, resource.Kind, string(operation)).Inc()
+}
+
+// RecordStatusUpdate counts a write of the status of an object of the given resource to the mailbox workspace.
+func RecordStatusUpdate(resource edgev1alpha1.EdgeSyncConfigResource) {
+	statusUpdates.WithLabelValues(resource.Group, resource.Version, resource.Kind).Inc()
+}
+
+// InstrumentConfig makes the clients made from the given config measure their requests
+// as being to the given side.
+func InstrumentConfig(config *rest.Config, side Side) {
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &instrumentedRoundTripper{delegate: rt, side: side}
+	})
+}
+
+type instrumentedRoundTripper struct {
+	delegate http.RoundTripper
+	side     Side
+}
+
+func (rt *instrumentedRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	started := time.Now()
+	resp, err := rt.delegate.RoundTrip(req)
+	code := "<error>"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	apiRequestDuration.WithLabelValues(string(rt.side), req.Method, code).Observe(time.Since(started).Seconds())
+	if rt.side == SideUpstream && err == nil && resp.StatusCode < http.StatusInternalServerError {
+		now := time.Now()
+		atomic.StoreInt64(&upstreamLastSuccessNanos, now.UnixNano())
+		upstreamLastSuccess.Set(float64(now.UnixNano()) / 1e9)
+	}
+	return resp, err
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/rest"
+	"k8s.io/component-base/metrics/testutil"
+
+	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+)
+
+func TestReasonFor(t *testing.T) {
+	gr := schema.GroupResource{Resource: "configmaps"}
+	for _, tc := range []struct {
+		err      error
+		expected string
+	}{
+		{k8serrors.NewNotFound(gr, "cm-1"), "NotFound"},
+		{k8serrors.NewForbidden(gr, "cm-1", errors.New("no")), "Forbidden"},
+		{k8serrors.NewServiceUnavailable("down"), "Unreachable"},
+		{&url.Error{Op: "Get", URL: "https://mailbox", Err: errors.New("connection refused")}, "Unreachable"},
+		{utilerrors.NewAggregate([]error{k8serrors.NewConflict(gr, "cm-1", errors.New("changed")), errors.New("other")}), "Conflict"},
+		{errors.New("something else"), "Unknown"},
+	} {
+		require.Equal(t, tc.expected, ReasonFor(tc.err), "error %v", tc.err)
+	}
+}
+
+func TestRecordWrite(t *testing.T) {
+	Register()
+	resource := edgev1alpha1.EdgeSyncConfigResource{Group: "apps", Version: "v1", Kind: "Deployment", Namespace: "ns", Name: "web"}
+	counter := objectWrites.WithLabelValues("Down", "apps", "v1", "Deployment", "create")
+	before, err := testutil.GetCounterMetricValue(counter)
+	require.NoError(t, err)
+	RecordWrite(edgev1alpha1.SyncDirectionDown, resource, OperationCreate)
+	after, err := testutil.GetCounterMetricValue(counter)
+	require.NoError(t, err)
+	require.Equal(t, before+1, after)
+}
+
+func TestInstrumentConfig(t *testing.T) {
+	Register()
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	config := &rest.Config{Host: server.URL}
+	InstrumentConfig(config, SideUpstream)
+	client, err := rest.HTTPClientFor(config)
+	require.NoError(t, err)
+	get := func() {
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	get()
+	lastSuccess, err := testutil.GetGaugeMetricValue(upstreamLastSuccess)
+	require.NoError(t, err)
+	require.InDelta(t, float64(time.Now().Unix()), lastSuccess, 5)
+	count, err := testutil.GetHistogramMetricCount(apiRequestDuration.WithLabelValues("upstream", "GET", "200"))
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), count)
+
+	// A server error is not a success
+	upstreamLastSuccess.Set(0)
+	status = http.StatusServiceUnavailable
+	get()
+	lastSuccess, err = testutil.GetGaugeMetricValue(upstreamLastSuccess)
+	require.NoError(t, err)
+	require.Zero(t, lastSuccess)
+}
@@ -16,8 +16,53 @@ limitations under the License.
 
 package shared
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
 const (
 	// SyncerFinalizerNamePrefix is the finalizer put onto resources by the syncer to claim ownership,
-	// *before* a downstream object is created. It is only removed when the downstream object is deleted.
+	// *after* a downstream object is first applied. It is removed when the downstream object is deleted,
+	// when the resource is no longer downsynced, or by the mailbox-controller when the SyncTarget is gone.
 	SyncerFinalizerNamePrefix = "workload.kcp.io/syncer-"
 )
+
+// FinalizerForSyncTarget returns the finalizer that the syncer for the named SyncTarget
+// puts on the objects that it downsyncs.
+// The name of the SyncTarget is replaced by a hash of it when otherwise the finalizer
+// would be too long to be valid.
+func FinalizerForSyncTarget(syncTargetName string) string {
+	// The part after the slash is limited to 63 characters
+	suffix := syncTargetName
+	namePrefix := SyncerFinalizerNamePrefix[strings.Index(SyncerFinalizerNamePrefix, "/")+1:]
+	if len(namePrefix)+len(suffix) > 63 {
+		hash := sha256.Sum256([]byte(syncTargetName))
+		suffix = hex.EncodeToString(hash[:])[:63-len(namePrefix)]
+	}
+	return SyncerFinalizerNamePrefix + suffix
+}
+
+// RemoveFinalizerPatch returns a JSON patch that removes the given finalizer from an object whose
+// finalizers are the given ones, and tests that the finalizer is still in the same place, so that
+// a concurrent change of the finalizers makes the patch fail rather than remove the wrong one.
+// Returns false if the finalizer is not among the given ones.
+func RemoveFinalizerPatch(finalizers []string, finalizer string) ([]byte, bool) {
+	for idx, elt := range finalizers {
+		if elt == finalizer {
+			path := fmt.Sprintf("/metadata/finalizers/%d", idx)
+			patch, err := json.Marshal([]map[string]interface{}{
+				{"op": "test", "path": path, "value": finalizer},
+				{"op": "remove", "path": path},
+			})
+			if err != nil {
+				panic(err)
+			}
+			return patch, true
+		}
+	}
+	return nil, false
+}
@@ -18,14 +18,20 @@ package syncer
 
 import (
 	"context"
-	"fmt"
+	"reflect"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/pkg/version"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 
 	"github.com/kcp-dev/logicalcluster/v3"
@@ -35,6 +41,9 @@ import (
 	edgeinformers "github.com/kubestellar/kubestellar/pkg/client/informers/externalversions"
 	"github.com/kubestellar/kubestellar/pkg/syncer/clientfactory"
 	"github.com/kubestellar/kubestellar/pkg/syncer/controller"
+	"github.com/kubestellar/kubestellar/pkg/syncer/localstore"
+	"github.com/kubestellar/kubestellar/pkg/syncer/metrics"
+	"github.com/kubestellar/kubestellar/pkg/syncer/shared"
 	"github.com/kubestellar/kubestellar/pkg/syncer/syncers"
 )
 
@@ -44,13 +53,25 @@ type SyncerConfig struct {
 	SyncTargetPath   logicalcluster.Path
 	SyncTargetName   string
 	SyncTargetUID    string
-	Interval         time.Duration
+	// Interval is the period of the full resync that backs up the informer-driven syncing.
+	Interval time.Duration
+	// HeartbeatInterval is the period of the heartbeat written to the SyncerConfig.
+	HeartbeatInterval time.Duration
+	// LocalStateDir, if not empty, is the directory where the syncer keeps its local
+	// copy of what it has read from the mailbox workspace, and the writes it has deferred,
+	// so that it keeps syncing while the mailbox workspace is unreachable.
+	LocalStateDir string
+	// Health, if not nil, is given what it needs to report how the syncer is doing.
+	Health *Health
 }
 
 const (
 	resyncPeriod    = 10 * time.Hour
-	defaultInterval = time.Second * 15
+	defaultInterval = time.Minute * 5
 	minimumInterval = time.Second * 1
+
+	defaultHeartbeatInterval = time.Second * 30
+	defaultReplayInterval    = time.Second * 10
 )
 
 func RunSyncer(ctx context.Context, cfg *SyncerConfig, numSyncerThreads int) error {
@@ -59,8 +80,10 @@ func RunSyncer(ctx context.Context, cfg *SyncerConfig, numSyncerThreads int) err
 	logger.V(2).Info("starting kubestellar syncer")
 	kcpVersion := version.Get().GitVersion
 
+	metrics.Register()
 	bootstrapConfig := rest.CopyConfig(cfg.UpstreamConfig)
 	rest.AddUserAgent(bootstrapConfig, "kubestellar#syncer/"+kcpVersion)
+	metrics.InstrumentConfig(bootstrapConfig, metrics.SideUpstream)
 
 	// For edgeSyncConfig
 	syncConfigClientSet, err := edgeclientset.NewForConfig(bootstrapConfig)
@@ -75,7 +98,9 @@ func RunSyncer(ctx context.Context, cfg *SyncerConfig, numSyncerThreads int) err
 	syncConfigAccess.Lister().List(labels.Everything()) // TODO: Remove (for now, need to invoke List at once)
 
 	syncConfigInformerFactory.Start(ctx.Done())
-	syncConfigInformerFactory.WaitForCacheSync(ctx.Done())
+	if cfg.LocalStateDir == "" {
+		syncConfigInformerFactory.WaitForCacheSync(ctx.Done())
+	}
 
 	// For syncerConfig
 	syncerConfigClientSet, err := edgeclientset.NewForConfig(bootstrapConfig)
@@ -90,10 +115,13 @@ func RunSyncer(ctx context.Context, cfg *SyncerConfig, numSyncerThreads int) err
 	syncerConfigAccess.Lister().List(labels.Everything()) // TODO: Remove (for now, need to invoke List at once)
 
 	syncerConfigInformerFactory.Start(ctx.Done())
-	syncerConfigInformerFactory.WaitForCacheSync(ctx.Done())
+	if cfg.LocalStateDir == "" {
+		syncerConfigInformerFactory.WaitForCacheSync(ctx.Done())
+	}
 
 	upstreamConfig := rest.CopyConfig(cfg.UpstreamConfig)
 	rest.AddUserAgent(upstreamConfig, "kubestellar#syncer/"+kcpVersion)
+	metrics.InstrumentConfig(upstreamConfig, metrics.SideUpstream)
 	upstreamDynamicClient, err := dynamic.NewForConfig(upstreamConfig)
 	if err != nil {
 		return err
@@ -103,18 +131,33 @@ func RunSyncer(ctx context.Context, cfg *SyncerConfig, numSyncerThreads int) err
 	if err != nil {
 		return err
 	}
+	upstreamClientFactory.SetFieldManager(clientfactory.FieldManagerForSyncTarget(cfg.SyncTargetName))
+	var localStore *localstore.Store
+	if cfg.LocalStateDir != "" {
+		localStore, err = localstore.Open(cfg.LocalStateDir)
+		if err != nil {
+			return err
+		}
+		upstreamClientFactory.SetLocalStore(localStore)
+	}
 
 	downstreamConfig := rest.CopyConfig(cfg.DownstreamConfig)
 	rest.AddUserAgent(downstreamConfig, "kubestellar#syncer/"+kcpVersion)
+	metrics.InstrumentConfig(downstreamConfig, metrics.SideDownstream)
 	downstreamDynamicClient, err := dynamic.NewForConfig(downstreamConfig)
 	if err != nil {
 		return err
 	}
+	downstreamKubeClient, err := kubernetes.NewForConfig(downstreamConfig)
+	if err != nil {
+		return err
+	}
 	downstreamDiscoveryClient := discovery.NewDiscoveryClientForConfigOrDie(downstreamConfig)
 	downstreamClientFactory, err := clientfactory.NewClientFactory(logger, downstreamDynamicClient, downstreamDiscoveryClient)
 	if err != nil {
 		return err
 	}
+	downstreamClientFactory.SetFieldManager(clientfactory.FieldManagerForSyncTarget(cfg.SyncTargetName))
 
 	upSyncer, err := syncers.NewUpSyncer(logger, upstreamClientFactory, downstreamClientFactory, []edgev1alpha1.EdgeSyncConfigResource{}, []edgev1alpha1.EdgeSynConversion{})
 	if err != nil {
@@ -124,6 +167,9 @@ func RunSyncer(ctx context.Context, cfg *SyncerConfig, numSyncerThreads int) err
 	if err != nil {
 		return err
 	}
+	syncStatusStore := syncers.NewSyncStatusStore()
+	upSyncer.SetStatusStore(syncStatusStore)
+	downSyncer.SetStatusStore(syncStatusStore)
 
 	syncConfigManager := controller.NewSyncConfigManager(logger)
 	syncConfigController, err := controller.NewEdgeSyncConfigController(logger, syncConfigClient, syncConfigAccess, syncConfigManager, upSyncer, downSyncer, 5*time.Second)
@@ -132,71 +178,83 @@ func RunSyncer(ctx context.Context, cfg *SyncerConfig, numSyncerThreads int) err
 	}
 
 	syncerConfigManager := controller.NewSyncerConfigManager(logger, syncConfigManager, upstreamClientFactory, downstreamClientFactory)
+	downSyncer.SetDriftPolicies(syncerConfigManager.DriftPolicyFor)
+	downSyncer.SetIgnoreDifferences(syncerConfigManager.IgnoreDifferencesFor)
+	downSyncer.SetDeletionPolicies(syncerConfigManager.DeletionPolicyFor)
+	downSyncer.SetSubresources(syncerConfigManager.SubresourcesFor)
+	downSyncer.SetFinalizer(shared.FinalizerForSyncTarget(cfg.SyncTargetName))
+	downSyncer.SetStillDownsynced(syncConfigManager.IsDownsynced)
+	upSyncer.SetSource(cfg.SyncTargetName, syncerConfigManager.LocationName)
+	upSyncer.SetCollisionPolicies(syncerConfigManager.UpsyncCollisionPolicyFor)
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: downstreamKubeClient.CoreV1().Events("")})
+	defer eventBroadcaster.Shutdown()
+	downSyncer.SetEventRecorder(eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: "kubestellar-syncer"}))
+	if localStore != nil {
+		// Start from the stored SyncerConfigs, rather than waiting for the mailbox workspace,
+		// and drop the stored ones that turn out to be deleted once the informers have synced.
+		syncerConfigManager.SetLocalStore(localStore)
+		go func() {
+			syncConfigInformerFactory.WaitForCacheSync(ctx.Done())
+			syncerConfigInformerFactory.WaitForCacheSync(ctx.Done())
+			if ctx.Err() == nil {
+				syncerConfigManager.DropUnlisted(syncerConfigAccess.Lister())
+			}
+		}()
+	}
 	syncerConfigController, err := controller.NewSyncerConfigController(logger, syncerConfigClient, syncerConfigAccess, syncerConfigManager, 5*time.Second)
 	if err != nil {
 		return err
 	}
 
-	go syncConfigController.Run(ctx, numSyncerThreads)
-	go syncerConfigController.Run(ctx, numSyncerThreads)
-	runSync(ctx, cfg, syncConfigManager, syncerConfigManager, upSyncer, downSyncer)
-	return nil
-}
-
-func runSync(ctx context.Context, cfg *SyncerConfig, syncConfigManager *controller.SyncConfigManager, syncerConfigManager *controller.SyncerConfigManager, upSyncer *syncers.UpSyncer, downSyncer *syncers.DownSyncer) {
-	logger := klog.FromContext(ctx)
-	logger.V(2).Info("Start sync")
 	interval := cfg.Interval
 	if interval < minimumInterval {
 		interval = defaultInterval
 	}
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-time.Tick(interval):
-			logger.V(2).Info(fmt.Sprintf("Sync with interval: %v", interval))
-			syncerConfigManager.Refresh()
-			downSyncedResources := syncConfigManager.GetDownSyncedResources()
-			downUnsyncedResources := syncConfigManager.GetDownUnsyncedResources()
-			upSyncedReousrces := syncConfigManager.GetUpSyncedResources()
-			upUnsyncedReousrces := syncConfigManager.GetUpUnsyncedResources()
-			conversions := syncConfigManager.GetConversions()
-			_ = downSyncer.ReInitializeClients(downSyncedResources, conversions)
-			_ = upSyncer.ReInitializeClients(upSyncedReousrces, conversions)
-			sync(logger.WithValues("actor", "DownSyncer:Sync"), downSyncer, downSyncedResources, conversions)
-			sync(logger.WithValues("actor", "DownSyncer:Unsync"), downSyncer, downUnsyncedResources, conversions)
-			syncStatus(logger.WithValues("actor", "DownSyncer:Sync"), downSyncer, downSyncedResources, conversions)
-			sync(logger.WithValues("actor", "UpSyncer:Sync"), upSyncer, upSyncedReousrces, conversions)
-			sync(logger.WithValues("actor", "UpSyncer:Unsync"), upSyncer, upUnsyncedReousrces, conversions)
-		}
-	}
-}
+	syncController := controller.NewSyncController(logger, syncConfigManager, syncerConfigManager, upstreamClientFactory, downstreamClientFactory, upSyncer, downSyncer, interval)
 
-func sync(logger klog.Logger, syncer syncers.SyncerInterface, resources []edgev1alpha1.EdgeSyncConfigResource, conversions []edgev1alpha1.EdgeSynConversion) {
-	for _, resource := range resources {
-		if resource.Name == "*" || resource.Namespace == "*" {
-			if err := syncer.SyncMany(resource, conversions); err != nil {
-				logger.V(1).Info(fmt.Sprintf("failed to sync-many %s.%s/%s (ns=%s)", resource.Kind, resource.Group, resource.Name, resource.Namespace))
-			}
-		} else {
-			if err := syncer.SyncOne(resource, conversions); err != nil {
-				logger.V(1).Info(fmt.Sprintf("failed to sync %s.%s/%s (ns=%s)", resource.Kind, resource.Group, resource.Name, resource.Namespace))
+	heartbeatInterval := cfg.HeartbeatInterval
+	if heartbeatInterval < minimumInterval {
+		heartbeatInterval = defaultHeartbeatInterval
+	}
+	if cfg.Health != nil {
+		// The heartbeat talks to the upstream, so a few missed heartbeats mean it is unreachable
+		cfg.Health.setComponents(syncConfigManager, syncStatusStore, 3*heartbeatInterval)
+		go func() {
+			if allSynced(syncConfigInformerFactory.WaitForCacheSync(ctx.Done())) && allSynced(syncerConfigInformerFactory.WaitForCacheSync(ctx.Done())) {
+				cfg.Health.setCachesSynced()
 			}
+		}()
+	}
+	// The heartbeat sums up the Nodes of the edge cluster from an informer, rather than listing them every beat
+	downstreamKubeInformerFactory := kubeinformers.NewSharedInformerFactory(downstreamKubeClient, 0)
+	nodeLister := downstreamKubeInformerFactory.Core().V1().Nodes().Lister()
+	downstreamKubeInformerFactory.Start(ctx.Done())
+	heartbeater := controller.NewHeartbeater(logger, syncerConfigClient, syncerConfigAccess.Lister(), syncStatusStore, nodeLister, heartbeatInterval)
+
+	go syncConfigController.Run(ctx, numSyncerThreads)
+	go syncerConfigController.Run(ctx, numSyncerThreads)
+	go func() {
+		// Do not report the capacity of an edge cluster without Nodes before they are listed
+		if allSynced(downstreamKubeInformerFactory.WaitForCacheSync(ctx.Done())) {
+			heartbeater.Run(ctx)
 		}
+	}()
+	if localStore != nil {
+		replayer := controller.NewPendingWriteReplayer(logger, upstreamClientFactory, syncStatusStore, defaultReplayInterval)
+		go replayer.Run(ctx)
 	}
+	logger.V(2).Info("Start sync")
+	syncController.Run(ctx, numSyncerThreads)
+	return nil
 }
 
-func syncStatus(logger klog.Logger, downSyncer *syncers.DownSyncer, resources []edgev1alpha1.EdgeSyncConfigResource, conversions []edgev1alpha1.EdgeSynConversion) {
-	for _, resource := range resources {
-		if resource.Name == "*" || resource.Namespace == "*" {
-			if err := downSyncer.BackStatusMany(resource, conversions); err != nil {
-				logger.V(1).Info(fmt.Sprintf("failed to status sync-many %s.%s/%s (ns=%s)", resource.Kind, resource.Group, resource.Name, resource.Namespace))
-			}
-		} else {
-			if err := downSyncer.BackStatusOne(resource, conversions); err != nil {
-				logger.V(1).Info(fmt.Sprintf("failed to status sync %s.%s/%s (ns=%s)", resource.Kind, resource.Group, resource.Name, resource.Namespace))
-			}
+// allSynced tells whether all the informers in the given result of WaitForCacheSync have synced.
+func allSynced(synced map[reflect.Type]bool) bool {
+	for _, ok := range synced {
+		if !ok {
+			return false
 		}
 	}
+	return true
 }
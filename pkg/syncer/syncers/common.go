@@ -19,11 +19,12 @@ package syncers
 import (
 	"errors"
 	"fmt"
-	"os"
+	"sort"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/klog/v2"
 
 	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
@@ -44,10 +45,13 @@ func initializeClients(
 	conversions []edgev1alpha1.EdgeSynConversion,
 ) error {
 	logger.V(3).Info("initialize clients")
+	// A kind that cannot be mapped (e.g., because its CRD is not Established yet) does not
+	// keep the clients for the other kinds from being set up
+	errs := []error{}
 	for _, syncResource := range syncResources {
 		logger.V(3).Info(fmt.Sprintf("  setup ResourceClient for %q", resourceToString(syncResource)))
 
-		syncResourceForUpstream := convertToUpstream(syncResource, conversions)
+		syncResourceForUpstream := ConvertToUpstream(syncResource, conversions)
 
 		groupForUp := syncResourceForUpstream.Group
 		kindForUp := syncResourceForUpstream.Kind
@@ -63,12 +67,13 @@ func initializeClients(
 			upstreamClient, err := upstreamClientFactory.GetResourceClient(groupForUp, kindForUp)
 			if err != nil {
 				logger.Error(err, fmt.Sprintf("failed to create kcpResourceClient '%s.%s'", groupForUp, kindForUp))
-				return err
+				errs = append(errs, err)
+				continue
 			}
 			upstreamClients[gkForUp] = &upstreamClient
 		}
 
-		syncResourceForDownstream := convertToDownstream(syncResource, conversions)
+		syncResourceForDownstream := ConvertToDownstream(syncResource, conversions)
 		groupForDown := syncResourceForDownstream.Group
 		kindForDown := syncResourceForDownstream.Kind
 		gkForDown := schema.GroupKind{
@@ -84,66 +89,76 @@ func initializeClients(
 			k8sClient, err := downstreamClientFactory.GetResourceClient(groupForDown, kindForDown)
 			if err != nil {
 				logger.Error(err, fmt.Sprintf("failed to create k8sResourceClient '%s.%s'", groupForDown, kindForDown))
-				return err
+				errs = append(errs, err)
+				continue
 			}
 			downstreamClients[gkForDown] = &k8sClient
 		}
 	}
-	return nil
+	return utilerrors.NewAggregate(errs)
 }
 
-// TODO: Disable dinaturing/re-naturing feature as default. Remove the feature flag once it's fully supported.
-func isDenaturingEnabled() bool {
-	env, ok := os.LookupEnv("ENABLE_DENATURING")
-	return ok && env == "true"
+// ConvertToUpstream returns the given resource as it appears upstream.
+// The resource is converted by the first of the given conversions whose
+// downstream side matches it; if none does, the resource is returned as is.
+func ConvertToUpstream(resource edgev1alpha1.EdgeSyncConfigResource, conversions []edgev1alpha1.EdgeSynConversion) edgev1alpha1.EdgeSyncConfigResource {
+	for _, conversion := range conversions {
+		if conversionSideMatches(conversion.Downstream, resource) {
+			return convertResource(resource, conversion.Upstream)
+		}
+	}
+	return resource
 }
 
-func convertToUpstream(resource edgev1alpha1.EdgeSyncConfigResource, conversions []edgev1alpha1.EdgeSynConversion) edgev1alpha1.EdgeSyncConfigResource {
-	if !isDenaturingEnabled() {
-		return resource
-	}
+// ConvertToDownstream returns the given resource as it appears downstream.
+// The resource is converted by the first of the given conversions whose
+// upstream side matches it; if none does, the resource is returned as is.
+func ConvertToDownstream(resource edgev1alpha1.EdgeSyncConfigResource, conversions []edgev1alpha1.EdgeSynConversion) edgev1alpha1.EdgeSyncConfigResource {
 	for _, conversion := range conversions {
-		if conversion.Downstream.Group == resource.Group && conversion.Downstream.Kind == resource.Kind && conversion.Downstream.Name == resource.Name {
-			resource.Group = conversion.Upstream.Group
-			resource.Kind = conversion.Upstream.Kind
-			resource.Name = conversion.Upstream.Name
-			return resource
+		if conversionSideMatches(conversion.Upstream, resource) {
+			return convertResource(resource, conversion.Downstream)
 		}
 	}
 	return resource
 }
 
-func convertToDownstream(resource edgev1alpha1.EdgeSyncConfigResource, conversions []edgev1alpha1.EdgeSynConversion) edgev1alpha1.EdgeSyncConfigResource {
-	if !isDenaturingEnabled() {
-		return resource
+// conversionSideMatches tells whether one side of a conversion matches the given resource.
+// A side with an empty name matches every object of its resource.
+func conversionSideMatches(side, resource edgev1alpha1.EdgeSyncConfigResource) bool {
+	return side.Group == resource.Group && side.Kind == resource.Kind &&
+		(side.Version == "" || side.Version == resource.Version) &&
+		(side.Name == "" || side.Name == resource.Name)
+}
+
+func convertResource(resource, target edgev1alpha1.EdgeSyncConfigResource) edgev1alpha1.EdgeSyncConfigResource {
+	resource.Group = target.Group
+	resource.Kind = target.Kind
+	if target.Version != "" {
+		resource.Version = target.Version
 	}
-	for _, conversion := range conversions {
-		if conversion.Upstream.Group == resource.Group && conversion.Upstream.Kind == resource.Kind && conversion.Upstream.Name == resource.Name {
-			resource.Group = conversion.Downstream.Group
-			resource.Kind = conversion.Downstream.Kind
-			resource.Name = conversion.Downstream.Name
-			return resource
-		}
+	if target.Name != "" {
+		resource.Name = target.Name
 	}
 	return resource
 }
 
+// applyConversion makes the given object into an object of the given target resource.
+// The object's status, if any, is kept; so this works in both directions.
 func applyConversion(source *unstructured.Unstructured, target edgev1alpha1.EdgeSyncConfigResource) {
-	if !isDenaturingEnabled() {
-		return
-	}
-	source.SetAPIVersion(target.Group + "/" + target.Version)
+	source.SetAPIVersion(schema.GroupVersion{Group: target.Group, Version: target.Version}.String())
 	source.SetKind(target.Kind)
-	source.SetName(target.Name)
+	if target.Name != "" && target.Name != "*" {
+		source.SetName(target.Name)
+	}
 	// CRD restricts the CRD name to be same as group
-	if target.Kind == "CustomResourceDefinition" {
-		names := strings.Split(target.Name, ".")[1:]
+	if target.Kind == "CustomResourceDefinition" && target.Group == "apiextensions.k8s.io" {
+		names := strings.Split(source.GetName(), ".")[1:]
 		unstructured.SetNestedField(source.Object, strings.Join(names, "."), "spec", "group")
 	}
 }
 
 func getClients(resource edgev1alpha1.EdgeSyncConfigResource, upstreamClients map[schema.GroupKind]*Client, downstreamClients map[schema.GroupKind]*Client, conversions []edgev1alpha1.EdgeSynConversion) (*Client, *Client, error) {
-	upstreamResource := convertToUpstream(resource, conversions)
+	upstreamResource := ConvertToUpstream(resource, conversions)
 	upstreamGk := schema.GroupKind{
 		Group: upstreamResource.Group,
 		Kind:  upstreamResource.Kind,
@@ -154,7 +169,7 @@ func getClients(resource edgev1alpha1.EdgeSyncConfigResource, upstreamClients ma
 		return nil, nil, errors.New(msg)
 	}
 
-	downstreamResource := convertToDownstream(resource, conversions)
+	downstreamResource := ConvertToDownstream(resource, conversions)
 	downstreamGk := schema.GroupKind{
 		Group: downstreamResource.Group,
 		Kind:  downstreamResource.Kind,
@@ -167,62 +182,55 @@ func getClients(resource edgev1alpha1.EdgeSyncConfigResource, upstreamClients ma
 	return upstreamClient, downstreamClient, nil
 }
 
-func diff(logger klog.Logger, srcResourceList *unstructured.UnstructuredList, destResourceList *unstructured.UnstructuredList, setAnnotation func(resource *unstructured.Unstructured), hasAnnotation func(resource *unstructured.Unstructured) bool) (
-	[]unstructured.Unstructured,
-	[]unstructured.Unstructured,
-	[]unstructured.Unstructured,
-) {
-	newResources := []unstructured.Unstructured{}
-	updatedResources := []unstructured.Unstructured{}
-	deletedResources := []unstructured.Unstructured{}
-	for _, srcResource := range srcResourceList.Items {
-		destResource, ok := findWithObject(srcResource, destResourceList)
-		if ok {
-			srcResource.SetResourceVersion(destResource.GetResourceVersion())
-			srcResource.SetUID(destResource.GetUID())
-			srcResource.SetManagedFields(nil)
-			if hasAnnotation(destResource) {
-				setAnnotation(&srcResource)
-				updatedResources = append(updatedResources, srcResource)
-			} else {
-				logger.V(2).Info(fmt.Sprintf("  ignore adding %s to updatedResources since annotation is not set.", destResource.GetName()))
-			}
-		} else {
-			srcResource.SetResourceVersion("")
-			srcResource.SetUID("")
-			setAnnotation(&srcResource)
-			newResources = append(newResources, srcResource)
-		}
-	}
-	for _, destResource := range destResourceList.Items {
-		_, ok := findWithObject(destResource, srcResourceList)
-		if !ok {
-			if hasAnnotation(&destResource) {
-				logger.V(3).Info(fmt.Sprintf("  %s is added to deletedResources since annotation is set.", destResource.GetName()))
-				deletedResources = append(deletedResources, destResource)
-			} else {
-				logger.V(2).Info(fmt.Sprintf("  ignore adding %s to deletedResources since annotation is not set.", destResource.GetName()))
-			}
+// destinationEntry is what a SyncMany keeps about an object at the destination of the sync.
+type destinationEntry struct {
+	namespace string
+	name      string
+	// owned tells whether the object has the syncer's annotation
+	owned bool
+	// applied tells whether the object was applied by the syncer's field manager
+	applied bool
+}
+
+// indexDestination lists the destination objects of the given resource one at a time
+// and returns, by namespace and name, what a SyncMany needs to know about them.
+// The objects themselves are not held, so that only one side of a sync is listed into memory.
+func indexDestination(client *Client, resource edgev1alpha1.EdgeSyncConfigResource, hasAnnotation func(resource *unstructured.Unstructured) bool) (map[string]destinationEntry, error) {
+	index := map[string]destinationEntry{}
+	err := client.EachListItem(resource, func(obj *unstructured.Unstructured) error {
+		index[objectKey(obj.GetNamespace(), obj.GetName())] = destinationEntry{
+			namespace: obj.GetNamespace(),
+			name:      obj.GetName(),
+			owned:     hasAnnotation(obj),
+			applied:   appliedBy(obj, client.FieldManager()),
 		}
-	}
+		return nil
+	})
+	return index, err
+}
 
-	newResourceNames := []string{}
-	for _, resource := range newResources {
-		newResourceNames = append(newResourceNames, resource.GetName())
-	}
-	updatedResourceNames := []string{}
-	for _, resource := range updatedResources {
-		updatedResourceNames = append(updatedResourceNames, resource.GetName())
-	}
-	deletedResourceNames := []string{}
-	for _, resource := range deletedResources {
-		deletedResourceNames = append(deletedResourceNames, resource.GetName())
+// unsyncedOwned returns, sorted by namespace and name, the entries of the given index that the syncer owns
+// and whose keys are not in the given set of synced objects.
+func unsyncedOwned(logger klog.Logger, index map[string]destinationEntry, synced map[string]bool) []destinationEntry {
+	ans := []destinationEntry{}
+	for key, entry := range index {
+		if synced[key] {
+			continue
+		}
+		if !entry.owned {
+			logger.V(2).Info(fmt.Sprintf("  ignore deleting %s since annotation is not set.", entry.name))
+			continue
+		}
+		ans = append(ans, entry)
 	}
-	logger.V(3).Info(fmt.Sprintf("  new resource names: %v", newResourceNames))
-	logger.V(3).Info(fmt.Sprintf("  updated resource names: %v", updatedResourceNames))
-	logger.V(3).Info(fmt.Sprintf("  deleted resource names: %v", deletedResourceNames))
+	sort.Slice(ans, func(i, j int) bool {
+		return objectKey(ans[i].namespace, ans[i].name) < objectKey(ans[j].namespace, ans[j].name)
+	})
+	return ans
+}
 
-	return newResources, updatedResources, deletedResources
+func objectKey(namespace, name string) string {
+	return namespace + "/" + name
 }
 
 func setAnnotation(resource *unstructured.Unstructured, key string, value string) {
@@ -24,10 +24,13 @@ import (
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 
 	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
 	. "github.com/kubestellar/kubestellar/pkg/syncer/clientfactory"
+	"github.com/kubestellar/kubestellar/pkg/syncer/metrics"
 )
 
 type DownSyncer struct {
@@ -37,6 +40,14 @@ type DownSyncer struct {
 	downstreamClientFactory ClientFactory
 	upstreamClients         map[schema.GroupKind]*Client
 	downstreamClients       map[schema.GroupKind]*Client
+	statusStore             *SyncStatusStore
+	driftPolicies           func(schema.GroupResource) edgev1alpha1.DriftPolicy
+	ignoreDifferences       func(schema.GroupResource) []edgev1alpha1.IgnoreDifferences
+	deletionPolicies        func(schema.GroupResource) edgev1alpha1.DeletionPolicy
+	subresources            func(schema.GroupResource) edgev1alpha1.ResourceSubresources
+	finalizer               string
+	stillDownsynced         func(*unstructured.Unstructured) bool
+	eventRecorder           record.EventRecorder
 }
 
 func NewDownSyncer(logger klog.Logger, upstreamClientFactory ClientFactory, downstreamClientFactory ClientFactory, syncedResources []edgev1alpha1.EdgeSyncConfigResource, conversions []edgev1alpha1.EdgeSynConversion) (*DownSyncer, error) {
@@ -67,25 +78,90 @@ func (ds *DownSyncer) ReInitializeClients(syncedResources []edgev1alpha1.EdgeSyn
 	return initializeClients(ds.logger, syncedResources, ds.upstreamClientFactory, ds.downstreamClientFactory, ds.upstreamClients, ds.downstreamClients, conversions)
 }
 
+// SetStatusStore sets where the outcome of each object's sync is recorded.
+func (ds *DownSyncer) SetStatusStore(statusStore *SyncStatusStore) {
+	ds.statusStore = statusStore
+}
+
+// SetDriftPolicies sets the source of the drift policy for each mailbox workspace resource.
+// Without it, drift is overwritten.
+func (ds *DownSyncer) SetDriftPolicies(driftPolicies func(schema.GroupResource) edgev1alpha1.DriftPolicy) {
+	ds.driftPolicies = driftPolicies
+}
+
+// SetIgnoreDifferences sets the source of the rules, for each mailbox workspace resource,
+// identifying the fields whose values in the edge cluster are kept.
+func (ds *DownSyncer) SetIgnoreDifferences(ignoreDifferences func(schema.GroupResource) []edgev1alpha1.IgnoreDifferences) {
+	ds.ignoreDifferences = ignoreDifferences
+}
+
+// keepIgnoredFields applies the ignoreDifferences rules for the given mailbox workspace resource,
+// and keeps the replicas if the edge cluster is authoritative for the scale.
+func (ds *DownSyncer) keepIgnoredFields(gvrForUp schema.GroupVersionResource, desired, existing *unstructured.Unstructured) {
+	rules := []edgev1alpha1.IgnoreDifferences{}
+	if ds.ignoreDifferences != nil {
+		rules = ds.ignoreDifferences(gvrForUp.GroupResource())
+	}
+	if rule, ok := ds.scaleRule(gvrForUp.GroupResource()); ok {
+		rules = append(rules, rule)
+	}
+	keepIgnoredFields(ds.logger, desired, existing, rules)
+}
+
+// SetEventRecorder sets where Events about drifted objects are recorded.
+func (ds *DownSyncer) SetEventRecorder(eventRecorder record.EventRecorder) {
+	ds.eventRecorder = eventRecorder
+}
+
 func (ds *DownSyncer) getClients(resource edgev1alpha1.EdgeSyncConfigResource, conversions []edgev1alpha1.EdgeSynConversion) (*Client, *Client, error) {
 	ds.Lock()
 	defer ds.Unlock()
 	return getClients(resource, ds.upstreamClients, ds.downstreamClients, conversions)
 }
 
-func (ds *DownSyncer) SyncOne(resource edgev1alpha1.EdgeSyncConfigResource, conversions []edgev1alpha1.EdgeSynConversion) error {
+func (ds *DownSyncer) SyncOne(resource edgev1alpha1.EdgeSyncConfigResource, conversions []edgev1alpha1.EdgeSynConversion) (err error) {
 	ds.logger.V(3).Info(fmt.Sprintf("sync %q from upstream to downstream", resourceToString(resource)))
 	upstreamClient, downstreamClient, err := ds.getClients(resource, conversions)
 	if err != nil {
 		ds.logger.Error(err, fmt.Sprintf("failed to get client %q", resourceToString(resource)))
 		return err
 	}
-	resourceForUp := convertToUpstream(resource, conversions)
+	resourceForUp := ConvertToUpstream(resource, conversions)
 	ds.logger.V(3).Info(fmt.Sprintf("  get %q from upstream", resourceToString(resourceForUp)))
 	upstreamResource, err := upstreamClient.Get(resourceForUp)
 	isDeleted := false
+	// notSynced explains why the object is left alone, when that is not an error
+	var notSynced error
+	// drift is how the object in downstream had drifted, and overwritten tells whether that was overwritten
+	var drift []driftedField
+	overwritten := false
+	defer func() {
+		gvr := upstreamClient.GroupVersionResource()
+		if isDeleted && err == nil {
+			ds.statusStore.Forget(edgev1alpha1.SyncDirectionDown, gvr, resourceForUp.Namespace, resourceForUp.Name)
+			return
+		}
+		var generation int64
+		if upstreamResource != nil {
+			generation = upstreamResource.GetGeneration()
+		}
+		if err == nil && notSynced == nil && len(drift) > 0 {
+			ds.statusStore.RecordDrift(edgev1alpha1.SyncDirectionDown, gvr, resourceForUp.Namespace, resourceForUp.Name, generation, driftedPaths(drift), overwritten)
+			return
+		}
+		outcome := err
+		if outcome == nil {
+			outcome = notSynced
+		}
+		ds.statusStore.Record(edgev1alpha1.SyncDirectionDown, gvr, resourceForUp.Namespace, resourceForUp.Name, generation, outcome)
+	}()
 	if err != nil {
-		if k8serrors.IsNotFound(err) {
+		if IsNotStored(err) {
+			// The object may well exist in upstream, so its downstream copy must not be deleted
+			ds.logger.V(2).Info(fmt.Sprintf("  leave %q alone since upstream is unreachable and it is not in the local store", resourceToString(resourceForUp)))
+			notSynced = err
+			return nil
+		} else if k8serrors.IsNotFound(err) {
 			ds.logger.V(3).Info(fmt.Sprintf("  not found %q in upstream", resourceToString(resourceForUp)))
 			ds.logger.V(3).Info(fmt.Sprintf("  delete %q from downstream", resourceToString(resourceForUp)))
 			isDeleted = true
@@ -93,9 +169,13 @@ func (ds *DownSyncer) SyncOne(resource edgev1alpha1.EdgeSyncConfigResource, conv
 			ds.logger.Error(err, fmt.Sprintf("failed to get resource from upstream %q", resourceToString(resourceForUp)))
 			return err
 		}
+	} else if isTerminating(upstreamResource) {
+		ds.logger.V(3).Info(fmt.Sprintf("  %q is being deleted in upstream", resourceToString(resourceForUp)))
+		isDeleted = true
+		return ds.finishDeletion(upstreamClient, downstreamClient, resourceForUp, ConvertToDownstream(resource, conversions), upstreamResource)
 	}
 
-	resourceForDown := convertToDownstream(resource, conversions)
+	resourceForDown := ConvertToDownstream(resource, conversions)
 	ds.logger.V(3).Info(fmt.Sprintf("  get %q from downstream", resourceToString(resourceForDown)))
 	downstreamResource, err := downstreamClient.Get(resourceForDown)
 	if err != nil {
@@ -104,12 +184,20 @@ func (ds *DownSyncer) SyncOne(resource edgev1alpha1.EdgeSyncConfigResource, conv
 				ds.logger.V(3).Info(fmt.Sprintf("  create %q in downstream since it's not found", resourceToString(resourceForDown)))
 				upstreamResource.SetResourceVersion("")
 				upstreamResource.SetUID("")
-				setDownsyncAnnotation(upstreamResource)
-				applyConversion(upstreamResource, resourceForDown)
-				if _, err := downstreamClient.Create(resourceForDown, upstreamResource); err != nil {
+				desired := upstreamResource.DeepCopy()
+				setDownsyncAnnotation(desired)
+				applyConversion(desired, resourceForDown)
+				prepareDesired(desired)
+				if _, err := downstreamClient.Apply(resourceForDown, desired); err != nil {
+					ds.logger.Error(err, fmt.Sprintf("failed to create resource to downstream %q", resourceToString(resourceForDown)))
+					return err
+				}
+				if err := ds.applySubresources(upstreamClient.GroupVersionResource(), downstreamClient, resourceForDown, desired); err != nil {
 					ds.logger.Error(err, fmt.Sprintf("failed to create resource to downstream %q", resourceToString(resourceForDown)))
 					return err
 				}
+				metrics.RecordWrite(edgev1alpha1.SyncDirectionDown, resourceForDown, metrics.OperationCreate)
+				return ds.ensureFinalizer(upstreamClient, resourceForUp, upstreamResource)
 			} else {
 				ds.logger.V(3).Info(fmt.Sprintf("  %q has already been deleted from downstream", resourceToString(resourceForDown)))
 			}
@@ -122,25 +210,41 @@ func (ds *DownSyncer) SyncOne(resource edgev1alpha1.EdgeSyncConfigResource, conv
 			if !isDeleted {
 				// update
 				ds.logger.V(3).Info(fmt.Sprintf("  update %q in downstream since it's found", resourceToString(resourceForDown)))
-				if hasDownsyncAnnotation(downstreamResource) {
-					upstreamResource.SetResourceVersion(downstreamResource.GetResourceVersion())
-					upstreamResource.SetUID(downstreamResource.GetUID())
-					setDownsyncAnnotation(upstreamResource)
-					applyConversion(upstreamResource, resourceForDown)
-					if _, err := downstreamClient.Update(resourceForDown, upstreamResource); err != nil {
+				if hasDownsyncAnnotation(downstreamResource) || appliedBy(downstreamResource, downstreamClient.FieldManager()) {
+					desired := upstreamResource.DeepCopy()
+					setDownsyncAnnotation(desired)
+					applyConversion(desired, resourceForDown)
+					prepareDesired(desired)
+					ds.keepIgnoredFields(upstreamClient.GroupVersionResource(), desired, downstreamResource)
+					var doApply bool
+					drift, doApply = ds.reconcileDrift(upstreamClient, resourceForUp, upstreamResource, desired, downstreamResource, downstreamClient.FieldManager())
+					if !doApply {
+						return ds.ensureFinalizer(upstreamClient, resourceForUp, upstreamResource)
+					}
+					if _, err := downstreamClient.Apply(resourceForDown, desired); err != nil {
 						ds.logger.Error(err, fmt.Sprintf("failed to update resource on downstream %q", resourceToString(resourceForDown)))
 						return err
 					}
+					if err := ds.applySubresources(upstreamClient.GroupVersionResource(), downstreamClient, resourceForDown, desired); err != nil {
+						ds.logger.Error(err, fmt.Sprintf("failed to update resource on downstream %q", resourceToString(resourceForDown)))
+						return err
+					}
+					metrics.RecordWrite(edgev1alpha1.SyncDirectionDown, resourceForDown, metrics.OperationUpdate)
+					overwritten = true
+					return ds.ensureFinalizer(upstreamClient, resourceForUp, upstreamResource)
 				} else {
 					ds.logger.V(2).Info(fmt.Sprintf("  ignore updating %q in downstream since downsync annotation is not set", resourceToString(resourceForDown)))
+					notSynced = fmt.Errorf("%q exists in downstream but was not created by the syncer", resourceToString(resourceForDown))
 				}
 			} else {
 				ds.logger.V(3).Info(fmt.Sprintf("  delete %q from downstream since it's found", resourceToString(resourceForDown)))
 				if hasDownsyncAnnotation(downstreamResource) {
-					if err := downstreamClient.Delete(resourceForDown, resourceForDown.Name); err != nil {
+					propagationPolicy := ds.deletionPolicyFor(upstreamClient.GroupVersionResource().GroupResource()).PropagationPolicy
+					if err := downstreamClient.DeleteWithPropagation(resourceForDown, resourceForDown.Name, propagationPolicy); err != nil {
 						ds.logger.Error(err, fmt.Sprintf("failed to delete resource from downstream %q", resourceToString(resourceForDown)))
 						return err
 					}
+					metrics.RecordWrite(edgev1alpha1.SyncDirectionDown, resourceForDown, metrics.OperationDelete)
 				} else {
 					ds.logger.V(2).Info(fmt.Sprintf("  ignore deleting %q from downstream since downsync annotation is not setn", resourceToString(resourceForDown)))
 				}
@@ -153,9 +257,24 @@ func (ds *DownSyncer) SyncOne(resource edgev1alpha1.EdgeSyncConfigResource, conv
 	return nil
 }
 
+// UnsyncOne releases the given upstream object of a resource that is no longer downsynced,
+// unless a resource that is still downsynced covers it. Its copy in the edge cluster is left alone.
 func (ds *DownSyncer) UnsyncOne(resource edgev1alpha1.EdgeSyncConfigResource, conversions []edgev1alpha1.EdgeSynConversion) error {
-	// It's OK to use same logic as SyncOne unless we execute specific actions for unsynced resources
-	return ds.SyncOne(resource, conversions)
+	upstreamClient, _, err := ds.getClients(resource, conversions)
+	if err != nil {
+		ds.logger.Error(err, fmt.Sprintf("failed to get client %q", resourceToString(resource)))
+		return err
+	}
+	resourceForUp := ConvertToUpstream(resource, conversions)
+	upstreamResource, err := upstreamClient.Get(resourceForUp)
+	if k8serrors.IsNotFound(err) || IsNotStored(err) {
+		return nil
+	}
+	if err != nil {
+		ds.logger.Error(err, fmt.Sprintf("failed to get resource from upstream %q", resourceToString(resourceForUp)))
+		return err
+	}
+	return ds.releaseUnsynced(upstreamClient, resourceForUp, upstreamResource)
 }
 
 func (ds *DownSyncer) BackStatusOne(resource edgev1alpha1.EdgeSyncConfigResource, conversions []edgev1alpha1.EdgeSynConversion) error {
@@ -164,7 +283,7 @@ func (ds *DownSyncer) BackStatusOne(resource edgev1alpha1.EdgeSyncConfigResource
 		ds.logger.Error(err, fmt.Sprintf("failed to get client %q", resourceToString(resource)))
 		return err
 	}
-	resourceForDown := convertToDownstream(resource, conversions)
+	resourceForDown := ConvertToDownstream(resource, conversions)
 	downstreamResource, err := downstreamClient.Get(resourceForDown)
 	if err != nil {
 		if k8serrors.IsNotFound(err) {
@@ -176,6 +295,7 @@ func (ds *DownSyncer) BackStatusOne(resource edgev1alpha1.EdgeSyncConfigResource
 			return err
 		}
 	}
+	ds.recordScale(upstreamClient.GroupVersionResource(), downstreamClient, resourceForDown, downstreamResource.GetNamespace(), downstreamResource.GetName())
 	status, found, err := unstructured.NestedMap(downstreamResource.Object, "status")
 	if err != nil {
 		ds.logger.Error(err, fmt.Sprintf("failed to extract status from downstream object %q", resourceToString(resourceForDown)))
@@ -184,10 +304,10 @@ func (ds *DownSyncer) BackStatusOne(resource edgev1alpha1.EdgeSyncConfigResource
 		ds.logger.V(3).Info(fmt.Sprintf("  skip status upsync %q since no status field in it", resourceToString(resourceForDown)))
 		return nil
 	}
-	resourceForUp := convertToUpstream(resource, conversions)
+	resourceForUp := ConvertToUpstream(resource, conversions)
 	upstreamResource, err := upstreamClient.Get(resourceForUp)
 	if err != nil {
-		if k8serrors.IsNotFound(err) {
+		if k8serrors.IsNotFound(err) || IsNotStored(err) {
 			ds.logger.V(3).Info(fmt.Sprintf("  not found %q in upstream", resourceToString(resourceForUp)))
 			ds.logger.V(3).Info(fmt.Sprintf("  skip status upsync %q", resourceToString(resourceForUp)))
 			return nil
@@ -207,9 +327,13 @@ func (ds *DownSyncer) BackStatusOne(resource edgev1alpha1.EdgeSyncConfigResource
 		ds.logger.Error(err, fmt.Sprintf("failed to update resource on upstream %q", resourceToString(resourceForUp)))
 		return err
 	}
+	metrics.RecordStatusUpdate(resourceForUp)
 	return nil
 }
 
+// SyncMany downsyncs the objects of the given resource. The upstream objects are listed one at a time
+// and only an index of the downstream objects is held; an existing downstream object is read again
+// (from the informer cache, when there is one) when it is to be updated.
 func (ds *DownSyncer) SyncMany(resource edgev1alpha1.EdgeSyncConfigResource, conversions []edgev1alpha1.EdgeSynConversion) error {
 	logger := ds.logger.WithName("SyncMany").WithValues("resource", resourceToString(resource))
 	logger.V(3).Info("downsync many")
@@ -218,119 +342,215 @@ func (ds *DownSyncer) SyncMany(resource edgev1alpha1.EdgeSyncConfigResource, con
 		logger.Error(err, "failed to get client")
 		return err
 	}
-	resourceForUp := convertToUpstream(resource, conversions)
-	logger.V(3).Info("  list resources from upstream")
-	upstreamResourceList, err := upstreamClient.List(resourceForUp)
-	if err != nil {
-		if k8serrors.IsNotFound(err) {
-			upstreamResourceList = &unstructured.UnstructuredList{}
-		} else {
-			logger.Error(err, "failed to list resource from upstream")
-			return err
-		}
-	}
-	logger.V(4).Info("  listed objects from upstream", "objects", upstreamResourceList)
-
-	resourceForDown := convertToDownstream(resource, conversions)
-	logger.V(3).Info("  list resources from downstream")
-	downstreamResourceList, err := downstreamClient.List(resourceForDown)
+	resourceForUp := ConvertToUpstream(resource, conversions)
+	resourceForDown := ConvertToDownstream(resource, conversions)
+	logger.V(3).Info("  index resources in downstream")
+	index, err := indexDestination(downstreamClient, resourceForDown, hasDownsyncAnnotation)
 	if err != nil {
 		logger.Error(err, "failed to list resource from downstream")
 		return err
 	}
-	logger.V(4).Info("  listed objects from downstream", "objects", downstreamResourceList)
-
-	logger.V(3).Info("  compute diff between upstream and downstream")
-	newResources, updatedResources, deletedResources := diff(logger, upstreamResourceList, downstreamResourceList, setDownsyncAnnotation, hasDownsyncAnnotation)
 
-	logger.V(3).Info("  create resources in downstream")
-	for _, resource := range newResources {
-		applyConversion(&resource, resourceForDown)
-		logger.V(3).Info("  create " + resource.GetName())
-		if _, err := downstreamClient.Create(resourceForDown, &resource); err != nil {
-			logger.Error(err, "failed to create resource to downstream")
-			return err
+	// Conflicts are reported after doing the rest of the work, since retrying will not resolve them
+	conflicts := []error{}
+	gvrForUp := upstreamClient.GroupVersionResource()
+	// The objects being deleted in upstream are left out of the sync, so that their
+	// downstream copies are deleted, and their deletion is finished after that
+	terminating := []unstructured.Unstructured{}
+	synced := map[string]bool{}
+	logger.V(3).Info("  sync resources to downstream")
+	err = upstreamClient.EachListItem(resourceForUp, func(upstreamObj *unstructured.Unstructured) error {
+		if isTerminating(upstreamObj) {
+			terminating = append(terminating, *upstreamObj)
+			return nil
 		}
-	}
-	logger.V(3).Info("  update resources in downstream")
-	for _, resource := range updatedResources {
-		applyConversion(&resource, resourceForDown)
-		logger.V(3).Info("  update " + resource.GetName())
-		if _, err := downstreamClient.Update(resourceForDown, &resource); err != nil {
-			logger.Error(err, "failed to create resource to downstream")
+		key := objectKey(upstreamObj.GetNamespace(), upstreamObj.GetName())
+		synced[key] = true
+		entry, exists := index[key]
+		if exists && !entry.owned && !entry.applied {
+			logger.V(2).Info(fmt.Sprintf("  ignore updating %s since annotation is not set.", upstreamObj.GetName()))
+			return nil
+		}
+		err := ds.downsyncObject(logger, upstreamClient, downstreamClient, resourceForUp, resourceForDown, upstreamObj, exists)
+		if IsApplyConflict(err) {
+			conflicts = append(conflicts, err)
+		} else if err != nil {
 			return err
 		}
+		// The finalizer goes only on the objects that have a copy in the edge cluster by now
+		if err == nil && !exists || entry.owned {
+			objForUp := resourceForUp
+			objForUp.Namespace, objForUp.Name = upstreamObj.GetNamespace(), upstreamObj.GetName()
+			return ds.ensureFinalizer(upstreamClient, objForUp, upstreamObj)
+		}
+		return nil
+	})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		logger.Error(err, "failed to sync resource from upstream")
+		return err
 	}
 	logger.V(3).Info("  delete resources from downstream")
-	for _, resource := range deletedResources {
-		applyConversion(&resource, resourceForDown)
-		logger.V(3).Info("  delete " + resource.GetName())
-		if err := downstreamClient.Delete(resourceForDown, resource.GetName()); err != nil {
+	propagationPolicy := ds.deletionPolicyFor(gvrForUp.GroupResource()).PropagationPolicy
+	for _, entry := range unsyncedOwned(logger, index, synced) {
+		objForDown := resourceForDown
+		objForDown.Namespace = entry.namespace
+		logger.V(3).Info("  delete " + entry.name)
+		if err := downstreamClient.DeleteWithPropagation(objForDown, entry.name, propagationPolicy); err != nil {
 			logger.Error(err, "failed to delete resource from downstream")
 			return err
 		}
+		metrics.RecordWrite(edgev1alpha1.SyncDirectionDown, resourceForDown, metrics.OperationDelete)
+		ds.statusStore.Forget(edgev1alpha1.SyncDirectionDown, gvrForUp, entry.namespace, entry.name)
 	}
-	return nil
+	// Deletions that are not finished yet are reported after doing the rest of the work
+	pending := []error{}
+	for _, obj := range terminating {
+		objForUp, objForDown := resourceForUp, resourceForDown
+		objForUp.Namespace, objForUp.Name = obj.GetNamespace(), obj.GetName()
+		objForDown.Namespace, objForDown.Name = obj.GetNamespace(), obj.GetName()
+		if err := ds.finishDeletion(upstreamClient, downstreamClient, objForUp, objForDown, &obj); err != nil {
+			ds.statusStore.Record(edgev1alpha1.SyncDirectionDown, gvrForUp, obj.GetNamespace(), obj.GetName(), obj.GetGeneration(), err)
+			pending = append(pending, err)
+			continue
+		}
+		ds.statusStore.Forget(edgev1alpha1.SyncDirectionDown, gvrForUp, obj.GetNamespace(), obj.GetName())
+	}
+	return utilerrors.NewAggregate(append(conflicts, pending...))
 }
 
-func (ds *DownSyncer) UnsyncMany(resource edgev1alpha1.EdgeSyncConfigResource, conversions []edgev1alpha1.EdgeSynConversion) error {
-	// It's OK to use same logic as SyncMany unless we execute specific actions for unsynced resources
-	return ds.SyncMany(resource, conversions)
+// downsyncObject applies the desired state given by the given upstream object to its copy in the edge cluster,
+// which exists or not as given, and records the outcome.
+func (ds *DownSyncer) downsyncObject(logger klog.Logger, upstreamClient, downstreamClient *Client, resourceForUp, resourceForDown edgev1alpha1.EdgeSyncConfigResource,
+	upstreamObj *unstructured.Unstructured, exists bool) error {
+	gvrForUp := upstreamClient.GroupVersionResource()
+	namespace, name, generation := upstreamObj.GetNamespace(), upstreamObj.GetName(), upstreamObj.GetGeneration()
+	objForUp, objForDown := resourceForUp, resourceForDown
+	objForUp.Namespace, objForUp.Name = namespace, name
+	objForDown.Namespace, objForDown.Name = namespace, name
+	desired := upstreamObj.DeepCopy()
+	desired.SetResourceVersion("")
+	desired.SetUID("")
+	desired.SetManagedFields(nil)
+	setDownsyncAnnotation(desired)
+	applyConversion(desired, resourceForDown)
+	prepareDesired(desired)
+	operation := metrics.OperationCreate
+	var drift []driftedField
+	if exists {
+		operation = metrics.OperationUpdate
+		existing, err := downstreamClient.Get(objForDown)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			logger.Error(err, "failed to get resource from downstream")
+			ds.statusStore.Record(edgev1alpha1.SyncDirectionDown, gvrForUp, namespace, name, generation, err)
+			return err
+		}
+		if err == nil {
+			ds.keepIgnoredFields(gvrForUp, desired, existing)
+			var doApply bool
+			drift, doApply = ds.reconcileDrift(upstreamClient, objForUp, upstreamObj, desired, existing, downstreamClient.FieldManager())
+			if !doApply {
+				ds.statusStore.RecordDrift(edgev1alpha1.SyncDirectionDown, gvrForUp, namespace, name, generation, driftedPaths(drift), false)
+				return nil
+			}
+		}
+	}
+	logger.V(3).Info(fmt.Sprintf("  %s %s", operation, name))
+	_, err := downstreamClient.Apply(objForDown, desired)
+	if err == nil {
+		err = ds.applySubresources(gvrForUp, downstreamClient, objForDown, desired)
+	}
+	if err == nil && len(drift) > 0 {
+		ds.statusStore.RecordDrift(edgev1alpha1.SyncDirectionDown, gvrForUp, namespace, name, generation, driftedPaths(drift), true)
+	} else {
+		ds.statusStore.Record(edgev1alpha1.SyncDirectionDown, gvrForUp, namespace, name, generation, err)
+	}
+	if err != nil {
+		if IsApplyConflict(err) {
+			logger.Error(err, "conflict in applying resource to downstream")
+		} else {
+			logger.Error(err, "failed to apply resource to downstream")
+		}
+		return err
+	}
+	metrics.RecordWrite(edgev1alpha1.SyncDirectionDown, resourceForDown, operation)
+	return nil
 }
 
-func (ds *DownSyncer) BackStatusMany(resource edgev1alpha1.EdgeSyncConfigResource, conversions []edgev1alpha1.EdgeSynConversion) error {
-	logger := ds.logger.WithName("BackStatusMany").WithValues("resource", resourceToString(resource))
-	upstreamClient, downstreamClient, err := ds.getClients(resource, conversions)
+// UnsyncMany releases the upstream objects of a resource that is no longer downsynced,
+// except those that a resource that is still downsynced covers. Their copies in the edge cluster are left alone.
+func (ds *DownSyncer) UnsyncMany(resource edgev1alpha1.EdgeSyncConfigResource, conversions []edgev1alpha1.EdgeSynConversion) error {
+	logger := ds.logger.WithName("UnsyncMany").WithValues("resource", resourceToString(resource))
+	upstreamClient, _, err := ds.getClients(resource, conversions)
 	if err != nil {
 		logger.Error(err, "failed to get client")
 		return err
 	}
-
-	logger.V(3).Info("  list resources from downstream")
-	resourceForDown := convertToDownstream(resource, conversions)
-	downstreamResourceList, err := downstreamClient.List(resourceForDown)
+	resourceForUp := ConvertToUpstream(resource, conversions)
+	errs := []error{}
+	err = upstreamClient.EachListItem(resourceForUp, func(obj *unstructured.Unstructured) error {
+		objForUp := resourceForUp
+		objForUp.Namespace, objForUp.Name = obj.GetNamespace(), obj.GetName()
+		if err := ds.releaseUnsynced(upstreamClient, objForUp, obj); err != nil {
+			errs = append(errs, err)
+		}
+		return nil
+	})
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
 	if err != nil {
-		logger.Error(err, "failed to list resource from downstream")
+		logger.Error(err, "failed to list resource from upstream")
 		return err
 	}
+	return utilerrors.NewAggregate(errs)
+}
 
-	resourceForUp := convertToUpstream(resource, conversions)
-	upstreamResourceList, err := upstreamClient.List(resourceForUp)
+// BackStatusMany copies the status of the objects of the given resource back from the edge cluster.
+// The downstream objects are listed one at a time and their upstream objects are read one at a time.
+func (ds *DownSyncer) BackStatusMany(resource edgev1alpha1.EdgeSyncConfigResource, conversions []edgev1alpha1.EdgeSynConversion) error {
+	logger := ds.logger.WithName("BackStatusMany").WithValues("resource", resourceToString(resource))
+	upstreamClient, downstreamClient, err := ds.getClients(resource, conversions)
 	if err != nil {
-		logger.Error(err, "failed to list resource from upstream")
+		logger.Error(err, "failed to get client")
 		return err
 	}
 
-	for _, downstreamResource := range downstreamResourceList.Items {
+	logger.V(3).Info("  list resources from downstream")
+	resourceForDown := ConvertToDownstream(resource, conversions)
+	resourceForUp := ConvertToUpstream(resource, conversions)
+	err = downstreamClient.EachListItem(resourceForDown, func(downstreamResource *unstructured.Unstructured) error {
+		objForUp := resourceForUp
+		objForUp.Namespace, objForUp.Name = downstreamResource.GetNamespace(), downstreamResource.GetName()
+		upstreamResource, err := upstreamClient.Get(objForUp)
+		if k8serrors.IsNotFound(err) || IsNotStored(err) {
+			return nil
+		}
+		if err != nil {
+			logger.Error(err, fmt.Sprintf("failed to get resource from upstream %q", resourceToString(objForUp)))
+			return err
+		}
+		ds.recordScale(upstreamClient.GroupVersionResource(), downstreamClient, resourceForDown, downstreamResource.GetNamespace(), downstreamResource.GetName())
 		status, found, err := unstructured.NestedMap(downstreamResource.Object, "status")
 		if err != nil {
 			logger.Error(err, fmt.Sprintf("failed to extract status from downstream object: %s. Skip", downstreamResource.GetName()))
-			continue
+			return nil
 		} else if !found {
 			logger.V(3).Info(fmt.Sprintf("  skip status upsync for since no status field in it: %s. Skip", downstreamResource.GetName()))
-			continue
-		}
-		upstreamResource, ok := findWithObject(downstreamResource, upstreamResourceList)
-		if ok {
-			resourceForUp := convertToUpstream(resource, conversions)
-			upstreamResource.Object["status"] = status
-			applyConversion(upstreamResource, resourceForUp)
-			if _, err := upstreamClient.UpdateStatus(resourceForUp, upstreamResource); err != nil {
-				ds.logger.Error(err, fmt.Sprintf("failed to update resource on upstream %q", resourceToString(resourceForUp)))
-				return err
-			}
+			return nil
 		}
-	}
-	return nil
-}
-
-func findWithObject(target unstructured.Unstructured, resourceList *unstructured.UnstructuredList) (*unstructured.Unstructured, bool) {
-	for _, resource := range resourceList.Items {
-		if target.GetName() == resource.GetName() && target.GetNamespace() == resource.GetNamespace() {
-			return &resource, true
+		upstreamResource.Object["status"] = status
+		applyConversion(upstreamResource, resourceForUp)
+		if _, err := upstreamClient.UpdateStatus(objForUp, upstreamResource); err != nil {
+			ds.logger.Error(err, fmt.Sprintf("failed to update resource on upstream %q", resourceToString(objForUp)))
+			return err
 		}
+		metrics.RecordStatusUpdate(resourceForUp)
+		return nil
+	})
+	if err != nil {
+		logger.Error(err, "failed to copy status back from downstream")
 	}
-	return nil, false
+	return err
 }
 
 const downsyncKey = "edge.kubestellar.io/downsynced"
@@ -0,0 +1,303 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+	. "github.com/kubestellar/kubestellar/pkg/syncer/clientfactory"
+)
+
+// downsyncHashKey is the key of the annotation in which the syncer records, on a downsynced
+// object in the edge cluster, a hash of the desired state that it last applied.
+// When the desired state still has that hash, any difference between the desired state
+// and the edge object was made in the edge cluster; that is drift.
+const downsyncHashKey = "edge.kubestellar.io/downsynced-hash"
+
+// driftedField is a field whose value in the edge cluster differs from the desired value.
+type driftedField struct {
+	path string
+	// value is the value in the edge cluster; nil if the field is missing there.
+	value interface{}
+}
+
+func driftedPaths(drift []driftedField) []string {
+	paths := make([]string, len(drift))
+	for idx, field := range drift {
+		paths[idx] = field.path
+	}
+	return paths
+}
+
+// prepareDesired finishes the desired state of an object in the edge cluster:
+// it drops any drift proposal and records the hash of the desired state.
+func prepareDesired(desired *unstructured.Unstructured) {
+	if annotations := desired.GetAnnotations(); annotations != nil {
+		delete(annotations, edgev1alpha1.DriftProposalAnnotationKey)
+		desired.SetAnnotations(annotations)
+	}
+	setAnnotation(desired, downsyncHashKey, desiredHash(desired))
+}
+
+// reconcileDrift checks whether the existing object in the edge cluster has drifted
+// and, if so, acts on that according to the drift policy.
+// The given upstream object is the source of the desired state; it may be nil.
+// Returns the drift and whether to go ahead and apply the desired state.
+func (ds *DownSyncer) reconcileDrift(upstreamClient *Client, resourceForUp edgev1alpha1.EdgeSyncConfigResource,
+	upstreamObj, desired, existing *unstructured.Unstructured, fieldManager string) ([]driftedField, bool) {
+	var defaultPolicy edgev1alpha1.DriftPolicy
+	if ds.driftPolicies != nil {
+		defaultPolicy = ds.driftPolicies(upstreamClient.GroupVersionResource().GroupResource())
+	}
+	policy := driftPolicyOf(upstreamObj, defaultPolicy)
+	drift := detectDrift(desired, existing, fieldManager)
+	proposal := ""
+	if len(drift) > 0 {
+		paths := strings.Join(driftedPaths(drift), ", ")
+		ds.logger.V(2).Info("Found drift in edge cluster", "object", resourceToString(resourceForUp), "fields", paths, "policy", policy)
+		switch policy {
+		case edgev1alpha1.DriftPolicyReport:
+			ds.event(existing, corev1.EventTypeWarning, "DriftDetected", "changed in the edge cluster, left as is: %s", paths)
+		case edgev1alpha1.DriftPolicyAdopt:
+			ds.event(existing, corev1.EventTypeWarning, "DriftProposed", "changed in the edge cluster, proposed upstream: %s", paths)
+			proposal = driftProposal(drift)
+		default:
+			ds.event(existing, corev1.EventTypeWarning, "DriftCorrected", "changed in the edge cluster, overwritten: %s", paths)
+		}
+	}
+	if upstreamObj != nil && getAnnotation(upstreamObj, edgev1alpha1.DriftProposalAnnotationKey) != proposal {
+		ds.propose(upstreamClient, resourceForUp, upstreamObj, proposal)
+	}
+	return drift, len(drift) == 0 || policy == edgev1alpha1.DriftPolicyEnforce || policy == ""
+}
+
+// propose sets the drift proposal annotation of the given upstream object, or removes it if
+// the proposal is empty.
+// Failure is logged rather than returned, it does not keep the object from being synced.
+func (ds *DownSyncer) propose(upstreamClient *Client, resourceForUp edgev1alpha1.EdgeSyncConfigResource, upstreamObj *unstructured.Unstructured, proposal string) {
+	patch := &unstructured.Unstructured{}
+	patch.SetAPIVersion(upstreamObj.GetAPIVersion())
+	patch.SetKind(upstreamObj.GetKind())
+	patch.SetNamespace(upstreamObj.GetNamespace())
+	patch.SetName(upstreamObj.GetName())
+	if proposal != "" {
+		patch.SetAnnotations(map[string]string{edgev1alpha1.DriftProposalAnnotationKey: proposal})
+	}
+	if _, err := upstreamClient.Apply(resourceForUp, patch); err != nil {
+		ds.logger.Error(err, "failed to write drift proposal to upstream", "object", resourceToString(resourceForUp))
+	}
+}
+
+func (ds *DownSyncer) event(obj *unstructured.Unstructured, eventType, reason, messageFmt string, args ...interface{}) {
+	if ds.eventRecorder != nil {
+		ds.eventRecorder.Eventf(obj, eventType, reason, messageFmt, args...)
+	}
+}
+
+// desiredHash returns a hash of the desired state in the given object:
+// everything but the status and the metadata other than labels and annotations.
+func desiredHash(desired *unstructured.Unstructured) string {
+	content := map[string]interface{}{}
+	for key, val := range desired.Object {
+		if key != "metadata" && key != "status" {
+			content[key] = val
+		}
+	}
+	content["labels"] = desired.GetLabels()
+	annotations := map[string]string{}
+	for key, val := range desired.GetAnnotations() {
+		if !ignoredAnnotation(key) {
+			annotations[key] = val
+		}
+	}
+	content["annotations"] = annotations
+	data, err := json.Marshal(content)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16])
+}
+
+// ignoredAnnotation tells whether an annotation is bookkeeping rather than desired state
+func ignoredAnnotation(key string) bool {
+	return key == downsyncHashKey || key == edgev1alpha1.DriftProposalAnnotationKey
+}
+
+// detectDrift returns the fields in which the given existing edge object has drifted from
+// the desired state that the syncer last applied to it, given the desired state now
+// (as prepared by prepareDesired).
+// If the desired state has changed since then, differences are not drift and none is returned.
+// An object that the syncer applied but that has lost its downsync annotation has drifted.
+func detectDrift(desired, existing *unstructured.Unstructured, fieldManager string) []driftedField {
+	drift := []driftedField{}
+	if !hasDownsyncAnnotation(existing) {
+		if !appliedBy(existing, fieldManager) {
+			return drift // not the syncer's object
+		}
+		drift = append(drift, driftedField{path: fmt.Sprintf("metadata.annotations[%s]", downsyncKey)})
+	}
+	if getAnnotation(existing, downsyncHashKey) != getAnnotation(desired, downsyncHashKey) {
+		return drift
+	}
+	return append(drift, driftedFields(desired, existing)...)
+}
+
+func appliedBy(obj *unstructured.Unstructured, fieldManager string) bool {
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Manager == fieldManager && entry.Operation == metav1.ManagedFieldsOperationApply {
+			return true
+		}
+	}
+	return false
+}
+
+// driftedFields compares the desired fields of the given object with the actual object.
+// Fields that are not desired, such as ones defaulted in the edge cluster, are not compared.
+func driftedFields(desired, actual *unstructured.Unstructured) []driftedField {
+	drift := []driftedField{}
+	for _, key := range sortedKeys(desired.Object) {
+		if key == "apiVersion" || key == "kind" || key == "metadata" || key == "status" {
+			continue
+		}
+		drift = appendDrift(drift, key, desired.Object[key], actual.Object[key])
+	}
+	for _, section := range []string{"labels", "annotations"} {
+		desiredMap, _, _ := unstructured.NestedStringMap(desired.Object, "metadata", section)
+		actualMap, _, _ := unstructured.NestedStringMap(actual.Object, "metadata", section)
+		keys := make([]string, 0, len(desiredMap))
+		for key := range desiredMap {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if ignoredAnnotation(key) {
+				continue
+			}
+			if actualVal, found := actualMap[key]; !found || actualVal != desiredMap[key] {
+				field := driftedField{path: fmt.Sprintf("metadata.%s[%s]", section, key)}
+				if found {
+					field.value = actualVal
+				}
+				drift = append(drift, field)
+			}
+		}
+	}
+	return drift
+}
+
+func appendDrift(drift []driftedField, path string, desired, actual interface{}) []driftedField {
+	switch desiredVal := desired.(type) {
+	case nil:
+		return drift
+	case map[string]interface{}:
+		actualVal, ok := actual.(map[string]interface{})
+		if !ok {
+			return append(drift, driftedField{path: path, value: actual})
+		}
+		for _, key := range sortedKeys(desiredVal) {
+			drift = appendDrift(drift, path+"."+key, desiredVal[key], actualVal[key])
+		}
+		return drift
+	case []interface{}:
+		actualVal, ok := actual.([]interface{})
+		if !ok || len(actualVal) != len(desiredVal) {
+			return append(drift, driftedField{path: path, value: actual})
+		}
+		for idx := range desiredVal {
+			drift = appendDrift(drift, fmt.Sprintf("%s[%d]", path, idx), desiredVal[idx], actualVal[idx])
+		}
+		return drift
+	default:
+		if !scalarsEqual(desired, actual) {
+			return append(drift, driftedField{path: path, value: actual})
+		}
+		return drift
+	}
+}
+
+// scalarsEqual compares scalars, treating numbers of different Go types by value
+func scalarsEqual(a, b interface{}) bool {
+	aNum, aIsNum := asFloat(a)
+	bNum, bIsNum := asFloat(b)
+	if aIsNum && bIsNum {
+		return aNum == bNum
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func asFloat(val interface{}) (float64, bool) {
+	switch typed := val.(type) {
+	case int64:
+		return float64(typed), true
+	case int:
+		return float64(typed), true
+	case float64:
+		return typed, true
+	}
+	return 0, false
+}
+
+func sortedKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// driftPolicyOf returns the drift policy for the given object in the mailbox workspace:
+// the one in its annotation if valid, otherwise the given default.
+func driftPolicyOf(upstreamObj *unstructured.Unstructured, defaultPolicy edgev1alpha1.DriftPolicy) edgev1alpha1.DriftPolicy {
+	if upstreamObj != nil {
+		annotated := getAnnotation(upstreamObj, edgev1alpha1.DriftPolicyAnnotationKey)
+		for _, policy := range []edgev1alpha1.DriftPolicy{edgev1alpha1.DriftPolicyEnforce, edgev1alpha1.DriftPolicyReport, edgev1alpha1.DriftPolicyAdopt} {
+			if strings.EqualFold(annotated, string(policy)) {
+				return policy
+			}
+		}
+	}
+	if defaultPolicy == "" {
+		return edgev1alpha1.DriftPolicyEnforce
+	}
+	return defaultPolicy
+}
+
+// driftProposal returns the value for the DriftProposalAnnotationKey annotation
+func driftProposal(drift []driftedField) string {
+	proposal := map[string]interface{}{}
+	for _, field := range drift {
+		proposal[field.path] = field.value
+	}
+	data, err := json.Marshal(proposal)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
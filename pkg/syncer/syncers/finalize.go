@@ -0,0 +1,218 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+	. "github.com/kubestellar/kubestellar/pkg/syncer/clientfactory"
+	"github.com/kubestellar/kubestellar/pkg/syncer/metrics"
+)
+
+// deletionRecheckDelay is the least time to wait before checking again on a deletion
+// in the edge cluster whose timeout has already run out when it is started.
+const deletionRecheckDelay = 5 * time.Second
+
+// DeletionPendingError reports that the deletion of a downstream copy is under way in the edge cluster.
+// The object is synced again when the downstream copy is deleted, which its informer notifies of,
+// so there is no point in retrying sooner than RetryAfter.
+type DeletionPendingError struct {
+	// Object identifies the downstream copy
+	Object string
+	// RetryAfter is how long until the deletion policy's timeout runs out, or zero if there is no timeout
+	RetryAfter time.Duration
+}
+
+func (e *DeletionPendingError) Error() string {
+	return fmt.Sprintf("waiting for deletion of %q to finish in the edge cluster", e.Object)
+}
+
+// DeletionPending tells whether the given error is a DeletionPendingError, or an aggregate of only
+// DeletionPendingErrors, and returns the soonest of their RetryAfters that is not zero.
+func DeletionPending(err error) (time.Duration, bool) {
+	if agg, ok := err.(utilerrors.Aggregate); ok {
+		errs := agg.Errors()
+		var soonest time.Duration
+		for _, err := range errs {
+			retryAfter, pending := DeletionPending(err)
+			if !pending {
+				return 0, false
+			}
+			if retryAfter > 0 && (soonest == 0 || retryAfter < soonest) {
+				soonest = retryAfter
+			}
+		}
+		return soonest, len(errs) > 0
+	}
+	var pendingErr *DeletionPendingError
+	if !errors.As(err, &pendingErr) {
+		return 0, false
+	}
+	return pendingErr.RetryAfter, true
+}
+
+// SetFinalizer sets the finalizer that the DownSyncer puts on the upstream objects
+// before downsyncing them, and removes once their downstream copies are gone.
+// With no finalizer (the default), an upstream object can be gone before
+// its downstream copy is deleted.
+func (ds *DownSyncer) SetFinalizer(finalizer string) {
+	ds.finalizer = finalizer
+}
+
+// SetStillDownsynced sets the test of whether an upstream object is in a resource that is still downsynced.
+// The finalizer is released from the objects of the resources that are no longer downsynced,
+// unless they pass this test. Without it, every such object is released.
+func (ds *DownSyncer) SetStillDownsynced(stillDownsynced func(*unstructured.Unstructured) bool) {
+	ds.stillDownsynced = stillDownsynced
+}
+
+// SetDeletionPolicies sets the source of the deletion policy for each mailbox workspace resource.
+func (ds *DownSyncer) SetDeletionPolicies(deletionPolicies func(schema.GroupResource) edgev1alpha1.DeletionPolicy) {
+	ds.deletionPolicies = deletionPolicies
+}
+
+func (ds *DownSyncer) deletionPolicyFor(gr schema.GroupResource) edgev1alpha1.DeletionPolicy {
+	if ds.deletionPolicies == nil {
+		return edgev1alpha1.DeletionPolicy{}
+	}
+	return ds.deletionPolicies(gr)
+}
+
+// isTerminating tells whether the given object is being deleted.
+func isTerminating(obj *unstructured.Unstructured) bool {
+	return obj.GetDeletionTimestamp() != nil
+}
+
+func hasFinalizer(obj *unstructured.Unstructured, finalizer string) bool {
+	for _, elt := range obj.GetFinalizers() {
+		if elt == finalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureFinalizer puts the DownSyncer's finalizer on the given upstream object, if it is not there already.
+// It is called once the object has been delivered, so that an object is held only by syncers
+// that have a copy of it to delete.
+// If the mailbox workspace is unreachable then the addition is left for a later sync.
+func (ds *DownSyncer) ensureFinalizer(upstreamClient *Client, resourceForUp edgev1alpha1.EdgeSyncConfigResource, upstreamObj *unstructured.Unstructured) error {
+	if ds.finalizer == "" || hasFinalizer(upstreamObj, ds.finalizer) {
+		return nil
+	}
+	_, err := upstreamClient.AddFinalizer(resourceForUp, upstreamObj, ds.finalizer)
+	if IsUnreachable(err) {
+		ds.logger.V(2).Info("Deferring addition of finalizer to unreachable upstream object", "object", resourceToString(resourceForUp))
+		return nil
+	}
+	if err != nil {
+		ds.logger.Error(err, "failed to add finalizer to upstream object", "object", resourceToString(resourceForUp))
+	}
+	return err
+}
+
+// removeFinalizer removes the DownSyncer's finalizer from the given upstream object, if it is there.
+func (ds *DownSyncer) removeFinalizer(upstreamClient *Client, resourceForUp edgev1alpha1.EdgeSyncConfigResource, upstreamObj *unstructured.Unstructured) error {
+	if ds.finalizer == "" || !hasFinalizer(upstreamObj, ds.finalizer) {
+		return nil
+	}
+	err := upstreamClient.RemoveFinalizer(resourceForUp, upstreamObj, ds.finalizer)
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		ds.logger.Error(err, "failed to remove finalizer from upstream object", "object", resourceToString(resourceForUp))
+	}
+	return err
+}
+
+// releaseUnsynced removes the DownSyncer's finalizer from the given upstream object of a resource
+// that is no longer downsynced, unless the object is still downsynced through another resource.
+// The copy in the edge cluster is no longer the DownSyncer's to delete, so nothing waits for it.
+func (ds *DownSyncer) releaseUnsynced(upstreamClient *Client, resourceForUp edgev1alpha1.EdgeSyncConfigResource, upstreamObj *unstructured.Unstructured) error {
+	if ds.stillDownsynced != nil && ds.stillDownsynced(upstreamObj) {
+		return nil
+	}
+	err := ds.removeFinalizer(upstreamClient, resourceForUp, upstreamObj)
+	if IsUnreachable(err) {
+		ds.logger.V(2).Info("Deferring removal of finalizer from unreachable upstream object", "object", resourceToString(resourceForUp))
+		return nil
+	}
+	return err
+}
+
+// finishDeletion does the downstream part of the deletion of the given upstream object,
+// which is terminating.
+// The downstream copy is deleted according to the deletion policy of the object's resource,
+// and the DownSyncer's finalizer is removed from the upstream object once the copy is gone
+// (or, if the policy says to force, once the policy's timeout has passed).
+// Returns a DeletionPendingError while the deletion is under way within the policy's timeout,
+// and some other error if it has not finished when the timeout runs out and the policy does not force.
+func (ds *DownSyncer) finishDeletion(upstreamClient, downstreamClient *Client, resourceForUp, resourceForDown edgev1alpha1.EdgeSyncConfigResource, upstreamObj *unstructured.Unstructured) error {
+	policy := ds.deletionPolicyFor(upstreamClient.GroupVersionResource().GroupResource())
+	downstreamObj, err := downstreamClient.Get(resourceForDown)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+	gone := k8serrors.IsNotFound(err) || !hasDownsyncAnnotation(downstreamObj)
+	if !gone && !isTerminating(downstreamObj) {
+		ds.logger.V(3).Info(fmt.Sprintf("  delete %q from downstream since it is being deleted in upstream", resourceToString(resourceForDown)), "propagationPolicy", policy.PropagationPolicy)
+		err := downstreamClient.DeleteWithPropagation(resourceForDown, resourceForDown.Name, policy.PropagationPolicy)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			ds.logger.Error(err, fmt.Sprintf("failed to delete resource from downstream %q", resourceToString(resourceForDown)))
+			return err
+		}
+		if err == nil {
+			metrics.RecordWrite(edgev1alpha1.SyncDirectionDown, resourceForDown, metrics.OperationDelete)
+			// The deletion has just started; the notification of its end brings the object back here
+			pending := &DeletionPendingError{Object: resourceToString(resourceForDown)}
+			if policy.Timeout != nil {
+				pending.RetryAfter = policy.Timeout.Duration - time.Since(upstreamObj.GetDeletionTimestamp().Time)
+				if pending.RetryAfter < deletionRecheckDelay {
+					pending.RetryAfter = deletionRecheckDelay
+				}
+			}
+			return pending
+		}
+		gone = true
+	}
+	if !gone {
+		waited := time.Since(upstreamObj.GetDeletionTimestamp().Time)
+		if policy.Timeout == nil {
+			return &DeletionPendingError{Object: resourceToString(resourceForDown)}
+		}
+		if waited < policy.Timeout.Duration {
+			return &DeletionPendingError{Object: resourceToString(resourceForDown), RetryAfter: policy.Timeout.Duration - waited}
+		}
+		if !policy.Force {
+			return fmt.Errorf("deletion of %q did not finish in the edge cluster within %v", resourceToString(resourceForDown), policy.Timeout.Duration)
+		}
+		ds.logger.Info("Releasing upstream object although deletion did not finish in the edge cluster", "object", resourceToString(resourceForUp), "timeout", policy.Timeout.Duration)
+		ds.event(downstreamObj, corev1.EventTypeWarning, "DeletionForced",
+			"deletion did not finish within %v, the object in the mailbox workspace was released", policy.Timeout.Duration)
+	}
+	return ds.removeFinalizer(upstreamClient, resourceForUp, upstreamObj)
+}
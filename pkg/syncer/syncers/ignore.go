@@ -0,0 +1,173 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncers
+
+import (
+	"bytes"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+
+	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+	"github.com/kubestellar/kubestellar/pkg/jsonpath"
+)
+
+// keepIgnoredFields sets, in the desired state of an object, the fields that the given
+// rules identify to their values in the existing object in the edge cluster.
+// Call this after prepareDesired, so that the recorded hash is of the desired state
+// from the mailbox workspace.
+func keepIgnoredFields(logger klog.Logger, desired, existing *unstructured.Unstructured, rules []edgev1alpha1.IgnoreDifferences) {
+	for _, rule := range rules {
+		if rule.JSONPath != "" {
+			path, err := jsonpath.ParseString(rule.JSONPath)
+			if err != nil {
+				logger.Error(err, "Ignoring malformed JSONPath in ignoreDifferences", "rule", rule)
+				continue
+			}
+			if obj, ok := jsonpath.CopySelected(desired.Object, existing.Object, path).(map[string]any); ok {
+				desired.Object = obj
+			}
+		}
+		if rule.ManagedFieldsManager != "" {
+			for _, entry := range existing.GetManagedFields() {
+				if entry.Manager != rule.ManagedFieldsManager || entry.FieldsV1 == nil {
+					continue
+				}
+				owned := &fieldpath.Set{}
+				if err := owned.FromJSON(bytes.NewReader(entry.FieldsV1.Raw)); err != nil {
+					logger.Error(err, "Failed to parse managedFields", "manager", entry.Manager)
+					continue
+				}
+				owned.Leaves().Iterate(func(path fieldpath.Path) {
+					if keepsManagedPath(path) {
+						desired.Object = copyFieldPath(desired.Object, existing.Object, path).(map[string]any)
+					}
+				})
+			}
+		}
+	}
+}
+
+// keepsManagedPath tells whether the value of the given field, owned by another
+// field manager, is kept. The status is not downsynced and most metadata is not the
+// syncer's to write.
+func keepsManagedPath(path fieldpath.Path) bool {
+	if len(path) == 0 || path[0].FieldName == nil {
+		return false
+	}
+	switch *path[0].FieldName {
+	case "status":
+		return false
+	case "metadata":
+		return len(path) > 1 && path[1].FieldName != nil &&
+			(*path[1].FieldName == "labels" || *path[1].FieldName == "annotations")
+	}
+	return true
+}
+
+// copyFieldPath returns the result of setting the place in dest identified by
+// the given path to the value at that place in src.
+// If src has no such place then dest is returned unchanged.
+func copyFieldPath(dest, src any, path fieldpath.Path) any {
+	if len(path) == 0 {
+		return runtime.DeepCopyJSONValue(src)
+	}
+	elt := path[0]
+	switch {
+	case elt.FieldName != nil:
+		srcMap, _ := src.(map[string]any)
+		srcVal, found := srcMap[*elt.FieldName]
+		if !found {
+			return dest
+		}
+		destMap, ok := dest.(map[string]any)
+		if !ok {
+			if dest != nil {
+				return dest
+			}
+			destMap = map[string]any{}
+		}
+		destMap[*elt.FieldName] = copyFieldPath(destMap[*elt.FieldName], srcVal, path[1:])
+		return destMap
+	case elt.Key != nil, elt.Value != nil:
+		srcList, _ := src.([]any)
+		srcIdx := indexOfElement(srcList, elt)
+		if srcIdx < 0 {
+			return dest
+		}
+		destList, ok := dest.([]any)
+		if !ok && dest != nil {
+			return dest
+		}
+		destIdx := indexOfElement(destList, elt)
+		if destIdx < 0 {
+			destList = append(destList, newElement(elt))
+			destIdx = len(destList) - 1
+		}
+		destList[destIdx] = copyFieldPath(destList[destIdx], srcList[srcIdx], path[1:])
+		return destList
+	case elt.Index != nil:
+		srcList, _ := src.([]any)
+		destList, ok := dest.([]any)
+		if ok && *elt.Index < len(srcList) && *elt.Index < len(destList) {
+			destList[*elt.Index] = copyFieldPath(destList[*elt.Index], srcList[*elt.Index], path[1:])
+		}
+	}
+	return dest
+}
+
+// indexOfElement returns the index of the list member identified by the given
+// key or value, or -1 if there is none.
+func indexOfElement(list []any, elt fieldpath.PathElement) int {
+	for index, member := range list {
+		if elt.Value != nil {
+			if scalarsEqual((*elt.Value).Unstructured(), member) {
+				return index
+			}
+			continue
+		}
+		memberMap, ok := member.(map[string]any)
+		if !ok {
+			continue
+		}
+		matches := true
+		for _, field := range *elt.Key {
+			if !scalarsEqual(field.Value.Unstructured(), memberMap[field.Name]) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return index
+		}
+	}
+	return -1
+}
+
+// newElement makes the list member identified by the given key or value.
+func newElement(elt fieldpath.PathElement) any {
+	if elt.Value != nil {
+		return (*elt.Value).Unstructured()
+	}
+	member := map[string]any{}
+	for _, field := range *elt.Key {
+		member[field.Name] = field.Value.Unstructured()
+	}
+	return member
+}
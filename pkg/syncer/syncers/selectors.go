@@ -0,0 +1,105 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncers
+
+import (
+	"fmt"
+	"strings"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+
+	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+)
+
+// fieldsOf returns the values of the fields of the given object that the given selector refers to.
+// A field is written as its dot-separated path in the object (e.g., `status.phase`).
+// Fields that are missing, or whose value is not a string, number or boolean, are left out.
+func fieldsOf(selector fields.Selector, obj *unstructured.Unstructured) fields.Set {
+	ans := fields.Set{}
+	for _, requirement := range selector.Requirements() {
+		value, found, err := unstructured.NestedFieldNoCopy(obj.Object, strings.Split(requirement.Field, ".")...)
+		if !found || err != nil {
+			continue
+		}
+		switch value.(type) {
+		case string, bool, int64, float64:
+			ans[requirement.Field] = fmt.Sprint(value)
+		}
+	}
+	return ans
+}
+
+// matchesFieldSelector tells whether the given object matches the given field selector.
+// The selector is evaluated here rather than by the server, so that it can refer
+// to any field of any kind of object.
+func matchesFieldSelector(fieldSelector string, obj *unstructured.Unstructured) (bool, error) {
+	if fieldSelector == "" {
+		return true, nil
+	}
+	selector, err := fields.ParseSelector(fieldSelector)
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(fieldsOf(selector, obj)), nil
+}
+
+// Selects tells whether the given edge cluster object is in the set of objects described by the given resource,
+// considering the selectors (the caller is responsible for the kind, namespace and name).
+func (us *UpSyncer) Selects(resource edgev1alpha1.EdgeSyncConfigResource, obj *unstructured.Unstructured) (bool, error) {
+	labelSelector, err := labels.Parse(resource.LabelSelector)
+	if err != nil {
+		return false, err
+	}
+	if !labelSelector.Matches(labels.Set(obj.GetLabels())) {
+		return false, nil
+	}
+	if matches, err := matchesFieldSelector(resource.FieldSelector, obj); err != nil || !matches {
+		return false, err
+	}
+	if resource.NamespaceSelector == "" || obj.GetNamespace() == "" {
+		return true, nil
+	}
+	namespaceSelector, err := labels.Parse(resource.NamespaceSelector)
+	if err != nil {
+		return false, err
+	}
+	nsResource := edgev1alpha1.EdgeSyncConfigResource{Kind: "Namespace", Group: "", Version: "v1", Name: obj.GetNamespace()}
+	_, downstreamClient, err := us.getClients(nsResource, []edgev1alpha1.EdgeSynConversion{})
+	if err != nil {
+		return false, err
+	}
+	namespace, err := downstreamClient.Get(nsResource)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return namespaceSelector.Matches(labels.Set(namespace.GetLabels())), nil
+}
+
+// upsyncSelectionOf returns the selectors of the given upsynced resource, as reported in the SyncerConfig status.
+func upsyncSelectionOf(resource edgev1alpha1.EdgeSyncConfigResource) edgev1alpha1.UpsyncSelection {
+	return edgev1alpha1.UpsyncSelection{
+		LabelSelector:     resource.LabelSelector,
+		FieldSelector:     resource.FieldSelector,
+		NamespaceSelector: resource.NamespaceSelector,
+	}
+}
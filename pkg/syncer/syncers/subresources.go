@@ -0,0 +1,91 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncers
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+	. "github.com/kubestellar/kubestellar/pkg/syncer/clientfactory"
+)
+
+// SetSubresources sets the source of what to do with the subresources of the objects
+// of each mailbox workspace resource.
+// Without it, only the objects themselves are downsynced.
+func (ds *DownSyncer) SetSubresources(subresources func(schema.GroupResource) edgev1alpha1.ResourceSubresources) {
+	ds.subresources = subresources
+}
+
+func (ds *DownSyncer) subresourcesFor(gr schema.GroupResource) edgev1alpha1.ResourceSubresources {
+	if ds.subresources == nil {
+		return edgev1alpha1.ResourceSubresources{}
+	}
+	return ds.subresources(gr)
+}
+
+// scaleRule returns the rule that keeps the edge cluster's replicas of the objects of the given
+// mailbox workspace resource, if the edge cluster is authoritative for their scale.
+func (ds *DownSyncer) scaleRule(gr schema.GroupResource) (edgev1alpha1.IgnoreDifferences, bool) {
+	scale := ds.subresourcesFor(gr).Scale
+	if scale == nil {
+		return edgev1alpha1.IgnoreDifferences{}, false
+	}
+	path := scale.SpecReplicasPath
+	if path == "" {
+		path = edgev1alpha1.DefaultSpecReplicasPath
+	}
+	return edgev1alpha1.IgnoreDifferences{GroupResource: metav1.GroupResource{Group: gr.Group, Resource: gr.Resource}, JSONPath: path}, true
+}
+
+// applySubresources writes the given downsynced object through the other subresources
+// listed for its mailbox workspace resource, after the object itself has been written.
+func (ds *DownSyncer) applySubresources(gvrForUp schema.GroupVersionResource, downstreamClient *Client, resourceForDown edgev1alpha1.EdgeSyncConfigResource, desired *unstructured.Unstructured) error {
+	for _, subresource := range ds.subresourcesFor(gvrForUp.GroupResource()).Names {
+		if subresource == "status" || subresource == "scale" {
+			ds.logger.V(2).Info("Not downsyncing through subresource", "subresource", subresource, "resource", gvrForUp)
+			continue
+		}
+		if _, err := downstreamClient.ApplySubresource(resourceForDown, desired, subresource); err != nil {
+			return fmt.Errorf("failed to write %s of %s: %w", subresource, desired.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// recordScale reads the scale of the given downstream object, if the edge cluster is authoritative
+// for the scale of its resource, and records it with the object's downsync status.
+func (ds *DownSyncer) recordScale(gvrForUp schema.GroupVersionResource, downstreamClient *Client, resourceForDown edgev1alpha1.EdgeSyncConfigResource, namespace, name string) {
+	if _, ok := ds.scaleRule(gvrForUp.GroupResource()); !ok {
+		return
+	}
+	objForDown := resourceForDown
+	objForDown.Namespace, objForDown.Name = namespace, name
+	scale, err := downstreamClient.GetScale(objForDown)
+	if err != nil {
+		ds.logger.V(3).Info("Failed to read the scale of downstream object", "object", resourceToString(objForDown), "err", err)
+		return
+	}
+	ds.statusStore.RecordScale(edgev1alpha1.SyncDirectionDown, gvrForUp, namespace, name, &edgev1alpha1.ObservedScale{
+		Replicas:       scale.Spec.Replicas,
+		StatusReplicas: scale.Status.Replicas,
+		Selector:       scale.Status.Selector,
+	})
+}
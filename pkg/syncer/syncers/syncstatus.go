@@ -0,0 +1,279 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+)
+
+// MaxReportedObjectStatuses bounds the number of object statuses that are reported
+// in the status of a SyncerConfig.
+const MaxReportedObjectStatuses = 20
+
+type syncedObjectKey struct {
+	direction edgev1alpha1.SyncDirection
+	group     string
+	resource  string
+	namespace string
+	name      string
+}
+
+// SyncStatusStore holds the outcome of the latest attempt to sync each object.
+// Objects are identified as they appear upstream.
+// A nil *SyncStatusStore is valid and records nothing.
+type SyncStatusStore struct {
+	sync.Mutex
+	statuses map[syncedObjectKey]edgev1alpha1.SyncedObjectStatus
+}
+
+func NewSyncStatusStore() *SyncStatusStore {
+	return &SyncStatusStore{statuses: map[syncedObjectKey]edgev1alpha1.SyncedObjectStatus{}}
+}
+
+// Record notes the outcome of an attempt to sync the identified object.
+// The generation is that of the source object; err is nil on success.
+func (s *SyncStatusStore) Record(direction edgev1alpha1.SyncDirection, gvr schema.GroupVersionResource, namespace, name string, generation int64, err error) {
+	if s == nil {
+		return
+	}
+	status := edgev1alpha1.SyncedObjectStatus{
+		Direction:          direction,
+		APIGroup:           gvr.Group,
+		Resource:           gvr.Resource,
+		Namespace:          namespace,
+		Name:               name,
+		Outcome:            edgev1alpha1.SyncOutcomeSucceeded,
+		ObservedGeneration: generation,
+		LastSyncTime:       metav1.Now(),
+	}
+	if err != nil {
+		status.Outcome = edgev1alpha1.SyncOutcomeFailed
+		status.Message = err.Error()
+	}
+	s.put(syncedObjectKey{direction, gvr.Group, gvr.Resource, namespace, name}, status)
+}
+
+// RecordDrift notes that the identified object was found drifted in the given fields,
+// and whether the drift was overwritten.
+// The generation is that of the source object.
+func (s *SyncStatusStore) RecordDrift(direction edgev1alpha1.SyncDirection, gvr schema.GroupVersionResource, namespace, name string, generation int64, fields []string, overwritten bool) {
+	if s == nil {
+		return
+	}
+	status := edgev1alpha1.SyncedObjectStatus{
+		Direction:          direction,
+		APIGroup:           gvr.Group,
+		Resource:           gvr.Resource,
+		Namespace:          namespace,
+		Name:               name,
+		Outcome:            edgev1alpha1.SyncOutcomeDrifted,
+		Message:            fmt.Sprintf("changed in the edge cluster: %s", strings.Join(fields, ", ")),
+		DriftedFields:      fields,
+		ObservedGeneration: generation,
+		LastSyncTime:       metav1.Now(),
+	}
+	if overwritten {
+		status.Outcome = edgev1alpha1.SyncOutcomeSucceeded
+		status.Message = "overwrote changes made in the edge cluster: " + strings.Join(fields, ", ")
+	}
+	s.put(syncedObjectKey{direction, gvr.Group, gvr.Resource, namespace, name}, status)
+}
+
+// RecordScale notes the scale of the identified object in the edge cluster.
+// It is kept with the outcomes recorded later, and does nothing if no outcome is recorded yet.
+func (s *SyncStatusStore) RecordScale(direction edgev1alpha1.SyncDirection, gvr schema.GroupVersionResource, namespace, name string, scale *edgev1alpha1.ObservedScale) {
+	if s == nil {
+		return
+	}
+	key := syncedObjectKey{direction, gvr.Group, gvr.Resource, namespace, name}
+	s.Lock()
+	defer s.Unlock()
+	if status, found := s.statuses[key]; found {
+		status.Scale = scale
+		s.statuses[key] = status
+	}
+}
+
+// RecordUpsyncSelection notes that the identified upsynced object was found
+// selected by the given selectors.
+// It is kept with the outcomes recorded later, and does nothing if no outcome is recorded yet.
+func (s *SyncStatusStore) RecordUpsyncSelection(gvr schema.GroupVersionResource, namespace, name string, selection edgev1alpha1.UpsyncSelection) {
+	if s == nil {
+		return
+	}
+	key := syncedObjectKey{edgev1alpha1.SyncDirectionUp, gvr.Group, gvr.Resource, namespace, name}
+	s.Lock()
+	defer s.Unlock()
+	status, found := s.statuses[key]
+	if !found {
+		return
+	}
+	for _, have := range status.UpsyncSelections {
+		if have == selection {
+			return
+		}
+	}
+	selections := append([]edgev1alpha1.UpsyncSelection{}, status.UpsyncSelections...)
+	selections = append(selections, selection)
+	sort.Slice(selections, func(i, j int) bool {
+		a, b := selections[i], selections[j]
+		if a.LabelSelector != b.LabelSelector {
+			return a.LabelSelector < b.LabelSelector
+		}
+		if a.FieldSelector != b.FieldSelector {
+			return a.FieldSelector < b.FieldSelector
+		}
+		return a.NamespaceSelector < b.NamespaceSelector
+	})
+	status.UpsyncSelections = selections
+	s.statuses[key] = status
+}
+
+// put records the given status, keeping the recorded scale and upsync selections.
+func (s *SyncStatusStore) put(key syncedObjectKey, status edgev1alpha1.SyncedObjectStatus) {
+	s.Lock()
+	defer s.Unlock()
+	if old, found := s.statuses[key]; found {
+		if status.Scale == nil {
+			status.Scale = old.Scale
+		}
+		status.UpsyncSelections = old.UpsyncSelections
+	}
+	s.statuses[key] = status
+}
+
+// Forget notes that the identified object is no longer being synced.
+func (s *SyncStatusStore) Forget(direction edgev1alpha1.SyncDirection, gvr schema.GroupVersionResource, namespace, name string) {
+	if s == nil {
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	delete(s.statuses, syncedObjectKey{direction, gvr.Group, gvr.Resource, namespace, name})
+}
+
+// List returns the recorded statuses, in a deterministic order.
+func (s *SyncStatusStore) List() []edgev1alpha1.SyncedObjectStatus {
+	if s == nil {
+		return nil
+	}
+	s.Lock()
+	defer s.Unlock()
+	statuses := make([]edgev1alpha1.SyncedObjectStatus, 0, len(s.statuses))
+	for _, status := range s.statuses {
+		statuses = append(statuses, status)
+	}
+	sortObjectStatuses(statuses)
+	return statuses
+}
+
+// Problems returns the recorded statuses whose outcome is not Succeeded, in a deterministic order.
+// Only the most recent max of them are returned.
+func (s *SyncStatusStore) Problems(max int) []edgev1alpha1.SyncedObjectStatus {
+	if s == nil {
+		return nil
+	}
+	s.Lock()
+	defer s.Unlock()
+	statuses := []edgev1alpha1.SyncedObjectStatus{}
+	for _, status := range s.statuses {
+		if status.Outcome != edgev1alpha1.SyncOutcomeSucceeded {
+			statuses = append(statuses, status)
+		}
+	}
+	if len(statuses) > max {
+		sortObjectStatuses(statuses)
+		sort.SliceStable(statuses, func(i, j int) bool {
+			return statuses[j].LastSyncTime.Before(&statuses[i].LastSyncTime)
+		})
+		statuses = statuses[:max]
+	}
+	sortObjectStatuses(statuses)
+	return statuses
+}
+
+// Counts returns the number of recorded statuses with each outcome,
+// by direction, resource and namespace, in a deterministic order.
+func (s *SyncStatusStore) Counts() []edgev1alpha1.SyncedObjectCount {
+	if s == nil {
+		return nil
+	}
+	s.Lock()
+	defer s.Unlock()
+	countsByKey := map[syncedObjectKey]*edgev1alpha1.SyncedObjectCount{}
+	for key, status := range s.statuses {
+		key.name = ""
+		count, found := countsByKey[key]
+		if !found {
+			count = &edgev1alpha1.SyncedObjectCount{Direction: key.direction, APIGroup: key.group, Resource: key.resource, Namespace: key.namespace}
+			countsByKey[key] = count
+		}
+		switch status.Outcome {
+		case edgev1alpha1.SyncOutcomeSucceeded:
+			count.Succeeded++
+		case edgev1alpha1.SyncOutcomeFailed:
+			count.Failed++
+		case edgev1alpha1.SyncOutcomeDrifted:
+			count.Drifted++
+		}
+	}
+	counts := make([]edgev1alpha1.SyncedObjectCount, 0, len(countsByKey))
+	for _, count := range countsByKey {
+		counts = append(counts, *count)
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		a, b := counts[i], counts[j]
+		if a.Direction != b.Direction {
+			return a.Direction < b.Direction
+		}
+		if a.APIGroup != b.APIGroup {
+			return a.APIGroup < b.APIGroup
+		}
+		if a.Resource != b.Resource {
+			return a.Resource < b.Resource
+		}
+		return a.Namespace < b.Namespace
+	})
+	return counts
+}
+
+func sortObjectStatuses(statuses []edgev1alpha1.SyncedObjectStatus) {
+	sort.Slice(statuses, func(i, j int) bool {
+		a, b := statuses[i], statuses[j]
+		if a.Direction != b.Direction {
+			return a.Direction < b.Direction
+		}
+		if a.APIGroup != b.APIGroup {
+			return a.APIGroup < b.APIGroup
+		}
+		if a.Resource != b.Resource {
+			return a.Resource < b.Resource
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+}
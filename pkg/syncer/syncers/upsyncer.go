@@ -24,10 +24,12 @@ import (
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/klog/v2"
 
 	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
 	. "github.com/kubestellar/kubestellar/pkg/syncer/clientfactory"
+	"github.com/kubestellar/kubestellar/pkg/syncer/metrics"
 )
 
 type UpSyncer struct {
@@ -37,6 +39,10 @@ type UpSyncer struct {
 	downstreamClientFactory ClientFactory
 	upstreamClients         map[schema.GroupKind]*Client
 	downstreamClients       map[schema.GroupKind]*Client
+	statusStore             *SyncStatusStore
+	syncTargetName          string
+	locationName            func() string
+	collisionPolicies       func(schema.GroupResource, string, string, map[string]string) edgev1alpha1.UpsyncCollisionPolicy
 }
 
 func NewUpSyncer(logger klog.Logger, upstreamClientFactory ClientFactory, downstreamClientFactory ClientFactory, syncedResources []edgev1alpha1.EdgeSyncConfigResource, conversions []edgev1alpha1.EdgeSynConversion) (*UpSyncer, error) {
@@ -67,23 +73,59 @@ func (us *UpSyncer) ReInitializeClients(syncedResources []edgev1alpha1.EdgeSyncC
 	return initializeClients(us.logger, syncedResources, us.upstreamClientFactory, us.downstreamClientFactory, us.upstreamClients, us.downstreamClients, conversions)
 }
 
+// SetStatusStore sets where the outcome of each object's sync is recorded.
+func (us *UpSyncer) SetStatusStore(statusStore *SyncStatusStore) {
+	us.statusStore = statusStore
+}
+
 func (us *UpSyncer) getClients(resource edgev1alpha1.EdgeSyncConfigResource, conversions []edgev1alpha1.EdgeSynConversion) (*Client, *Client, error) {
 	us.Lock()
 	defer us.Unlock()
 	return getClients(resource, us.upstreamClients, us.downstreamClients, conversions)
 }
 
-func (us *UpSyncer) SyncOne(resource edgev1alpha1.EdgeSyncConfigResource, conversions []edgev1alpha1.EdgeSynConversion) error {
+func (us *UpSyncer) SyncOne(resource edgev1alpha1.EdgeSyncConfigResource, conversions []edgev1alpha1.EdgeSynConversion) (err error) {
 	us.logger.V(3).Info(fmt.Sprintf("upsync %q", resourceToString(resource)))
 	upstreamClient, downstreamClient, err := us.getClients(resource, conversions)
 	if err != nil {
 		us.logger.Error(err, fmt.Sprintf("failed to get client %q", resourceToString(resource)))
 		return err
 	}
-	resourceForDown := convertToDownstream(resource, conversions)
+	resourceForDown := ConvertToDownstream(resource, conversions)
 	us.logger.V(3).Info(fmt.Sprintf("  get %q from downstream", resourceToString(resourceForDown)))
 	downstreamResource, err := downstreamClient.Get(resourceForDown)
 	isDeleted := false
+	resourceForUp := ConvertToUpstream(resource, conversions)
+	// The labels of an object that is gone are not known, so its label selectors are taken to match
+	var objLabels map[string]string
+	if downstreamResource != nil {
+		objLabels = downstreamResource.GetLabels()
+		if objLabels == nil {
+			objLabels = map[string]string{}
+		}
+	}
+	gr := downstreamClient.GroupVersionResource().GroupResource()
+	policy := us.collisionPolicyFor(gr, resource.Namespace, resource.Name, objLabels)
+	resourceForUp.Namespace, resourceForUp.Name = upsyncedNamespaceAndName(policy, gr, us.syncTargetName, resourceForUp.Namespace, resourceForUp.Name)
+	// notSynced explains why the object is left alone, when that is not an error
+	var notSynced error
+	defer func() {
+		gvr := upstreamClient.GroupVersionResource()
+		if isDeleted && err == nil {
+			us.statusStore.Forget(edgev1alpha1.SyncDirectionUp, gvr, resourceForUp.Namespace, resourceForUp.Name)
+			return
+		}
+		var generation int64
+		if downstreamResource != nil {
+			generation = downstreamResource.GetGeneration()
+		}
+		outcome := err
+		if outcome == nil {
+			outcome = notSynced
+		}
+		us.statusStore.Record(edgev1alpha1.SyncDirectionUp, gvr, resourceForUp.Namespace, resourceForUp.Name, generation, outcome)
+		us.statusStore.RecordUpsyncSelection(gvr, resourceForUp.Namespace, resourceForUp.Name, upsyncSelectionOf(resource))
+	}()
 	if err != nil {
 		if k8serrors.IsNotFound(err) {
 			us.logger.V(3).Info(fmt.Sprintf("  not found %q in downstream", resourceToString(resourceForDown)))
@@ -93,24 +135,41 @@ func (us *UpSyncer) SyncOne(resource edgev1alpha1.EdgeSyncConfigResource, conver
 			us.logger.Error(err, fmt.Sprintf("failed to get resource from upstream %q", resourceToString(resourceForDown)))
 			return err
 		}
+	} else {
+		// An object that the selectors do not select is treated like one that is gone
+		selected, err := us.Selects(resource, downstreamResource)
+		if err != nil {
+			us.logger.Error(err, fmt.Sprintf("failed to evaluate selectors for %q", resourceToString(resourceForDown)))
+			return err
+		}
+		if !selected {
+			us.logger.V(3).Info(fmt.Sprintf("  not selected %q in downstream", resourceToString(resourceForDown)))
+			isDeleted = true
+		}
 	}
 
-	resourceForUp := convertToUpstream(resource, conversions)
 	us.logger.V(3).Info(fmt.Sprintf("  get %q from upstream", resourceToString(resourceForUp)))
 	upstreamResource, err := upstreamClient.Get(resourceForUp)
 	if err != nil {
-		if k8serrors.IsNotFound(err) {
+		// While upstream is unreachable, an object that is not in the local store is created by a deferred write
+		if k8serrors.IsNotFound(err) || IsNotStored(err) {
 			if !isDeleted {
 				// create
 				us.logger.V(3).Info(fmt.Sprintf("  create %q in upstream since it's not found", resourceToString(resourceForUp)))
+				if err := us.ensurePerClusterNamespace(policy, downstreamResource.GetNamespace(), resourceForUp.Namespace); err != nil {
+					us.logger.Error(err, fmt.Sprintf("failed to create namespace in upstream for %q", resourceToString(resourceForUp)))
+					return err
+				}
 				downstreamResource.SetResourceVersion("")
 				downstreamResource.SetUID("")
+				us.toUpsyncedCopy(downstreamResource, resourceForUp.Namespace, resourceForUp.Name)
 				setUpsyncAnnotation(downstreamResource)
 				applyConversion(downstreamResource, resourceForUp)
-				if _, err := upstreamClient.Create(resourceForUp, downstreamResource); err != nil {
+				if _, err := upstreamClient.Apply(resourceForUp, downstreamResource); err != nil {
 					us.logger.Error(err, fmt.Sprintf("failed to create resource to upstream %q", resourceToString(resourceForUp)))
 					return err
 				}
+				metrics.RecordWrite(edgev1alpha1.SyncDirectionUp, resourceForUp, metrics.OperationCreate)
 			} else {
 				us.logger.V(3).Info(fmt.Sprintf("  %q has already been deleted from upstream", resourceToString(resourceForUp)))
 			}
@@ -124,16 +183,17 @@ func (us *UpSyncer) SyncOne(resource edgev1alpha1.EdgeSyncConfigResource, conver
 				// update
 				us.logger.V(3).Info(fmt.Sprintf("  update %q in upstream since it's found", resourceToString(resourceForUp)))
 				if hasUpsyncAnnotation(upstreamResource) {
-					downstreamResource.SetResourceVersion(upstreamResource.GetResourceVersion())
-					downstreamResource.SetUID(upstreamResource.GetUID())
+					us.toUpsyncedCopy(downstreamResource, resourceForUp.Namespace, resourceForUp.Name)
 					setUpsyncAnnotation(downstreamResource)
 					applyConversion(downstreamResource, resourceForUp)
-					if _, err := upstreamClient.Update(resourceForUp, downstreamResource); err != nil {
+					if _, err := upstreamClient.Apply(resourceForUp, downstreamResource); err != nil {
 						us.logger.Error(err, fmt.Sprintf("failed to update resource on upstream %q", resourceToString(resourceForUp)))
 						return err
 					}
+					metrics.RecordWrite(edgev1alpha1.SyncDirectionUp, resourceForUp, metrics.OperationUpdate)
 				} else {
 					us.logger.V(2).Info(fmt.Sprintf("  ignore updating %q in upstream since upstream annotation is not set", resourceToString(resourceForUp)))
+					notSynced = fmt.Errorf("%q exists in upstream but was not created by the syncer", resourceToString(resourceForUp))
 				}
 			} else {
 				// Upsyncer should not delete upstream resource objects that are not created by Upsyncer
@@ -143,6 +203,7 @@ func (us *UpSyncer) SyncOne(resource edgev1alpha1.EdgeSyncConfigResource, conver
 						us.logger.Error(err, fmt.Sprintf("failed to delete resource from upstream %q", resourceToString(resourceForUp)))
 						return err
 					}
+					metrics.RecordWrite(edgev1alpha1.SyncDirectionUp, resourceForUp, metrics.OperationDelete)
 				} else {
 					us.logger.V(2).Info(fmt.Sprintf("  ignore deleting %q from upstream since downsync annotation is not set", resourceToString(resourceForUp)))
 				}
@@ -181,57 +242,102 @@ func (us *UpSyncer) syncMany(resource edgev1alpha1.EdgeSyncConfigResource, conve
 		return err
 	}
 
-	logger.V(3).Info("  list resources from downstream")
-	resourceForDown := convertToDownstream(resource, conversions)
-	downstreamResourceList, err := downstreamClient.List(resourceForDown)
-	if err != nil {
-		if k8serrors.IsNotFound(err) {
-			downstreamResourceList = &unstructured.UnstructuredList{}
-		} else {
-			logger.Error(err, "failed to list resource from downstream")
-			return err
-		}
-	}
-
-	logger.V(3).Info("  list resources from upstream")
-	resourceForUp := convertToUpstream(resource, conversions)
-	upstreamResourceList, err := upstreamClient.List(resourceForUp)
+	// The upsynced copies are named according to the collision policy for each object,
+	// and listed according to the policy for all the objects that the resource covers.
+	// They carry the labels but may not match the field selector,
+	// so only the label selector is used for listing them.
+	gr := downstreamClient.GroupVersionResource().GroupResource()
+	policy := us.collisionPolicyFor(gr, resource.Namespace, resource.Name, nil)
+	logger.V(3).Info("  index resources in upstream")
+	resourceForUp := ConvertToUpstream(resource, conversions)
+	resourceForUp.FieldSelector = ""
+	resourceForUp.Namespace, _ = upsyncedNamespaceAndName(policy, gr, us.syncTargetName, resourceForUp.Namespace, resourceForUp.Name)
+	index, err := indexDestination(upstreamClient, resourceForUp, hasUpsyncAnnotation)
 	if err != nil {
 		logger.Error(err, "failed to list resource from upstream")
 		return err
 	}
 
-	logger.V(3).Info("  compute diff between downstream and upstream")
-	newResources, updatedResources, deletedResources := diff(logger, downstreamResourceList, upstreamResourceList, setUpsyncAnnotation, hasUpsyncAnnotation)
-
-	logger.V(3).Info("  create resources in upstream")
-	for _, resource := range newResources {
-		applyConversion(&resource, resourceForUp)
-		logger.V(3).Info("  create " + resource.GetName())
-		if _, err := upstreamClient.Create(resourceForUp, &resource); err != nil {
-			logger.Error(err, "failed to create resource in upstream")
+	// Conflicts are reported after doing the rest of the work, since retrying will not resolve them
+	conflicts := []error{}
+	gvrForUp := upstreamClient.GroupVersionResource()
+	selection := upsyncSelectionOf(resource)
+	synced := map[string]bool{}
+	// ensuredNamespaces are the upstream namespaces known to exist
+	ensuredNamespaces := map[string]bool{}
+	logger.V(3).Info("  sync resources from downstream")
+	resourceForDown := ConvertToDownstream(resource, conversions)
+	err = downstreamClient.EachListItem(resourceForDown, func(item *unstructured.Unstructured) error {
+		if matches, err := matchesFieldSelector(resource.FieldSelector, item); err != nil {
+			logger.Error(err, "failed to evaluate field selector")
 			return err
+		} else if !matches {
+			return nil
 		}
-	}
-	logger.V(3).Info("  update resources in upstream")
-	for _, resource := range updatedResources {
-		applyConversion(&resource, resourceForUp)
-		logger.V(3).Info("  update " + resource.GetName())
-		if _, err := upstreamClient.Update(resourceForUp, &resource); err != nil {
-			logger.Error(err, "failed to update resource in upstream")
+		objLabels := item.GetLabels()
+		if objLabels == nil {
+			objLabels = map[string]string{}
+		}
+		itemPolicy := us.collisionPolicyFor(gr, item.GetNamespace(), item.GetName(), objLabels)
+		namespace, name := upsyncedNamespaceAndName(itemPolicy, gr, us.syncTargetName, item.GetNamespace(), item.GetName())
+		key := objectKey(namespace, name)
+		synced[key] = true
+		entry, exists := index[key]
+		if exists && !entry.owned {
+			logger.V(2).Info(fmt.Sprintf("  ignore updating %s since annotation is not set.", name))
+			return nil
+		}
+		operation := metrics.OperationCreate
+		if exists {
+			operation = metrics.OperationUpdate
+		} else if !ensuredNamespaces[namespace] {
+			if err := us.ensurePerClusterNamespace(itemPolicy, item.GetNamespace(), namespace); err != nil {
+				logger.Error(err, "failed to create namespace in upstream", "namespace", namespace)
+				return err
+			}
+			ensuredNamespaces[namespace] = true
+		}
+		us.toUpsyncedCopy(item, namespace, name)
+		item.SetResourceVersion("")
+		item.SetUID("")
+		item.SetManagedFields(nil)
+		setUpsyncAnnotation(item)
+		applyConversion(item, resourceForUp)
+		objForUp := resourceForUp
+		objForUp.Namespace, objForUp.Name = namespace, name
+		logger.V(3).Info(fmt.Sprintf("  %s %s", operation, name))
+		_, err := upstreamClient.Apply(objForUp, item)
+		us.statusStore.Record(edgev1alpha1.SyncDirectionUp, gvrForUp, namespace, name, item.GetGeneration(), err)
+		us.statusStore.RecordUpsyncSelection(gvrForUp, namespace, name, selection)
+		if err != nil {
+			if IsApplyConflict(err) {
+				logger.Error(err, "conflict in applying resource to upstream")
+				conflicts = append(conflicts, err)
+				return nil
+			}
+			logger.Error(err, "failed to apply resource to upstream")
 			return err
 		}
+		metrics.RecordWrite(edgev1alpha1.SyncDirectionUp, resourceForUp, operation)
+		return nil
+	})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		logger.Error(err, "failed to sync resource from downstream")
+		return err
 	}
 	logger.V(3).Info("  delete resources from upstream")
-	for _, resource := range deletedResources {
-		applyConversion(&resource, resourceForUp)
-		logger.V(3).Info("  delete " + resource.GetName())
-		if err := upstreamClient.Delete(resourceForUp, resource.GetName()); err != nil {
+	for _, entry := range unsyncedOwned(logger, index, synced) {
+		objForUp := resourceForUp
+		objForUp.Namespace = entry.namespace
+		logger.V(3).Info("  delete " + entry.name)
+		if err := upstreamClient.Delete(objForUp, entry.name); err != nil {
 			logger.Error(err, "failed to delete resource from upstream")
 			return err
 		}
+		metrics.RecordWrite(edgev1alpha1.SyncDirectionUp, resourceForUp, metrics.OperationDelete)
+		us.statusStore.Forget(edgev1alpha1.SyncDirectionUp, gvrForUp, entry.namespace, entry.name)
 	}
-	return nil
+	return utilerrors.NewAggregate(conflicts)
 }
 
 func (us *UpSyncer) UnsyncMany(resource edgev1alpha1.EdgeSyncConfigResource, conversions []edgev1alpha1.EdgeSynConversion) error {
@@ -244,7 +350,7 @@ func (us *UpSyncer) syncAllNamespaces(
 	conversions []edgev1alpha1.EdgeSynConversion,
 	syncFunc func(resource edgev1alpha1.EdgeSyncConfigResource, conversions []edgev1alpha1.EdgeSynConversion) error,
 ) error {
-	namespaces, err := us.getNamespaces()
+	namespaces, err := us.getNamespaces(resource.NamespaceSelector)
 	if err != nil {
 		us.logger.Error(err, fmt.Sprintf("failed to get namespaces %q", resourceToString(resource)))
 		return err
@@ -261,24 +367,25 @@ func (us *UpSyncer) syncAllNamespaces(
 	return nil
 }
 
-func (us *UpSyncer) getNamespaces() ([]string, error) {
+// getNamespaces returns the names of the namespaces in the edge cluster whose labels match the given label selector.
+func (us *UpSyncer) getNamespaces(labelSelector string) ([]string, error) {
 	namespaces := []string{}
 	nsResource := edgev1alpha1.EdgeSyncConfigResource{
-		Kind: "Namespace", Group: "", Version: "v1",
+		Kind: "Namespace", Group: "", Version: "v1", LabelSelector: labelSelector,
 	}
 	_, downstreamClient, err := us.getClients(nsResource, []edgev1alpha1.EdgeSynConversion{})
 	if err != nil {
 		us.logger.Error(err, "failed to get namespace client")
 		return nil, err
 	}
-	nsUnstList, err := downstreamClient.List(nsResource)
+	err = downstreamClient.EachListItem(nsResource, func(nsUnst *unstructured.Unstructured) error {
+		namespaces = append(namespaces, nsUnst.GetName())
+		return nil
+	})
 	if err != nil {
 		us.logger.Error(err, "failed to get namespaces")
 		return nil, err
 	}
-	for _, nsUnst := range nsUnstList.Items {
-		namespaces = append(namespaces, nsUnst.GetName())
-	}
 	return namespaces, nil
 }
 
@@ -0,0 +1,220 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+	. "github.com/kubestellar/kubestellar/pkg/syncer/clientfactory"
+	"github.com/kubestellar/kubestellar/pkg/syncer/metrics"
+)
+
+// SetSource sets the names of the SyncTarget and the source of the name of the Location
+// of the edge cluster, with which the UpSyncer names and labels the upsynced copies.
+func (us *UpSyncer) SetSource(syncTargetName string, locationName func() string) {
+	us.syncTargetName = syncTargetName
+	us.locationName = locationName
+}
+
+// SetCollisionPolicies sets the source of the collision policy for the edge cluster objects
+// of a given resource, namespace and name (either of which may be "*") and labels
+// (nil when the object is not at hand).
+func (us *UpSyncer) SetCollisionPolicies(collisionPolicies func(gr schema.GroupResource, namespace, name string, labels map[string]string) edgev1alpha1.UpsyncCollisionPolicy) {
+	us.collisionPolicies = collisionPolicies
+}
+
+func (us *UpSyncer) collisionPolicyFor(gr schema.GroupResource, namespace, name string, labels map[string]string) edgev1alpha1.UpsyncCollisionPolicy {
+	if us.collisionPolicies == nil || us.syncTargetName == "" {
+		return edgev1alpha1.UpsyncCollisionPolicyMerge
+	}
+	return us.collisionPolicies(gr, namespace, name, labels)
+}
+
+const (
+	// maxNameLength is the limit on the names of most kinds of objects
+	maxNameLength = 253
+	// maxLabelLength is the limit on namespaces and on the names of the kinds in dnsLabelResources
+	maxLabelLength = 63
+	// nameHashLength is the number of hex digits of the hash that ends a name that was cut short
+	nameHashLength = 10
+)
+
+// dnsLabelResources are the core resources whose object names must be DNS labels.
+var dnsLabelResources = sets.NewString("namespaces", "services")
+
+// maxNameLengthFor returns the limit on the names of the objects of the given resource.
+func maxNameLengthFor(gr schema.GroupResource) int {
+	if gr.Group == "" && dnsLabelResources.Has(gr.Resource) {
+		return maxLabelLength
+	}
+	return maxNameLength
+}
+
+// boundedName returns the given name if it is no longer than the given limit,
+// otherwise the start of it followed by a dash and a hash of the whole name.
+func boundedName(name string, limit int) string {
+	if len(name) <= limit {
+		return name
+	}
+	hash := sha256.Sum256([]byte(name))
+	start := strings.TrimRight(name[:limit-nameHashLength-1], "-.")
+	return start + "-" + hex.EncodeToString(hash[:])[:nameHashLength]
+}
+
+// upsyncedNamespaceAndName returns the namespace and name of the upsynced copy of the edge cluster
+// object, of the given resource, with the given namespace and name, under the given policy.
+func upsyncedNamespaceAndName(policy edgev1alpha1.UpsyncCollisionPolicy, gr schema.GroupResource, syncTargetName, namespace, name string) (string, string) {
+	limit := maxNameLengthFor(gr)
+	switch policy {
+	case edgev1alpha1.UpsyncCollisionPolicyPrefix:
+		return namespace, boundedName(syncTargetName+"-"+name, limit)
+	case edgev1alpha1.UpsyncCollisionPolicySuffix:
+		return namespace, boundedName(name+"-"+syncTargetName, limit)
+	case edgev1alpha1.UpsyncCollisionPolicyPerClusterNamespace:
+		if namespace != "" {
+			return boundedName(syncTargetName+"-"+namespace, maxLabelLength), name
+		}
+		return namespace, boundedName(syncTargetName+"-"+name, limit)
+	}
+	return namespace, name
+}
+
+// EdgeNamespaceAndName returns the namespace and name of the edge cluster object, of the given resource,
+// of which the given object is the upsynced copy. It is the inverse of upsyncedNamespaceAndName
+// under the collision policy for that edge cluster object. A name that was cut short
+// is found from the copy's SourceObjectAnnotationKey annotation.
+func (us *UpSyncer) EdgeNamespaceAndName(resource edgev1alpha1.EdgeSyncConfigResource, conversions []edgev1alpha1.EdgeSynConversion, upsyncedCopy *unstructured.Unstructured) (string, string) {
+	namespace, name := upsyncedCopy.GetNamespace(), upsyncedCopy.GetName()
+	if us.syncTargetName == "" {
+		return namespace, name
+	}
+	_, downstreamClient, err := us.getClients(resource, conversions)
+	if err != nil {
+		return namespace, name
+	}
+	gr := downstreamClient.GroupVersionResource().GroupResource()
+	prefix, suffix := us.syncTargetName+"-", "-"+us.syncTargetName
+	candidates := [][2]string{}
+	if source, ok := upsyncedCopy.GetAnnotations()[edgev1alpha1.SourceObjectAnnotationKey]; ok {
+		if sourceNamespace, sourceName, found := strings.Cut(source, "/"); found {
+			candidates = append(candidates, [2]string{sourceNamespace, sourceName})
+		} else {
+			candidates = append(candidates, [2]string{"", source})
+		}
+	}
+	if strings.HasPrefix(name, prefix) {
+		candidates = append(candidates, [2]string{namespace, strings.TrimPrefix(name, prefix)})
+	}
+	if strings.HasSuffix(name, suffix) {
+		candidates = append(candidates, [2]string{namespace, strings.TrimSuffix(name, suffix)})
+	}
+	if strings.HasPrefix(namespace, prefix) {
+		candidates = append(candidates, [2]string{strings.TrimPrefix(namespace, prefix), name})
+	}
+	candidates = append(candidates, [2]string{namespace, name})
+	// A candidate is the one only if its own policy names its copy as given
+	for _, candidate := range candidates {
+		policy := us.collisionPolicyFor(gr, candidate[0], candidate[1], nil)
+		if copyNamespace, copyName := upsyncedNamespaceAndName(policy, gr, us.syncTargetName, candidate[0], candidate[1]); copyNamespace == namespace && copyName == name {
+			return candidate[0], candidate[1]
+		}
+	}
+	return namespace, name
+}
+
+// toUpsyncedCopy turns the given edge cluster object into its upsynced copy,
+// with the given namespace and name, labeled and annotated with its source.
+func (us *UpSyncer) toUpsyncedCopy(obj *unstructured.Unstructured, namespace, name string) {
+	source := obj.GetName()
+	if obj.GetNamespace() != "" {
+		source = obj.GetNamespace() + "/" + source
+	}
+	setAnnotation(obj, edgev1alpha1.SourceObjectAnnotationKey, source)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	if us.syncTargetName != "" {
+		labels[edgev1alpha1.SourceSyncTargetLabelKey] = us.syncTargetName
+	}
+	if us.locationName != nil {
+		if locationName := us.locationName(); locationName != "" {
+			labels[edgev1alpha1.SourceLocationLabelKey] = locationName
+		}
+	}
+	if len(labels) > 0 {
+		obj.SetLabels(labels)
+	}
+}
+
+// ensurePerClusterNamespace creates, in the upstream, the namespace of the upsynced copies of the edge
+// cluster objects in the given namespace under the given policy, if that policy gives them a namespace
+// of their own that does not exist yet. The namespace is created as the upsynced copy of the edge
+// cluster's Namespace object would be, so that upsyncing the Namespace objects too takes it over.
+func (us *UpSyncer) ensurePerClusterNamespace(policy edgev1alpha1.UpsyncCollisionPolicy, edgeNamespace, namespace string) error {
+	if policy != edgev1alpha1.UpsyncCollisionPolicyPerClusterNamespace || edgeNamespace == "" || edgeNamespace == namespace {
+		return nil
+	}
+	nsClient, err := us.upstreamNamespaceClient()
+	if err != nil {
+		return err
+	}
+	nsResource := edgev1alpha1.EdgeSyncConfigResource{Kind: "Namespace", Group: "", Version: "v1", Name: namespace}
+	if _, err := nsClient.Get(nsResource); err == nil || !(k8serrors.IsNotFound(err) || IsNotStored(err)) {
+		return err
+	}
+	us.logger.V(3).Info(fmt.Sprintf("  create namespace %q in upstream", namespace))
+	ns := &unstructured.Unstructured{}
+	ns.SetAPIVersion("v1")
+	ns.SetKind("Namespace")
+	ns.SetName(edgeNamespace)
+	us.toUpsyncedCopy(ns, "", namespace)
+	setUpsyncAnnotation(ns)
+	if _, err := nsClient.Apply(nsResource, ns); err != nil {
+		return err
+	}
+	metrics.RecordWrite(edgev1alpha1.SyncDirectionUp, nsResource, metrics.OperationCreate)
+	return nil
+}
+
+// upstreamNamespaceClient returns the client for the Namespace objects in the upstream,
+// setting it up when the Namespace objects are not among the upsynced resources.
+func (us *UpSyncer) upstreamNamespaceClient() (*Client, error) {
+	us.Lock()
+	defer us.Unlock()
+	gk := schema.GroupKind{Group: "", Kind: "Namespace"}
+	if client, ok := us.upstreamClients[gk]; ok {
+		return client, nil
+	}
+	client, err := us.upstreamClientFactory.GetResourceClient(gk.Group, gk.Kind)
+	if err != nil {
+		return nil, err
+	}
+	us.upstreamClients[gk] = &client
+	return &client, nil
+}
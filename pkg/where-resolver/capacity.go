@@ -0,0 +1,90 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package where_resolver
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+)
+
+// capacityFit describes how well a SyncTarget fits an EdgePlacement's resource requests
+type capacityFit struct {
+	// fits is true when every requested resource is covered
+	fits bool
+
+	// headroom is, over the requested resources, the least fraction
+	// of the allocatable amount that would remain after the request.
+	// Meaningful only when fits.
+	headroom float64
+
+	// reason and message explain why the SyncTarget does not fit
+	reason, message string
+}
+
+// fitOf judges how well the given SyncTarget fits the EdgePlacement's resource requests
+func fitOf(ep *edgev1alpha1.EdgePlacement, st *edgev1alpha1.SyncTarget) capacityFit {
+	requests := ep.Spec.ResourceRequests
+	if len(requests) == 0 {
+		return capacityFit{fits: true, headroom: 1}
+	}
+	if st.Status.Allocatable == nil {
+		return capacityFit{reason: edgev1alpha1.RejectedCapacityUnknown,
+			message: "SyncTarget does not report allocatable resources"}
+	}
+	allocatable := *st.Status.Allocatable
+	names := make([]string, 0, len(requests))
+	for name := range requests {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	ans := capacityFit{fits: true, headroom: 1}
+	shortages := []string{}
+	for _, nameStr := range names {
+		name := corev1.ResourceName(nameStr)
+		request := requests[name]
+		available, found := allocatable[name]
+		if !found || available.Cmp(request) < 0 {
+			shortages = append(shortages, fmt.Sprintf("%s: requested %s, allocatable %s", name, request.String(), available.String()))
+			continue
+		}
+		if availableVal := available.AsApproximateFloat64(); availableVal > 0 {
+			headroom := (availableVal - request.AsApproximateFloat64()) / availableVal
+			if headroom < ans.headroom {
+				ans.headroom = headroom
+			}
+		}
+	}
+	if len(shortages) > 0 {
+		return capacityFit{reason: edgev1alpha1.RejectedInsufficientCapacity, message: strings.Join(shortages, "; ")}
+	}
+	return ans
+}
+
+// capacityFilters tells whether the EdgePlacement rejects SyncTargets that do not fit
+func capacityFilters(ep *edgev1alpha1.EdgePlacement) bool {
+	return len(ep.Spec.ResourceRequests) > 0 && ep.Spec.CapacityPolicy != edgev1alpha1.CapacityPolicyRank
+}
+
+// capacityRanks tells whether the EdgePlacement prefers SyncTargets with more room to spare
+func capacityRanks(ep *edgev1alpha1.EdgePlacement) bool {
+	return len(ep.Spec.ResourceRequests) > 0 && ep.Spec.CapacityPolicy == edgev1alpha1.CapacityPolicyRank
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package where_resolver
+
+import (
+	"hash/fnv"
+	"sort"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+)
+
+// stRef identifies a SyncTarget
+type stRef struct {
+	cluster string
+	name    string
+}
+
+// epIsSelective tells whether the EdgePlacement chooses among the SyncTargets
+// that its Locations select, rather than taking them all.
+// The incremental updates done on Location and SyncTarget changes do not apply
+// to such an EdgePlacement; it has to be resolved as a whole.
+func epIsSelective(ep *edgev1alpha1.EdgePlacement) bool {
+	return ep.Spec.NumberOfClusters != nil || len(ep.Spec.ResourceRequests) > 0
+}
+
+// chooseDestinations returns the subset of the given candidate SinglePlacements
+// that the given EdgePlacement chooses, and the SyncTargets that were rejected.
+// The SyncTargets of the candidates are given in `sts`.
+// The current destinations are preferred, for stability.
+func chooseDestinations(ep *edgev1alpha1.EdgePlacement, candidates []edgev1alpha1.SinglePlacement,
+	sts map[stRef]*edgev1alpha1.SyncTarget, current []edgev1alpha1.SinglePlacement) ([]edgev1alpha1.SinglePlacement, []edgev1alpha1.RejectedCandidate) {
+	if !epIsSelective(ep) {
+		return candidates, nil
+	}
+	wasChosen := map[stRef]bool{}
+	for _, sp := range current {
+		wasChosen[stRef{sp.Cluster, sp.SyncTargetName}] = true
+	}
+	remaining := []stRef{}
+	fits := map[stRef]capacityFit{}
+	for _, sp := range candidates {
+		ref := stRef{sp.Cluster, sp.SyncTargetName}
+		if st, found := sts[ref]; found && !stRefsContain(remaining, ref) {
+			remaining = append(remaining, ref)
+			fits[ref] = fitOf(ep, st)
+		}
+	}
+	tieBreak := tieBreakerOrder(ep)
+	sort.Slice(remaining, func(i, j int) bool { return tieBreak(remaining[i], remaining[j]) })
+
+	rejected := []edgev1alpha1.RejectedCandidate{}
+	if capacityFilters(ep) {
+		fitting := []stRef{}
+		for _, ref := range remaining {
+			if fit := fits[ref]; fit.fits {
+				fitting = append(fitting, ref)
+			} else {
+				rejected = append(rejected, edgev1alpha1.RejectedCandidate{
+					Cluster: ref.cluster, SyncTargetName: ref.name, Reason: fit.reason, Message: fit.message})
+			}
+		}
+		remaining = fitting
+	}
+
+	// domainCounts[i][v] is the number of chosen SyncTargets whose label spreadConstraints[i].TopologyKey has value v
+	domainCounts := make([]map[string]int, len(ep.Spec.SpreadConstraints))
+	for idx := range domainCounts {
+		domainCounts[idx] = map[string]int{}
+	}
+	domainCountsOf := func(ref stRef) []int {
+		ans := make([]int, len(ep.Spec.SpreadConstraints))
+		for idx, constraint := range ep.Spec.SpreadConstraints {
+			ans[idx] = domainCounts[idx][sts[ref].Labels[constraint.TopologyKey]]
+		}
+		return ans
+	}
+	ranksCapacity := capacityRanks(ep)
+	better := func(a, b stRef) bool {
+		if wasChosen[a] != wasChosen[b] {
+			return wasChosen[a]
+		}
+		aCounts, bCounts := domainCountsOf(a), domainCountsOf(b)
+		for idx := range aCounts {
+			if aCounts[idx] != bCounts[idx] {
+				return aCounts[idx] < bCounts[idx]
+			}
+		}
+		if ranksCapacity {
+			aFit, bFit := fits[a], fits[b]
+			if aFit.fits != bFit.fits {
+				return aFit.fits
+			}
+			if aFit.headroom != bFit.headroom {
+				return aFit.headroom > bFit.headroom
+			}
+		}
+		return false // the remaining are already in tie-breaker order
+	}
+
+	limit := len(remaining)
+	if ep.Spec.NumberOfClusters != nil && int(*ep.Spec.NumberOfClusters) < limit {
+		limit = int(*ep.Spec.NumberOfClusters)
+	}
+	chosen := map[stRef]bool{}
+	for numChosen := 0; numChosen < limit; numChosen++ {
+		bestIdx := 0
+		for idx := 1; idx < len(remaining); idx++ {
+			if better(remaining[idx], remaining[bestIdx]) {
+				bestIdx = idx
+			}
+		}
+		best := remaining[bestIdx]
+		remaining = append(remaining[:bestIdx], remaining[bestIdx+1:]...)
+		chosen[best] = true
+		for idx, constraint := range ep.Spec.SpreadConstraints {
+			domainCounts[idx][sts[best].Labels[constraint.TopologyKey]]++
+		}
+	}
+	ans := []edgev1alpha1.SinglePlacement{}
+	for _, sp := range candidates {
+		if chosen[stRef{sp.Cluster, sp.SyncTargetName}] {
+			ans = append(ans, sp)
+		}
+	}
+	return ans, rejected
+}
+
+// tieBreakerOrder returns the "less than" function for the EdgePlacement's TieBreaker.
+func tieBreakerOrder(ep *edgev1alpha1.EdgePlacement) func(a, b stRef) bool {
+	byName := func(a, b stRef) bool {
+		if a.cluster != b.cluster {
+			return a.cluster < b.cluster
+		}
+		return a.name < b.name
+	}
+	if ep.Spec.TieBreaker != edgev1alpha1.TieBreakerHash {
+		return byName
+	}
+	epID := logicalcluster.From(ep).String() + "/" + ep.Name
+	hashOf := func(ref stRef) uint64 {
+		hasher := fnv.New64a()
+		hasher.Write([]byte(epID + "\n" + ref.cluster + "/" + ref.name))
+		return hasher.Sum64()
+	}
+	return func(a, b stRef) bool {
+		aHash, bHash := hashOf(a), hashOf(b)
+		if aHash != bHash {
+			return aHash < bHash
+		}
+		return byName(a, b)
+	}
+}
+
+func stRefsContain(refs []stRef, ref stRef) bool {
+	for _, elt := range refs {
+		if elt == ref {
+			return true
+		}
+	}
+	return false
+}
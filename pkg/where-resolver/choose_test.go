@@ -0,0 +1,159 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package where_resolver
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+)
+
+func TestChooseDestinations(t *testing.T) {
+	sts := map[stRef]*edgev1alpha1.SyncTarget{}
+	candidates := []edgev1alpha1.SinglePlacement{}
+	for _, spec := range []struct{ name, region string }{
+		{"a", "east"}, {"b", "east"}, {"c", "east"}, {"d", "west"}, {"e", "west"}, {"f", ""},
+	} {
+		st := &edgev1alpha1.SyncTarget{ObjectMeta: metav1.ObjectMeta{Name: spec.name, Labels: map[string]string{}}}
+		if spec.region != "" {
+			st.Labels["region"] = spec.region
+		}
+		sts[stRef{"inv", spec.name}] = st
+		candidates = append(candidates, edgev1alpha1.SinglePlacement{Cluster: "inv", LocationName: "loc-" + spec.name, SyncTargetName: spec.name})
+	}
+	names := func(sps []edgev1alpha1.SinglePlacement) []string {
+		ans := []string{}
+		for _, sp := range sps {
+			ans = append(ans, sp.SyncTargetName)
+		}
+		return ans
+	}
+	int32Ptr := func(val int32) *int32 { return &val }
+	spread := []edgev1alpha1.SpreadConstraint{{TopologyKey: "region"}}
+	for idx, testCase := range []struct {
+		spec     edgev1alpha1.EdgePlacementSpec
+		current  []string
+		expected []string
+	}{
+		{edgev1alpha1.EdgePlacementSpec{}, nil, []string{"a", "b", "c", "d", "e", "f"}},
+		{edgev1alpha1.EdgePlacementSpec{NumberOfClusters: int32Ptr(2)}, nil, []string{"a", "b"}},
+		{edgev1alpha1.EdgePlacementSpec{NumberOfClusters: int32Ptr(3), SpreadConstraints: spread}, nil, []string{"a", "d", "f"}},
+		{edgev1alpha1.EdgePlacementSpec{NumberOfClusters: int32Ptr(4), SpreadConstraints: spread}, nil, []string{"a", "b", "d", "f"}},
+		{edgev1alpha1.EdgePlacementSpec{NumberOfClusters: int32Ptr(2)}, []string{"e", "c", "gone"}, []string{"c", "e"}},
+		{edgev1alpha1.EdgePlacementSpec{NumberOfClusters: int32Ptr(2)}, []string{"gone"}, []string{"a", "b"}},
+		{edgev1alpha1.EdgePlacementSpec{NumberOfClusters: int32Ptr(3), SpreadConstraints: spread}, []string{"b", "c"}, []string{"b", "c", "d"}},
+		{edgev1alpha1.EdgePlacementSpec{NumberOfClusters: int32Ptr(9)}, nil, []string{"a", "b", "c", "d", "e", "f"}},
+		{edgev1alpha1.EdgePlacementSpec{NumberOfClusters: int32Ptr(0)}, nil, []string{}},
+	} {
+		ep := &edgev1alpha1.EdgePlacement{ObjectMeta: metav1.ObjectMeta{Name: "ep"}, Spec: testCase.spec}
+		current := []edgev1alpha1.SinglePlacement{}
+		for _, name := range testCase.current {
+			current = append(current, edgev1alpha1.SinglePlacement{Cluster: "inv", SyncTargetName: name})
+		}
+		chosen, _ := chooseDestinations(ep, candidates, sts, current)
+		actual := names(chosen)
+		if !reflect.DeepEqual(actual, testCase.expected) {
+			t.Errorf("Case %d: expected %v, got %v", idx, testCase.expected, actual)
+		}
+	}
+}
+
+func TestChooseDestinationsHashIsStable(t *testing.T) {
+	sts := map[stRef]*edgev1alpha1.SyncTarget{}
+	candidates := []edgev1alpha1.SinglePlacement{}
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		sts[stRef{"inv", name}] = &edgev1alpha1.SyncTarget{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		candidates = append(candidates, edgev1alpha1.SinglePlacement{Cluster: "inv", SyncTargetName: name})
+	}
+	three := int32(3)
+	ep := &edgev1alpha1.EdgePlacement{ObjectMeta: metav1.ObjectMeta{Name: "ep"},
+		Spec: edgev1alpha1.EdgePlacementSpec{NumberOfClusters: &three, TieBreaker: edgev1alpha1.TieBreakerHash}}
+	chosen, _ := chooseDestinations(ep, candidates, sts, nil)
+	if len(chosen) != 3 {
+		t.Fatalf("Expected 3 chosen, got %v", chosen)
+	}
+	// Adding and removing SyncTargets that were not chosen must not change the choice
+	isChosen := map[string]bool{}
+	for _, sp := range chosen {
+		isChosen[sp.SyncTargetName] = true
+	}
+	changed := []edgev1alpha1.SinglePlacement{}
+	for _, sp := range candidates {
+		if isChosen[sp.SyncTargetName] || sp.SyncTargetName < "d" {
+			changed = append(changed, sp)
+		}
+	}
+	sts[stRef{"inv", "z"}] = &edgev1alpha1.SyncTarget{ObjectMeta: metav1.ObjectMeta{Name: "z"}}
+	changed = append(changed, edgev1alpha1.SinglePlacement{Cluster: "inv", SyncTargetName: "z"})
+	rechosen, _ := chooseDestinations(ep, changed, sts, chosen)
+	if !reflect.DeepEqual(rechosen, chosen) {
+		t.Errorf("Expected %v to remain chosen, got %v", chosen, rechosen)
+	}
+}
+
+func TestChooseDestinationsCapacity(t *testing.T) {
+	sts := map[stRef]*edgev1alpha1.SyncTarget{}
+	candidates := []edgev1alpha1.SinglePlacement{}
+	for _, spec := range []struct{ name, cpu string }{
+		{"a", "1"}, {"b", "4"}, {"c", ""}, {"d", "2"}, {"e", "8"},
+	} {
+		st := &edgev1alpha1.SyncTarget{ObjectMeta: metav1.ObjectMeta{Name: spec.name}}
+		if spec.cpu != "" {
+			st.Status.Allocatable = &corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(spec.cpu)}
+		}
+		sts[stRef{"inv", spec.name}] = st
+		candidates = append(candidates, edgev1alpha1.SinglePlacement{Cluster: "inv", SyncTargetName: spec.name})
+	}
+	requests := corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")}
+	int32Ptr := func(val int32) *int32 { return &val }
+	for idx, testCase := range []struct {
+		spec             edgev1alpha1.EdgePlacementSpec
+		expected         []string
+		expectedRejected []string
+	}{
+		{edgev1alpha1.EdgePlacementSpec{ResourceRequests: requests},
+			[]string{"b", "d", "e"}, []string{"a:InsufficientCapacity", "c:CapacityUnknown"}},
+		{edgev1alpha1.EdgePlacementSpec{ResourceRequests: requests, NumberOfClusters: int32Ptr(2)},
+			[]string{"b", "d"}, []string{"a:InsufficientCapacity", "c:CapacityUnknown"}},
+		{edgev1alpha1.EdgePlacementSpec{ResourceRequests: requests, CapacityPolicy: edgev1alpha1.CapacityPolicyRank},
+			[]string{"a", "b", "c", "d", "e"}, []string{}},
+		{edgev1alpha1.EdgePlacementSpec{ResourceRequests: requests, CapacityPolicy: edgev1alpha1.CapacityPolicyRank, NumberOfClusters: int32Ptr(2)},
+			[]string{"b", "e"}, []string{}},
+		{edgev1alpha1.EdgePlacementSpec{ResourceRequests: requests, CapacityPolicy: edgev1alpha1.CapacityPolicyRank, NumberOfClusters: int32Ptr(4)},
+			[]string{"a", "b", "d", "e"}, []string{}},
+	} {
+		ep := &edgev1alpha1.EdgePlacement{ObjectMeta: metav1.ObjectMeta{Name: "ep"}, Spec: testCase.spec}
+		chosen, rejected := chooseDestinations(ep, candidates, sts, nil)
+		actual := []string{}
+		for _, sp := range chosen {
+			actual = append(actual, sp.SyncTargetName)
+		}
+		actualRejected := []string{}
+		for _, rc := range rejected {
+			actualRejected = append(actualRejected, rc.SyncTargetName+":"+rc.Reason)
+		}
+		if !reflect.DeepEqual(actual, testCase.expected) || !reflect.DeepEqual(actualRejected, testCase.expectedRejected) {
+			t.Errorf("Case %d: expected %v and rejected %v, got %v and rejected %v", idx,
+				testCase.expected, testCase.expectedRejected, actual, actualRejected)
+		}
+	}
+}
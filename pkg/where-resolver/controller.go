@@ -125,7 +125,8 @@ func NewController(
 		UpdateFunc: func(old, obj interface{}) {
 			oldST := old.(*edgev1alpha1.SyncTarget)
 			newST := obj.(*edgev1alpha1.SyncTarget)
-			if !apiequality.Semantic.DeepEqual(oldST.Spec, newST.Spec) || !apiequality.Semantic.DeepEqual(oldST.Labels, newST.Labels) {
+			if !apiequality.Semantic.DeepEqual(oldST.Spec, newST.Spec) || !apiequality.Semantic.DeepEqual(oldST.Labels, newST.Labels) ||
+				!apiequality.Semantic.DeepEqual(oldST.Status.Allocatable, newST.Status.Allocatable) {
 				c.enqueueSyncTarget((obj))
 			}
 		},
@@ -183,6 +184,27 @@ func (c *controller) enqueueSyncTarget(obj interface{}) {
 	)
 }
 
+// enqueueIfSelective enqueues the EdgePlacement with the given key for resolution
+// as a whole, and returns true, if that EdgePlacement is selective.
+func (c *controller) enqueueIfSelective(ctx context.Context, epKey string) bool {
+	ws, _, name, err := kcpcache.SplitMetaClusterNamespaceKey(epKey)
+	if err != nil {
+		return false
+	}
+	ep, err := c.edgePlacementLister.Cluster(ws).Get(name)
+	if err != nil || !epIsSelective(ep) {
+		return false
+	}
+	klog.FromContext(ctx).V(2).Info("queueing selective EdgePlacement", "key", epKey)
+	c.queue.Add(
+		queueItem{
+			triggeringKind: triggeringKindEdgePlacement,
+			key:            epKey,
+		},
+	)
+	return true
+}
+
 // Run starts the controller, which stops when c.context.Done() is closed.
 func (c *controller) Run(numThreads int) {
 	defer runtime.HandleCrash()
@@ -49,7 +49,8 @@ func (c *controller) reconcileOnEdgePlacement(ctx context.Context, epKey string)
 
 		3) update store, with loc(s) that being selected by ep
 
-		4) update apiserver
+		4) update apiserver, after leaving out the unschedulable st(s) that are not already in use
+		   and choosing among the st(s) if ep is selective
 
 		Need data structure: none.
 	*/
@@ -83,6 +84,7 @@ func (c *controller) reconcileOnEdgePlacement(ctx context.Context, epKey string)
 	locsSelecting := packLocKeys(locsFilteredByEp)
 
 	singles := []edgev1alpha1.SinglePlacement{}
+	stsSelected := map[stRef]*edgev1alpha1.SyncTarget{}
 	for _, loc := range locsFilteredByEp {
 		// 2)
 		lws := logicalcluster.From(loc)
@@ -97,6 +99,9 @@ func (c *controller) reconcileOnEdgePlacement(ctx context.Context, epKey string)
 			return err
 		}
 		singles = append(singles, makeSinglePlacementsForLoc(loc, stsSelecting)...)
+		for _, st := range stsSelecting {
+			stsSelected[stRef{lws.String(), st.Name}] = st
+		}
 	}
 
 	// 3)
@@ -115,6 +120,15 @@ func (c *controller) reconcileOnEdgePlacement(ctx context.Context, epKey string)
 
 	// 4)
 	currentSPS, err := c.singlePlacementSliceLister.Cluster(epws).Get(epName)
+	currentDests := []edgev1alpha1.SinglePlacement{}
+	if err == nil {
+		currentDests = currentSPS.Destinations
+	}
+	singles = admitSingles(singles, stsSelected, currentDests)
+	var rejected []edgev1alpha1.RejectedCandidate
+	if epIsSelective(ep) {
+		singles, rejected = chooseDestinations(ep, singles, stsSelected, currentDests)
+	}
 	if err != nil {
 		if errors.IsNotFound(err) { // create
 			logger.V(1).Info("creating SinglePlacementSlice")
@@ -130,7 +144,8 @@ func (c *controller) reconcileOnEdgePlacement(ctx context.Context, epKey string)
 						},
 					},
 				},
-				Destinations: singles,
+				Destinations:       singles,
+				RejectedCandidates: rejected,
 			}
 			_, err = c.edgeClusterClient.Cluster(epws.Path()).EdgeV1alpha1().SinglePlacementSlices().Create(ctx, sps, metav1.CreateOptions{})
 			if err != nil {
@@ -147,6 +162,7 @@ func (c *controller) reconcileOnEdgePlacement(ctx context.Context, epKey string)
 		}
 	} else { // update
 		currentSPS.Destinations = singles
+		currentSPS.RejectedCandidates = rejected
 		_, err = c.edgeClusterClient.Cluster(epws.Path()).EdgeV1alpha1().SinglePlacementSlices().Update(ctx, currentSPS, metav1.UpdateOptions{})
 		if err != nil {
 			logger.Error(err, "failed updating SinglePlacementSlice")
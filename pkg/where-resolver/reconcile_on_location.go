@@ -18,6 +18,7 @@ package where_resolver
 
 import (
 	"context"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -138,6 +139,9 @@ func (c *controller) reconcileOnLocation(ctx context.Context, locKey string) err
 	singles := makeSinglePlacementsForLoc(loc, stsFilteredByLoc)
 
 	for ep := range epsSelectedLoc {
+		if c.enqueueIfSelective(ctx, ep) {
+			continue
+		}
 		if _, ok := epsSelectingLoc[ep]; !ok {
 			// 4a)
 			// an (obsolete) ep doesn't select loc anymore
@@ -179,8 +183,9 @@ func (c *controller) reconcileOnLocation(ctx context.Context, locKey string) err
 				return err
 			}
 
+			currentDests := currentSPS.Destinations
 			nextSPS := cleanSPSByLoc(currentSPS, lws.String(), lName)
-			nextSPS = extendSPS(nextSPS, singles)
+			nextSPS = extendSPS(nextSPS, admitSingles(singles, stsByRef(stsFilteredByLoc), currentDests))
 
 			_, err = c.edgeClusterClient.EdgeV1alpha1().SinglePlacementSlices().Cluster(ws.Path()).Update(ctx, nextSPS, metav1.UpdateOptions{})
 			if err != nil {
@@ -194,6 +199,9 @@ func (c *controller) reconcileOnLocation(ctx context.Context, locKey string) err
 
 	for ep := range epsSelectingLoc {
 		if _, ok := epsSelectedLoc[ep]; !ok {
+			if c.enqueueIfSelective(ctx, ep) {
+				continue
+			}
 			// 4c)
 			// a (new) ep begins to select loc
 			// we need to ensure the existence of sp(s) in the corresponding sps
@@ -209,8 +217,9 @@ func (c *controller) reconcileOnLocation(ctx context.Context, locKey string) err
 				return err
 			}
 
+			currentDests := currentSPS.Destinations
 			nextSPS := cleanSPSByLoc(currentSPS, lws.String(), lName)
-			nextSPS = extendSPS(nextSPS, singles)
+			nextSPS = extendSPS(nextSPS, admitSingles(singles, stsByRef(stsFilteredByLoc), currentDests))
 
 			_, err = c.edgeClusterClient.EdgeV1alpha1().SinglePlacementSlices().Cluster(ws.Path()).Update(ctx, nextSPS, metav1.UpdateOptions{})
 			if err != nil {
@@ -225,10 +234,15 @@ func (c *controller) reconcileOnLocation(ctx context.Context, locKey string) err
 	return nil
 }
 
-// filterStsByLoc returns those SyncTargets that selected by the Location
+// filterStsByLoc returns those SyncTargets that selected by the Location,
+// leaving out the evicted ones
 func filterStsByLoc(sts []*edgev1alpha1.SyncTarget, loc *edgev1alpha1.Location) ([]*edgev1alpha1.SyncTarget, error) {
 	filtered := []*edgev1alpha1.SyncTarget{}
+	now := time.Now()
 	for _, st := range sts {
+		if stIsEvicted(st, now) {
+			continue
+		}
 		s := loc.Spec.InstanceSelector
 		selector, err := metav1.LabelSelectorAsSelector(s)
 		if err != nil {
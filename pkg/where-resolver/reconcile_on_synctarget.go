@@ -18,6 +18,7 @@ package where_resolver
 
 import (
 	"context"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -75,6 +76,13 @@ func (c *controller) reconcileOnSyncTarget(ctx context.Context, stKey string) er
 		}
 	}
 
+	if !stDeleted && st.Spec.EvictAfter != nil {
+		if untilEviction := time.Until(st.Spec.EvictAfter.Time); untilEviction > 0 {
+			logger.V(2).Info("will reconcile again at eviction time", "evictAfter", st.Spec.EvictAfter)
+			c.queue.AddAfter(queueItem{triggeringKind: triggeringKindSyncTarget, key: stKey}, untilEviction)
+		}
+	}
+
 	// 1)
 	epsUsedSt := store.findEpsUsedSt(stKey)
 
@@ -115,6 +123,9 @@ func (c *controller) reconcileOnSyncTarget(ctx context.Context, stKey string) er
 
 	// 4)
 	for ep := range epsUsedSt {
+		if c.enqueueIfSelective(ctx, ep) {
+			continue
+		}
 		if _, ok := epsUsingSt[ep]; !ok {
 			// 4a)
 			// for an (obsolite) ep in epsUsedSt but not in epsUsingSt
@@ -157,6 +168,7 @@ func (c *controller) reconcileOnSyncTarget(ctx context.Context, stKey string) er
 				logger.Error(err, "failed to get SinglePlacementSlice", "workloadWorkspace", ws, "singlePlacementSlice", name)
 				return err
 			}
+			currentDests := currentSPS.Destinations
 			nextSPS := cleanSPSBySt(currentSPS, stws.String(), stName)
 
 			epObj, err := c.edgePlacementLister.Cluster(ws).Get(name)
@@ -170,6 +182,7 @@ func (c *controller) reconcileOnSyncTarget(ctx context.Context, stKey string) er
 				return err
 			}
 			additionalSingles := makeSinglePlacementsForSt(locsFilteredByStAndEp, st)
+			additionalSingles = admitSingles(additionalSingles, stsByRef([]*edgev1alpha1.SyncTarget{st}), currentDests)
 			nextSPS = extendSPS(nextSPS, additionalSingles)
 
 			_, err = c.edgeClusterClient.EdgeV1alpha1().SinglePlacementSlices().Cluster(ws.Path()).Update(ctx, nextSPS, metav1.UpdateOptions{})
@@ -184,6 +197,9 @@ func (c *controller) reconcileOnSyncTarget(ctx context.Context, stKey string) er
 
 	for ep := range epsUsingSt {
 		if _, ok := epsUsedSt[ep]; !ok {
+			if c.enqueueIfSelective(ctx, ep) {
+				continue
+			}
 			// 4c)
 			// for a (new) ep in epsUsingSt but not in epsUsedSt
 			// ensure the existence of sp(s) in the ep's sps
@@ -198,6 +214,7 @@ func (c *controller) reconcileOnSyncTarget(ctx context.Context, stKey string) er
 				logger.Error(err, "failed to get SinglePlacementSlice", "workloadWorkspace", ws, "singlePlacementSlice", name)
 				return err
 			}
+			currentDests := currentSPS.Destinations
 			nextSPS := cleanSPSBySt(currentSPS, stws.String(), stName)
 
 			epObj, err := c.edgePlacementLister.Cluster(ws).Get(name)
@@ -211,6 +228,7 @@ func (c *controller) reconcileOnSyncTarget(ctx context.Context, stKey string) er
 				return err
 			}
 			additionalSingles := makeSinglePlacementsForSt(locsFilteredByStAndEp, st)
+			additionalSingles = admitSingles(additionalSingles, stsByRef([]*edgev1alpha1.SyncTarget{st}), currentDests)
 			nextSPS = extendSPS(nextSPS, additionalSingles)
 
 			_, err = c.edgeClusterClient.EdgeV1alpha1().SinglePlacementSlices().Cluster(ws.Path()).Update(ctx, nextSPS, metav1.UpdateOptions{})
@@ -226,9 +244,13 @@ func (c *controller) reconcileOnSyncTarget(ctx context.Context, stKey string) er
 	return nil
 }
 
-// filterLocsBySt returns those Locations that select the SyncTarget
+// filterLocsBySt returns those Locations that select the SyncTarget;
+// there are none if the SyncTarget is evicted
 func filterLocsBySt(locs []*edgev1alpha1.Location, st *edgev1alpha1.SyncTarget) ([]*edgev1alpha1.Location, error) {
 	filtered := []*edgev1alpha1.Location{}
+	if stIsEvicted(st, time.Now()) {
+		return filtered, nil
+	}
 	for _, l := range locs {
 		s := l.Spec.InstanceSelector
 		selector, err := metav1.LabelSelectorAsSelector(s)
@@ -0,0 +1,59 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package where_resolver
+
+import (
+	"time"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+)
+
+// stIsEvicted tells whether the SyncTarget's EvictAfter time has passed.
+// An evicted SyncTarget is not selected by any Location.
+func stIsEvicted(st *edgev1alpha1.SyncTarget, now time.Time) bool {
+	return st.Spec.EvictAfter != nil && !now.Before(st.Spec.EvictAfter.Time)
+}
+
+// stsByRef indexes the given SyncTargets
+func stsByRef(sts []*edgev1alpha1.SyncTarget) map[stRef]*edgev1alpha1.SyncTarget {
+	ans := make(map[stRef]*edgev1alpha1.SyncTarget, len(sts))
+	for _, st := range sts {
+		ans[stRef{logicalcluster.From(st).String(), st.Name}] = st
+	}
+	return ans
+}
+
+// admitSingles returns those of the given SinglePlacements that may be destinations.
+// A SinglePlacement whose SyncTarget is unschedulable is admitted only if
+// that SyncTarget is among the current destinations.
+func admitSingles(singles []edgev1alpha1.SinglePlacement, sts map[stRef]*edgev1alpha1.SyncTarget, current []edgev1alpha1.SinglePlacement) []edgev1alpha1.SinglePlacement {
+	isCurrent := map[stRef]bool{}
+	for _, sp := range current {
+		isCurrent[stRef{sp.Cluster, sp.SyncTargetName}] = true
+	}
+	ans := []edgev1alpha1.SinglePlacement{}
+	for _, sp := range singles {
+		ref := stRef{sp.Cluster, sp.SyncTargetName}
+		if st := sts[ref]; st != nil && st.Spec.Unschedulable && !isCurrent[ref] {
+			continue
+		}
+		ans = append(ans, sp)
+	}
+	return ans
+}
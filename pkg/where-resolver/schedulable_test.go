@@ -0,0 +1,79 @@
+/*
+Copyright 2023 The KubeStellar Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package where_resolver
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	edgev1alpha1 "github.com/kubestellar/kubestellar/pkg/apis/edge/v1alpha1"
+)
+
+func TestAdmitSingles(t *testing.T) {
+	syncTarget := func(name string, unschedulable bool) *edgev1alpha1.SyncTarget {
+		return &edgev1alpha1.SyncTarget{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: map[string]string{logicalcluster.AnnotationKey: "inv"}},
+			Spec:       edgev1alpha1.SyncTargetSpec{Unschedulable: unschedulable},
+		}
+	}
+	sts := stsByRef([]*edgev1alpha1.SyncTarget{syncTarget("a", false), syncTarget("b", true), syncTarget("c", true)})
+	singles := []edgev1alpha1.SinglePlacement{
+		{Cluster: "inv", LocationName: "l1", SyncTargetName: "a"},
+		{Cluster: "inv", LocationName: "l1", SyncTargetName: "b"},
+		{Cluster: "inv", LocationName: "l2", SyncTargetName: "b"},
+		{Cluster: "inv", LocationName: "l1", SyncTargetName: "c"},
+	}
+	current := []edgev1alpha1.SinglePlacement{{Cluster: "inv", LocationName: "l1", SyncTargetName: "b"}}
+	expected := singles[:3]
+	actual := admitSingles(singles, sts, current)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+}
+
+func TestFilterStsByLocLeavesOutEvicted(t *testing.T) {
+	past := metav1.NewTime(time.Now().Add(-time.Minute))
+	future := metav1.NewTime(time.Now().Add(time.Hour))
+	sts := []*edgev1alpha1.SyncTarget{
+		{ObjectMeta: metav1.ObjectMeta{Name: "a", Labels: map[string]string{"env": "prod"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "b", Labels: map[string]string{"env": "prod"}}, Spec: edgev1alpha1.SyncTargetSpec{EvictAfter: &past}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "c", Labels: map[string]string{"env": "prod"}}, Spec: edgev1alpha1.SyncTargetSpec{EvictAfter: &future}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "d", Labels: map[string]string{"env": "prod"}}, Spec: edgev1alpha1.SyncTargetSpec{Unschedulable: true}},
+	}
+	loc := &edgev1alpha1.Location{Spec: edgev1alpha1.LocationSpec{
+		InstanceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}}}}
+	filtered, err := filterStsByLoc(sts, loc)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	actual := []string{}
+	for _, st := range filtered {
+		actual = append(actual, st.Name)
+	}
+	if expected := []string{"a", "c", "d"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	locs, err := filterLocsBySt([]*edgev1alpha1.Location{loc}, sts[1])
+	if err != nil || len(locs) != 0 {
+		t.Errorf("Expected no Locations for an evicted SyncTarget, got %v, %v", locs, err)
+	}
+}